@@ -43,6 +43,7 @@ func TestIntegration(t *testing.T) {
 			"DirCacheFlush",
 			"UserInfo",
 			"Disconnect",
+			"Hardlink",
 		},
 	}
 	if *fstest.RemoteName == "" {
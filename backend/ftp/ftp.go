@@ -13,7 +13,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/jlaffaye/ftp"
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config"
@@ -22,6 +21,7 @@ import (
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/lib/encoder"
+	ftp "github.com/rclone/rclone/lib/ftpclient"
 	"github.com/rclone/rclone/lib/pacer"
 	"github.com/rclone/rclone/lib/readers"
 )
@@ -81,6 +81,14 @@ to an encrypted one. Cannot be used in combination with implicit FTP.`,
 			Help:     "Disable using EPSV even if server advertises support",
 			Default:  false,
 			Advanced: true,
+		}, {
+			Name: "disable_mfmt",
+			Help: `Disable using MFMT even if server advertises support
+
+If this is set then modification times aren't set on the server or
+used to compare files, as was the case before rclone used MFMT.`,
+			Default:  false,
+			Advanced: true,
 		}, {
 			Name:     config.ConfigEncoding,
 			Help:     config.ConfigEncodingHelp,
@@ -107,6 +115,7 @@ type Options struct {
 	Concurrency       int                  `config:"concurrency"`
 	SkipVerifyTLSCert bool                 `config:"no_check_certificate"`
 	DisableEPSV       bool                 `config:"disable_epsv"`
+	DisableMFMT       bool                 `config:"disable_mfmt"`
 	Enc               encoder.MultiEncoder `config:"encoding"`
 }
 
@@ -123,6 +132,9 @@ type Fs struct {
 	poolMu   sync.Mutex
 	pool     []*ftp.ServerConn
 	tokens   *pacer.TokenDispenser
+	fSetTime bool     // true if the server can set modification times
+	fLstTime bool     // true if the server lists precise modification times
+	hashes   hash.Set // hashes the server can read with HASH
 }
 
 // Object describes an FTP file
@@ -342,6 +354,10 @@ func NewFs(name, root string, m configmap.Mapper) (ff fs.Fs, err error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "NewFs")
 	}
+	// Find out what the server advertised in its FEAT response
+	f.fSetTime = c.IsSetTimeSupported() && !f.opt.DisableMFMT
+	f.fLstTime = c.IsTimePreciseInList()
+	f.hashes = serverHashes(c)
 	f.putFtpConnection(&c, nil)
 	if root != "" {
 		// Check to see if the root actually an existing file
@@ -563,13 +579,19 @@ func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err e
 	return entries, nil
 }
 
-// Hashes are not supported
+// Hashes returns the hashes the server advertised with HASH
 func (f *Fs) Hashes() hash.Set {
-	return 0
+	return f.hashes
 }
 
-// Precision shows Modified Time not supported
+// Precision returns the precision of the modification times
+//
+// Modification times are only supported if the server can both set
+// them (MFMT) and list them precisely (MLSD).
 func (f *Fs) Precision() time.Duration {
+	if f.fSetTime && f.fLstTime {
+		return time.Second
+	}
 	return fs.ModTimeNotSupported
 }
 
@@ -796,7 +818,20 @@ func (o *Object) Remote() string {
 
 // Hash returns the hash of an object returning a lowercase hex string
 func (o *Object) Hash(ctx context.Context, t hash.Type) (string, error) {
-	return "", hash.ErrUnsupported
+	if !o.fs.hashes.Contains(t) {
+		return "", hash.ErrUnsupported
+	}
+	c, err := o.fs.getFtpConnection()
+	if err != nil {
+		return "", errors.Wrap(err, "Hash")
+	}
+	path := path.Join(o.fs.root, o.remote)
+	sum, err := hashFile(c, t, o.fs.opt.Enc.FromStandardPath(path))
+	o.fs.putFtpConnection(&c, err)
+	if err != nil {
+		return "", errors.Wrap(err, "Hash")
+	}
+	return sum, nil
 }
 
 // Size returns the size of an object in bytes
@@ -810,7 +845,23 @@ func (o *Object) ModTime(ctx context.Context) time.Time {
 }
 
 // SetModTime sets the modification time of the object
+//
+// This does nothing if the server doesn't support it
 func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	if !o.fs.fSetTime {
+		return nil
+	}
+	c, err := o.fs.getFtpConnection()
+	if err != nil {
+		return errors.Wrap(err, "SetModTime")
+	}
+	path := path.Join(o.fs.root, o.remote)
+	err = c.SetTime(o.fs.opt.Enc.FromStandardPath(path), modTime)
+	o.fs.putFtpConnection(&c, err)
+	if err != nil {
+		return errors.Wrap(err, "SetModTime")
+	}
+	o.info.ModTime = modTime.Truncate(time.Second)
 	return nil
 }
 
@@ -937,7 +988,15 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		o.fs.putFtpConnection(nil, err)
 		return errors.Wrap(err, "update stor")
 	}
-	o.fs.putFtpConnection(&c, nil)
+	if o.fs.fSetTime {
+		// The upload has worked so don't fail it if the time
+		// can't be set
+		err = c.SetTime(o.fs.opt.Enc.FromStandardPath(path), src.ModTime(ctx))
+		if err != nil {
+			fs.Errorf(o, "Failed to set modification time: %v", err)
+		}
+	}
+	o.fs.putFtpConnection(&c, err)
 	o.info, err = o.fs.getInfo(path)
 	if err != nil {
 		return errors.Wrap(err, "update getinfo")
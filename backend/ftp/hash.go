@@ -0,0 +1,63 @@
+package ftp
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs/hash"
+	ftp "github.com/rclone/rclone/lib/ftpclient"
+)
+
+// ftpHashes maps the names in the HASH command to hash types
+var ftpHashes = map[string]*hash.Type{
+	"SHA-1": &hash.SHA1,
+	"MD5":   &hash.MD5,
+	"CRC32": &hash.CRC32,
+}
+
+// serverHashes returns the hashes the server advertised in the HASH
+// line of its FEAT response
+func serverHashes(c *ftp.ServerConn) (set hash.Set) {
+	line, ok := c.Feature("HASH")
+	if !ok {
+		return set
+	}
+	for _, name := range strings.Split(line, ";") {
+		if ht, ok := ftpHashes[strings.ToUpper(strings.TrimSuffix(name, "*"))]; ok {
+			set.Add(*ht)
+		}
+	}
+	return set
+}
+
+// ftpHashName returns the name of ht in the HASH command
+func ftpHashName(ht hash.Type) string {
+	for name, t := range ftpHashes {
+		if *t == ht {
+			return name
+		}
+	}
+	return ""
+}
+
+// hashFile reads the hash of type ht of the file at path using the
+// HASH command
+//
+// Only call this if serverHashes contains ht.
+func hashFile(c *ftp.ServerConn, ht hash.Type, path string) (string, error) {
+	name := ftpHashName(ht)
+	_, _, err := c.Cmd(ftp.StatusCommandOK, "OPTS HASH %s", name)
+	if err != nil {
+		return "", errors.Wrap(err, "select hash")
+	}
+	_, message, err := c.Cmd(ftp.StatusFile, "HASH %s", path)
+	if err != nil {
+		return "", err
+	}
+	// The reply is "<name> <start>-<end> <hash> <path>"
+	fields := strings.SplitN(message, " ", 4)
+	if len(fields) < 3 || !strings.EqualFold(fields[0], name) {
+		return "", errors.Errorf("bad HASH response %q", message)
+	}
+	return strings.ToLower(fields[2]), nil
+}
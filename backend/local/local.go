@@ -31,7 +31,7 @@ import (
 
 // Constants
 const devUnset = 0xdeadbeefcafebabe                                       // a device id meaning it is unset
-const linkSuffix = ".rclonelink"                                          // The suffix added to a translated symbolic link
+const linkSuffix = fs.LinkSuffix                                          // The suffix added to a translated symbolic link
 const useReadDir = (runtime.GOOS == "windows" || runtime.GOOS == "plan9") // these OSes read FileInfos directly
 
 // Register with Fs
@@ -679,6 +679,58 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	return dstObj, nil
 }
 
+// Hardlink makes src available at remote as a hard link
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantHardlink
+func (f *Fs) Hardlink(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't hard link - not same remote type")
+		return nil, fs.ErrorCantHardlink
+	}
+
+	// Temporary Object under construction
+	dstObj := f.newObject(remote)
+
+	// Refuse to replace anything which exists already
+	err := dstObj.lstat()
+	if err == nil {
+		return nil, errors.New("can't hard link onto existing file")
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Create destination
+	err = dstObj.mkdirAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the link
+	err = os.Link(srcObj.path, dstObj.path)
+	if os.IsNotExist(err) || os.IsPermission(err) {
+		return nil, err
+	} else if err != nil {
+		// probably across file system boundaries or not supported
+		fs.Debugf(src, "Can't hard link: %v", err)
+		return nil, fs.ErrorCantHardlink
+	}
+
+	// Update the info
+	err = dstObj.lstat()
+	if err != nil {
+		return nil, err
+	}
+
+	return dstObj, nil
+}
+
 // DirMove moves src, srcRemote to this remote at dstRemote
 // using server side move operations.
 //
@@ -1259,6 +1311,7 @@ var (
 	_ fs.PutStreamer    = &Fs{}
 	_ fs.Mover          = &Fs{}
 	_ fs.DirMover       = &Fs{}
+	_ fs.Hardlinker     = &Fs{}
 	_ fs.Commander      = &Fs{}
 	_ fs.OpenWriterAter = &Fs{}
 	_ fs.Object         = &Object{}
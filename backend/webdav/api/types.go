@@ -73,6 +73,7 @@ type Prop struct {
 	Size         int64     `xml:"DAV: prop>getcontentlength,omitempty"`
 	Modified     Time      `xml:"DAV: prop>getlastmodified,omitempty"`
 	Checksums    []string  `xml:"prop>checksums>checksum,omitempty"`
+	HashType     string    `xml:"prop>hashtype,omitempty"` // returned by rclone serve webdav
 }
 
 // Parse a status of the form "HTTP/1.1 200 OK" or "HTTP/1.1 200"
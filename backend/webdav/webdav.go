@@ -68,6 +68,9 @@ func init() {
 			}, {
 				Value: "sharepoint",
 				Help:  "Sharepoint",
+			}, {
+				Value: "rclone",
+				Help:  "rclone serve webdav",
 			}, {
 				Value: "other",
 				Help:  "Other site/service or software",
@@ -466,6 +469,10 @@ func (f *Fs) setQuirks(ctx context.Context, vendor string) error {
 		// to determine if we may have found a file, the request has to be resent
 		// with the depth set to 0
 		f.retryWithZeroDepth = true
+	case "rclone":
+		// rclone serve webdav returns the --etag-hash in owncloud
+		// style checksums if set so find out which it is
+		f.readRcloneHashType(ctx)
 	case "other":
 	default:
 		fs.Debugf(f, "Unknown vendor %q", vendor)
@@ -521,6 +528,55 @@ var owncloudProps = []byte(`<?xml version="1.0"?>
 </d:propfind>
 `)
 
+// Read the hash type rclone serve webdav returns in the checksums
+//
+// <r:hashtype>MD5</r:hashtype>
+var rcloneHashTypeProps = []byte(`<?xml version="1.0"?>
+<d:propfind  xmlns:d="DAV:" xmlns:r="http://rclone.org/ns">
+ <d:prop>
+  <r:hashtype />
+ </d:prop>
+</d:propfind>
+`)
+
+// readRcloneHashType asks rclone serve webdav which hash it returns
+// in the checksums and sets hasMD5 or hasSHA1 to match.
+//
+// If the server doesn't say then no hashes are used.
+func (f *Fs) readRcloneHashType(ctx context.Context) {
+	opts := rest.Opts{
+		Method: "PROPFIND",
+		ExtraHeaders: map[string]string{
+			"Depth": "0",
+		},
+		Body:       bytes.NewBuffer(rcloneHashTypeProps),
+		NoRedirect: true,
+	}
+	var result api.Multistatus
+	var resp *http.Response
+	err := f.pacer.Call(func() (bool, error) {
+		var err error
+		resp, err = f.srv.CallXML(ctx, &opts, nil, &result)
+		return f.shouldRetry(resp, err)
+	})
+	if err != nil {
+		fs.Debugf(f, "Failed to read hash type from server: %v", err)
+		return
+	}
+	hashType := ""
+	if len(result.Responses) > 0 && result.Responses[0].Props.StatusOK() {
+		hashType = strings.ToLower(strings.TrimSpace(result.Responses[0].Props.HashType))
+	}
+	switch hashType {
+	case "md5":
+		f.hasMD5 = true
+	case "sha1":
+		f.hasSHA1 = true
+	default:
+		fs.Debugf(f, "Server doesn't return MD5 or SHA1 checksums")
+	}
+}
+
 // list the objects into the function supplied
 //
 // If directories is set it only sends directories
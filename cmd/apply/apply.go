@@ -0,0 +1,59 @@
+package apply
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/sync"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "apply plan.json",
+	Short: `Carry out a plan made by sync --plan.`,
+	Long: `
+Carry out exactly the operations in a plan made with
+` + "`rclone sync --plan plan.json source:path dest:path`" + `.
+
+The source and destination are read from the plan.  Before each
+operation rclone checks that the fingerprints (size, modification
+time and hash where available) of the source and destination files
+are the same as when the plan was made.  If they aren't then that
+operation is refused and reported as an error, and the rest of the
+plan carries on.
+
+Renames are done first, then copies and updates, then deletes, then
+directories are made and removed.  As with sync, nothing is deleted
+if there were errors earlier unless ` + "`--ignore-errors`" + ` is set.
+
+**Important**: Since this can cause data loss, test first with the
+` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		cmd.Run(false, true, command, func() error {
+			return applyPlan(args[0])
+		})
+	},
+}
+
+// applyPlan reads the plan in fileName and applies it
+func applyPlan(fileName string) (err error) {
+	in, err := os.Open(fileName)
+	if err != nil {
+		return errors.Wrap(err, "failed to open plan")
+	}
+	defer fs.CheckClose(in, &err)
+	p, err := sync.ReadPlan(in)
+	if err != nil {
+		return err
+	}
+	return sync.Apply(context.Background(), p)
+}
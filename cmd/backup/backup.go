@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/backup"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOpt = backup.PruneOpt{}
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.AddCommand(pruneCommand)
+	cmdFlags := pruneCommand.Flags()
+	flags.IntVarP(cmdFlags, &pruneOpt.KeepDaily, "keep-daily", "", pruneOpt.KeepDaily, "Keep the last snapshot of this many days")
+	flags.IntVarP(cmdFlags, &pruneOpt.KeepWeekly, "keep-weekly", "", pruneOpt.KeepWeekly, "Keep the last snapshot of this many weeks")
+	flags.IntVarP(cmdFlags, &pruneOpt.KeepMonthly, "keep-monthly", "", pruneOpt.KeepMonthly, "Keep the last snapshot of this many months")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "backup source:path dest:path",
+	Short: `Make a dated snapshot of source in dest.`,
+	Long: `
+Make a new snapshot of source:path in a directory named after today's
+date (eg ` + "`2026-10-16`" + `) inside dest:path.
+
+Each snapshot is a complete copy of the source, but files which are
+unchanged since the previous snapshot aren't uploaded again.  Instead
+they are hard linked from the previous snapshot if dest:path is on the
+local disk, or copied server side if the remote supports it.  Only new
+and changed files are transferred from the source.  Files are compared
+in the same way as ` + "`--compare-dest`" + ` does.
+
+If a snapshot for today exists already it is updated to match the
+source, so running ` + "`rclone backup`" + ` several times a day keeps
+one snapshot per day.
+
+    rclone backup /home/user remote:backups
+
+Old snapshots can be removed with ` + "`rclone backup prune`" + `.
+
+**Note**: Use the ` + "`-P`" + `/` + "`--progress`" + ` flag to view real-time transfer statistics
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc, fdst := cmd.NewFsSrcDst(args)
+		cmd.Run(true, true, command, func() error {
+			_, err := backup.Backup(context.Background(), fdst, fsrc, time.Now())
+			return err
+		})
+	},
+}
+
+var pruneCommand = &cobra.Command{
+	Use:   "prune remote:path",
+	Short: `Remove old snapshots made by rclone backup.`,
+	Long: `
+Remove snapshots made by ` + "`rclone backup`" + ` in remote:path which
+aren't kept by the retention policy.
+
+The policy keeps the newest snapshot in each of the most recent days,
+weeks and months given by the flags.  A snapshot kept by any of the
+flags is not removed.  For example
+
+    rclone backup prune --keep-daily 7 --keep-weekly 4 --keep-monthly 12 remote:backups
+
+keeps a snapshot for each of the last 7 days, the last 4 weeks and the
+last 12 months which have snapshots.  At least one of the flags must
+be set.
+
+**Important**: Since this can cause data loss, test first with the
+` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsDir(args)
+		cmd.Run(true, false, command, func() error {
+			return backup.Prune(context.Background(), f, pruneOpt)
+		})
+	},
+}
@@ -154,6 +154,8 @@ func (fsys *FS) stat(node vfs.Node, stat *fuse.Stat_t) (errc int) {
 	Mode := node.Mode().Perm()
 	if node.IsDir() {
 		Mode |= fuse.S_IFDIR
+	} else if node.Mode()&os.ModeSymlink != 0 {
+		Mode |= fuse.S_IFLNK
 	} else {
 		Mode |= fuse.S_IFREG
 	}
@@ -479,13 +481,23 @@ func (fsys *FS) Link(oldpath string, newpath string) (errc int) {
 // Symlink creates a symbolic link.
 func (fsys *FS) Symlink(target string, newpath string) (errc int) {
 	defer log.Trace(target, "newpath=%q", newpath)("errc=%d", &errc)
-	return -fuse.ENOSYS
+	leaf, parentDir, errc := fsys.lookupParentDir(newpath)
+	if errc != 0 {
+		return errc
+	}
+	_, err := parentDir.Symlink(target, leaf)
+	return translateError(err)
 }
 
 // Readlink reads the target of a symbolic link.
 func (fsys *FS) Readlink(path string) (errc int, linkPath string) {
 	defer log.Trace(path, "")("linkPath=%q, errc=%d", &linkPath, &errc)
-	return -fuse.ENOSYS, ""
+	node, errc := fsys.lookupNode(path)
+	if errc != 0 {
+		return errc, ""
+	}
+	linkPath, err := node.Readlink()
+	return translateError(err), linkPath
 }
 
 // Chmod changes the permission bits of a file.
@@ -518,12 +530,27 @@ func (fsys *FS) Fsyncdir(path string, datasync bool, fh uint64) (errc int) {
 
 // Setxattr sets extended attributes.
 func (fsys *FS) Setxattr(path string, name string, value []byte, flags int) (errc int) {
-	return -fuse.ENOSYS
+	defer log.Trace(path, "name=%q, value=%q", name, value)("errc=%d", &errc)
+	file, errc := fsys.lookupFile(path)
+	if errc != 0 {
+		return errc
+	}
+	return translateError(file.Setxattr(name, value))
 }
 
 // Getxattr gets extended attributes.
 func (fsys *FS) Getxattr(path string, name string) (errc int, value []byte) {
-	return -fuse.ENOSYS, nil
+	defer log.Trace(path, "name=%q", name)("errc=%d", &errc)
+	node, errc := fsys.lookupNode(path)
+	if errc != 0 {
+		return errc, nil
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return -fuse.ENOATTR, nil
+	}
+	value, err := file.Getxattr(name)
+	return translateError(err), value
 }
 
 // Removexattr removes extended attributes.
@@ -533,7 +560,19 @@ func (fsys *FS) Removexattr(path string, name string) (errc int) {
 
 // Listxattr lists extended attributes.
 func (fsys *FS) Listxattr(path string, fill func(name string) bool) (errc int) {
-	return -fuse.ENOSYS
+	defer log.Trace(path, "")("errc=%d", &errc)
+	node, errc := fsys.lookupNode(path)
+	if errc != 0 {
+		return errc
+	}
+	if file, ok := node.(*vfs.File); ok {
+		for _, name := range file.Listxattr() {
+			if !fill(name) {
+				return -fuse.ERANGE
+			}
+		}
+	}
+	return 0
 }
 
 // Translate errors from mountlib
@@ -564,6 +603,14 @@ func translateError(err error) (errc int) {
 		return -fuse.ENOSYS
 	case vfs.EINVAL:
 		return -fuse.EINVAL
+	case vfs.ENOATTR:
+		return -fuse.ENOATTR
+	case vfs.EAGAIN:
+		return -fuse.EAGAIN
+	case vfs.ENOSPC:
+		return -fuse.ENOSPC
+	case vfs.EFBIG:
+		return -fuse.EFBIG
 	}
 	fs.Errorf(nil, "IO error: %v", err)
 	return -fuse.EIO
@@ -12,12 +12,16 @@ import (
 
 var (
 	dedupeMode = operations.DeduplicateInteractive
+	dedupeLink = operations.DedupeLinkNone
+	byHash     = false
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlag := commandDefinition.Flags()
 	flags.FVarP(cmdFlag, &dedupeMode, "dedupe-mode", "", "Dedupe mode interactive|skip|first|newest|oldest|largest|smallest|rename.")
+	flags.BoolVarP(cmdFlag, &byHash, "by-hash", "", byHash, "Find files with the same hash whatever their names.")
+	flags.FVarP(cmdFlag, &dedupeLink, "dedupe-link", "", "With --by-hash replace duplicates with none|symlink|hardlink.")
 }
 
 var commandDefinition = &cobra.Command{
@@ -106,6 +110,40 @@ For example to rename all the identically named photos in your Google Photos dir
 Or
 
     rclone dedupe rename "drive:Google Photos"
+
+### Finding duplicates by hash ###
+
+With the ` + "`--by-hash`" + ` flag ` + "`dedupe`" + ` looks for files with the
+same hash and size anywhere under remote:path, whatever their names.
+This works on any remote which supports hashes, not just those which
+can have duplicate file names.  Each set of identical files is
+reported along with the total space the copies are wasting.
+
+One file from each set is kept, chosen with the ` + "`--dedupe-mode`" + `
+as above.  ` + "`first`" + ` keeps the first file sorted by name and
+` + "`newest`" + ` and ` + "`oldest`" + ` keep the file with the newest or
+oldest modification time.  As all the files in a set are the same size
+` + "`largest`" + ` and ` + "`smallest`" + ` are the same as ` + "`first`" + `.
+` + "`skip`" + ` just reports the duplicates and ` + "`rename`" + ` can't be
+used.
+
+By default the other copies are deleted.  Use
+` + "`--dedupe-link symlink`" + ` to replace each of them with a
+` + "`.rclonelink`" + ` file pointing to the copy kept, which the local
+backend turns into a real symbolic link when used with ` + "`-l`/`--links`" + `.
+On the local backend ` + "`--dedupe-link hardlink`" + ` replaces them with
+hard links to the copy kept.
+
+For example to see how much space duplicates are wasting
+
+    rclone dedupe --by-hash skip remote:path
+
+And to replace the duplicates with hard links to the oldest copy
+
+    rclone dedupe --by-hash --dedupe-link hardlink oldest /path/to/dir
+
+Note that files which are already hard linked to each other will be
+reported as duplicates.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 2, command, args)
@@ -117,7 +155,13 @@ Or
 			args = args[1:]
 		}
 		fdst := cmd.NewFsSrc(args)
+		if dedupeLink != operations.DedupeLinkNone && !byHash {
+			log.Fatalf("Can't use --dedupe-link without --by-hash")
+		}
 		cmd.Run(false, false, command, func() error {
+			if byHash {
+				return operations.DeduplicateByHash(context.Background(), fdst, dedupeMode, dedupeLink)
+			}
 			return operations.Deduplicate(context.Background(), fdst, dedupeMode)
 		})
 	},
@@ -120,6 +120,8 @@ func (d *Dir) ReadDirAll(ctx context.Context) (dirents []fuse.Dirent, err error)
 		}
 		if node.IsDir() {
 			dirent.Type = fuse.DT_Dir
+		} else if node.Mode()&os.ModeSymlink != 0 {
+			dirent.Type = fuse.DT_Link
 		}
 		dirents = append(dirents, dirent)
 	}
@@ -214,3 +216,18 @@ func (d *Dir) Link(ctx context.Context, req *fuse.LinkRequest, old fusefs.Node)
 	defer log.Trace(d, "req=%v, old=%v", req, old)("new=%v, err=%v", &newNode, &err)
 	return nil, fuse.ENOSYS
 }
+
+// Check interface satisfied
+var _ fusefs.NodeSymlinker = (*Dir)(nil)
+
+// Symlink creates a new symbolic link in the receiver, which must be a directory.
+func (d *Dir) Symlink(ctx context.Context, req *fuse.SymlinkRequest) (node fusefs.Node, err error) {
+	defer log.Trace(d, "newName=%q, target=%q", req.NewName, req.Target)("node=%v, err=%v", &node, &err)
+	file, err := d.Dir.Symlink(req.Target, req.NewName)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	node = &File{file, d.fsys}
+	file.SetSys(node) // cache the FUSE node for later
+	return node, nil
+}
@@ -31,6 +31,9 @@ func (f *File) Attr(ctx context.Context, a *fuse.Attr) (err error) {
 	a.Gid = f.VFS().Opt.GID
 	a.Uid = f.VFS().Opt.UID
 	a.Mode = f.VFS().Opt.FilePerms
+	if f.File.IsSymlink() {
+		a.Mode = f.File.Mode()
+	}
 	a.Size = Size
 	a.Atime = modTime
 	a.Mtime = modTime
@@ -40,6 +43,16 @@ func (f *File) Attr(ctx context.Context, a *fuse.Attr) (err error) {
 	return nil
 }
 
+// Check interface satisfied
+var _ fusefs.NodeReadlinker = (*File)(nil)
+
+// Readlink reads the target of a symbolic link
+func (f *File) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (target string, err error) {
+	defer log.Trace(f, "")("target=%q, err=%v", &target, &err)
+	target, err = f.File.Readlink()
+	return target, translateError(err)
+}
+
 // Check interface satisfied
 var _ fusefs.NodeSetattrer = (*File)(nil)
 
@@ -96,23 +109,27 @@ func (f *File) Fsync(ctx context.Context, req *fuse.FsyncRequest) (err error) {
 // node.
 //
 // If there is no xattr by that name, returns fuse.ErrNoXattr.
-func (f *File) Getxattr(ctx context.Context, req *fuse.GetxattrRequest, resp *fuse.GetxattrResponse) error {
-	return fuse.ENOSYS // we never implement this
+func (f *File) Getxattr(ctx context.Context, req *fuse.GetxattrRequest, resp *fuse.GetxattrResponse) (err error) {
+	defer log.Trace(f, "name=%q", req.Name)("err=%v", &err)
+	resp.Xattr, err = f.File.Getxattr(req.Name)
+	return translateError(err)
 }
 
 var _ fusefs.NodeGetxattrer = (*File)(nil)
 
 // Listxattr lists the extended attributes recorded for the node.
 func (f *File) Listxattr(ctx context.Context, req *fuse.ListxattrRequest, resp *fuse.ListxattrResponse) error {
-	return fuse.ENOSYS // we never implement this
+	resp.Append(f.File.Listxattr()...)
+	return nil
 }
 
 var _ fusefs.NodeListxattrer = (*File)(nil)
 
 // Setxattr sets an extended attribute with the given name and
 // value for the node.
-func (f *File) Setxattr(ctx context.Context, req *fuse.SetxattrRequest) error {
-	return fuse.ENOSYS // we never implement this
+func (f *File) Setxattr(ctx context.Context, req *fuse.SetxattrRequest) (err error) {
+	defer log.Trace(f, "name=%q, value=%q", req.Name, req.Xattr)("err=%v", &err)
+	return translateError(f.File.Setxattr(req.Name, req.Xattr))
 }
 
 var _ fusefs.NodeSetxattrer = (*File)(nil)
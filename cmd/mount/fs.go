@@ -98,6 +98,14 @@ func translateError(err error) error {
 		return fuse.ENOSYS
 	case vfs.EINVAL:
 		return fuse.Errno(syscall.EINVAL)
+	case vfs.ENOATTR:
+		return fuse.ErrNoXattr
+	case vfs.EAGAIN:
+		return fuse.Errno(syscall.EAGAIN)
+	case vfs.ENOSPC:
+		return fuse.Errno(syscall.ENOSPC)
+	case vfs.EFBIG:
+		return fuse.Errno(syscall.EFBIG)
 	}
 	return err
 }
@@ -69,6 +69,10 @@ var _ fusefs.HandleFlusher = (*FileHandle)(nil)
 // some writes, or that if will be called at all.
 func (fh *FileHandle) Flush(ctx context.Context, req *fuse.FlushRequest) (err error) {
 	defer log.Trace(fh, "")("err=%v", &err)
+	// Closing any descriptor drops the POSIX locks of its owner
+	if file, ok := fh.Handle.Node().(*vfs.File); ok && req.LockOwner != 0 {
+		file.UnlockAll(uint64(req.LockOwner))
+	}
 	return translateError(fh.Handle.Flush())
 }
 
@@ -80,5 +84,100 @@ var _ fusefs.HandleReleaser = (*FileHandle)(nil)
 // the kernel
 func (fh *FileHandle) Release(ctx context.Context, req *fuse.ReleaseRequest) (err error) {
 	defer log.Trace(fh, "")("err=%v", &err)
+	if file, ok := fh.Handle.Node().(*vfs.File); ok && req.ReleaseFlags&fuse.ReleaseFlockUnlock != 0 {
+		file.UnlockAll(uint64(req.LockOwner))
+	}
 	return translateError(fh.Handle.Release())
 }
+
+// convert a FUSE lock into a vfs.Lock
+func vfsLock(owner fuse.LockOwner, lk fuse.FileLock) vfs.Lock {
+	vlk := vfs.Lock{
+		Owner: uint64(owner),
+		Start: int64(lk.Start),
+		End:   vfs.LockEOF,
+		Type:  vfs.LockShared,
+		PID:   lk.PID,
+	}
+	if lk.End < vfs.LockEOF {
+		vlk.End = int64(lk.End)
+	}
+	if lk.Type == fuse.LockWrite {
+		vlk.Type = vfs.LockExclusive
+	}
+	return vlk
+}
+
+// file returns the vfs.File the handle is open on
+func (fh *FileHandle) file() (*vfs.File, error) {
+	file, ok := fh.Handle.Node().(*vfs.File)
+	if !ok {
+		return nil, vfs.EINVAL
+	}
+	return file, nil
+}
+
+// Check interface satisfied
+var _ fusefs.HandleLocker = (*FileHandle)(nil)
+
+// Lock tries to acquire a lock on a byte range of the node. If a
+// conflicting lock is already held, returns syscall.EAGAIN.
+func (fh *FileHandle) Lock(ctx context.Context, req *fuse.LockRequest) (err error) {
+	defer log.Trace(fh, "req=%v", req)("err=%v", &err)
+	file, err := fh.file()
+	if err != nil {
+		return translateError(err)
+	}
+	return translateError(file.Lock(ctx, vfsLock(req.LockOwner, req.Lock), false))
+}
+
+// LockWait acquires a lock on a byte range of the node, waiting
+// until the lock can be obtained (or context is canceled).
+func (fh *FileHandle) LockWait(ctx context.Context, req *fuse.LockWaitRequest) (err error) {
+	defer log.Trace(fh, "req=%v", req)("err=%v", &err)
+	file, err := fh.file()
+	if err != nil {
+		return translateError(err)
+	}
+	err = file.Lock(ctx, vfsLock(req.LockOwner, req.Lock), true)
+	if err != nil && err == ctx.Err() {
+		return fuse.EINTR
+	}
+	return translateError(err)
+}
+
+// Unlock releases the lock on a byte range of the node.
+func (fh *FileHandle) Unlock(ctx context.Context, req *fuse.UnlockRequest) (err error) {
+	defer log.Trace(fh, "req=%v", req)("err=%v", &err)
+	file, err := fh.file()
+	if err != nil {
+		return translateError(err)
+	}
+	lk := vfsLock(req.LockOwner, req.Lock)
+	file.Unlock(lk.Owner, lk.Start, lk.End)
+	return nil
+}
+
+// QueryLock returns the current state of locks held for the byte
+// range of the node.
+func (fh *FileHandle) QueryLock(ctx context.Context, req *fuse.QueryLockRequest, resp *fuse.QueryLockResponse) (err error) {
+	defer log.Trace(fh, "req=%v", req)("resp=%v, err=%v", &resp, &err)
+	file, err := fh.file()
+	if err != nil {
+		return translateError(err)
+	}
+	conflict, found := file.TestLock(vfsLock(req.LockOwner, req.Lock))
+	if !found {
+		return nil
+	}
+	resp.Lock = fuse.FileLock{
+		Start: uint64(conflict.Start),
+		End:   uint64(conflict.End),
+		Type:  fuse.LockRead,
+		PID:   conflict.PID,
+	}
+	if conflict.Type == vfs.LockExclusive {
+		resp.Lock.Type = fuse.LockWrite
+	}
+	return nil
+}
@@ -28,6 +28,10 @@ func mountOptions(VFS *vfs.VFS, device string, opt *mountlib.Options) (options [
 		fuse.FSName(device),
 		fuse.VolumeName(opt.VolumeName),
 
+		// Pass locks to rclone so they are seen by the VFS
+		fuse.LockingFlock(),
+		fuse.LockingPOSIX(),
+
 		// Options from benchmarking in the fuse module
 		// fuse.MaxReadahead(64 * 1024 * 1024),
 		// fuse.WritebackCache(),
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"syscall"
 
 	fusefs "github.com/hanwen/go-fuse/v2/fs"
@@ -35,6 +36,9 @@ import (
 type FileHandle struct {
 	h    vfs.Handle
 	fsys *FS
+
+	mu     sync.Mutex
+	owners map[uint64]struct{} // lock owners seen on this handle
 }
 
 // Create a new FileHandle
@@ -95,6 +99,16 @@ var _ fusefs.FileFlusher = (*FileHandle)(nil)
 // so any cleanup that requires specific synchronization or
 // could fail with I/O errors should happen in Flush instead.
 func (f *FileHandle) Release(ctx context.Context) syscall.Errno {
+	// go-fuse doesn't tell us the lock owner on release so drop the
+	// locks of every owner seen on this handle
+	if file, ok := f.h.Node().(*vfs.File); ok {
+		f.mu.Lock()
+		for owner := range f.owners {
+			file.UnlockAll(owner)
+		}
+		f.owners = nil
+		f.mu.Unlock()
+	}
 	return translateError(f.h.Release())
 }
 
@@ -149,3 +163,88 @@ func (f *FileHandle) Setattr(ctx context.Context, in *fuse.SetAttrIn, out *fuse.
 }
 
 var _ fusefs.FileSetattrer = (*FileHandle)(nil)
+
+// convert a FUSE lock into a vfs.Lock
+func vfsLock(owner uint64, lk *fuse.FileLock) vfs.Lock {
+	vlk := vfs.Lock{
+		Owner: owner,
+		Start: int64(lk.Start),
+		End:   vfs.LockEOF,
+		Type:  vfs.LockShared,
+		PID:   int32(lk.Pid),
+	}
+	if lk.End < vfs.LockEOF {
+		vlk.End = int64(lk.End)
+	}
+	if lk.Typ == syscall.F_WRLCK {
+		vlk.Type = vfs.LockExclusive
+	}
+	return vlk
+}
+
+// setLock takes or releases the lock lk for owner
+func (f *FileHandle) setLock(ctx context.Context, owner uint64, lk *fuse.FileLock, wait bool) (errno syscall.Errno) {
+	file, ok := f.h.Node().(*vfs.File)
+	if !ok {
+		return syscall.EINVAL
+	}
+	vlk := vfsLock(owner, lk)
+	if lk.Typ == syscall.F_UNLCK {
+		file.Unlock(owner, vlk.Start, vlk.End)
+		return 0
+	}
+	f.mu.Lock()
+	if f.owners == nil {
+		f.owners = make(map[uint64]struct{})
+	}
+	f.owners[owner] = struct{}{}
+	f.mu.Unlock()
+	err := file.Lock(ctx, vlk, wait)
+	if err != nil && err == ctx.Err() {
+		return syscall.EINTR
+	}
+	return translateError(err)
+}
+
+// Getlk returns locks that would conflict with the given input
+// lock. If no locks conflict, the output has type L_UNLCK.
+func (f *FileHandle) Getlk(ctx context.Context, owner uint64, lk *fuse.FileLock, flags uint32, out *fuse.FileLock) (errno syscall.Errno) {
+	defer log.Trace(f, "owner=%d, lk=%+v, flags=%#x", owner, lk, flags)("out=%+v, errno=%v", out, &errno)
+	file, ok := f.h.Node().(*vfs.File)
+	if !ok {
+		return syscall.EINVAL
+	}
+	conflict, found := file.TestLock(vfsLock(owner, lk))
+	if !found {
+		*out = *lk
+		out.Typ = syscall.F_UNLCK
+		return 0
+	}
+	out.Start = uint64(conflict.Start)
+	out.End = uint64(conflict.End)
+	out.Pid = uint32(conflict.PID)
+	out.Typ = syscall.F_RDLCK
+	if conflict.Type == vfs.LockExclusive {
+		out.Typ = syscall.F_WRLCK
+	}
+	return 0
+}
+
+var _ fusefs.FileGetlker = (*FileHandle)(nil)
+
+// Setlk obtains a lock on a file, or fail if the lock could not
+// obtained.
+func (f *FileHandle) Setlk(ctx context.Context, owner uint64, lk *fuse.FileLock, flags uint32) (errno syscall.Errno) {
+	defer log.Trace(f, "owner=%d, lk=%+v, flags=%#x", owner, lk, flags)("errno=%v", &errno)
+	return f.setLock(ctx, owner, lk, false)
+}
+
+var _ fusefs.FileSetlker = (*FileHandle)(nil)
+
+// Setlkw obtains a lock on a file, waiting if necessary.
+func (f *FileHandle) Setlkw(ctx context.Context, owner uint64, lk *fuse.FileLock, flags uint32) (errno syscall.Errno) {
+	defer log.Trace(f, "owner=%d, lk=%+v, flags=%#x", owner, lk, flags)("errno=%v", &errno)
+	return f.setLock(ctx, owner, lk, true)
+}
+
+var _ fusefs.FileSetlkwer = (*FileHandle)(nil)
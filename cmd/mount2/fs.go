@@ -53,6 +53,8 @@ func getMode(node os.FileInfo) uint32 {
 	Mode := node.Mode().Perm()
 	if node.IsDir() {
 		Mode |= fuse.S_IFDIR
+	} else if node.Mode()&os.ModeSymlink != 0 {
+		Mode |= fuse.S_IFLNK
 	} else {
 		Mode |= fuse.S_IFREG
 	}
@@ -126,6 +128,14 @@ func translateError(err error) syscall.Errno {
 		return syscall.ENOSYS
 	case vfs.EINVAL:
 		return syscall.EINVAL
+	case vfs.ENOATTR:
+		return syscall.Errno(fuse.ENOATTR)
+	case vfs.EAGAIN:
+		return syscall.EAGAIN
+	case vfs.ENOSPC:
+		return syscall.ENOSPC
+	case vfs.EFBIG:
+		return syscall.EFBIG
 	}
 	fs.Errorf(nil, "IO error: %v", err)
 	return syscall.EIO
@@ -30,9 +30,10 @@ func mountOptions(fsys *FS, f fs.Fs) (mountOpts *fuse.MountOptions) {
 		AllowOther:    fsys.opt.AllowOther,
 		FsName:        device,
 		Name:          "rclone",
-		DisableXAttrs: true,
+		DisableXAttrs: false,
 		Debug:         fsys.opt.DebugFUSE,
 		MaxReadAhead:  int(fsys.opt.MaxReadAhead),
+		EnableLocks:   true, // pass locks to rclone so they are seen by the VFS
 
 		// RememberInodes: true,
 		// SingleThreaded: true,
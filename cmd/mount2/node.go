@@ -404,3 +404,142 @@ func (n *Node) Rename(ctx context.Context, oldName string, newParent fusefs.Inod
 }
 
 var _ = (fusefs.NodeRenamer)((*Node)(nil))
+
+// Symlink is similar to Lookup, but must create a new
+// symbolic link entry and Inode.
+func (n *Node) Symlink(ctx context.Context, target, name string, out *fuse.EntryOut) (inode *fusefs.Inode, errno syscall.Errno) {
+	defer log.Trace(n, "target=%q, name=%q", target, name)("inode=%v, errno=%v", &inode, &errno)
+	dir, ok := n.node.(*vfs.Dir)
+	if !ok {
+		return nil, syscall.ENOTDIR
+	}
+	file, err := dir.Symlink(target, name)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	newNode := newNode(n.fsys, file)
+	n.fsys.setEntryOut(newNode.node, out)
+	newInode := n.NewInode(ctx, newNode, fusefs.StableAttr{Mode: out.Attr.Mode})
+	return newInode, 0
+}
+
+var _ = (fusefs.NodeSymlinker)((*Node)(nil))
+
+// Readlink reads the content of a symlink.
+func (n *Node) Readlink(ctx context.Context) (target []byte, errno syscall.Errno) {
+	defer log.Trace(n, "")("target=%q, errno=%v", &target, &errno)
+	link, err := n.node.Readlink()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return []byte(link), 0
+}
+
+var _ = (fusefs.NodeReadlinker)((*Node)(nil))
+
+// fileHandle returns f as a *FileHandle for the lock methods
+func fileHandle(f fusefs.FileHandle) (*FileHandle, syscall.Errno) {
+	fh, ok := f.(*FileHandle)
+	if !ok {
+		return nil, syscall.EBADF
+	}
+	return fh, 0
+}
+
+// The lock methods are implemented on the Node as go-fuse only
+// looks for Setlk and Setlkw there, and they call the FileHandle.
+
+// Getlk returns locks that would conflict with the given input
+// lock. If no locks conflict, the output has type L_UNLCK.
+func (n *Node) Getlk(ctx context.Context, f fusefs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32, out *fuse.FileLock) syscall.Errno {
+	fh, errno := fileHandle(f)
+	if errno != 0 {
+		return errno
+	}
+	return fh.Getlk(ctx, owner, lk, flags, out)
+}
+
+var _ = (fusefs.NodeGetlker)((*Node)(nil))
+
+// Setlk obtains a lock on a file, or fail if the lock could not
+// obtained.
+func (n *Node) Setlk(ctx context.Context, f fusefs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+	fh, errno := fileHandle(f)
+	if errno != 0 {
+		return errno
+	}
+	return fh.Setlk(ctx, owner, lk, flags)
+}
+
+var _ = (fusefs.NodeSetlker)((*Node)(nil))
+
+// Setlkw obtains a lock on a file, waiting if necessary.
+func (n *Node) Setlkw(ctx context.Context, f fusefs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+	fh, errno := fileHandle(f)
+	if errno != 0 {
+		return errno
+	}
+	return fh.Setlkw(ctx, owner, lk, flags)
+}
+
+var _ = (fusefs.NodeSetlkwer)((*Node)(nil))
+
+// Getxattr should read data for the given attribute into
+// `dest` and return the number of bytes. If `dest` is too
+// small, it should return ERANGE and the size of the attribute.
+// If not defined, Getxattr will return ENOATTR.
+func (n *Node) Getxattr(ctx context.Context, attr string, dest []byte) (size uint32, errno syscall.Errno) {
+	defer log.Trace(n, "attr=%q", attr)("size=%d, errno=%v", &size, &errno)
+	file, ok := n.node.(*vfs.File)
+	if !ok {
+		return 0, translateError(vfs.ENOATTR)
+	}
+	value, err := file.Getxattr(attr)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return copyXattr(dest, value)
+}
+
+var _ = (fusefs.NodeGetxattrer)((*Node)(nil))
+
+// Setxattr should store data for the given attribute.  See
+// setxattr(2) for information about flags.
+// If not defined, Setxattr will return ENOATTR.
+func (n *Node) Setxattr(ctx context.Context, attr string, data []byte, flags uint32) (errno syscall.Errno) {
+	defer log.Trace(n, "attr=%q, data=%q", attr, data)("errno=%v", &errno)
+	file, ok := n.node.(*vfs.File)
+	if !ok {
+		return syscall.EPERM
+	}
+	return translateError(file.Setxattr(attr, data))
+}
+
+var _ = (fusefs.NodeSetxattrer)((*Node)(nil))
+
+// Listxattr should read all attributes (null terminated) into
+// `dest`. If the `dest` buffer is too small, it should return ERANGE
+// and the correct size.  If not defined, return an empty list and
+// success.
+func (n *Node) Listxattr(ctx context.Context, dest []byte) (size uint32, errno syscall.Errno) {
+	defer log.Trace(n, "")("size=%d, errno=%v", &size, &errno)
+	var value []byte
+	if file, ok := n.node.(*vfs.File); ok {
+		for _, name := range file.Listxattr() {
+			value = append(value, name...)
+			value = append(value, 0)
+		}
+	}
+	return copyXattr(dest, value)
+}
+
+var _ = (fusefs.NodeListxattrer)((*Node)(nil))
+
+// copyXattr copies value into dest returning its size, or ERANGE if
+// dest is too small
+func copyXattr(dest []byte, value []byte) (uint32, syscall.Errno) {
+	if len(dest) < len(value) {
+		return uint32(len(value)), syscall.ERANGE
+	}
+	return uint32(copy(dest, value)), 0
+}
@@ -305,6 +305,25 @@ then there is no chance of corruption.
 
 This is the same as setting the attr_timeout option in mount.fuse.
 
+### Extended attributes
+
+Files in the mount have read only extended attributes holding
+information about the object on the remote, so checksums can be read
+without reading the whole file.
+
+  * ` + "`user.rclone.md5`, `user.rclone.sha1`" + ` etc - each hash the remote supports
+  * ` + "`user.rclone.mimetype`" + ` - the MIME type of the object
+  * ` + "`user.rclone.tier`" + ` - the storage tier if the remote has tiers
+  * ` + "`user.rclone.id`" + ` - the ID of the object if the remote has IDs
+
+For example on Linux
+
+    getfattr -n user.rclone.md5 /path/to/mountpoint/file
+
+Reading a hash may be slow if the remote has to calculate it, as the
+local backend does.  On remotes which support changing the storage
+tier ` + "`user.rclone.tier`" + ` can be set to change it.
+
 ### Filters
 
 Note that all the rclone filters can be used to select a subset of the
@@ -0,0 +1,63 @@
+package prune
+
+import (
+	"context"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+var (
+	opt = operations.PruneOpt{}
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.IntVarP(cmdFlags, &opt.KeepVersions, "keep-versions", "", opt.KeepVersions, "Keep this many newest versions of each file")
+	flags.FVarP(cmdFlags, &opt.KeepWithin, "keep-within", "", "Keep versions younger than this, eg 30d")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "prune remote:path",
+	Short: `Remove old versions of files left by --backup-dir and --suffix.`,
+	Long: `
+Remove old versions of files from remote:path, which is usually the
+directory given to ` + "`--backup-dir`" + `.
+
+Versions of the same file are found by removing the suffix added by
+` + "`--suffix`" + `, so pass the same ` + "`--suffix`" + ` and
+` + "`--suffix-keep-extension`" + ` flags as were used when making
+them.  If ` + "`--suffix`" + ` isn't set then a timestamp suffix in
+exactly the form ` + "`-YYYYMMDD-HHMMSS`" + `, eg ` + "`-20200102-150405`" + `,
+is removed instead.  Files which don't end in a timestamp of that
+form, eg ` + "`report-20200102`" + `, are left alone.  So versions made
+with
+
+    rclone sync --backup-dir remote:old --suffix -$(date +%Y%m%d-%H%M%S) ...
+
+can be pruned with
+
+    rclone prune --keep-versions 5 remote:old
+
+The age of a version is read from its timestamp suffix if it has one,
+otherwise its modification time is used.
+
+Use ` + "`--keep-versions N`" + ` to keep the newest N versions of each
+file and ` + "`--keep-within`" + ` to keep all versions younger than
+the duration given.  If both are set then a version is kept if either
+would keep it.  At least one must be set.
+
+**Important**: Since this can cause data loss, test first with the
+` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsSrc(args)
+		cmd.Run(true, false, command, func() error {
+			return operations.Prune(context.Background(), f, opt)
+		})
+	},
+}
@@ -16,11 +16,16 @@ import (
 	"github.com/anacrolix/dms/soap"
 	"github.com/anacrolix/dms/ssdp"
 	"github.com/anacrolix/dms/upnp"
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/dlna/data"
 	"github.com/rclone/rclone/cmd/serve/dlna/dlnaflags"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
@@ -28,6 +33,7 @@ import (
 func init() {
 	dlnaflags.AddFlags(Command.Flags())
 	vfsflags.AddFlags(Command.Flags())
+	servelib.AddRc("dlna", startRc)
 }
 
 // Command definition for cobra.
@@ -49,7 +55,7 @@ players might show files that they are not able to play back correctly.
 		f := cmd.NewFsSrc(args)
 
 		cmd.Run(false, false, command, func() error {
-			s := newServer(f, &dlnaflags.Opt)
+			s := newServer(f, &dlnaflags.Opt, &vfsflags.Opt)
 			if err := s.Serve(); err != nil {
 				return err
 			}
@@ -90,7 +96,7 @@ type server struct {
 	vfs *vfs.VFS
 }
 
-func newServer(f fs.Fs, opt *dlnaflags.Options) *server {
+func newServer(f fs.Fs, opt *dlnaflags.Options, vfsOpt *vfscommon.Options) *server {
 	friendlyName := opt.FriendlyName
 	if friendlyName == "" {
 		friendlyName = makeDefaultFriendlyName()
@@ -105,7 +111,7 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) *server {
 		httpListenAddr: opt.ListenAddr,
 
 		f:   f,
-		vfs: vfs.New(f, &vfsflags.Opt),
+		vfs: vfs.New(f, vfsOpt),
 	}
 
 	s.services = map[string]UPnPService{
@@ -294,6 +300,38 @@ func (s *server) Close() {
 	close(s.waitChan)
 }
 
+// startRc starts a server for serve/start
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	if proxyOpt.AuthProxy != "" {
+		return nil, errors.New("auth proxy not supported")
+	}
+	opt := dlnaflags.DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = addr
+	s := newServer(f, &opt, vfsOpt)
+	err = s.Serve()
+	if err != nil {
+		s.vfs.Shutdown()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *server) Addr() string {
+	return s.HTTPConn.Addr().String()
+}
+
+// Shutdown stops the server and its VFS
+func (s *server) Shutdown() error {
+	s.Close()
+	s.vfs.Shutdown()
+	return nil
+}
+
 // Run SSDP (multicast for server discovery) on all interfaces.
 func (s *server) startSSDP() {
 	active := 0
@@ -0,0 +1,261 @@
+// +build !plan9,go1.13
+
+package ftp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs/hash"
+	ftp "github.com/rclone/rclone/lib/ftpserver"
+)
+
+// extraCommands are the commands added to the ftp server library
+var extraCommands = map[string]ftp.Command{
+	"FEAT":  commandFeat{},
+	"OPTS":  commandOpts{ftp.BuiltinCommand("OPTS")},
+	"HASH":  commandHash{},
+	"XMD5":  commandXHash{hashName: "MD5"},
+	"XSHA1": commandXHash{hashName: "SHA-1"},
+	"MLSD":  commandMlsd{},
+	"MLST":  commandMlst{},
+	"MFMT":  commandMfmt{},
+}
+
+// ftpHashes are the hashes which can be read with HASH in order of
+// preference
+var ftpHashes = []struct {
+	name string
+	ht   *hash.Type
+}{
+	{"SHA-1", &hash.SHA1},
+	{"MD5", &hash.MD5},
+	{"CRC32", &hash.CRC32},
+}
+
+// findHash returns the hash type called name in the HASH command
+func findHash(name string) (hash.Type, bool) {
+	for _, h := range ftpHashes {
+		if strings.EqualFold(h.name, name) {
+			return *h.ht, true
+		}
+	}
+	return hash.None, false
+}
+
+// driver returns the Driver of conn
+func driver(conn *ftp.Conn) *Driver {
+	d, _ := conn.Driver().(*Driver)
+	return d
+}
+
+// mlsxFacts returns the MLSD and MLST facts of fi
+func mlsxFacts(fi ftp.FileInfo) string {
+	kind := "file"
+	if fi.IsDir() {
+		kind = "dir"
+	}
+	facts := fmt.Sprintf("type=%s;", kind)
+	if !fi.IsDir() {
+		facts += fmt.Sprintf("size=%d;", fi.Size())
+	}
+	facts += fmt.Sprintf("modify=%s;", fi.ModTime().UTC().Format(mfmtTimeFormat))
+	return facts
+}
+
+// mfmtTimeFormat is the time format used by MFMT and the modify fact
+const mfmtTimeFormat = "20060102150405"
+
+// commandFeat responds to FEAT adding the extra commands to the
+// features of the library
+type commandFeat struct{}
+
+func (cmd commandFeat) IsExtend() bool     { return false }
+func (cmd commandFeat) RequireParam() bool { return false }
+func (cmd commandFeat) RequireAuth() bool  { return false }
+
+func (cmd commandFeat) Execute(conn *ftp.Conn, param string) {
+	feats := append([]string{"Extensions supported:"}, conn.Features()...)
+	var names []string
+	if d := driver(conn); d != nil {
+		current := d.currentHash()
+		for _, h := range ftpHashes {
+			if d.hashes().Contains(*h.ht) {
+				name := h.name
+				if *h.ht == current {
+					name += "*"
+				}
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		feats = append(feats, "HASH "+strings.Join(names, ";"))
+	}
+	feats = append(feats, "MLST type*;size*;modify*;", "MFMT", "END")
+	conn.WriteMessageLines(211, feats...)
+}
+
+// commandOpts responds to OPTS HASH passing other options to the
+// library
+type commandOpts struct {
+	ftp.Command
+}
+
+func (cmd commandOpts) Execute(conn *ftp.Conn, param string) {
+	parts := strings.Fields(param)
+	if len(parts) == 0 || !strings.EqualFold(parts[0], "HASH") {
+		cmd.Command.Execute(conn, param)
+		return
+	}
+	d := driver(conn)
+	if d == nil {
+		conn.WriteMessage(550, "Not available")
+		return
+	}
+	if len(parts) > 1 {
+		ht, ok := findHash(parts[1])
+		if !ok || !d.hashes().Contains(ht) {
+			conn.WriteMessage(501, "Unknown algorithm, current selection not changed")
+			return
+		}
+		d.setHash(ht)
+	}
+	for _, h := range ftpHashes {
+		if *h.ht == d.currentHash() {
+			conn.WriteMessage(200, h.name)
+			return
+		}
+	}
+	conn.WriteMessage(550, "No hashes available")
+}
+
+// commandHash responds to HASH with the hash chosen with OPTS HASH
+type commandHash struct{}
+
+func (cmd commandHash) IsExtend() bool     { return false }
+func (cmd commandHash) RequireParam() bool { return true }
+func (cmd commandHash) RequireAuth() bool  { return true }
+
+func (cmd commandHash) Execute(conn *ftp.Conn, param string) {
+	d := driver(conn)
+	ht := d.currentHash()
+	path := conn.BuildPath(param)
+	sum, size, err := d.Hash(path, ht)
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	for _, h := range ftpHashes {
+		if *h.ht == ht {
+			conn.WriteMessage(213, fmt.Sprintf("%s 0-%d %s %s", h.name, size, sum, param))
+			return
+		}
+	}
+}
+
+// commandXHash responds to XMD5 and XSHA1
+type commandXHash struct {
+	hashName string
+}
+
+func (cmd commandXHash) IsExtend() bool     { return false }
+func (cmd commandXHash) RequireParam() bool { return true }
+func (cmd commandXHash) RequireAuth() bool  { return true }
+
+func (cmd commandXHash) Execute(conn *ftp.Conn, param string) {
+	ht, _ := findHash(cmd.hashName)
+	sum, _, err := driver(conn).Hash(conn.BuildPath(param), ht)
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	conn.WriteMessage(250, sum)
+}
+
+// commandMlsd responds to MLSD listing a directory with its facts
+type commandMlsd struct{}
+
+func (cmd commandMlsd) IsExtend() bool     { return false }
+func (cmd commandMlsd) RequireParam() bool { return false }
+func (cmd commandMlsd) RequireAuth() bool  { return true }
+
+func (cmd commandMlsd) Execute(conn *ftp.Conn, param string) {
+	d := driver(conn)
+	path := conn.BuildPath(param)
+	info, err := d.Stat(path)
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	if !info.IsDir() {
+		conn.WriteMessage(501, "Not a directory")
+		return
+	}
+	var b strings.Builder
+	err = d.ListDir(path, func(fi ftp.FileInfo) error {
+		_, err := fmt.Fprintf(&b, "%s %s\r\n", mlsxFacts(fi), fi.Name())
+		return err
+	})
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	conn.WriteMessage(150, "Opening ASCII mode data connection for file list")
+	conn.SendOutofbandData([]byte(b.String()))
+}
+
+// commandMlst responds to MLST with the facts of a file or directory
+type commandMlst struct{}
+
+func (cmd commandMlst) IsExtend() bool     { return false }
+func (cmd commandMlst) RequireParam() bool { return false }
+func (cmd commandMlst) RequireAuth() bool  { return true }
+
+func (cmd commandMlst) Execute(conn *ftp.Conn, param string) {
+	path := conn.BuildPath(param)
+	info, err := driver(conn).Stat(path)
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	conn.WriteMessageLines(250, "Listing "+path, mlsxFacts(info)+" "+path, "End")
+}
+
+// commandMfmt responds to MFMT setting the modification time
+type commandMfmt struct{}
+
+func (cmd commandMfmt) IsExtend() bool     { return false }
+func (cmd commandMfmt) RequireParam() bool { return true }
+func (cmd commandMfmt) RequireAuth() bool  { return true }
+
+func (cmd commandMfmt) Execute(conn *ftp.Conn, param string) {
+	parts := strings.SplitN(param, " ", 2)
+	if len(parts) != 2 {
+		conn.WriteMessage(501, "Syntax error in parameters")
+		return
+	}
+	modTime, err := time.ParseInLocation(mfmtTimeFormat, parts[0], time.UTC)
+	if err != nil {
+		conn.WriteMessage(501, "Bad time")
+		return
+	}
+	err = driver(conn).SetModTime(conn.BuildPath(parts[1]), modTime)
+	if err != nil {
+		conn.WriteMessage(550, err.Error())
+		return
+	}
+	conn.WriteMessage(213, fmt.Sprintf("Modify=%s; %s", parts[0], parts[1]))
+}
+
+// check interfaces
+var (
+	_ ftp.Command = commandFeat{}
+	_ ftp.Command = commandOpts{}
+	_ ftp.Command = commandHash{}
+	_ ftp.Command = commandXHash{}
+	_ ftp.Command = commandMlsd{}
+	_ ftp.Command = commandMlst{}
+	_ ftp.Command = commandMfmt{}
+)
@@ -5,28 +5,34 @@
 package ftp
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"os/user"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/fs/rc"
+	ftp "github.com/rclone/rclone/lib/ftpserver"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	ftp "goftp.io/server/core"
 )
 
 // Options contains options for the http Server
@@ -37,6 +43,10 @@ type Options struct {
 	PassivePorts string // Passive ports range
 	BasicUser    string // single username for basic auth if not using Htpasswd
 	BasicPass    string // password for BasicUser
+	TLSCert      string // TLS PEM key (concatenation of certificate and CA certificate)
+	TLSKey       string // TLS PEM Private key
+	TLSRequired  bool   // refuse commands until the client has used AUTH TLS
+	ImplicitAddr string // Port to listen on for implicit TLS, blank for none
 }
 
 // DefaultOpt is the default values used for Options
@@ -46,6 +56,10 @@ var DefaultOpt = Options{
 	PassivePorts: "30000-32000",
 	BasicUser:    "anonymous",
 	BasicPass:    "",
+	TLSCert:      "",
+	TLSKey:       "",
+	TLSRequired:  false,
+	ImplicitAddr: "",
 }
 
 // Opt is options set by command line flags
@@ -59,12 +73,17 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &Opt.PassivePorts, "passive-port", "", Opt.PassivePorts, "Passive port range to use.")
 	flags.StringVarP(flagSet, &Opt.BasicUser, "user", "", Opt.BasicUser, "User name for authentication.")
 	flags.StringVarP(flagSet, &Opt.BasicPass, "pass", "", Opt.BasicPass, "Password for authentication. (empty value allow every password)")
+	flags.StringVarP(flagSet, &Opt.TLSCert, "cert", "", Opt.TLSCert, "TLS PEM key (concatenation of certificate and CA certificate)")
+	flags.StringVarP(flagSet, &Opt.TLSKey, "key", "", Opt.TLSKey, "TLS PEM Private key")
+	flags.BoolVarP(flagSet, &Opt.TLSRequired, "tls-required", "", Opt.TLSRequired, "Refuse clients which don't use AUTH TLS.")
+	flags.StringVarP(flagSet, &Opt.ImplicitAddr, "implicit-tls-addr", "", Opt.ImplicitAddr, "IPaddress:Port or :Port to serve implicit TLS on.")
 }
 
 func init() {
 	vfsflags.AddFlags(Command.Flags())
 	proxyflags.AddFlags(Command.Flags())
 	AddFlags(Command.Flags())
+	servelib.AddRc("ftp", startRc)
 }
 
 // Command definition for cobra
@@ -91,6 +110,35 @@ then using Authentication is advised - see the next section for info.
 By default this will serve files without needing a login.
 
 You can set a single username and password with the --user and --pass flags.
+
+#### TLS
+
+By default the FTP server sends passwords and data unencrypted. Use
+--cert and --key to give it a TLS certificate and private key in PEM
+format and the server will accept AUTH TLS from clients (explicit
+FTPS). Clients which have done this must use PROT P and their data
+connections are encrypted too.
+
+Clients which don't use AUTH TLS are still allowed unless
+--tls-required is set, in which case they can't log in or do anything
+else until they switch to TLS.
+
+Use --implicit-tls-addr to listen on another address for clients using
+implicit FTPS, where the connection is encrypted from the start. This
+is usually port 990, eg --implicit-tls-addr :990.
+
+For an rclone ftp remote use explicit_tls to connect to --addr and tls
+to connect to --implicit-tls-addr.
+
+#### Modification times and hashes
+
+The server supports MLSD and MLST for listing with precise
+modification times and MFMT for setting them, so these are preserved
+when using an rclone ftp remote to connect to it.
+
+It also supports HASH, with the hash chosen with OPTS HASH, and XMD5
+and XSHA1 to read the hashes the remote supports out of SHA-1, MD5 and
+CRC32. An rclone ftp remote will use these for checksums.
 ` + vfs.Help + proxy.Help,
 	Run: func(command *cobra.Command, args []string) {
 		var f fs.Fs
@@ -101,7 +149,7 @@ You can set a single username and password with the --user and --pass flags.
 			cmd.CheckArgs(0, 0, command, args)
 		}
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(f, &Opt)
+			s, err := newServer(f, &Opt, &vfsflags.Opt, &proxyflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -112,32 +160,49 @@ You can set a single username and password with the --user and --pass flags.
 
 // server contains everything to run the server
 type server struct {
-	f     fs.Fs
-	srv   *ftp.Server
-	opt   Options
-	vfs   *vfs.VFS
-	proxy *proxy.Proxy
+	f           fs.Fs
+	srv         *ftp.Server
+	implicitSrv *ftp.Server // serves implicit TLS if set
+	opt         Options
+	vfs         *vfs.VFS
+	proxy       *proxy.Proxy
 }
 
-// Make a new FTP to serve the remote
-func newServer(f fs.Fs, opt *Options) (*server, error) {
-	host, port, err := net.SplitHostPort(opt.ListenAddr)
+// splitHostPort splits addr into host and port number
+func splitHostPort(addr string) (host string, portNum int, err error) {
+	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
-		return nil, errors.New("Failed to parse host:port")
+		return "", 0, errors.New("Failed to parse host:port")
 	}
-	portNum, err := strconv.Atoi(port)
+	portNum, err = strconv.Atoi(port)
 	if err != nil {
-		return nil, errors.New("Failed to parse host:port")
+		return "", 0, errors.New("Failed to parse host:port")
+	}
+	return host, portNum, nil
+}
+
+// Make a new FTP to serve the remote
+func newServer(f fs.Fs, opt *Options, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options) (*server, error) {
+	host, portNum, err := splitHostPort(opt.ListenAddr)
+	if err != nil {
+		return nil, err
+	}
+	useTLS := opt.TLSCert != "" || opt.TLSKey != ""
+	if useTLS && (opt.TLSCert == "" || opt.TLSKey == "") {
+		return nil, errors.New("need both --cert and --key to use TLS")
+	}
+	if !useTLS && (opt.TLSRequired || opt.ImplicitAddr != "") {
+		return nil, errors.New("need --cert and --key to use TLS")
 	}
 
 	s := &server{
 		f:   f,
 		opt: *opt,
 	}
-	if proxyflags.Opt.AuthProxy != "" {
-		s.proxy = proxy.New(&proxyflags.Opt)
+	if proxyOpt.AuthProxy != "" {
+		s.proxy = proxy.New(proxyOpt)
 	} else {
-		s.vfs = vfs.New(f, &vfsflags.Opt)
+		s.vfs = vfs.New(f, vfsOpt)
 	}
 
 	ftpopt := &ftp.ServerOpts{
@@ -150,22 +215,130 @@ func newServer(f fs.Fs, opt *Options) (*server, error) {
 		PassivePorts:   opt.PassivePorts,
 		Auth:           s, // implemented by CheckPasswd method
 		Logger:         &Logger{},
+		TLS:            useTLS,
+		CertFile:       opt.TLSCert,
+		KeyFile:        opt.TLSKey,
+		ExplicitFTPS:   true,
+		ForceTLS:       opt.TLSRequired,
+		Commands:       extraCommands,
 		// TODO implement a maximum of https://godoc.org/goftp.io/server#ServerOpts
 	}
 	s.srv = ftp.NewServer(ftpopt)
+
+	if opt.ImplicitAddr != "" {
+		implicitOpt := *ftpopt
+		implicitOpt.Hostname, implicitOpt.Port, err = splitHostPort(opt.ImplicitAddr)
+		if err != nil {
+			return nil, err
+		}
+		implicitOpt.ExplicitFTPS = false
+		s.implicitSrv = ftp.NewServer(&implicitOpt)
+	}
 	return s, nil
 }
 
 // serve runs the ftp server
 func (s *server) serve() error {
+	errs := make(chan error, 1)
+	if s.implicitSrv != nil {
+		fs.Logf(s.f, "Serving FTP with implicit TLS on %s", s.implicitSrv.Hostname+":"+strconv.Itoa(s.implicitSrv.Port))
+		go func() {
+			err := s.implicitSrv.ListenAndServe()
+			if err != ftp.ErrServerClosed {
+				// stop the main server if this one failed
+				_ = s.srv.Shutdown()
+			}
+			errs <- err
+		}()
+	}
 	fs.Logf(s.f, "Serving FTP on %s", s.srv.Hostname+":"+strconv.Itoa(s.srv.Port))
-	return s.srv.ListenAndServe()
+	err := s.srv.ListenAndServe()
+	if s.implicitSrv == nil {
+		return err
+	}
+	if err != ftp.ErrServerClosed {
+		_ = s.implicitSrv.Shutdown()
+		return err
+	}
+	return <-errs
 }
 
 // serve runs the ftp server
 func (s *server) close() error {
 	fs.Logf(s.f, "Stopping FTP on %s", s.srv.Hostname+":"+strconv.Itoa(s.srv.Port))
-	return s.srv.Shutdown()
+	err := s.srv.Shutdown()
+	if s.implicitSrv != nil {
+		implicitErr := s.implicitSrv.Shutdown()
+		if err == nil {
+			err = implicitErr
+		}
+	}
+	return err
+}
+
+// how long to wait for the server to fail when started by serve/start
+var startupTime = 250 * time.Millisecond
+
+// rcServer is a server started by serve/start
+type rcServer struct {
+	*server
+	done chan struct{} // closed when the server has stopped
+}
+
+// startRc starts a server for serve/start
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	opt := DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = addr
+	s, err := newServer(f, &opt, vfsOpt, proxyOpt)
+	if err != nil {
+		return nil, err
+	}
+	r := &rcServer{
+		server: s,
+		done:   make(chan struct{}),
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.serve()
+		close(r.done)
+	}()
+	// goftp doesn't say when it is listening so wait a short
+	// time to see if it fails to start
+	select {
+	case err = <-errChan:
+		s.shutdownVFS()
+		return nil, err
+	case <-time.After(startupTime):
+	}
+	return r, nil
+}
+
+// Wait blocks until the server has stopped
+func (r *rcServer) Wait() {
+	<-r.done
+}
+
+// Addr returns the address the server is listening on
+func (s *server) Addr() string {
+	return net.JoinHostPort(s.srv.Hostname, strconv.Itoa(s.srv.Port))
+}
+
+// Shutdown stops the server and its VFS
+func (s *server) Shutdown() error {
+	err := s.close()
+	s.shutdownVFS()
+	return err
+}
+
+// shutdownVFS stops the VFS if it isn't from the proxy
+func (s *server) shutdownVFS() {
+	if s.vfs != nil {
+		s.vfs.Shutdown()
+	}
 }
 
 //Logger ftp logger output formatted message
@@ -216,9 +389,11 @@ func (s *server) NewDriver() (ftp.Driver, error) {
 
 // Driver implementation of ftp server
 type Driver struct {
-	s    *server
-	vfs  *vfs.VFS
-	lock sync.Mutex
+	s        *server
+	vfs      *vfs.VFS
+	lock     sync.Mutex
+	hashType hash.Type   // hash chosen with OPTS HASH if set
+	user     *proxy.User // proxy user whose connection this is if set
 }
 
 // CheckPasswd handle auth based on configuration
@@ -231,6 +406,11 @@ func (d *Driver) CheckPasswd(user, pass string) (ok bool, err error) {
 			fs.Infof(nil, "proxy login failed: %v", err)
 			return false, nil
 		}
+		err = d.connect(user)
+		if err != nil {
+			fs.Infof(nil, "proxy login failed: %v", err)
+			return false, nil
+		}
 		d.vfs = VFS
 	} else {
 		ok = s.opt.BasicUser == user && (s.opt.BasicPass == "" || s.opt.BasicPass == pass)
@@ -429,11 +609,15 @@ func (d *Driver) PutFile(path string, data io.Reader, appendData bool) (n int64,
 		if err != nil {
 			return 0, err
 		}
-		defer closeIO(path, f)
 		bytes, err := io.Copy(f, data)
+		// the upload may only fail on close, eg if it is over quota
+		closeErr := f.Close()
 		if err != nil {
 			return 0, err
 		}
+		if closeErr != nil {
+			return 0, closeErr
+		}
 		return bytes, nil
 	}
 
@@ -441,21 +625,103 @@ func (d *Driver) PutFile(path string, data io.Reader, appendData bool) (n int64,
 	if err != nil {
 		return 0, err
 	}
-	defer closeIO(path, of)
-
 	_, err = of.Seek(0, os.SEEK_END)
 	if err != nil {
+		closeIO(path, of)
 		return 0, err
 	}
 
 	bytes, err := io.Copy(of, data)
+	closeErr := of.Close()
 	if err != nil {
 		return 0, err
 	}
+	if closeErr != nil {
+		return 0, closeErr
+	}
 
 	return bytes, nil
 }
 
+// hashes returns the hashes which can be read with HASH
+//
+// Until the user has logged in with the auth proxy the remote isn't
+// known so all of them are returned.
+func (d *Driver) hashes() (set hash.Set) {
+	for _, h := range ftpHashes {
+		if d.vfs == nil || d.vfs.Fs().Hashes().Contains(*h.ht) {
+			set.Add(*h.ht)
+		}
+	}
+	return set
+}
+
+// currentHash returns the hash HASH reads
+func (d *Driver) currentHash() hash.Type {
+	hashes := d.hashes()
+	if d.hashType != hash.None && hashes.Contains(d.hashType) {
+		return d.hashType
+	}
+	for _, h := range ftpHashes {
+		if hashes.Contains(*h.ht) {
+			return *h.ht
+		}
+	}
+	return hash.None
+}
+
+// setHash sets the hash HASH reads
+func (d *Driver) setHash(ht hash.Type) {
+	d.hashType = ht
+}
+
+// Hash reads the hash of type ht of a file and its size
+func (d *Driver) Hash(path string, ht hash.Type) (sum string, size int64, err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	defer log.Trace(path, "hashType=%v", ht)("sum=%q, err = %v", &sum, &err)
+	if ht == hash.None || !d.vfs.Fs().Hashes().Contains(ht) {
+		return "", 0, errors.New("Hash not supported")
+	}
+	node, err := d.vfs.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if !node.IsFile() {
+		return "", 0, errors.New("Not a file")
+	}
+	// The object may not have caught up with a rename of its
+	// directory so find it again if its path is out of date
+	remote := strings.Trim(path, "/")
+	o, ok := node.DirEntry().(fs.Object)
+	if !ok || o.Remote() != remote {
+		o, err = d.vfs.Fs().NewObject(context.TODO(), remote)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	sum, err = o.Hash(context.TODO(), ht)
+	if err != nil {
+		return "", 0, err
+	}
+	if sum == "" {
+		return "", 0, errors.New("Hash not available")
+	}
+	return sum, node.Size(), nil
+}
+
+// SetModTime sets the modification time of a file or directory
+func (d *Driver) SetModTime(path string, modTime time.Time) (err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	defer log.Trace(path, "modTime=%v", modTime)("err = %v", &err)
+	node, err := d.vfs.Stat(path)
+	if err != nil {
+		return err
+	}
+	return node.SetModTime(modTime)
+}
+
 //FileInfo struct to hold file info for ftp server
 type FileInfo struct {
 	os.FileInfo
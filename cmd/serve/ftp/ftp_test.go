@@ -8,15 +8,32 @@
 package ftp
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	ftpbackend "github.com/rclone/rclone/backend/ftp"
 	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/cmd/serve/servetest"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/hash"
+	ftp "github.com/rclone/rclone/lib/ftpserver"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
-	ftp "goftp.io/server/core"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -38,7 +55,7 @@ func TestFTP(t *testing.T) {
 		opt.BasicUser = testUSER
 		opt.BasicPass = testPASS
 
-		w, err := newServer(f, &opt)
+		w, err := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
 		assert.NoError(t, err)
 
 		quit := make(chan struct{})
@@ -68,3 +85,212 @@ func TestFTP(t *testing.T) {
 
 	servetest.Run(t, "ftp", start)
 }
+
+// writeTestCert writes a self signed certificate and key for
+// localhost into dir returning their paths
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: testHOST},
+		DNSNames:     []string{testHOST},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+// TestFTPTLS checks the ftp remote can use explicit and implicit TLS
+// with the server.
+func TestFTPTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-ftp-tls")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.Mkdir(root, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "file.txt"), []byte("hello"), 0600))
+	f, err := fs.NewFs(root)
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.ListenAddr = testHOST + ":51781"
+	opt.ImplicitAddr = testHOST + ":51782"
+	opt.PassivePorts = testPASSIVEPORTRANGE
+	opt.BasicUser = testUSER
+	opt.BasicPass = testPASS
+	opt.TLSCert, opt.TLSKey = writeTestCert(t, dir)
+	opt.TLSRequired = true
+
+	w, err := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, err)
+	quit := make(chan struct{})
+	go func() {
+		err := w.serve()
+		close(quit)
+		if err != ftp.ErrServerClosed {
+			assert.NoError(t, err)
+		}
+	}()
+	defer func() {
+		assert.NoError(t, w.close())
+		<-quit
+	}()
+
+	ctx := context.Background()
+	connect := func(port string, extra configmap.Simple) (fs.Fs, error) {
+		m := configmap.Simple{
+			"host":                 testHOST,
+			"port":                 port,
+			"user":                 testUSER,
+			"pass":                 obscure.MustObscure(testPASS),
+			"no_check_certificate": "true",
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		return ftpbackend.NewFs("ftptls", "", m)
+	}
+	for _, test := range []struct {
+		name  string
+		port  string
+		extra configmap.Simple
+	}{
+		{"Explicit", "51781", configmap.Simple{"explicit_tls": "true"}},
+		{"Implicit", "51782", configmap.Simple{"tls": "true"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// Wait for the server to start
+			var fremote fs.Fs
+			for i := 0; i < 50; i++ {
+				fremote, err = connect(test.port, test.extra)
+				if err == nil {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			require.NoError(t, err)
+
+			// Listing and reading use the encrypted data connection
+			entries, err := fremote.List(ctx, "")
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			o, err := fremote.NewObject(ctx, "file.txt")
+			require.NoError(t, err)
+			in, err := o.Open(ctx)
+			require.NoError(t, err)
+			data, err := ioutil.ReadAll(in)
+			require.NoError(t, err)
+			require.NoError(t, in.Close())
+			assert.Equal(t, "hello", string(data))
+		})
+	}
+
+	// Without TLS the server refuses to log in
+	fplain, err := connect("51781", nil)
+	if err == nil {
+		_, err = fplain.List(ctx, "")
+	}
+	assert.Error(t, err)
+}
+
+// TestDriverClose checks the auth proxy connection is released when
+// the driver is closed.
+func TestDriverClose(t *testing.T) {
+	d := &Driver{}
+	require.NoError(t, d.connect("unknown-user"))
+	assert.Nil(t, d.user)
+	assert.NoError(t, d.Close())
+}
+
+// TestFTPExtensions checks the ftp remote uses HASH, MLSD and MFMT
+// with the server.
+func TestFTPExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-ftp-ext")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0600))
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.ListenAddr = testHOST + ":51783"
+	opt.PassivePorts = testPASSIVEPORTRANGE
+	opt.BasicUser = testUSER
+	opt.BasicPass = testPASS
+	w, err := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, err)
+	quit := make(chan struct{})
+	go func() {
+		err := w.serve()
+		close(quit)
+		if err != ftp.ErrServerClosed {
+			assert.NoError(t, err)
+		}
+	}()
+	defer func() {
+		assert.NoError(t, w.close())
+		<-quit
+	}()
+
+	// Wait for the server to start
+	var fremote fs.Fs
+	for i := 0; i < 50; i++ {
+		fremote, err = ftpbackend.NewFs("ftpext", "", configmap.Simple{
+			"host": testHOST,
+			"port": "51783",
+			"user": testUSER,
+			"pass": obscure.MustObscure(testPASS),
+		})
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+
+	assert.True(t, fremote.Hashes().Contains(hash.SHA1))
+	assert.True(t, fremote.Hashes().Contains(hash.MD5))
+	assert.Equal(t, time.Second, fremote.Precision())
+
+	ctx := context.Background()
+	o, err := fremote.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+	sum, err := o.Hash(ctx, hash.SHA1)
+	require.NoError(t, err)
+	assert.Equal(t, "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", sum)
+	sum, err = o.Hash(ctx, hash.MD5)
+	require.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", sum)
+
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, o.SetModTime(ctx, modTime))
+	o, err = fremote.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+	assert.True(t, modTime.Equal(o.ModTime(ctx)), o.ModTime(ctx))
+
+	// Modification times can be turned off
+	fremote, err = ftpbackend.NewFs("ftpext", "", configmap.Simple{
+		"host":         testHOST,
+		"port":         "51783",
+		"user":         testUSER,
+		"pass":         obscure.MustObscure(testPASS),
+		"disable_mfmt": "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModTimeNotSupported, fremote.Precision())
+}
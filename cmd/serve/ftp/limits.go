@@ -0,0 +1,43 @@
+// +build !plan9,go1.13
+
+package ftp
+
+import (
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	ftp "github.com/rclone/rclone/lib/ftpserver"
+)
+
+// check interface
+var _ ftp.DriverCloser = (*Driver)(nil)
+
+// connect counts a connection for the proxy user called userName,
+// releasing the one from any earlier login on this connection.
+//
+// The connection is released by Close when the client disconnects.
+func (d *Driver) connect(userName string) error {
+	u := proxy.GetUser(userName)
+	if u != nil {
+		err := u.Connect()
+		if err != nil {
+			return err
+		}
+	}
+	d.disconnect()
+	d.user = u
+	return nil
+}
+
+// disconnect releases the connection of the proxy user if any
+func (d *Driver) disconnect() {
+	if d.user != nil {
+		d.user.Disconnect()
+		d.user = nil
+	}
+}
+
+// Close is called by the ftp server library when the connection the
+// Driver was made for has ended.
+func (d *Driver) Close() error {
+	d.disconnect()
+	return nil
+}
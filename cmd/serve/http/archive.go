@@ -0,0 +1,263 @@
+package http
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// archiveWriter writes the entries of an archive
+type archiveWriter interface {
+	addDir(name string, modTime time.Time) error
+	addFile(name string, size int64, modTime time.Time, in io.Reader) error
+	addLink(name string, target string, modTime time.Time) error
+	Close() error
+}
+
+// archiveFormat describes a type of archive which can be downloaded
+type archiveFormat struct {
+	ext         string                        // file extension
+	contentType string                        // MIME type
+	new         func(io.Writer) archiveWriter // make an archiveWriter
+}
+
+// archiveFormats are the archives which can be downloaded by the
+// value of the download parameter
+var archiveFormats = map[string]archiveFormat{
+	"zip":    {ext: ".zip", contentType: "application/zip", new: newZipArchive},
+	"tar.gz": {ext: ".tar.gz", contentType: "application/gzip", new: newTarArchive},
+}
+
+// zipArchive writes a zip file
+type zipArchive struct {
+	*zip.Writer
+}
+
+func newZipArchive(out io.Writer) archiveWriter {
+	return zipArchive{zip.NewWriter(out)}
+}
+
+func (a zipArchive) addDir(name string, modTime time.Time) error {
+	fh := &zip.FileHeader{Name: name + "/", Modified: modTime, Method: zip.Store}
+	fh.SetMode(os.ModeDir | 0755)
+	_, err := a.CreateHeader(fh)
+	return err
+}
+
+func (a zipArchive) addFile(name string, size int64, modTime time.Time, in io.Reader) error {
+	fh := &zip.FileHeader{Name: name, Modified: modTime, Method: zip.Deflate}
+	fh.SetMode(0644)
+	out, err := a.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func (a zipArchive) addLink(name string, target string, modTime time.Time) error {
+	fh := &zip.FileHeader{Name: name, Modified: modTime, Method: zip.Store}
+	fh.SetMode(os.ModeSymlink | 0777)
+	out, err := a.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, target)
+	return err
+}
+
+// tarArchive writes a gzipped tar file
+type tarArchive struct {
+	gz *gzip.Writer
+	*tar.Writer
+}
+
+func newTarArchive(out io.Writer) archiveWriter {
+	gz := gzip.NewWriter(out)
+	return tarArchive{gz: gz, Writer: tar.NewWriter(gz)}
+}
+
+func (a tarArchive) addDir(name string, modTime time.Time) error {
+	return a.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  modTime,
+	})
+}
+
+func (a tarArchive) addFile(name string, size int64, modTime time.Time, in io.Reader) error {
+	err := a.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	// The size is in the header so write exactly that much
+	_, err = io.CopyN(a.Writer, in, size)
+	return err
+}
+
+func (a tarArchive) addLink(name string, target string, modTime time.Time) error {
+	return a.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     0777,
+		ModTime:  modTime,
+	})
+}
+
+func (a tarArchive) Close() error {
+	err := a.Writer.Close()
+	gzErr := a.gz.Close()
+	if err == nil {
+		err = gzErr
+	}
+	return err
+}
+
+// serveArchive sends the directory dirRemote and everything in it as
+// an archive of the format in the download parameter, building it as
+// it goes
+func (s *server) serveArchive(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
+	format, ok := archiveFormats[r.URL.Query().Get("download")]
+	if !ok {
+		http.Error(w, "Unknown download format", http.StatusBadRequest)
+		return
+	}
+	node, err := VFS.Stat(dirRemote)
+	if err == vfs.ENOENT {
+		http.Error(w, "Directory not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serve.Error(dirRemote, w, "Failed to find directory", err)
+		return
+	}
+	if !node.IsDir() {
+		http.Error(w, "Not a directory", http.StatusNotFound)
+		return
+	}
+	dir := node.(*vfs.Dir)
+
+	// Everything goes in a directory named after the one downloaded
+	name := path.Base("/" + dirRemote)
+	if name == "/" {
+		name = "download"
+	}
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + format.ext}))
+	if r.Method == "HEAD" {
+		return
+	}
+
+	fs.Infof(dirRemote, "%s: Serving directory as %s", r.RemoteAddr, name+format.ext)
+	b := &archiveBuilder{
+		ctx:     r.Context(),
+		archive: format.new(w),
+	}
+	err = b.archive.addDir(name, dir.ModTime())
+	if err == nil {
+		err = b.addDir(dir, name)
+	}
+	closeErr := b.archive.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// The headers have been sent so all we can do is stop - the
+		// client will see a truncated archive
+		fs.Errorf(dirRemote, "Failed to send archive: %v", err)
+	}
+}
+
+// archiveBuilder adds the contents of directories to an archive
+type archiveBuilder struct {
+	ctx     context.Context
+	archive archiveWriter
+}
+
+// addDir adds the contents of dir to the archive under prefix
+func (b *archiveBuilder) addDir(dir *vfs.Dir, prefix string) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %q", dir.Path())
+	}
+	for _, node := range nodes {
+		if b.ctx.Err() != nil {
+			return b.ctx.Err()
+		}
+		name := path.Join(prefix, node.Name())
+		switch x := node.(type) {
+		case *vfs.Dir:
+			err = b.archive.addDir(name, x.ModTime())
+			if err == nil {
+				err = b.addDir(x, name)
+			}
+		case *vfs.File:
+			err = b.addFile(x, name)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to add %q", node.Path())
+		}
+	}
+	return nil
+}
+
+// open opens file for reading into the archive.
+//
+// Without the VFS cache the object is read directly and accounted
+// here so the archive is limited by --bwlimit and shown in the
+// stats. With the cache the file is read through the VFS which
+// accounts any reads it needs to make from the remote itself.
+func (b *archiveBuilder) open(file *vfs.File) (in io.ReadCloser, done func(error), err error) {
+	o, ok := file.DirEntry().(fs.Object)
+	if !ok || o == nil || o.Size() != file.Size() || file.VFS().Opt.CacheMode != vfscommon.CacheModeOff {
+		in, err = file.Open(os.O_RDONLY)
+		return in, func(error) {}, err
+	}
+	in, err = o.Open(b.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr := accounting.Stats(b.ctx).NewTransfer(o)
+	return tr.Account(in), tr.Done, nil
+}
+
+// addFile adds file to the archive as name
+func (b *archiveBuilder) addFile(file *vfs.File, name string) (err error) {
+	if file.IsSymlink() {
+		target, err := file.Readlink()
+		if err != nil {
+			return err
+		}
+		return b.archive.addLink(name, target, file.ModTime())
+	}
+	in, done, err := b.open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		done(err)
+	}()
+	defer fs.CheckClose(in, &err)
+	return b.archive.addFile(name, file.Size(), file.ModTime(), in)
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"path"
@@ -8,20 +9,48 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
 	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
+	"github.com/rclone/rclone/cmd/serve/share"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
 
+// Options required for http server
+type Options struct {
+	httplib.Options
+	ReadWrite bool // allow uploading, making directories, deleting and renaming
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	Options:   httplib.DefaultOpt,
+	ReadWrite: false,
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
 func init() {
-	httpflags.AddFlags(Command.Flags())
-	vfsflags.AddFlags(Command.Flags())
+	flagSet := Command.Flags()
+	httpflags.AddFlags(flagSet)
+	vfsflags.AddFlags(flagSet)
+	proxyflags.AddFlags(flagSet)
+	flags.BoolVarP(flagSet, &Opt.ReadWrite, "read-write", "", Opt.ReadWrite, "Allow uploading, making directories, deleting and renaming")
+	servelib.AddRc("http", startRc)
 }
 
 // Command definition for cobra
@@ -39,12 +68,67 @@ The server will log errors.  Use -v to see access logs.
 
 --bwlimit will be respected for file transfers.  Use --stats to
 control the stats printing.
-` + httplib.Help + vfs.Help,
+
+Any directory can be downloaded as a single archive by adding
+?download=zip or ?download=tar.gz to its URL, or by using the links
+at the top of the directory listing. The archive is built on the fly
+as it is sent.
+
+### JSON listings
+
+Directory listings are returned as JSON instead of HTML if the client
+sends "Accept: application/json" or adds ?format=json to the URL.
+Each entry has its name, url, size, modTime, isDir and mimeType. Add
+?hashes=md5,sha1 (or ?hashes=all) to include the hashes of files -
+these may be slow to read on some remotes.
+
+These parameters work with both HTML and JSON listings
+
+- sort=name|namedirfirst|size|time and order=asc|desc to sort
+- offset=N and limit=N to page through the entries - the JSON has
+  the total number of entries in "total"
+- recursive=true to list the subdirectories too, down to --max-depth
+  if set or 8 levels if not. Recursive listings of more than 100,000
+  entries are refused - list a subdirectory instead.
+
+### Read-write mode
+
+By default the server is read only. Use --read-write to allow files
+to be uploaded and directories to be made and files and directories
+to be deleted and renamed from the directory listing.
+
+These are done by POSTing a form to the URL of the directory with
+these fields:
+
+- upload: a multipart/form-data POST with one or more files
+- action=mkdir, name=DIR: make the directory DIR
+- action=delete, name=NAME: delete the file or empty directory NAME
+- action=rename, name=NAME, newname=NEWNAME: rename NAME to NEWNAME
+
+Only names in the directory itself are allowed. Requests must have an
+Origin or Referer header matching the server, which browsers send, so
+clients such as curl need to set one, eg
+
+    curl -H "Origin: http://localhost:8080" -F file=@file.txt http://localhost:8080/dir/
+
+Uploads are written through the VFS, so use --vfs-cache-mode writes
+or full if you need the uploads to be buffered on disk first.
+
+You will probably want to set up authentication with --htpasswd or
+--auth-proxy when using --read-write.
+` + httplib.Help + vfs.Help + proxy.Help,
 	Run: func(command *cobra.Command, args []string) {
-		cmd.CheckArgs(1, 1, command, args)
-		f := cmd.NewFsSrc(args)
+		var f fs.Fs
+		if proxyflags.Opt.AuthProxy == "" {
+			cmd.CheckArgs(1, 1, command, args)
+			f = cmd.NewFsSrc(args)
+		} else {
+			cmd.CheckArgs(0, 0, command, args)
+		}
 		cmd.Run(false, true, command, func() error {
-			s := newServer(f, &httpflags.Opt)
+			opt := Opt
+			opt.Options = httpflags.Opt
+			s := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
 			err := s.Serve()
 			if err != nil {
 				return err
@@ -58,21 +142,63 @@ control the stats printing.
 // server contains everything to run the server
 type server struct {
 	*httplib.Server
-	f   fs.Fs
-	vfs *vfs.VFS
+	f     fs.Fs
+	opt   Options
+	_vfs  *vfs.VFS // don't use directly, use getVFS
+	proxy *proxy.Proxy
+	roots *proxy.Roots // set if each user has their own root
 }
 
-func newServer(f fs.Fs, opt *httplib.Options) *server {
+func newServer(f fs.Fs, opt *Options, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options) *server {
 	mux := http.NewServeMux()
 	s := &server{
-		Server: httplib.NewServer(mux, opt),
-		f:      f,
-		vfs:    vfs.New(f, &vfsflags.Opt),
+		f:   f,
+		opt: *opt,
 	}
+	httpOpt := opt.Options
+	if proxyOpt.AuthProxy != "" {
+		s.proxy = proxy.New(proxyOpt)
+		// override auth
+		httpOpt.Auth = s.auth
+	} else {
+		s._vfs = vfs.New(f, vfsOpt)
+		if opt.JWTRootClaim != "" {
+			s.roots = proxy.NewRoots(f, vfsOpt)
+		}
+	}
+	s.Server = httplib.NewServer(mux, &httpOpt)
 	mux.HandleFunc(s.Opt.BaseURL+"/", s.handler)
 	return s
 }
 
+// getVFS gets the VFS for this request
+func (s *server) getVFS(ctx context.Context) (VFS *vfs.VFS, err error) {
+	if root, ok := ctx.Value(httplib.ContextRootKey).(string); ok && s.roots != nil {
+		return s.roots.Get(root)
+	}
+	if s._vfs != nil {
+		return s._vfs, nil
+	}
+	value := ctx.Value(httplib.ContextAuthKey)
+	if value == nil {
+		return nil, errors.New("no VFS found in context")
+	}
+	VFS, ok := value.(*vfs.VFS)
+	if !ok {
+		return nil, errors.Errorf("context value is not VFS: %#v", value)
+	}
+	return VFS, nil
+}
+
+// auth does proxy authorization
+func (s *server) auth(user, pass string) (value interface{}, err error) {
+	VFS, _, err := s.proxy.Call(user, pass, false)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, err
+}
+
 // Serve runs the http server in the background.
 //
 // Use s.Close() and s.Wait() to shutdown server
@@ -81,13 +207,50 @@ func (s *server) Serve() error {
 	if err != nil {
 		return err
 	}
-	fs.Logf(s.f, "Serving on %s", s.URL())
+	fs.Logf(s.f, "Serving on %s", strings.Join(s.URLs(), ", "))
 	return nil
 }
 
+// startRc starts a server for serve/start
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	opt := DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = []string{addr}
+	s := newServer(f, &opt, vfsOpt, proxyOpt)
+	err = s.Serve()
+	if err != nil {
+		s.shutdownVFS()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the URL the server is listening on
+func (s *server) Addr() string {
+	return s.URL()
+}
+
+// Shutdown stops the server and its VFS
+func (s *server) Shutdown() error {
+	s.Close()
+	s.shutdownVFS()
+	return nil
+}
+
+// shutdownVFS stops the VFS if it isn't from the proxy
+func (s *server) shutdownVFS() {
+	if s._vfs != nil {
+		s._vfs.Shutdown()
+	}
+}
+
 // handler reads incoming requests and dispatches them
 func (s *server) handler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" && r.Method != "HEAD" {
+	isPost := r.Method == "POST" && s.opt.ReadWrite
+	if r.Method != "GET" && r.Method != "HEAD" && !isPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -98,19 +261,44 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if root, ok := r.Context().Value(httplib.ContextRootKey).(string); ok && s.roots != nil {
+		defer s.roots.Hold(root)()
+	}
+	VFS, err := s.getVFS(r.Context())
+	if err != nil {
+		http.Error(w, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve directory: %v", err)
+		return
+	}
+	if s.proxy != nil {
+		user, _ := r.Context().Value(httplib.ContextUserKey).(string)
+		disconnect, err := proxy.ConnectUser(user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		defer disconnect()
+	}
 	isDir := strings.HasSuffix(urlPath, "/")
 	remote := strings.Trim(urlPath, "/")
-	if isDir {
-		s.serveDir(w, r, remote)
-	} else {
-		s.serveFile(w, r, remote)
+	switch {
+	case isPost && isDir:
+		s.postDir(w, r, VFS, remote)
+	case isPost:
+		http.Error(w, "Can only POST to a directory", http.StatusMethodNotAllowed)
+	case isDir && r.URL.Query().Get("download") != "":
+		s.serveArchive(w, r, VFS, remote)
+	case isDir:
+		s.serveDir(w, r, VFS, remote)
+	default:
+		s.serveFile(w, r, VFS, remote)
 	}
 }
 
 // serveDir serves a directory index at dirRemote
-func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote string) {
+func (s *server) serveDir(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
 	// List the directory
-	node, err := s.vfs.Stat(dirRemote)
+	node, err := VFS.Stat(dirRemote)
 	if err == vfs.ENOENT {
 		http.Error(w, "Directory not found", http.StatusNotFound)
 		return
@@ -123,25 +311,40 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 		return
 	}
 	dir := node.(*vfs.Dir)
-	dirEntries, err := dir.ReadDirAll()
+	query := r.URL.Query()
+	hashTypes, err := parseHashes(VFS, query.Get("hashes"))
 	if err != nil {
-		serve.Error(dirRemote, w, "Failed to list directory", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Make the entries for display
 	directory := serve.NewDirectory(dirRemote, s.HTMLTemplate)
-	for _, node := range dirEntries {
-		if vfsflags.Opt.NoModTime {
-			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
-		} else {
-			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
-		}
+	directory.Recursive = query.Get("recursive") == "true"
+	directory.Archive = true
+	if _, isShare := r.Context().Value(httplib.ContextShareKey).(*share.Share); isShare {
+		// keep the share link working in the links to the entries
+		directory.SetQuery(share.Query(r.URL.Query()))
+	} else {
+		directory.ReadWrite = s.opt.ReadWrite
+	}
+	err = addEntries(r.Context(), directory, dir, 1, serve.WantsJSON(r), hashTypes)
+	if err == errTooManyEntries {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		serve.Error(dirRemote, w, "Failed to list directory", err)
+		return
 	}
 
-	sortParm := r.URL.Query().Get("sort")
-	orderParm := r.URL.Query().Get("order")
+	sortParm := query.Get("sort")
+	orderParm := query.Get("order")
 	directory.ProcessQueryParams(sortParm, orderParm)
+	err = directory.ProcessPageParams(query.Get("offset"), query.Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Set the Last-Modified header to the timestamp
 	w.Header().Set("Last-Modified", dir.ModTime().UTC().Format(http.TimeFormat))
@@ -149,9 +352,118 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 	directory.Serve(w, r)
 }
 
+// hashName returns the name used for hashType in listings, eg "sha1"
+// for SHA-1
+func hashName(hashType hash.Type) string {
+	return strings.ToLower(strings.Replace(hashType.String(), "-", "", -1))
+}
+
+// parseHashes parses the comma separated hash names in hashes which
+// may be "all" for all the hashes the remote supports
+func parseHashes(VFS *vfs.VFS, hashes string) (hashTypes []hash.Type, err error) {
+	if hashes == "" {
+		return nil, nil
+	}
+	supported := VFS.Fs().Hashes().Array()
+	if hashes == "all" {
+		return supported, nil
+	}
+outer:
+	for _, name := range strings.Split(hashes, ",") {
+		for _, hashType := range supported {
+			if strings.EqualFold(name, hashName(hashType)) || strings.EqualFold(name, hashType.String()) {
+				hashTypes = append(hashTypes, hashType)
+				continue outer
+			}
+		}
+		return nil, errors.Errorf("hash %q not supported by the remote", name)
+	}
+	return hashTypes, nil
+}
+
+// Limits on recursive listings which are built in memory
+var (
+	maxRecursiveDepth   = 8      // used if --max-depth isn't set
+	maxRecursiveEntries = 100000 // listings larger than this are refused
+)
+
+// errTooManyEntries is returned by addEntries if a recursive listing
+// is larger than maxRecursiveEntries
+var errTooManyEntries = errors.New("recursive listing has too many entries - list a subdirectory instead")
+
+// addEntries adds the contents of dir, which is depth levels below
+// the directory being listed, to directory. It recurses into
+// subdirectories if directory.Recursive is set up to --max-depth or
+// maxRecursiveDepth.
+//
+// If details is set then the MIME types and hashes are read.
+func addEntries(ctx context.Context, directory *serve.Directory, dir *vfs.Dir, depth int, details bool, hashTypes []hash.Type) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	maxDepth := fs.Config.MaxDepth
+	if maxDepth < 0 {
+		maxDepth = maxRecursiveDepth
+	}
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if directory.Recursive && len(directory.Entries) >= maxRecursiveEntries {
+			return errTooManyEntries
+		}
+		modTime := node.ModTime().UTC()
+		if dir.VFS().Opt.NoModTime {
+			modTime = time.Time{}
+		}
+		if !details {
+			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), modTime)
+		} else {
+			mimeType, hashes := entryDetails(ctx, node, hashTypes)
+			directory.AddHTMLEntryDetails(node.Path(), node.IsDir(), node.Size(), modTime, mimeType, hashes)
+		}
+		subdir, isDir := node.(*vfs.Dir)
+		if isDir && directory.Recursive && depth < maxDepth {
+			err = addEntries(ctx, directory, subdir, depth+1, details, hashTypes)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// entryDetails returns the MIME type of node and the hashes of
+// hashTypes if it is a file
+func entryDetails(ctx context.Context, node vfs.Node, hashTypes []hash.Type) (mimeType string, hashes map[string]string) {
+	entry := node.DirEntry()
+	if entry == nil {
+		// file being written
+		return fs.MimeTypeFromName(node.Name()), nil
+	}
+	mimeType = fs.MimeTypeDirEntry(ctx, entry)
+	obj, ok := entry.(fs.Object)
+	if !ok || len(hashTypes) == 0 {
+		return mimeType, nil
+	}
+	hashes = make(map[string]string, len(hashTypes))
+	for _, hashType := range hashTypes {
+		sum, err := obj.Hash(ctx, hashType)
+		if err != nil {
+			fs.Errorf(obj, "Failed to read %v hash: %v", hashType, err)
+			continue
+		}
+		if sum != "" {
+			hashes[hashName(hashType)] = sum
+		}
+	}
+	return mimeType, hashes
+}
+
 // serveFile serves a file object at remote
-func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string) {
-	node, err := s.vfs.Stat(remote)
+func (s *server) serveFile(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, remote string) {
+	node, err := VFS.Stat(remote)
 	if err == vfs.ENOENT {
 		fs.Infof(remote, "%s: File not found", r.RemoteAddr)
 		http.Error(w, "File not found", http.StatusNotFound)
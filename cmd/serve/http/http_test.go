@@ -1,19 +1,33 @@
 package http
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"flag"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	_ "github.com/rclone/rclone/backend/local"
-	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/share"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,10 +44,10 @@ const (
 )
 
 func startServer(t *testing.T, f fs.Fs) {
-	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
 	opt.Template = testTemplate
-	httpServer = newServer(f, &opt)
+	httpServer = newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
 	assert.NoError(t, httpServer.Serve())
 	testURL = httpServer.Server.URL()
 
@@ -224,7 +238,372 @@ func TestGET(t *testing.T) {
 	}
 }
 
+func TestArchive(t *testing.T) {
+	want := []string{"three/", "three/a.txt", "three/b.txt"}
+	get := func(format string) []byte {
+		resp, err := http.Get(testURL + "three/?download=" + format)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `attachment; filename=three.`+format, resp.Header.Get("Content-Disposition"))
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return body
+	}
+
+	// zip - the files read are accounted
+	before := accounting.GlobalStats().GetBytes()
+	body := get("zip")
+	assert.Equal(t, int64(13), accounting.GlobalStats().GetBytes()-before)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+		if f.Name == "three/a.txt" {
+			in, err := f.Open()
+			require.NoError(t, err)
+			data, err := ioutil.ReadAll(in)
+			require.NoError(t, err)
+			assert.Equal(t, "three\n", string(data))
+		}
+	}
+	sort.Strings(got)
+	assert.Equal(t, want, got)
+
+	// tar.gz
+	body = get("tar.gz")
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	got = nil
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, hdr.Name)
+	}
+	sort.Strings(got)
+	assert.Equal(t, want, got)
+
+	// bad format
+	resp, err := http.Get(testURL + "three/?download=potato")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestJSONListing(t *testing.T) {
+	type entry struct {
+		Name     string
+		Size     int64
+		IsDir    bool
+		MimeType string
+		Hashes   map[string]string
+	}
+	var listing struct {
+		Entries []entry
+		Total   int
+	}
+	get := func(query string, accept string) {
+		req, err := http.NewRequest("GET", testURL+query, nil)
+		require.NoError(t, err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		listing.Entries = nil
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&listing))
+	}
+	names := func() (out []string) {
+		for _, entry := range listing.Entries {
+			out = append(out, entry.Name)
+		}
+		return out
+	}
+
+	get("?recursive=true&hashes=md5&sort=name", "application/json")
+	assert.Equal(t, []string{"one%.txt", "three", "three/a.txt", "three/b.txt", "two.txt"}, names())
+	a := listing.Entries[2]
+	assert.Equal(t, int64(6), a.Size)
+	assert.False(t, a.IsDir)
+	assert.Equal(t, "text/plain; charset=utf-8", a.MimeType)
+	assert.Equal(t, map[string]string{"md5": "febe6995bad457991331348f7b9c85fa"}, a.Hashes)
+	assert.True(t, listing.Entries[1].IsDir)
+
+	// --max-depth limits the recursion
+	oldMaxDepth := fs.Config.MaxDepth
+	fs.Config.MaxDepth = 1
+	get("?format=json&recursive=true", "")
+	fs.Config.MaxDepth = oldMaxDepth
+	assert.Equal(t, []string{"three", "one%.txt", "two.txt"}, names())
+
+	// and so does the default depth if it isn't set
+	oldMaxRecursiveDepth := maxRecursiveDepth
+	maxRecursiveDepth = 1
+	get("?format=json&recursive=true", "")
+	maxRecursiveDepth = oldMaxRecursiveDepth
+	assert.Equal(t, []string{"three", "one%.txt", "two.txt"}, names())
+
+	// Pagination
+	get("?format=json&offset=1&limit=1", "")
+	assert.Equal(t, []string{"one%.txt"}, names())
+	assert.Equal(t, 3, listing.Total)
+
+	// Bad parameters
+	for _, query := range []string{"?format=json&limit=potato", "?format=json&hashes=potato"} {
+		resp, err := http.Get(testURL + query)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	// Recursive listings which are too large
+	oldMaxRecursiveEntries := maxRecursiveEntries
+	maxRecursiveEntries = 4
+	resp, err := http.Get(testURL + "?format=json&recursive=true")
+	maxRecursiveEntries = oldMaxRecursiveEntries
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestFinalise(t *testing.T) {
 	httpServer.Close()
 	httpServer.Wait()
 }
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-http")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
+	opt.ReadWrite = true
+	s := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, s.Serve())
+	defer func() {
+		s.Close()
+		s.Wait()
+	}()
+	baseURL := s.Server.URL()
+
+	// don't follow the redirects back to the listing
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	post := func(contentType string, body io.Reader, origin string) *http.Response {
+		req, err := http.NewRequest("POST", baseURL, body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		switch origin {
+		case "":
+			req.Header.Set("Origin", strings.TrimSuffix(baseURL, "/"))
+		case "none":
+		case "referer":
+			req.Header.Set("Referer", baseURL)
+		default:
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+	action := func(values url.Values) int {
+		return post("application/x-www-form-urlencoded", strings.NewReader(values.Encode()), "").StatusCode
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	// Upload some files
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, name := range []string{"file1.txt", `C:\Users\potato\file2.txt`} {
+		part, err := mw.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte("hello " + name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	resp := post(mw.FormDataContentType(), &buf, "")
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "file1.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello file1.txt", string(data))
+	assert.True(t, exists("file2.txt"))
+
+	// Mkdir
+	assert.Equal(t, http.StatusSeeOther, action(url.Values{"action": {"mkdir"}, "name": {"dir"}}))
+	assert.True(t, exists("dir"))
+	assert.Equal(t, http.StatusConflict, action(url.Values{"action": {"mkdir"}, "name": {"file1.txt"}}))
+
+	// Rename
+	assert.Equal(t, http.StatusSeeOther, action(url.Values{"action": {"rename"}, "name": {"file2.txt"}, "newname": {"file3.txt"}}))
+	assert.False(t, exists("file2.txt"))
+	assert.True(t, exists("file3.txt"))
+
+	// Delete
+	assert.Equal(t, http.StatusSeeOther, action(url.Values{"action": {"delete"}, "name": {"file3.txt"}}))
+	assert.False(t, exists("file3.txt"))
+	assert.Equal(t, http.StatusSeeOther, action(url.Values{"action": {"delete"}, "name": {"dir/"}}))
+	assert.False(t, exists("dir"))
+	assert.Equal(t, http.StatusNotFound, action(url.Values{"action": {"delete"}, "name": {"potato"}}))
+
+	// Names outside the directory are refused
+	assert.Equal(t, http.StatusBadRequest, action(url.Values{"action": {"delete"}, "name": {"../file1.txt"}}))
+	assert.Equal(t, http.StatusBadRequest, action(url.Values{"action": {"rename"}, "name": {"file1.txt"}, "newname": {".."}}))
+	assert.Equal(t, http.StatusBadRequest, action(url.Values{"action": {"potato"}, "name": {"file1.txt"}}))
+
+	// Cross origin requests are refused
+	resp = post("application/x-www-form-urlencoded", strings.NewReader("action=delete&name=file1.txt"), "http://example.com")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.True(t, exists("file1.txt"))
+
+	// as are requests without Origin or Referer
+	resp = post("application/x-www-form-urlencoded", strings.NewReader("action=delete&name=file1.txt"), "none")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.True(t, exists("file1.txt"))
+
+	// but the Referer can be used instead of the Origin
+	resp = post("application/x-www-form-urlencoded", strings.NewReader("action=mkdir&name=dir2"), "referer")
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.True(t, exists("dir2"))
+
+	// The listing shows the forms
+	resp, err = http.Get(baseURL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Contains(t, string(body), `value="rename"`)
+	assert.Contains(t, string(body), `?download=zip`)
+}
+
+func TestShareLink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-http")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	f, err := fs.NewFs("testdata/files")
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
+	opt.BasicUser = "user"
+	opt.BasicPass = "pass"
+	opt.ShareSecret = "potato"
+	opt.ShareStore = filepath.Join(dir, "shares.json")
+	s := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, s.Serve())
+	defer func() {
+		s.Close()
+		s.Wait()
+	}()
+	shares := share.New(&share.Options{Secret: opt.ShareSecret, Store: opt.ShareStore})
+	sh, err := shares.Create("three/", time.Hour, share.ModeRead, 0)
+	require.NoError(t, err)
+	link := shares.URL(sh, s.Server.URL())
+
+	get := func(u string) (int, string) {
+		resp, err := http.Get(u)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode, string(body)
+	}
+
+	// Without the link a login is needed
+	status, _ := get(s.Server.URL() + "three/")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// The listing keeps the share in the links
+	status, body := get(link)
+	assert.Equal(t, http.StatusOK, status)
+	query := strings.SplitN(link, "?", 2)[1]
+	assert.Contains(t, body, `href="a.txt?`+strings.Replace(query, "&", "&amp;", -1)+`"`)
+
+	// Files in the share can be read but not outside it
+	status, body = get(strings.Replace(link, "three/?", "three/a.txt?", 1))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "three\n", body)
+	status, _ = get(strings.Replace(link, "three/?", "two.txt?", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestShareUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-http")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "existing.txt"), []byte("original"), 0600))
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
+	opt.BasicUser = "user"
+	opt.BasicPass = "pass"
+	opt.ShareSecret = "potato"
+	opt.ShareStore = filepath.Join(dir, "shares.json")
+	opt.ReadWrite = true
+	s := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, s.Serve())
+	defer func() {
+		s.Close()
+		s.Wait()
+	}()
+	shares := share.New(&share.Options{Secret: opt.ShareSecret, Store: opt.ShareStore})
+	sh, err := shares.Create("", time.Hour, share.ModeUpload, 0)
+	require.NoError(t, err)
+	link := shares.URL(sh, s.Server.URL())
+
+	upload := func(name string) int {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		part, err := mw.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte("uploaded"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+		req, err := http.NewRequest("POST", link, &buf)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("Origin", strings.TrimSuffix(s.Server.URL(), "/"))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// New files can be uploaded
+	assert.Equal(t, http.StatusCreated, upload("new.txt"))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "uploaded", string(data))
+
+	// but existing ones can't be overwritten
+	assert.Equal(t, http.StatusConflict, upload("existing.txt"))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "existing.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(data))
+}
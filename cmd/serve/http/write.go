@@ -0,0 +1,205 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/cmd/serve/share"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/vfs"
+)
+
+// errBadRequest is the cause of errors from malformed POSTs
+var errBadRequest = errors.New("bad request")
+
+// checkName returns the name of an entry in a directory as sent by
+// the listing with any trailing / removed or an error if it isn't a
+// single path element
+func checkName(name string) (string, error) {
+	name = strings.TrimSuffix(name, "/")
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", errors.Wrapf(errBadRequest, "invalid name %q", name)
+	}
+	return name, nil
+}
+
+// sameOrigin returns true if the request was sent from a page on this
+// site, to stop other sites using the credentials of a logged in
+// browser to change things.
+//
+// The Origin header is used, or the Referer if there isn't one.
+// Requests with neither are refused as the site they came from can't
+// be checked.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// postDir handles a POST from the listing of the directory dirRemote
+// which changes it then redirects back to the listing
+func (s *server) postDir(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
+	if !sameOrigin(r) {
+		fs.Infof(dirRemote, "%s: Refusing POST from origin %q referer %q", r.RemoteAddr, r.Header.Get("Origin"), r.Header.Get("Referer"))
+		http.Error(w, "Cross origin request refused", http.StatusForbidden)
+		return
+	}
+	// Upload share links can only upload and can't see the listing
+	_, isShare := r.Context().Value(httplib.ContextShareKey).(*share.Share)
+	node, err := VFS.Stat(dirRemote)
+	if err == nil && !node.IsDir() {
+		http.Error(w, "Not a directory", http.StatusNotFound)
+		return
+	}
+	if err == nil {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "multipart/form-data" {
+			err = s.upload(r, VFS, dirRemote)
+		} else if isShare {
+			http.Error(w, "Share link only allows uploads", http.StatusForbidden)
+			return
+		} else {
+			err = s.action(r, VFS, dirRemote)
+		}
+	}
+	if err != nil {
+		postError(w, dirRemote, err)
+		return
+	}
+	if isShare {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintln(w, "Upload complete")
+		return
+	}
+	http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+}
+
+// postError returns err from a POST to the client
+func postError(w http.ResponseWriter, dirRemote string, err error) {
+	var code int
+	switch errors.Cause(err) {
+	case errBadRequest:
+		code = http.StatusBadRequest
+	case vfs.ENOENT:
+		code = http.StatusNotFound
+	case vfs.EEXIST, vfs.ENOTEMPTY:
+		code = http.StatusConflict
+	case vfs.EROFS, vfs.EPERM:
+		code = http.StatusForbidden
+	case vfs.ENOSPC:
+		code = http.StatusInsufficientStorage
+	case vfs.EFBIG:
+		code = http.StatusRequestEntityTooLarge
+	default:
+		serve.Error(dirRemote, w, "Failed to change directory", err)
+		return
+	}
+	fs.Infof(dirRemote, "POST failed: %v", err)
+	http.Error(w, err.Error(), code)
+}
+
+// action does the mkdir, delete or rename in the form posted to
+// dirRemote
+func (s *server) action(r *http.Request, VFS *vfs.VFS, dirRemote string) error {
+	err := r.ParseForm()
+	if err != nil {
+		return errors.Wrapf(errBadRequest, "%v", err)
+	}
+	name, err := checkName(r.PostForm.Get("name"))
+	if err != nil {
+		return err
+	}
+	remote := path.Join(dirRemote, name)
+	switch action := r.PostForm.Get("action"); action {
+	case "mkdir":
+		fs.Infof(remote, "Making directory")
+		return VFS.Mkdir(remote, 0777)
+	case "delete":
+		fs.Infof(remote, "Deleting")
+		return VFS.Remove(remote)
+	case "rename":
+		newName, err := checkName(r.PostForm.Get("newname"))
+		if err != nil {
+			return err
+		}
+		newRemote := path.Join(dirRemote, newName)
+		fs.Infof(remote, "Renaming to %q", newRemote)
+		return VFS.Rename(remote, newRemote)
+	default:
+		return errors.Wrapf(errBadRequest, "unknown action %q", action)
+	}
+}
+
+// upload writes the files in the multipart form posted to dirRemote
+// into it
+func (s *server) upload(r *http.Request, VFS *vfs.VFS, dirRemote string) error {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return errors.Wrapf(errBadRequest, "%v", err)
+	}
+	// Upload share links can't replace existing files
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if _, isShare := r.Context().Value(httplib.ContextShareKey).(*share.Share); isShare {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read upload")
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		// Some browsers send the full path of the file
+		fileName := part.FileName()
+		fileName = fileName[strings.LastIndexAny(fileName, `/\`)+1:]
+		name, err := checkName(fileName)
+		if err != nil {
+			return err
+		}
+		err = uploadFile(r, VFS, path.Join(dirRemote, name), part, flags)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// uploadFile writes in to the file remote opened with flags
+func uploadFile(r *http.Request, VFS *vfs.VFS, remote string, in io.ReadCloser, flags int) (err error) {
+	fs.Infof(remote, "%s: Uploading", r.RemoteAddr)
+	tr := accounting.Stats(r.Context()).NewTransferRemoteSize(remote, -1)
+	defer func() {
+		tr.Done(err)
+	}()
+	fd, err := VFS.OpenFile(remote, flags, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fd, tr.Account(in))
+	closeErr := fd.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload %q", remote)
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package httpflags
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/rc"
@@ -15,7 +20,10 @@ var (
 // AddFlagsPrefix adds flags for the httplib
 func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *httplib.Options) {
 	rc.AddOption(prefix+"http", &Opt)
-	flags.StringVarP(flagSet, &Opt.ListenAddr, prefix+"addr", "", Opt.ListenAddr, "IPaddress:Port or :Port to bind server to.")
+	flags.StringArrayVarP(flagSet, (*[]string)(&Opt.ListenAddr), prefix+"addr", "", Opt.ListenAddr, "IPaddress:Port, :Port or unix:///path to bind server to - may be repeated.")
+	flags.FVarP(flagSet, (*socketMode)(&Opt.SocketMode), prefix+"socket-mode", "", "Permissions for unix sockets.")
+	flags.StringVarP(flagSet, &Opt.SocketOwner, prefix+"socket-owner", "", Opt.SocketOwner, "Owner of unix sockets as user, user:group or :group.")
+	flags.BoolVarP(flagSet, &Opt.SocketActivation, prefix+"socket-activation", "", Opt.SocketActivation, "Use the sockets passed in by systemd socket activation instead of --addr.")
 	flags.DurationVarP(flagSet, &Opt.ServerReadTimeout, prefix+"server-read-timeout", "", Opt.ServerReadTimeout, "Timeout for server reading data")
 	flags.DurationVarP(flagSet, &Opt.ServerWriteTimeout, prefix+"server-write-timeout", "", Opt.ServerWriteTimeout, "Timeout for server writing data")
 	flags.IntVarP(flagSet, &Opt.MaxHeaderBytes, prefix+"max-header-bytes", "", Opt.MaxHeaderBytes, "Maximum size of request header")
@@ -28,6 +36,13 @@ func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *httplib.Options)
 	flags.StringVarP(flagSet, &Opt.BasicPass, prefix+"pass", "", Opt.BasicPass, "Password for authentication.")
 	flags.StringVarP(flagSet, &Opt.BaseURL, prefix+"baseurl", "", Opt.BaseURL, "Prefix for URLs - leave blank for root.")
 	flags.StringVarP(flagSet, &Opt.Template, prefix+"template", "", Opt.Template, "User Specified Template.")
+	flags.StringVarP(flagSet, &Opt.ShareSecret, prefix+"share-secret", "", Opt.ShareSecret, "Secret to check share links with - if not set share links aren't accepted.")
+	flags.StringVarP(flagSet, &Opt.ShareStore, prefix+"share-store", "", Opt.ShareStore, "File the share links are kept in - blank for the default.")
+	flags.StringVarP(flagSet, &Opt.JWKS, prefix+"jwks", "", Opt.JWKS, "File or URL of the JSON Web Key Set to check bearer tokens with.")
+	flags.StringVarP(flagSet, &Opt.JWTIssuer, prefix+"jwt-issuer", "", Opt.JWTIssuer, "Issuer (iss) bearer tokens must have - required with --jwks.")
+	flags.StringVarP(flagSet, &Opt.JWTAudience, prefix+"jwt-audience", "", Opt.JWTAudience, "Audience (aud) bearer tokens must have - required with --jwks.")
+	flags.StringVarP(flagSet, &Opt.JWTUserClaim, prefix+"jwt-user-claim", "", Opt.JWTUserClaim, "Claim in bearer tokens to use as the user name.")
+	flags.StringVarP(flagSet, &Opt.JWTRootClaim, prefix+"jwt-root-claim", "", Opt.JWTRootClaim, "Claim in bearer tokens to use as the root directory for the user.")
 
 }
 
@@ -35,3 +50,27 @@ func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *httplib.Options)
 func AddFlags(flagSet *pflag.FlagSet) {
 	AddFlagsPrefix(flagSet, "", &Opt)
 }
+
+// socketMode is a command line friendly os.FileMode for the unix
+// socket permissions
+type socketMode os.FileMode
+
+// String turns socketMode into a string
+func (x *socketMode) String() string {
+	return fmt.Sprintf("0%3o", *x)
+}
+
+// Set a socketMode
+func (x *socketMode) Set(s string) error {
+	i, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return errors.Wrap(err, "bad socket mode - must be octal digits")
+	}
+	*x = socketMode(i)
+	return nil
+}
+
+// Type of the value
+func (x *socketMode) Type() string {
+	return "FileMode"
+}
@@ -6,19 +6,22 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
-	"fmt"
 	"html/template"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"path"
 	"strings"
 	"time"
 
 	auth "github.com/abbot/go-http-auth"
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd/serve/httplib/serve/data"
+	"github.com/rclone/rclone/cmd/serve/share"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/jwtutil/jwtverify"
 )
 
 // Globals
@@ -34,6 +37,19 @@ listen on, eg --addr 1.2.3.4:8000 or --addr :8080 to listen to all
 IPs.  By default it only listens on localhost.  You can use port
 :0 to let the OS choose an available port.
 
+--addr may be repeated to listen on more than one address, eg
+--addr 127.0.0.1:8080 --addr [::1]:8080.
+
+To listen on a unix socket use --addr unix:///path/to/socket.  The
+permissions of the socket can be set with --socket-mode (default
+0660) and its owner with --socket-owner which takes user, user:group
+or :group.  This is useful when proxying rclone with a web server such
+as nginx.
+
+If --socket-activation is set and rclone is started by systemd socket
+activation then it will listen on the sockets passed in by systemd and
+ignore --addr.
+
 If you set --addr to listen on a public or LAN accessible IP address
 then using Authentication is advised - see the next section for info.
 
@@ -96,6 +112,33 @@ The password file can be updated while rclone is running.
 
 Use --realm to set the authentication realm.
 
+#### Bearer tokens
+
+Use --jwks to accept JWT bearer tokens, such as OpenID Connect access
+tokens, in an "Authorization: Bearer" header.  This takes the path of
+a JSON Web Key Set file or an http(s) URL to fetch it from, eg the
+jwks_uri of your OpenID Connect provider.  Tokens must be signed by
+one of its keys with an RS, PS or ES algorithm and must not have
+expired.  The key set is read again if a token signed by an unknown
+key is seen so key rotation is supported.
+
+--jwt-issuer and --jwt-audience must be set to check the iss and aud
+claims in the tokens, so tokens the provider issued for other services
+aren't accepted.
+
+The user name is read from the claim set by --jwt-user-claim which
+is "sub" by default.  For example use --jwt-user-claim
+preferred_username to use the user name from OpenID Connect.
+
+Use --jwt-root-claim to give each user their own root with serve
+http, serve webdav and serve restic.  The value of this claim in the
+token is used as a directory within the remote being served and each
+user gets their own VFS rooted there.  Tokens without the claim,
+or where it is empty, is the top of the remote or contains "..", are
+refused.  This isn't supported with --auth-proxy.
+
+Bearer tokens may be used at the same time as --htpasswd or --user.
+
 #### SSL/TLS
 
 By default this will serve over http.  If you want you can serve over
@@ -107,11 +150,18 @@ supply --client-ca also.
 of that with the CA certificate.  --key should be the PEM encoded
 private key and --client-ca should be the PEM encoded client
 certificate authority certificate.
-`
+
+Send SIGHUP to rclone to read --cert and --key again, for example
+after the certificate has been renewed.  This also reads the --jwks
+key set again.
+` + share.Help
 
 // Options contains options for the http Server
 type Options struct {
-	ListenAddr         string        // Port to listen on
+	ListenAddr         ListenAddrs   // Ports or unix sockets to listen on
+	SocketMode         os.FileMode   // Permissions for unix sockets
+	SocketOwner        string        // user:group to own unix sockets
+	SocketActivation   bool          // use the sockets from systemd socket activation if set
 	BaseURL            string        // prefix to strip from URLs
 	ServerReadTimeout  time.Duration // Timeout for server reading data
 	ServerWriteTimeout time.Duration // Timeout for server writing data
@@ -125,6 +175,13 @@ type Options struct {
 	BasicPass          string        // password for BasicUser
 	Auth               AuthFn        `json:"-"` // custom Auth (not set by command line flags)
 	Template           string        // User specified template
+	ShareSecret        string        // secret to check share links with - if not set they aren't accepted
+	ShareStore         string        // file the share links are kept in
+	JWKS               string        // file or URL of the JSON Web Key Set to check bearer tokens with
+	JWTIssuer          string        // bearer tokens must be issued by this
+	JWTAudience        string        // bearer tokens must be for this audience
+	JWTUserClaim       string        // claim in bearer tokens to use as the user name
+	JWTRootClaim       string        // if set the claim in bearer tokens to use as the root for the user
 }
 
 // AuthFn if used will be used to authenticate user, pass. If an error
@@ -135,24 +192,30 @@ type AuthFn func(user, pass string) (value interface{}, err error)
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
-	ListenAddr:         "localhost:8080",
+	ListenAddr:         []string{"localhost:8080"},
+	SocketMode:         0660,
 	Realm:              "rclone",
 	ServerReadTimeout:  1 * time.Hour,
 	ServerWriteTimeout: 1 * time.Hour,
 	MaxHeaderBytes:     4096,
+	JWTUserClaim:       "sub",
 }
 
 // Server contains info about the running http server
 type Server struct {
 	Opt             Options
 	handler         http.Handler // original handler
-	listener        net.Listener
+	listeners       []net.Listener
 	waitChan        chan struct{} // for waiting on the listener to close
 	httpServer      *http.Server
 	basicPassHashed string
-	useSSL          bool               // if server is configured for SSL/TLS
-	usingAuth       bool               // set if authentication is configured
-	HTMLTemplate    *template.Template // HTML template for web interface
+	useSSL          bool                // if server is configured for SSL/TLS
+	usingAuth       bool                // set if authentication is configured
+	HTMLTemplate    *template.Template  // HTML template for web interface
+	shares          *share.Shares       // share links accepted if set
+	jwt             *jwtverify.Verifier // bearer tokens accepted if set
+	certs           *certReloader       // TLS certificate if using SSL/TLS
+	stopSignals     chan struct{}       // close to stop the SIGHUP handler
 }
 
 type contextUserType struct{}
@@ -165,6 +228,21 @@ type contextAuthType struct{}
 // ContextAuthKey is a simple context key for storing info returned by AuthFn
 var ContextAuthKey = &contextAuthType{}
 
+type contextShareType struct{}
+
+// ContextShareKey is a simple context key for storing the *share.Share
+// if the request was authorized by a share link
+var ContextShareKey = &contextShareType{}
+
+type contextRootType struct{}
+
+// ContextRootKey is a simple context key for storing the root for the
+// user read from the --jwt-root-claim of the bearer token
+var ContextRootKey = &contextRootType{}
+
+// jwtLeeway is the clock skew allowed when checking bearer tokens
+const jwtLeeway = time.Minute
+
 // singleUserProvider provides the encrypted password for a single user
 func (s *Server) singleUserProvider(user, realm string) string {
 	if user == s.Opt.BasicUser {
@@ -194,6 +272,60 @@ func parseAuthorization(r *http.Request) (user, pass string, ok bool) {
 	return
 }
 
+// parseBearer returns the token from a bearer Authorization header
+func parseBearer(r *http.Request) (token string, ok bool) {
+	s := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(s) == 2 && strings.EqualFold(s[0], "Bearer") && s[1] != "" {
+		return strings.TrimSpace(s[1]), true
+	}
+	return "", false
+}
+
+// checkBearer checks the bearer token and returns r with the user
+// and root from its claims in the context
+func (s *Server) checkBearer(r *http.Request, token string) (*http.Request, string, error) {
+	claims, err := s.jwt.Verify(token)
+	if err != nil {
+		return nil, "", err
+	}
+	user, ok := claims.String(s.Opt.JWTUserClaim)
+	if !ok || user == "" {
+		return nil, "", errors.Errorf("bearer token has no %q claim", s.Opt.JWTUserClaim)
+	}
+	ctx := context.WithValue(r.Context(), ContextUserKey, user)
+	if s.Opt.JWTRootClaim != "" {
+		root, ok := claims.String(s.Opt.JWTRootClaim)
+		if !ok {
+			return nil, user, errors.Errorf("bearer token has no %q claim", s.Opt.JWTRootClaim)
+		}
+		root, err = CleanRoot(root)
+		if err != nil {
+			return nil, user, errors.Wrapf(err, "bearer token has a bad %q claim", s.Opt.JWTRootClaim)
+		}
+		ctx = context.WithValue(ctx, ContextRootKey, root)
+	}
+	return r.WithContext(ctx), user, nil
+}
+
+// CleanRoot makes root relative and checks it is a directory within
+// the remote.
+//
+// Roots which are empty, which are the top of the remote or which
+// contain ".." are refused so a missing or bad claim can't give
+// access to the whole remote.
+func CleanRoot(root string) (string, error) {
+	for _, elem := range strings.Split(root, "/") {
+		if elem == ".." {
+			return "", errors.Errorf("root %q mustn't contain \"..\"", root)
+		}
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+root), "/")
+	if cleaned == "" {
+		return "", errors.Errorf("root %q must be a directory within the remote", root)
+	}
+	return cleaned, nil
+}
+
 // NewServer creates an http server.  The opt can be nil in which case
 // the default options will be used.
 func NewServer(handler http.Handler, opt *Options) *Server {
@@ -208,10 +340,30 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 		s.Opt = DefaultOpt
 	}
 
+	// Check bearer tokens if required
+	if s.Opt.JWKS != "" {
+		var err error
+		s.jwt, err = jwtverify.New(&jwtverify.Options{
+			JWKS:     s.Opt.JWKS,
+			Issuer:   s.Opt.JWTIssuer,
+			Audience: s.Opt.JWTAudience,
+			Leeway:   jwtLeeway,
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up bearer token authentication: %v", err)
+		}
+		fs.Infof(nil, "Using %q to check bearer tokens", s.Opt.JWKS)
+	}
+
 	// Use htpasswd if required on everything
-	if s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil {
+	useBasic := s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil
+	if useBasic || s.jwt != nil {
 		var authenticator *auth.BasicAuth
-		if s.Opt.Auth == nil {
+		if !useBasic {
+			if s.Opt.ShareSecret != "" {
+				fs.Logf(nil, "Share links need --htpasswd or --user - ignoring --share-secret")
+			}
+		} else if s.Opt.Auth == nil {
 			var secretProvider auth.SecretProvider
 			if s.Opt.HtPasswd != "" {
 				fs.Infof(nil, "Using %q as htpasswd storage", s.Opt.HtPasswd)
@@ -222,6 +374,14 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 				secretProvider = s.singleUserProvider
 			}
 			authenticator = auth.NewBasicAuthenticator(s.Opt.Realm, secretProvider)
+			if s.Opt.ShareSecret != "" {
+				s.shares = share.New(&share.Options{
+					Secret: s.Opt.ShareSecret,
+					Store:  s.Opt.ShareStore,
+				})
+			}
+		} else if s.Opt.ShareSecret != "" {
+			fs.Logf(nil, "Share links can't be used with custom authentication - ignoring --share-secret")
 		}
 		oldHandler := handler
 		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -232,11 +392,32 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 			}
 			unauthorized := func() {
 				w.Header().Set("Content-Type", "text/plain")
-				w.Header().Set("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
+				if useBasic {
+					w.Header().Add("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
+				}
+				if s.jwt != nil {
+					w.Header().Add("WWW-Authenticate", `Bearer realm="`+s.Opt.Realm+`"`)
+				}
 				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			}
+			if s.shares != nil && share.IsShare(r) {
+				s.serveShare(oldHandler, w, r)
+				return
+			}
+			if s.jwt != nil {
+				if token, ok := parseBearer(r); ok {
+					bearerRequest, user, err := s.checkBearer(r, token)
+					if err != nil {
+						fs.Infof(r.URL.Path, "%s: Bearer token refused for %q: %v", r.RemoteAddr, user, err)
+						unauthorized()
+						return
+					}
+					oldHandler.ServeHTTP(w, bearerRequest)
+					return
+				}
+			}
 			user, pass, authValid := parseAuthorization(r)
-			if !authValid {
+			if !authValid || !useBasic {
 				unauthorized()
 				return
 			}
@@ -268,6 +449,13 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 	if (s.Opt.SslCert != "") != s.useSSL {
 		log.Fatalf("Need both -cert and -key to use SSL")
 	}
+	if s.useSSL {
+		var err error
+		s.certs, err = newCertReloader(s.Opt.SslCert, s.Opt.SslKey)
+		if err != nil {
+			log.Fatalf("Failed to read TLS certificate: %v", err)
+		}
+	}
 
 	// If a Base URL is set then serve from there
 	s.Opt.BaseURL = strings.Trim(s.Opt.BaseURL, "/")
@@ -277,7 +465,6 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 
 	// FIXME make a transport?
 	s.httpServer = &http.Server{
-		Addr:              s.Opt.ListenAddr,
 		Handler:           handler,
 		ReadTimeout:       s.Opt.ServerReadTimeout,
 		WriteTimeout:      s.Opt.ServerWriteTimeout,
@@ -288,6 +475,9 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 			MinVersion: tls.VersionTLS10, // disable SSL v3.0 and earlier
 		},
 	}
+	if s.certs != nil {
+		s.httpServer.TLSConfig.GetCertificate = s.certs.GetCertificate
+	}
 
 	if s.Opt.ClientCA != "" {
 		if !s.useSSL {
@@ -314,47 +504,63 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 	return s
 }
 
+// serveShare serves r with handler if the share link it uses allows it
+func (s *Server) serveShare(handler http.Handler, w http.ResponseWriter, r *http.Request) {
+	urlPath := strings.TrimPrefix(r.URL.Path, s.Opt.BaseURL)
+	sh, err := s.shares.Check(r, urlPath)
+	if err != nil {
+		fs.Infof(r.URL.Path, "%s: Share link refused: %v", r.RemoteAddr, err)
+		http.Error(w, "Share link refused: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), ContextShareKey, sh))
+	handler.ServeHTTP(w, r)
+}
+
 // Serve runs the server - returns an error only if
-// the listener was not started; does not block, so
-// use s.Wait() to block on the listener indefinitely.
+// the listeners were not started; does not block, so
+// use s.Wait() to block on the listeners indefinitely.
 func (s *Server) Serve() error {
-	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	listeners, err := s.listen()
 	if err != nil {
 		return errors.Wrapf(err, "start server failed")
 	}
-	s.listener = ln
+	s.listeners = listeners
 	s.waitChan = make(chan struct{})
-	go func() {
-		var err error
-		if s.useSSL {
-			// hacky hack to get this to work with old Go versions, which
-			// don't have ServeTLS on http.Server; see PR #2194.
-			type tlsServer interface {
-				ServeTLS(ln net.Listener, cert, key string) error
-			}
-			srvIface := interface{}(s.httpServer)
-			if tlsSrv, ok := srvIface.(tlsServer); ok {
-				// yay -- we get easy TLS support with HTTP/2
-				err = tlsSrv.ServeTLS(s.listener, s.Opt.SslCert, s.Opt.SslKey)
-			} else {
-				// oh well -- we can still do TLS but might not have HTTP/2
-				tlsConfig := new(tls.Config)
-				tlsConfig.Certificates = make([]tls.Certificate, 1)
-				tlsConfig.Certificates[0], err = tls.LoadX509KeyPair(s.Opt.SslCert, s.Opt.SslKey)
-				if err != nil {
-					log.Printf("Error loading key pair: %v", err)
-				}
-				tlsLn := tls.NewListener(s.listener, tlsConfig)
-				err = s.httpServer.Serve(tlsLn)
-			}
-		} else {
-			err = s.httpServer.Serve(s.listener)
+	for _, ln := range s.listeners {
+		go s.serve(ln)
+	}
+	s.startSignalHandler()
+	return nil
+}
+
+// serve runs the server on a single listener
+func (s *Server) serve(ln net.Listener) {
+	var err error
+	if s.useSSL {
+		// hacky hack to get this to work with old Go versions, which
+		// don't have ServeTLS on http.Server; see PR #2194.
+		type tlsServer interface {
+			ServeTLS(ln net.Listener, cert, key string) error
 		}
-		if err != nil {
-			log.Printf("Error on serving HTTP server: %v", err)
+		srvIface := interface{}(s.httpServer)
+		//
+		// The certificate comes from GetCertificate in the TLSConfig
+		// so it can be reloaded.
+		if tlsSrv, ok := srvIface.(tlsServer); ok {
+			// yay -- we get easy TLS support with HTTP/2
+			err = tlsSrv.ServeTLS(ln, "", "")
+		} else {
+			// oh well -- we can still do TLS but might not have HTTP/2
+			tlsLn := tls.NewListener(ln, s.httpServer.TLSConfig)
+			err = s.httpServer.Serve(tlsLn)
 		}
-	}()
-	return nil
+	} else {
+		err = s.httpServer.Serve(ln)
+	}
+	if err != nil {
+		log.Printf("Error on serving HTTP server: %v", err)
+	}
 }
 
 // Wait blocks while the listener is open.
@@ -364,7 +570,10 @@ func (s *Server) Wait() {
 
 // Close shuts the running server down
 func (s *Server) Close() {
+	s.stopSignalHandler()
 	err := s.httpServer.Close()
+	// Close any listeners the server hadn't started using yet
+	closeListeners(s.listeners)
 	if err != nil {
 		log.Printf("Error on closing HTTP server: %v", err)
 		return
@@ -372,19 +581,35 @@ func (s *Server) Close() {
 	close(s.waitChan)
 }
 
-// URL returns the serving address of this server
+// URL returns the first serving address of this server
 func (s *Server) URL() string {
-	proto := "http"
-	if s.useSSL {
-		proto = "https"
+	urls := s.URLs()
+	if len(urls) == 0 {
+		return ""
 	}
-	addr := s.Opt.ListenAddr
-	if s.listener != nil {
-		// prefer actual listener address; required if using 0-port
+	return urls[0]
+}
+
+// URLs returns the serving addresses of this server
+//
+// Unix sockets are returned in the form http+unix://%2Fpath%2Fto%2Fsocket/
+func (s *Server) URLs() (urls []string) {
+	if len(s.listeners) > 0 {
+		// prefer actual listener addresses; required if using 0-port
 		// (i.e. port assigned by operating system)
-		addr = s.listener.Addr().String()
+		for _, ln := range s.listeners {
+			urls = append(urls, s.listenerURL(ln.Addr().Network(), ln.Addr().String()))
+		}
+		return urls
+	}
+	for _, addr := range s.Opt.ListenAddr {
+		if strings.HasPrefix(addr, unixPrefix) {
+			urls = append(urls, s.listenerURL("unix", addr[len(unixPrefix):]))
+		} else {
+			urls = append(urls, s.listenerURL("tcp", addr))
+		}
 	}
-	return fmt.Sprintf("%s://%s%s/", proto, addr, s.Opt.BaseURL)
+	return urls
 }
 
 // UsingAuth returns true if authentication is required
@@ -0,0 +1,202 @@
+package httplib
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeJWKS writes a JWKS for key into dir returning its path
+func makeJWKS(t *testing.T, dir string, key *rsa.PrivateKey) string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "jwks.json")
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+	return path
+}
+
+// makeToken makes an RS256 token with claims signed by key
+func makeToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	header := b64([]byte(`{"alg":"RS256","typ":"JWT","kid":"test"}`))
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := header + "." + b64(payload)
+	digest := crypto.SHA256.New()
+	_, _ = digest.Write([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	require.NoError(t, err)
+	return signed + "." + b64(signature)
+}
+
+func TestBearerAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-httplib")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// The handler returns the user and root it was called with
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := r.Context().Value(ContextUserKey).(string)
+		root, ok := r.Context().Value(ContextRootKey).(string)
+		if !ok {
+			root = "<none>"
+		}
+		_, _ = w.Write([]byte(user + " " + root))
+	})
+
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:0"}
+	opt.JWKS = makeJWKS(t, dir, key)
+	opt.JWTIssuer = "https://issuer.example.com"
+	opt.JWTAudience = "rclone"
+	opt.JWTUserClaim = "preferred_username"
+	opt.JWTRootClaim = "rclone_root"
+	opt.BasicUser = "user"
+	opt.BasicPass = "pass"
+	s := NewServer(handler, &opt)
+	require.NoError(t, s.Serve())
+	defer s.Close()
+	assert.True(t, s.UsingAuth())
+
+	claims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub":                "1234",
+			"preferred_username": "alice",
+			"rclone_root":        "alice/files",
+			"iss":                opt.JWTIssuer,
+			"aud":                opt.JWTAudience,
+			"exp":                time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	do := func(setAuth func(r *http.Request)) (status int, body string, authenticate []string) {
+		req, err := http.NewRequest("GET", s.URL(), nil)
+		require.NoError(t, err)
+		setAuth(req)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode, string(data), resp.Header["Www-Authenticate"]
+	}
+	bearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	// No auth asks for both types
+	status, _, authenticate := do(func(r *http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, []string{`Basic realm="rclone"`, `Bearer realm="rclone"`}, authenticate)
+
+	// Good token
+	status, body, _ := do(bearer(makeToken(t, key, claims())))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "alice alice/files", body)
+
+	// Basic auth still works
+	status, body, _ = do(func(r *http.Request) { r.SetBasicAuth("user", "pass") })
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "user <none>", body)
+
+	// Bad tokens
+	for _, test := range []struct {
+		name  string
+		token string
+	}{
+		{"Garbage", "potato"},
+		{"WrongKey", makeToken(t, otherKey, claims())},
+		{"WrongAudience", func() string {
+			c := claims()
+			c["aud"] = "other"
+			return makeToken(t, key, c)
+		}()},
+		{"NoUser", func() string {
+			c := claims()
+			delete(c, "preferred_username")
+			return makeToken(t, key, c)
+		}()},
+		{"NoRoot", func() string {
+			c := claims()
+			delete(c, "rclone_root")
+			return makeToken(t, key, c)
+		}()},
+		{"EmptyRoot", func() string {
+			c := claims()
+			c["rclone_root"] = ""
+			return makeToken(t, key, c)
+		}()},
+		{"TopRoot", func() string {
+			c := claims()
+			c["rclone_root"] = "/"
+			return makeToken(t, key, c)
+		}()},
+		{"ParentRoot", func() string {
+			c := claims()
+			c["rclone_root"] = "../alice"
+			return makeToken(t, key, c)
+		}()},
+	} {
+		status, _, _ := do(bearer(test.token))
+		assert.Equal(t, http.StatusUnauthorized, status, test.name)
+	}
+}
+
+func TestBearerAuthOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-httplib")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:0"}
+	opt.JWKS = makeJWKS(t, dir, key)
+	opt.JWTIssuer = "https://issuer.example.com"
+	opt.JWTAudience = "rclone"
+	s := NewServer(testHandler, &opt)
+	require.NoError(t, s.Serve())
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL(), nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "pass")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, []string{`Bearer realm="rclone"`}, resp.Header["Www-Authenticate"])
+
+	token := makeToken(t, key, map[string]interface{}{"sub": "bob", "iss": opt.JWTIssuer, "aud": opt.JWTAudience, "exp": time.Now().Add(time.Hour).Unix()})
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
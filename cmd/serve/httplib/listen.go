@@ -0,0 +1,219 @@
+package httplib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// unixPrefix marks a ListenAddr as a unix socket
+const unixPrefix = "unix://"
+
+// ListenAddrs is the list of addresses to listen on.
+//
+// It can be read from JSON as a single string as well as a list so
+// options using the single address from before still work.
+type ListenAddrs []string
+
+// UnmarshalJSON reads a list of addresses or a single address
+func (l *ListenAddrs) UnmarshalJSON(in []byte) error {
+	var addrs []string
+	err := json.Unmarshal(in, &addrs)
+	if err == nil {
+		*l = addrs
+		return nil
+	}
+	var addr string
+	if json.Unmarshal(in, &addr) != nil {
+		return err
+	}
+	*l = ListenAddrs{addr}
+	return nil
+}
+
+// listenFdsStart is the first file descriptor passed by systemd
+// socket activation - a variable so it can be changed in the tests
+var listenFdsStart = 3
+
+// listen makes the listeners for the server
+//
+// If SocketActivation is set and rclone was started by systemd
+// socket activation then the sockets passed in are used, otherwise
+// one listener is made for each of the ListenAddr.
+func (s *Server) listen() (listeners []net.Listener, err error) {
+	if s.Opt.SocketActivation {
+		listeners, err = activationListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(listeners) > 0 {
+			fs.Infof(nil, "Using %d socket(s) from systemd socket activation", len(listeners))
+			return listeners, nil
+		}
+	}
+	if len(s.Opt.ListenAddr) == 0 {
+		return nil, errors.New("no address to listen on")
+	}
+	for _, addr := range s.Opt.ListenAddr {
+		ln, err := s.listenAddr(addr)
+		if err != nil {
+			closeListeners(listeners)
+			return nil, errors.Wrapf(err, "failed to listen on %q", addr)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// listenAddr makes a listener for addr which is either an ip:port
+// or a unix socket in the form unix:///path/to/socket
+func (s *Server) listenAddr(addr string) (net.Listener, error) {
+	if strings.HasPrefix(addr, unixPrefix) {
+		return s.listenUnix(addr[len(unixPrefix):])
+	}
+	return net.Listen("tcp", addr)
+}
+
+// listenUnix makes a listener on the unix socket at path setting its
+// owner and permissions from the options.
+//
+// The socket is made accessible to its owner only and the permissions
+// are only loosened once the owner has been set.
+func (s *Server) listenUnix(path string) (ln net.Listener, err error) {
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+	// Remove a socket left behind by a previous run
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	ln, err = listenUnixSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = ln.Close()
+		}
+	}()
+	if s.Opt.SocketOwner != "" {
+		uid, gid, err := lookupOwner(s.Opt.SocketOwner)
+		if err != nil {
+			return nil, err
+		}
+		err = os.Chown(path, uid, gid)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set socket owner")
+		}
+	}
+	err = os.Chmod(path, s.Opt.SocketMode)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set socket permissions")
+	}
+	return ln, nil
+}
+
+// lookupOwner parses owner in the form user, user:group or :group
+// where user and group may be names or numeric IDs.
+//
+// It returns -1 for the uid or gid if they weren't supplied.
+func lookupOwner(owner string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	userName, groupName := owner, ""
+	if i := strings.IndexRune(owner, ':'); i >= 0 {
+		userName, groupName = owner[:i], owner[i+1:]
+	}
+	if userName != "" {
+		uid, err = strconv.Atoi(userName)
+		if err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return -1, -1, errors.Wrap(err, "bad socket owner")
+			}
+			uid, err = strconv.Atoi(u.Uid)
+			if err != nil {
+				return -1, -1, errors.Wrap(err, "bad socket owner")
+			}
+		}
+	}
+	if groupName != "" {
+		gid, err = strconv.Atoi(groupName)
+		if err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return -1, -1, errors.Wrap(err, "bad socket group")
+			}
+			gid, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return -1, -1, errors.Wrap(err, "bad socket group")
+			}
+		}
+	}
+	return uid, gid, nil
+}
+
+// activationListeners returns the listeners passed to rclone by
+// systemd socket activation or nil if there aren't any.
+//
+// The environment variables are cleared so that the sockets are only
+// used once and aren't passed on to child processes.
+func activationListeners() (listeners []net.Listener, err error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(key)
+	}
+	for i := 0; i < nfds; i++ {
+		fd := listenFdsStart + i
+		name := fmt.Sprintf("LISTEN_FD_%d", fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(fd), name)
+		ln, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			closeListeners(listeners)
+			return nil, errors.Wrapf(err, "systemd socket activation: bad socket %q", name)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// closeListeners closes all the listeners ignoring errors
+func closeListeners(listeners []net.Listener) {
+	for _, ln := range listeners {
+		_ = ln.Close()
+	}
+}
+
+// listenerURL returns the URL for a server listening on addr
+//
+// Unix sockets are returned in the form http+unix://%2Fpath%2Fto%2Fsocket/
+func (s *Server) listenerURL(network, addr string) string {
+	proto := "http"
+	if s.useSSL {
+		proto = "https"
+	}
+	if network == "unix" {
+		proto += "+unix"
+		addr = url.PathEscape(addr)
+	}
+	return fmt.Sprintf("%s://%s%s/", proto, addr, s.Opt.BaseURL)
+}
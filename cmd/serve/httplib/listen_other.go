@@ -0,0 +1,12 @@
+// +build windows plan9
+
+package httplib
+
+import (
+	"net"
+)
+
+// listenUnixSocket listens on the unix socket at path
+func listenUnixSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,102 @@
+package httplib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("hello"))
+})
+
+// get fetches the root of the server using client
+func get(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return string(body)
+}
+
+func TestMultipleAddr(t *testing.T) {
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:0", "127.0.0.1:0"}
+	s := NewServer(testHandler, &opt)
+	require.NoError(t, s.Serve())
+	defer s.Close()
+
+	urls := s.URLs()
+	require.Len(t, urls, 2)
+	assert.NotEqual(t, urls[0], urls[1])
+	assert.Equal(t, urls[0], s.URL())
+	for _, url := range urls {
+		assert.Regexp(t, `^http://127\.0\.0\.1:\d+/$`, url)
+		assert.Equal(t, "hello", get(t, http.DefaultClient, url))
+	}
+}
+
+func TestBadAddr(t *testing.T) {
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:0", "127.0.0.1:-1"}
+	s := NewServer(testHandler, &opt)
+	assert.Error(t, s.Serve())
+
+	opt.ListenAddr = nil
+	s = NewServer(testHandler, &opt)
+	assert.Error(t, s.Serve())
+}
+
+func TestListenAddrsUnmarshalJSON(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    ListenAddrs
+		wantErr bool
+	}{
+		{in: `"localhost:5572"`, want: ListenAddrs{"localhost:5572"}},
+		{in: `["localhost:5572", "unix:///tmp/sock"]`, want: ListenAddrs{"localhost:5572", "unix:///tmp/sock"}},
+		{in: `[]`, want: ListenAddrs{}},
+		{in: `null`, want: nil},
+		{in: `5572`, wantErr: true},
+	} {
+		var opt Options
+		err := json.Unmarshal([]byte(`{"ListenAddr": `+test.in+`}`), &opt)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, opt.ListenAddr, test.in)
+	}
+}
+
+func TestLookupOwner(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		uid     int
+		gid     int
+		wantErr bool
+	}{
+		{in: "1000", uid: 1000, gid: -1},
+		{in: "1000:1001", uid: 1000, gid: 1001},
+		{in: ":1001", uid: -1, gid: 1001},
+		{in: "1000:", uid: 1000, gid: -1},
+		{in: "rclone-no-such-user", wantErr: true},
+		{in: ":rclone-no-such-group", wantErr: true},
+	} {
+		uid, gid, err := lookupOwner(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.uid, uid, test.in)
+		assert.Equal(t, test.gid, gid, test.in)
+	}
+}
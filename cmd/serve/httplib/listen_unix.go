@@ -0,0 +1,20 @@
+// +build !windows,!plan9
+
+package httplib
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnixSocket listens on the unix socket at path, making it
+// readable and writable by its owner only.
+//
+// The umask is changed while the socket is made so it never exists
+// with looser permissions - the umask is per process so files made
+// by other goroutines in the meantime get tighter permissions too.
+func listenUnixSocket(path string) (net.Listener, error) {
+	oldMask := syscall.Umask(0177)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,105 @@
+// +build !windows,!plan9
+
+package httplib
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-httplib")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	socket := filepath.Join(dir, "rclone.sock")
+
+	// A stale socket should be removed
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"unix://" + socket}
+	opt.SocketMode = 0600
+	opt.SocketOwner = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	opt.BaseURL = "rclone"
+	s := NewServer(testHandler, &opt)
+	require.NoError(t, s.Serve())
+
+	assert.Equal(t, "http+unix://"+strings.Replace(socket, "/", "%2F", -1)+"/rclone/", s.URL())
+	fi, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	assert.Equal(t, "hello", get(t, client, "http://localhost/"))
+
+	s.Close()
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSocketActivation(t *testing.T) {
+	// Not activated
+	listeners, err := activationListeners()
+	require.NoError(t, err)
+	assert.Nil(t, listeners)
+
+	// Pretend systemd has passed in a socket
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	file, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	fd, err := syscall.Dup(int(file.Fd()))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, ln.Close())
+	oldListenFdsStart := listenFdsStart
+	listenFdsStart = fd
+	defer func() {
+		listenFdsStart = oldListenFdsStart
+	}()
+	require.NoError(t, os.Setenv("LISTEN_PID", fmt.Sprint(os.Getpid())))
+	require.NoError(t, os.Setenv("LISTEN_FDS", "1"))
+	require.NoError(t, os.Setenv("LISTEN_FDNAMES", "rclone"))
+
+	// Only used if the server opts in
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:-1"}
+	s := NewServer(testHandler, &opt)
+	assert.Error(t, s.Serve())
+	assert.Equal(t, "1", os.Getenv("LISTEN_FDS"))
+
+	opt.SocketActivation = true // ListenAddr is ignored
+	s = NewServer(testHandler, &opt)
+	require.NoError(t, s.Serve())
+	defer s.Close()
+
+	urls := s.URLs()
+	require.Len(t, urls, 1)
+	assert.Equal(t, "hello", get(t, http.DefaultClient, urls[0]))
+
+	// The environment should be cleared so the sockets are only used once
+	assert.Equal(t, "", os.Getenv("LISTEN_PID"))
+	assert.Equal(t, "", os.Getenv("LISTEN_FDS"))
+	assert.Equal(t, "", os.Getenv("LISTEN_FDNAMES"))
+}
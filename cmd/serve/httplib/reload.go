@@ -0,0 +1,70 @@
+package httplib
+
+import (
+	"crypto/tls"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// certReloader holds the TLS certificate loaded from the cert and key
+// files so it can be replaced while the server is running
+type certReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+}
+
+// newCertReloader loads the certificate from certFile and keyFile
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	err := c.reload()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reload reads the certificate from the files again
+func (c *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load certificate")
+	}
+	c.mu.Lock()
+	c.cert = &cert
+	c.mu.Unlock()
+	return nil
+}
+
+// GetCertificate returns the current certificate for use in tls.Config
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// reload reloads the TLS certificate and the JWKS used by the server
+func (s *Server) reload() {
+	if s.certs != nil {
+		err := s.certs.reload()
+		if err != nil {
+			fs.Errorf(nil, "Keeping old TLS certificate: %v", err)
+		} else {
+			fs.Logf(nil, "Reloaded TLS certificate from %q", s.Opt.SslCert)
+		}
+	}
+	if s.jwt != nil {
+		err := s.jwt.Reload()
+		if err != nil {
+			fs.Errorf(nil, "Keeping old JWKS: %v", err)
+		} else {
+			fs.Logf(nil, "Reloaded JWKS from %q", s.Opt.JWKS)
+		}
+	}
+}
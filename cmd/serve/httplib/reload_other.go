@@ -0,0 +1,9 @@
+// +build windows plan9
+
+package httplib
+
+// startSignalHandler does nothing as SIGHUP isn't supported
+func (s *Server) startSignalHandler() {}
+
+// stopSignalHandler does nothing as SIGHUP isn't supported
+func (s *Server) stopSignalHandler() {}
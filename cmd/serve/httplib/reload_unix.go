@@ -0,0 +1,39 @@
+// +build !windows,!plan9
+
+package httplib
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// startSignalHandler reloads the TLS certificate and JWKS when
+// SIGHUP is received until stopSignalHandler is called
+func (s *Server) startSignalHandler() {
+	if s.certs == nil && s.jwt == nil {
+		return
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	s.stopSignals = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				s.reload()
+			case <-stop:
+				return
+			}
+		}
+	}(s.stopSignals)
+}
+
+// stopSignalHandler stops the SIGHUP handler
+func (s *Server) stopSignalHandler() {
+	if s.stopSignals != nil {
+		close(s.stopSignals)
+		s.stopSignals = nil
+	}
+}
@@ -0,0 +1,96 @@
+// +build !windows,!plan9
+
+package httplib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self signed certificate with serial to certFile
+// and keyFile
+func writeCert(t *testing.T, certFile, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+}
+
+// serverSerial returns the serial number of the certificate the
+// server at addr is using
+func serverSerial(t *testing.T, addr string) int64 {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	certs := conn.ConnectionState().PeerCertificates
+	require.NotEmpty(t, certs)
+	return certs[0].SerialNumber.Int64()
+}
+
+func TestCertReloadOnSIGHUP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-httplib")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, 1)
+
+	opt := DefaultOpt
+	opt.ListenAddr = []string{"127.0.0.1:0"}
+	opt.SslCert = certFile
+	opt.SslKey = keyFile
+	s := NewServer(testHandler, &opt)
+	require.NoError(t, s.Serve())
+	defer s.Close()
+
+	url := s.URL()
+	require.True(t, strings.HasPrefix(url, "https://"), url)
+	addr := strings.Trim(strings.TrimPrefix(url, "https://"), "/")
+	assert.Equal(t, int64(1), serverSerial(t, addr))
+
+	// Renew the certificate and tell rclone
+	writeCert(t, certFile, keyFile, 2)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	var serial int64
+	for i := 0; i < 100; i++ {
+		serial = serverSerial(t, addr)
+		if serial == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(2), serial)
+
+	// A bad certificate keeps the old one
+	require.NoError(t, ioutil.WriteFile(certFile, []byte("potato"), 0600))
+	s.reload()
+	assert.Equal(t, int64(2), serverSerial(t, addr))
+}
@@ -21,14 +21,14 @@ var Assets = func() http.FileSystem {
 	fs := vfsgen۰FS{
 		"/": &vfsgen۰DirInfo{
 			name:    "/",
-			modTime: time.Date(2020, 5, 5, 16, 40, 6, 115915195, time.UTC),
+			modTime: time.Date(2026, 10, 18, 16, 1, 13, 741794215, time.UTC),
 		},
 		"/index.html": &vfsgen۰CompressedFileInfo{
 			name:             "index.html",
-			modTime:          time.Date(2020, 5, 5, 16, 40, 5, 919909715, time.UTC),
-			uncompressedSize: 15424,
+			modTime:          time.Date(2026, 10, 18, 16, 1, 13, 741794215, time.UTC),
+			uncompressedSize: 17172,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x7b\x6d\x73\xdb\x46\xf2\xe7\x6b\xe9\x53\x4c\x98\xcd\x8a\x4a\xc0\xe1\x3c\x3f\x48\xa4\xf6\x6c\xc6\x59\xbb\x56\x71\x52\xb1\x9d\xad\x6c\x2a\x2f\x20\x62\x48\xe2\x0c\x02\x0c\x00\xea\xc1\x3a\x55\xdd\x87\xb8\x4f\x78\x9f\xe4\xaa\x67\x00\x12\x90\x28\x27\x7b\xf5\xdf\xbf\xec\xa2\x80\x9e\x99\x9e\x7e\xf8\x75\x4f\x37\x08\x4d\xbe\x18\x8d\xd0\xf1\x78\x8c\x66\xc5\xe6\xae\x4c\x97\xab\x1a\x31\x42\x25\xfa\x3e\xae\xeb\x95\xbb\x41\xaf\x8b\xac\x46\x71\x9e\xa0\xf7\x2b\x87\x66\x71\x92\xdc\xa1\x17\xdb\x7a\x55\x94\xd5\xf1\x78\x0c\xeb\x2e\xd3\xb9\xcb\x2b\x97\xa0\x6d\x9e\xb8\x12\xd5\x2b\x87\x5e\x6c\xe2\xf9\xca\xb5\x23\x11\xfa\xd9\x95\x55\x5a\xe4\x88\x61\x82\x86\x30\x61\xd0\x0c\x0d\x4e\xcf\x81\xc5\x5d\xb1\x45\xeb\xf8\x0e\xe5\x45\x8d\xb6\x95\x43\xf5\x2a\xad\xd0\x22\xcd\x1c\x72\xb7\x73\xb7\xa9\x51\x9a\xa3\x79\xb1\xde\x64\x69\x9c\xcf\x1d\xba\x49\xeb\x95\xdf\xa7\xe1\x82\x81\xc7\x2f\x0d\x8f\xe2\xaa\x8e\xd3\x1c\xc5\x68\x5e\x6c\xee\x50\xb1\xe8\x4e\x44\x71\xdd\x08\x0d\x3f\xab\xba\xde\x9c\x8d\xc7\x37\x37\x37\x38\xf6\x02\xe3\xa2\x5c\x8e\xb3\x30\xb5\x1a\x5f\xbe\x99\xbd\x7a\xfb\xee\xd5\x88\x61\xd2\x2c\xfa\x90\x67\xae\xaa\x50\xe9\x7e\xdf\xa6\xa5\x4b\xd0\xd5\x1d\x8a\x37\x9b\x2c\x9d\xc7\x57\x99\x43\x59\x7c\x83\x8a\x12\xc5\xcb\xd2\xb9\x04\xd5\x05\x08\x7d\x53\xa6\x75\x9a\x2f\x23\x54\x15\x8b\xfa\x26\x2e\x1d\xb0\x49\xd2\xaa\x2e\xd3\xab\x6d\xdd\xb3\x59\x2b\x62\x5a\xf5\x26\x14\x39\x8a\x73\x34\x78\xf1\x0e\xbd\x79\x37\x40\x2f\x5f\xbc\x7b\xf3\x2e\x02\x26\xff\x7c\xf3\xfe\xf5\x0f\x1f\xde\xa3\x7f\xbe\xf8\xe9\xa7\x17\x6f\xdf\xbf\x79\xf5\x0e\xfd\xf0\x13\x9a\xfd\xf0\xf6\xdb\x37\xef\xdf\xfc\xf0\xf6\x1d\xfa\xe1\x3b\xf4\xe2\xed\x2f\xe8\x1f\x6f\xde\x7e\x1b\x21\x97\xd6\x2b\x57\x22\x77\xbb\x29\x41\x83\xa2\x44\x29\x58\xd3\x25\xde\x74\xef\x9c\xeb\x89\xb0\x28\x82\x48\xd5\xc6\xcd\xd3\x45\x3a\x47\x59\x9c\x2f\xb7\xf1\xd2\xa1\x65\x71\xed\xca\x3c\xcd\x97\x68\xe3\xca\x75\x5a\x81\x57\x2b\x40\x07\xb0\xc9\xd2\x75\x5a\xc7\xb5\x27\x3d\xd1\x0b\xa3\xe3\xef\x8b\x04\xb8\x85\x19\x67\x08\xbd\x48\xe2\x4d\x1d\x4c\x55\xce\xb3\x22\x77\x68\x1d\x97\x1f\xb7\x1b\x34\x1a\x5d\x1c\x1f\x4f\xbe\xf8\xf6\x87\xd9\xfb\x5f\x7e\x7c\x85\x56\xf5\x3a\xbb\x38\x9e\x84\x5f\x47\x93\x95\x8b\x93\x8b\xe3\xa3\xa3\x49\x9d\xd6\x99\xbb\xb8\xbf\x87\x01\x84\xdf\xc6\x6b\xf7\xf0\x30\x19\x07\x2a\x8c\xaf\x5d\x1d\xa3\xf9\x2a\x2e\x2b\x57\x4f\x07\xdb\x7a\x31\x32\x83\xfd\x40\x1e\xaf\xdd\x74\x70\x9d\xba\x9b\x4d\x51\xd6\x03\x34\x2f\xf2\xda\xe5\xf5\x74\x70\x93\x26\xf5\x6a\x9a\xb8\xeb\x74\xee\x46\xfe\x26\x42\x69\x9e\xd6\x69\x9c\x8d\xaa\x79\x9c\xb9\x29\xc5\xe4\x09\xa3\x65\x51\x2c\x33\xd7\x61\x93\x17\x75\x19\xe7\x55\x16\xd7\x6e\x70\x71\x3c\xa9\xea\x3b\x10\xeb\x6b\x74\x8f\x36\x71\x92\xa4\xf9\xf2\x0c\x91\x73\xd0\x78\x99\xe6\xfe\xf2\xe1\xf8\xaa\x48\xee\xd0\xfd\xf1\xd1\xa2\xc8\xeb\xd1\x22\x5e\xa7\xd9\xdd\x19\xaa\xe2\xbc\x1a\x55\xae\x4c\x17\xe7\xc7\x47\xb5\xbb\xad\x47\xa5\x03\xe3\x7a\x0e\xc5\xa6\x4e\xd7\xe9\x27\x57\x6d\x9c\x4b\xce\x8f\x8f\xae\xe2\xf9\xc7\x65\x59\x6c\xf3\x64\x34\x2f\xb2\xa2\x3c\x43\x5f\x2e\xfc\xcf\xf9\xf1\xc3\x71\x0c\xbc\x5b\x32\x21\xca\x25\xbc\x65\x99\xb8\x79\x51\x7a\xc7\x9c\xa1\xbc\xc8\x9d\x9f\x7e\xb6\x02\x6f\x47\xc7\x2b\x8a\x9a\xeb\x2e\x03\x4e\xed\x3c\xf0\x05\x87\xc0\xbc\x2f\xab\xed\x7a\x1d\x97\x5e\x85\x46\xc7\x51\xe6\x16\xf5\x19\x92\x5f\x9d\xef\x49\x3e\xc7\x04\xda\xc3\x71\xbd\x3a\x5b\xa4\x65\x55\x8f\xe6\xab\x34\x4b\xa2\xe3\x3a\xe9\xde\x03\x27\xef\x81\x33\x44\xbf\x3a\x47\xe3\xaf\x51\x0d\x8b\x5d\xe9\x21\xba\x2e\xae\x20\x45\x7c\x3d\x0e\x7c\xb2\xb8\xc7\x66\x7f\xfb\xe7\xb9\x04\x4d\xba\xf2\xd7\xc5\xe6\x0c\x31\xb9\xb9\xed\x28\x70\x55\xd4\x75\xb1\x3e\x43\x34\x90\x0f\xd9\x9c\xc1\x3f\x6f\x1b\xba\x73\x68\x95\x7e\x72\x67\x88\x11\xbf\xc8\x53\x6e\x5c\x30\x45\x5e\x94\xeb\x38\x3b\x3f\x3e\xba\x59\xa5\xb5\x1b\x55\x9b\x78\xee\x80\x7a\x53\xc6\x9b\xf3\xe3\x23\xb0\xfc\x22\x2b\x6e\x46\xb7\x67\x68\x95\x26\x89\xcb\x5b\xb7\xb5\x23\x67\xc8\x65\x59\xba\xa9\xd2\xea\x7c\xef\x20\x6b\x6d\x23\xc1\x23\xc7\x93\xf3\xe3\xa3\x1d\xee\x90\x00\x79\x1e\x1e\x39\xf9\x09\x28\x7c\x3c\x67\x69\x40\x86\x9f\xfb\xc8\x4d\x7b\x20\x1f\x3f\x1c\xaf\x21\x03\xdf\x1f\x1f\x25\x69\xb5\xc9\xe2\xbb\x33\x74\x95\x15\xf3\x8f\x30\x82\x7d\xc8\xf4\x4d\x42\xd9\xde\x24\x2d\xea\x7f\x76\x65\x12\xe7\x71\xd4\x87\xff\x55\x51\x26\xae\xdc\x3b\x60\x73\x8b\xaa\x22\x4b\x13\xf4\xa5\x9d\xc1\xbf\xf3\x47\x8e\xa3\xe4\xb0\xe3\x48\xd0\xd9\x0b\x33\x4a\x6b\xb7\xde\x6b\xd0\xc2\x93\xba\x35\x4c\xf9\x72\x91\x66\x75\x0f\x12\x67\xc1\x62\x8d\x2c\x3d\x21\x66\xb3\x99\xc7\xb4\x3f\x0e\x3a\xa0\x23\xe4\xab\xbd\xf0\xf3\x22\xcb\xe2\x4d\xe5\xce\x50\x7b\xe5\xd7\xf8\x2d\x0e\xe8\x97\xc4\xd5\xca\x25\xe8\xcb\x24\x86\x7f\x7e\xaa\x4f\x13\x75\xb9\xf7\xd6\x33\x51\xef\xe6\x21\xc2\x20\x1c\x76\x4e\x8d\xb3\x74\x99\x9f\x21\x88\xcb\xf3\x8e\x4e\x60\x12\x04\x7e\x80\xf0\x98\xad\xe2\x7c\xe9\x12\xb4\x28\x8b\x35\x22\x90\x9f\x59\x1b\x65\x4f\x62\xe3\xb3\x26\xee\x79\x59\x79\xca\x41\x88\x7b\xce\x5d\x94\x5e\x65\x71\xc0\x4b\xbd\x42\xd5\xf5\x12\x46\xae\x5d\x59\xa7\xf3\x38\x6b\x35\x58\xa7\x49\x92\x05\xdb\x85\x08\x3f\x18\x3b\x5d\x01\x1a\xa4\xd7\xc9\x59\x5e\xaf\x02\x72\x87\xec\xb4\xe3\x28\x43\xbe\x7a\x32\x81\x9f\xf6\x7c\x4f\x7c\x00\x37\xbf\x9a\x04\xb6\x9f\x2c\x4e\xa3\xfe\x6a\x71\xfa\xd8\xf0\x1e\x5e\x87\xc4\x68\xd4\xdc\x14\x55\x1a\x42\x2e\xbe\xaa\x8a\x6c\x5b\xb7\x2a\x62\x38\x67\xbc\x2b\xf1\xb2\xd8\x6e\x3a\x88\x0d\x39\x96\x62\x2d\x01\xb3\x47\x37\x45\x99\x8c\xae\x4a\x17\x7f\x3c\x43\xfe\xd7\x28\xce\xb2\x6e\x1a\x01\xd3\xb4\x43\x30\xf9\xb1\x57\x36\xa5\x1b\xb5\x7e\xc1\xe9\xbc\xc8\x9f\x46\x87\x6c\x02\x08\x46\x71\x55\x94\x75\x2f\xda\xd3\x1c\x32\xc5\xa8\x09\xfa\x5d\x18\x78\xe9\x56\xae\x13\x5f\x1d\x6d\x4b\x97\xc5\x75\x7a\xed\x20\xb5\x01\xae\x30\x0b\x01\xd8\xd9\x02\xd7\xc5\xe6\x39\x13\x1d\x05\x23\x90\x76\xf9\x88\x3e\x91\x10\x07\x6c\x3e\xcb\xa1\x85\x6e\x58\xba\x67\xf8\x70\xbc\x28\x8a\x27\x39\xc0\xc7\xcb\x53\x90\x87\x54\xd6\x75\xf8\xdc\xe5\xb5\x2b\x81\xcd\xff\x58\xbb\x24\x8d\xd1\x70\x1d\xdf\x8e\x1a\x9b\x28\x42\x36\xb7\x80\x91\xf1\xd7\x47\x78\x95\x26\xae\x4d\x1d\x7b\x63\x86\xe3\xf8\xe8\x01\x95\x6e\x5d\x5c\x87\x72\xe9\xa3\x73\x1b\x94\xc4\xb5\xab\xa0\x3e\xdc\x9f\x60\x47\x07\xb0\xdd\x9a\x3f\xde\xd6\x05\xf0\x39\x3e\xea\x41\x96\x9f\x46\x8f\x96\x05\xc4\x1f\x3a\xae\x8f\x0e\x21\x19\x38\x86\x53\xee\xd1\x11\x13\xe8\x3e\xaa\xbb\xa7\x03\xd0\x3b\x59\xf5\xa8\x63\x0d\x4a\x82\x41\x1f\x8e\x1f\x8e\x27\xe3\xa6\x62\x3a\x9a\x8c\x9b\x8a\x6f\xe2\x13\x5f\x91\x67\x45\x9c\x4c\x4f\x02\x8b\xe1\xe9\x79\x5d\x2c\x97\x99\x1b\x0e\x7c\xee\x1c\x9c\x9e\xcf\x7d\xf6\x7a\x97\x7e\x72\xc3\xd3\x13\x5f\xa6\x41\x68\x5d\x87\x16\x64\x3a\xa0\x98\x0e\xd0\xed\x3a\xcb\xab\xe9\xa0\xd3\x01\xdc\x70\x5f\xfd\x33\x42\xc8\xb8\xba\x5e\x36\x53\xce\x6e\xb3\x34\xff\x78\x68\x22\xb5\xd6\x8e\xfd\xe8\x00\x05\x4c\x4f\x07\x64\x80\x42\xf1\x08\x57\x5e\xfc\xe9\xe0\x00\xd4\x7c\xed\x78\x34\x49\xdc\xa2\xf2\x57\x47\xbe\x05\xfb\xae\xc8\xa0\xf6\x80\xda\xd7\xd3\x96\x28\x4d\xa6\x83\x85\xa7\x0e\xa0\x19\xca\x46\xe5\x16\x38\xe6\x45\xfe\xc9\x95\x45\xa0\xf9\x5b\x17\x38\x1e\x1d\x4d\x36\x71\xbd\x42\xc9\x74\xf0\x3d\x33\x12\x33\x86\xb8\xc6\x52\xae\x46\x54\x30\xac\x2e\x29\x25\xd8\x22\xf2\x9a\x53\xac\x67\x54\x60\x26\x11\x41\x04\x51\x05\xd4\x30\xf5\x5a\x4b\x4c\x57\x1c\x48\xec\x67\xb8\x9e\x93\x11\x23\x58\xc9\x11\xcc\x57\x23\x3f\x69\x04\x0c\xc2\xe5\xa7\x56\x8a\x2f\xbf\xfb\xee\x05\x21\x64\x30\x7e\x56\x12\xd5\xdd\x97\x2b\x44\x90\x24\x98\x19\x44\x90\xd2\x58\x8b\x6b\x2a\x0d\xd6\x73\x82\xa8\xc6\x42\x23\xbf\x1d\x82\x15\xd2\x7f\x86\xcb\xd7\x9e\xd9\x1c\xa6\x08\x10\x19\xe4\xa0\x02\xf3\x70\xe5\xa7\xfc\x0c\xdc\xe4\x9c\x8c\x3c\x9f\x56\x6c\x18\x19\xed\x27\x75\xc5\x9e\xbd\x60\xa6\x15\x7b\x32\x5e\x1e\xb0\xfe\xa8\x5a\x15\x65\x3d\xdf\xd6\xe0\xd4\xb2\xf8\xe8\x1a\xa3\x37\x77\xa3\xc6\xe7\xb4\xe7\x91\xae\xc7\xdc\xb5\xcb\x8b\x24\xd9\x79\xe9\x20\xf3\x11\x9c\xe0\x9b\x83\x9e\x6e\xd6\x3d\xb7\xb0\x5a\xc5\x9b\x1d\x04\x9e\x9a\x5e\x18\xad\x22\xf0\x96\x30\xca\x12\x86\x2e\x3d\x1a\x28\x13\xdc\xf4\xc9\x00\x0f\x46\xb4\x91\x11\x41\x97\x9c\x62\x65\xa9\x92\xcc\x46\x04\x79\xaf\x35\x4b\x08\x22\x11\x55\xd8\x58\x65\x29\x51\x88\xf4\x78\x90\x88\x52\x86\x95\x50\x44\x53\xe0\xa1\x70\xc3\xe3\x19\xb2\x96\x98\x58\xcd\x0d\x91\x68\xd6\x21\x4b\x81\x85\x90\x8a\x10\x83\x38\x61\x58\x49\xc9\x8c\xec\x6e\x74\x58\xb3\x7f\x0d\xbc\x7d\xde\x79\x7b\x3c\x02\xe6\xc5\x64\x0c\x76\xf9\x03\x2b\xa9\x9e\xe2\x5c\xf5\x34\x07\xd0\x46\x1e\xb4\xdc\x48\x65\x10\x89\x3c\x72\xa9\x25\xdc\x82\xea\x8c\x29\x2c\x24\x15\x4c\xa0\x19\x89\x98\xe0\xd8\x12\x2b\x34\x45\x1d\x1e\x4c\x1a\x4c\x2d\xe7\xcc\xa0\xce\x46\x1d\xea\x65\x47\x9c\x0e\x79\xd6\xb1\x43\x8f\xc7\xce\x66\x9d\xfd\xba\xd4\xbd\x4c\x5d\xbb\x77\x04\xef\xd9\x7d\xaf\x5c\xd7\xee\x0a\xf5\x6d\xf4\x8c\x9d\x7d\x24\x3d\xb2\xf3\x2e\xa2\xba\x16\xa7\x4c\x61\x2a\x05\xe5\x22\x62\x92\x60\x29\x2d\x35\x02\xcd\x80\x6c\x24\xb1\x1a\xc8\x14\x1b\xc3\x95\xe6\x88\x32\x8d\x39\x21\x52\x80\x99\x38\x26\x44\x51\xc6\x3c\x55\x6b\xa6\x08\x8b\x98\x14\x98\x06\xea\x8c\x32\x83\x85\xb2\x42\x00\x59\x62\xd6\x4e\x36\xd8\x52\x4b\x28\x98\x54\x61\x4a\x04\x31\x40\xb5\x58\x71\xc3\x39\x58\x54\x63\x42\x18\x11\x14\xcd\x28\xf7\x12\x59\xc5\xbc\xa1\x39\x53\x92\x53\x44\x21\x6f\x30\x63\x24\x4c\xb6\x88\x72\x8e\x29\x21\x44\x6a\x7f\x3b\xa3\x5c\x60\x61\xb9\xe6\xba\x19\x96\x58\x50\xc9\x95\xf0\x3c\xa4\xa4\x84\x21\xca\x15\xa6\x94\x31\x22\xfc\x7e\x4a\x4b\xe9\xb7\x53\xd8\x10\x4b\x84\xe8\x4a\x41\xb9\xc6\x4c\x1a\x45\xad\xd7\xc3\x1e\xa0\x0a\x2c\x75\xcb\xa2\x43\x06\x10\x04\xf5\xba\x54\x86\xcd\x9e\x4a\x38\x37\x9c\x79\x1b\x0b\xa9\xa9\xe0\x41\x0a\x6d\x94\x54\x2a\x62\xc2\x62\x4b\x0c\x55\xdc\x4b\x2c\x15\xd5\xda\x7a\x2a\xf1\xb6\xe8\x53\x0d\x96\xc1\x4d\x9e\x05\x31\x56\x33\x60\xc1\xb0\xa1\x82\x19\xe5\x2d\x61\x94\xb0\xdc\x46\x8c\x6b\xc8\x2f\x82\x98\x3e\x95\x63\xa6\xb9\x50\xde\x8a\x7b\x32\x93\x98\x04\xe1\xba\xb2\x51\x8d\x65\xcb\xd8\x60\x6a\x08\x13\x40\x25\xd8\x08\x65\xb9\x67\x61\xb1\xb6\x46\x53\x1e\x31\x22\x30\x6b\x0c\x27\x00\x4e\x96\x71\x11\x51\x6b\xb0\x82\xed\x04\xa2\x42\x60\xc1\x2c\x67\x26\xa2\x96\x63\xad\x40\x3f\x88\x78\x8d\x19\x55\xca\xd8\x88\x1a\x83\x8d\xb2\xdc\x18\x44\x25\xc1\x4a\x1b\x41\x69\x44\x8d\xc0\x26\x88\x4c\xa5\xc0\x86\x2b\xab\x79\x44\x0d\x6d\xc1\x32\x83\xb3\xcc\x5a\x29\xb9\x8c\xa8\x06\xa0\x5a\x69\x19\xa2\x8a\x63\xc5\x14\x15\x36\xa2\x5a\xec\xf0\xad\x0c\x16\x86\x4a\xc9\x22\xaa\x19\x56\x80\x58\x08\x06\xcd\x31\xe7\xca\x4a\x11\x51\x4d\xb0\xe0\x46\x6b\x85\xa8\xb6\x98\x52\x6e\x0d\x8f\x60\x9d\x52\x92\x13\x85\xa8\x91\x58\x1a\x6d\x80\x85\xd2\x98\x0b\x70\x1f\x9a\x51\xcb\x30\x51\x54\x33\x20\x2b\xcc\x28\x6c\x88\xc0\x00\x5a\x11\xae\x4d\x44\x95\xc4\x5c\x30\x23\x35\x62\x44\x7a\x29\xa8\x88\xa8\x12\x58\x05\xa5\x67\x8c\x32\xb0\x32\xd5\x9e\xca\x82\xf7\x18\xb5\x58\x5a\x43\x85\x8a\x40\x25\x6b\x21\x7e\x11\x63\x06\x53\xc5\xbc\xce\x7b\xea\x25\x13\x0a\x13\x09\x21\xfe\x2c\xd9\xca\xd6\xa9\xb3\x1e\x59\x63\x0d\x16\x92\x08\xa8\x5a\x32\xc1\x81\x6a\x31\x44\x13\x11\x08\xc0\xc7\x35\x31\xd6\x46\x8c\x50\x4c\x9a\x2c\x02\x19\x85\x51\x88\xbe\x88\x41\x0e\x0b\x50\x86\x10\x20\xda\x1a\xa3\x22\x46\x38\x96\x21\x31\x40\x14\x71\xcd\x34\x95\x5d\xea\x0c\x92\x84\x50\x9c\xf1\x47\x93\x0d\x96\x9c\x32\xad\x7b\x8c\x15\xc1\x60\x62\xc6\xbb\x52\x5c\x72\x48\x71\x84\x31\x00\x11\xd7\x58\x5b\x80\x00\x9a\x71\x48\x5b\x8c\x68\xa9\x23\x80\x35\x13\xc6\x1a\xc4\x99\xc1\x4a\x30\x6e\x44\xe4\xf3\x88\x8f\xea\x1e\x91\x61\x06\x8e\xa6\xc0\xa0\x43\x26\x98\x00\x95\xa1\x2e\x5b\x66\x30\x0b\x81\xd3\x95\x81\x29\xac\x83\xc0\x97\x1d\x89\x15\xc7\x42\xb4\xae\xf6\x89\x8a\x6b\xa9\x22\x45\xb1\xb1\x01\xb3\x1d\x53\x28\x1a\xec\x65\x25\xb5\x02\xee\x66\x1d\xa3\xfa\x41\x82\x19\x57\x8a\xb3\x1e\x03\xf0\x92\xe5\x5c\xeb\xfe\x6e\xe0\x52\x2d\x2c\x8d\x94\x68\x40\x21\xbc\x9f\x89\x36\x44\x47\x4a\x61\x0d\x33\xb5\xe9\x12\x21\xa8\x02\x88\x2f\xf7\x54\x4a\x48\x8b\x88\xcb\x2e\x06\xf7\xe4\x19\xa0\xdf\x28\x4e\xc0\x68\x7b\x32\xe4\x7f\xaa\x14\x33\x2c\xa2\x54\x63\xaa\x34\x87\xc2\x93\x4a\xcc\xb4\x10\x5c\x47\x10\xf2\xa2\x3d\x9b\x28\xc1\x4a\x29\x4e\x00\xc6\x14\x2a\x0e\x6b\x10\x25\x06\x73\x49\xac\xe5\x11\xd5\x12\xf3\x86\x6f\x87\x6a\x29\xd6\x21\xc0\x66\x1d\x32\x04\x1b\x6b\x72\x02\x85\x84\x1d\xa0\xc6\x38\xb6\x10\xfc\x12\x51\x26\x20\x46\x85\x14\x11\x13\xba\x0d\xfe\x19\x85\xa4\x48\xb4\x66\x3e\xf1\xd2\x76\xae\x84\x34\xce\xac\x08\x49\xba\x55\xee\xd0\x11\xfb\xcc\xc1\x0d\x3f\x03\xe4\x1f\x57\x2f\x8a\x72\x3d\x1d\xec\x9e\x5c\x0f\x19\x35\x58\x58\x9f\x0c\x11\x55\x04\x13\xff\x73\x8a\xfc\x83\xf0\xe1\x88\x46\x88\x9e\xa2\xfd\xf4\x51\x77\xfe\xa8\xbb\xe0\x51\x61\xb0\xaf\xb4\x77\x17\xbe\x09\x82\x46\xf6\x71\x0b\x94\x66\x6e\x5f\x79\x43\x73\xf9\xb8\xf2\x66\xb2\xab\xcc\xc1\xd2\xbb\x5d\x91\xa5\xb9\x9b\xc7\x9b\xe9\xc0\x3f\x2e\xeb\x91\xff\x67\x91\xe6\x2d\xfd\x49\x17\x43\x39\x62\x02\x53\x76\xcd\x34\xb8\x66\x4e\x90\xc2\x54\x21\x89\x0d\x40\x06\x53\x38\x59\x31\x6d\xae\x5f\x33\x6e\xe7\x1a\x73\x68\xae\x80\x3a\x12\xd8\xaa\xe6\xd2\x4f\xf8\xd9\xd7\x02\xf2\x1d\x44\x36\x0c\xf8\x0a\x85\x6b\x44\xf9\x6b\xf0\x9b\x9e\x51\xe3\x19\x73\xff\x5f\x87\xd5\x41\x80\x4f\x07\x5a\x2c\x40\xb2\x5f\x7d\x49\x99\x0d\x90\x82\x46\x8a\x60\x69\x90\x86\x3e\x8a\x5a\x4c\xa1\xcf\x63\xda\x5f\xbe\x66\xc2\x5e\xee\x16\x7d\x7a\xb6\xfb\x49\x33\xf7\x9f\xea\x7d\xba\xac\xdb\xce\xe7\x20\x00\x29\x6f\x20\x14\xa1\xdd\xe5\xe9\x93\x8e\xa8\xc7\x2e\xf4\x43\x3d\xc4\xfc\x21\x68\x3c\x6e\xfe\xbf\x30\xd2\x75\x04\xb4\x3f\x98\x32\x2a\x8c\x51\xbe\x23\x30\x00\x10\x23\xb4\xf6\x1d\x01\x9c\xc7\xdc\x5a\x26\x3c\x6e\x84\x35\xbe\xc8\xb7\x0a\x5b\x6b\xad\xe1\x01\x21\xcc\x70\xcd\xbb\xd4\x4b\xa8\x85\xac\xd5\x70\xe8\x77\xc8\x33\x5f\x39\x59\xe9\xcb\xe5\x3d\x99\x71\x8b\xa9\x26\x86\xed\xb6\x13\xac\x4b\xdc\x4b\x74\xb9\xa7\x52\xc6\x31\x15\x32\x64\xe6\x43\x54\x0a\x47\xbe\x91\x82\x46\x0c\x1b\xc1\xa8\x26\x56\xb8\x11\x15\x3e\x5d\x72\x65\x05\x1c\x7f\xfd\x91\xcb\x46\x1b\x09\x3a\xf6\x87\x66\x20\x83\x24\xc4\x12\x1d\x8d\x28\xd6\x54\x68\x6b\x0d\x73\x23\x22\x11\x89\x20\x58\x08\x63\xd6\xc2\x4d\xc7\x9e\x4d\xf2\x2a\xdd\xbc\xa6\x54\xd3\xcf\x74\x74\x94\x2a\xa8\x0c\x88\x6f\x64\x29\x55\x3e\xeb\x5b\x22\xac\xf2\x99\x5c\x45\x94\x52\x2c\x0c\x9c\x55\x08\x94\x64\xd2\x10\x62\x22\xca\x20\x5e\x19\x94\xa3\x70\x5c\xc1\xed\x25\x24\x66\x7f\xf1\x98\xe7\xee\xa6\x2b\x96\xb6\xe2\x4f\x35\x40\x42\x63\x43\x38\x05\x73\x5a\x81\x49\x38\xe8\x66\x02\x52\xa7\xb5\x86\x02\x59\x62\x1a\xca\x7b\x61\xb0\x15\x56\x4a\x19\xbc\x4f\x42\x01\x25\x2c\x16\x8c\x2a\xb2\xc3\x84\xa7\xce\x24\xc1\x94\x1a\x21\x0c\x60\x42\x63\x13\xc8\x70\x00\x28\x43\x18\xd3\x11\xf3\xe5\xaf\x2f\x1a\x24\xc5\x0c\xca\x58\x28\x7e\xac\xc5\x3c\xd4\x92\x33\xc9\x30\x23\xc6\x2a\x63\x22\x4e\x08\x16\xfe\xa4\x93\x1c\x73\xad\x7d\x33\xc1\x09\x45\x52\x60\x2d\x2c\x51\x5c\xf8\xdb\x19\x34\x55\x82\x69\xc1\x55\x18\xd6\x98\x28\xc1\x35\xb1\x9e\x85\x0a\x7d\x83\xd4\x58\x2b\xca\xbc\x76\x16\x3a\x4e\xce\x34\x9a\x49\x83\x85\x34\x44\x36\xe4\x46\x0a\xa8\x9f\x89\x56\x0c\x0e\x40\x0b\x2d\xdd\x53\xaa\xc6\x1c\xea\x19\xee\x59\xec\xc9\x0a\x9b\x46\xbd\x2e\x55\x62\xbb\xa3\x2a\x03\x3d\x2e\xf3\xa6\x37\x80\x4f\x1a\xa4\xe0\x52\x6a\x06\x93\x39\xb4\x37\x50\x84\x4b\x83\x19\x25\xbe\xae\x86\x60\x32\x8d\x2d\xfa\x54\xd1\x74\x61\xa0\x1e\x37\x9a\x43\x24\x18\x8d\x75\xa8\xc1\x24\x34\x2c\xdc\x0a\x49\x23\x66\x38\xd6\xa1\x8c\xeb\x52\xb5\xc5\xd6\xf7\x87\xb3\x1e\x95\x63\x16\x64\xeb\x8a\xa6\x74\xdb\x14\x49\x8b\x0d\xb3\x4c\x42\xb7\xa5\x28\x56\x4d\xf3\xaa\x28\x16\xc2\x17\x08\xe0\x92\x60\x35\xc5\xb1\xe4\x86\x09\xc2\x7d\xcb\xa7\x42\x81\xa0\x7c\xfd\xc4\x39\xb4\xd5\x42\x63\xc5\x84\xb0\x68\xa6\xa0\xdf\x91\xbe\xeb\x60\x02\xba\x15\x5f\xf0\x6b\x86\x39\xd3\x82\xfa\xc2\x83\x60\xee\xc5\xd5\x0a\x0b\x23\xad\xb6\xcc\x77\x76\xc1\x36\x33\x43\xb0\x12\x42\x0a\x0a\x54\x81\x65\x68\xcb\x0c\xd4\x3b\x92\x4a\x45\x23\xc6\x59\x8b\x6c\x4b\x30\xe5\x44\x4a\xf0\x05\x07\xb6\xa1\xd4\xb2\x02\x5b\x23\xad\x02\x79\x99\xc1\x32\x74\x33\x10\xc2\x5a\x31\x28\xf6\x99\xf6\x8d\x26\x1c\x8a\x44\x43\xc9\x69\x64\x78\xd0\x41\xda\xa7\x00\x94\x63\x4d\x89\x66\x26\xf4\x91\x06\xf6\x43\x94\x11\x2c\x20\xd6\x64\xc4\x18\xd4\xfd\x54\xc0\x69\xc9\xb4\x97\x82\xf9\xfa\xcb\x04\x85\x67\xd0\xdf\x1b\x66\x29\xd4\xfa\x8c\x63\x11\xdc\x06\x6d\x24\x13\x9a\x36\x93\x59\xd3\x19\x0a\x8b\x0d\xa5\x52\xf4\xa8\x97\xd0\x89\x69\x22\xa4\x35\xcf\x92\xa1\x5c\x6b\x1b\xf0\x0e\x59\x12\x48\x8f\x92\x86\xd6\x90\xd0\xf0\xdc\x88\xed\x1e\x45\x68\x82\x09\xb5\x96\x48\xdf\xee\xcb\x26\x7b\x50\x4d\xa1\xc8\xf5\xcf\x38\x04\x36\x01\xc1\xd0\x45\x6a\x66\x0c\x14\x9d\x52\x62\x19\xf2\x01\xd5\x0a\x13\xe6\x1b\xc3\x0e\x75\x46\x75\x53\x54\xf6\xc8\xd4\x10\xdf\x68\x43\x4a\xe9\x30\x36\x14\x1b\x46\x01\xec\x7b\x19\x2e\x01\x49\x5a\x52\x66\x95\x6f\x86\x4c\xe8\xb3\x67\xa0\x28\x34\xc9\x0a\x4a\x5f\xc8\x45\xbe\xd2\xf6\xfd\x82\xa5\xdc\x86\xae\x8e\x86\x84\xd0\xa3\x6a\xcc\x9a\xc6\xa9\x47\x96\x58\xb4\xcd\xc5\x8e\x31\x85\x44\x1a\x22\xa6\x23\x05\xb4\xc0\x3a\x48\x7c\xb9\x17\x19\xfc\xb8\x7b\xdc\x63\x08\x66\x84\x79\x16\xdc\x62\xdd\x3c\x1a\xd8\x9b\x82\x72\x1b\x0c\x26\x04\x83\xea\xdf\x3f\x65\xd8\x9b\x35\x0c\x53\x6c\x8c\x54\xdc\xf6\x79\x10\x4c\x9a\x56\xad\xbb\x21\x38\x95\x71\x0b\x3d\xb5\x60\x3b\x10\x81\xff\x99\x26\x70\xee\x08\x60\x1e\x9e\x93\x74\xa9\x12\xc2\x18\xfa\x80\xcb\x2e\x59\xef\x9e\x3a\x5c\x76\x80\xd8\x21\xcf\x8c\xc1\x92\x32\x62\xe1\x84\xdb\x93\x01\x64\x54\x32\xe8\xdd\xa8\x11\xd8\x86\x67\x54\x5c\x61\xcb\xb8\xef\x7e\x7c\xeb\xdf\x60\x8b\x33\xcc\xa9\xe4\x44\x43\xe8\x50\xcc\x82\x03\x39\xf1\xd1\x2c\x03\x43\xb8\x13\x12\xdb\x10\x56\x33\xb8\x85\x73\x20\x24\x00\x2e\xb1\x94\x60\x4e\x16\x31\xcd\xc0\x93\x86\x6b\x24\x14\x04\xa4\x50\x04\xce\x25\xda\x46\xfa\x4c\x28\xac\xa4\xd2\x4c\xa9\x50\xc3\x34\x93\x35\xa6\x44\x71\x42\x6c\x48\xc6\x61\xd7\x83\x27\x69\xb7\xcd\x19\xcd\x8a\xcd\xdd\xae\xd2\x6b\x4b\xc1\x43\x5f\xa7\x1c\x2e\x3f\x05\x81\x1a\x48\x59\x19\x21\xc6\xfe\xb8\xff\xe9\xce\x1f\x75\x17\xfc\xb9\xfe\xe7\xc3\x06\xc5\x65\x59\xdc\x3c\xee\x81\xb6\x9b\x91\xa7\x3f\x23\xe5\x08\x4e\x11\xc6\xd0\x88\x11\x83\x29\x3b\xed\xf7\x2f\x9d\x25\xeb\xb8\x2e\xd3\xdb\x21\x66\x4c\x50\xee\xbf\xfd\xc1\x14\x4e\x7b\xc4\xb9\xc4\x4a\x23\xaa\x04\xe6\xf2\xf4\x71\xad\x4c\x06\x50\xb6\xac\x47\x10\x64\x54\x23\x41\x19\x26\x74\x35\x62\x06\x1b\xa6\x9b\x5f\x19\x15\x58\x50\x31\x62\x50\xbf\x49\x74\xe8\x0e\x85\xbb\x03\x0d\x07\xe8\xfe\x6d\x71\x93\x1f\xd6\x3e\x29\x6e\xf2\xff\x94\xfe\xa3\xbe\x01\x00\xb3\x96\xff\x77\x1b\x60\x32\x6e\xbf\x0c\x9c\x8c\xab\x6b\x4f\x9b\x84\x77\x91\xc2\xf0\x8a\x86\xf9\xf7\xf7\x65\x9c\x2f\x1d\xfa\x4b\x1a\xa1\xbf\xcc\xcb\xed\xfa\x0a\x9d\x4d\x11\x7e\x59\xba\x38\xf1\xb7\x0f\x0f\x93\x18\xad\x4a\xb7\x98\x0e\x9a\xf7\xe2\xc2\x34\x7c\x99\xe6\x1f\x1f\x1e\x06\x17\x7d\xea\x7b\x77\x5b\x3f\x3c\x4c\xc6\xf1\xc5\xfd\x7d\xba\x40\x39\x70\x46\xe4\xe1\x61\x7c\x7f\xef\xf2\xe4\xe1\xa1\xf9\x15\x44\x0c\x42\x84\x6f\x63\x83\x60\x93\x75\x9c\xe6\xcd\x97\x99\xe9\x35\x9a\x67\x71\x55\x4d\x07\x6b\x57\xc7\x8d\x03\x3c\x19\x3c\xd8\xbc\x19\xb6\xf3\x4b\xb5\x89\xf3\xee\x7c\xff\x12\xce\xe0\x62\x92\xe6\x9b\x6d\x8d\xea\xbb\x8d\x9b\x0e\x6a\x77\x5b\x0f\xd0\x26\x8b\xe7\x6e\xe5\xbf\xf1\xf2\x7d\x5e\xed\xca\x41\xdb\xf3\xf9\xeb\x22\xff\xe8\xee\xb6\x9b\xfd\x17\xc2\x27\x17\x93\x31\xf0\x6f\x4d\x9c\xa4\xd7\xad\x91\xdb\xab\x8e\xb4\x59\x5a\xd5\x69\xbe\x6c\x05\x0e\xef\xee\xc4\x65\x1a\x8f\x12\x57\xcd\xcb\xf4\xca\x25\x57\x77\x4f\x15\xa8\xdb\xb7\x10\xfd\x4d\xb9\x2b\xf1\xeb\xd5\xc5\x64\xdc\xa9\xfe\xbb\xfd\x49\xeb\x99\xbf\x55\x45\x59\x4f\xf3\x78\xed\x92\xb4\xf4\xaf\x51\xfd\xd5\x7f\x77\x3d\x8d\xab\xf9\xa0\x95\xcb\xbf\x77\xe1\xdf\x5b\x08\xdf\x6b\x5f\xf8\x6f\xb1\x9b\xc1\xba\xd8\xec\xbe\x6a\xa6\x6e\xbd\xff\x06\x1a\x4b\xb8\xeb\x7f\xd7\x7d\x9d\xba\x9b\x97\xc5\xed\x74\xe0\xbf\xec\x65\xd8\x32\x46\xad\x40\x0a\x13\x2e\x8d\xb1\x76\x70\x31\xd9\x56\x0e\xf9\xef\xb2\xcf\x82\x84\x5f\xee\xf2\xcd\xc5\x64\xbc\xad\xdc\x45\x80\x65\x57\x84\xf0\xb6\xc4\x7f\x56\x8a\x4e\xdc\xf7\xe5\x18\xc7\x3b\xab\x7e\xc6\xba\x07\xac\xda\xd8\xf2\x6d\xbc\x76\x1d\x26\x7f\xd2\x63\x55\xfa\xe9\x33\x3c\xdf\xa5\x9f\x3e\xc3\xb3\x9d\xdc\xbe\xe3\x31\x78\x6e\x93\x3a\xfd\x9c\xe0\xe1\x15\x5a\x97\xfc\x3b\x1b\x75\x26\x4c\xc6\x3b\xa8\x02\xb5\x0b\xe1\xab\x22\xb9\x3b\x84\xe7\x04\xd6\x27\xdd\xfb\x27\x82\x63\xbc\xd7\xa6\x1f\xda\xcb\x62\xbb\x19\x5c\xfc\xbd\x40\xdb\x4d\x37\x26\xfd\xf6\x5d\x05\x7a\xfc\xff\xba\x4e\xe2\x6a\x75\xfe\x88\xfc\x54\xaf\x3f\x3b\xaf\x33\xa1\xa3\xff\xfd\xfd\x08\x85\x64\x8a\x5f\xe5\x75\x99\xba\x2a\xe4\x39\xaf\x7e\xcb\xc4\x3f\x7a\x3c\xa0\xfb\x73\x26\x01\xa6\xe9\x02\xe1\x37\xd5\xb7\x69\xd9\xf2\x6b\x5e\x40\x69\x03\x25\x04\x47\x1b\x2a\xf4\x0f\x22\x85\x53\x38\x93\x0e\x46\x47\xf3\x6a\x48\x2f\x32\xba\x82\xb8\xac\x72\xff\x25\x32\x30\x25\x11\x67\xfc\xa0\x0c\x69\xb0\xf0\x33\x12\xb4\x87\xc7\x13\x60\x40\x78\x0e\x2e\x1e\x9f\x55\xf8\xc3\x4f\x97\x9d\x43\x0a\x5f\xba\x78\x11\x8e\xa7\x3e\x7a\xba\xe6\x3f\x6c\x72\x00\x42\x12\xd7\xf1\x28\x44\xd2\x60\x44\x0f\xe2\xe5\x89\x99\x1e\xaf\xbb\xbf\xc7\x10\xd7\x20\xd4\x04\xc2\xff\x62\x47\x98\x8c\xfd\xfd\x13\x6e\x1d\x95\x5b\xd1\xbe\x2f\x92\xf7\xe9\xda\xa1\xff\x85\xe2\x45\xed\xca\x57\x9b\x62\xbe\x42\xbd\x2d\x9f\x62\x16\xd2\x80\x7f\xc3\x0b\x2e\xbc\x1c\x2d\x97\x60\xa0\xce\xed\x64\x0c\x73\x9e\x4a\xf2\x58\xaf\x27\x9b\xfc\xdf\xff\xfd\x7f\x3e\x27\xfe\xbf\x19\x4c\x9d\xa5\x93\x71\x27\x9d\x4c\xc6\xfe\x4c\xed\x1f\xc1\x93\x71\x5b\x3a\x4c\xe0\x90\xdd\xd4\x7e\xf8\x3a\x2e\x51\x38\xc5\x5f\x65\x68\x8a\x92\x62\xbe\x5d\xbb\xbc\xc6\x4b\x57\xbf\xca\x1c\x5c\xbe\xbc\x7b\x93\x0c\x9b\x93\xfe\xe4\xf4\x1c\x16\xb5\x0b\xf0\xa2\x98\x6f\xab\x61\x43\xdc\xe6\xf3\x3a\x2d\x72\xd4\x16\x05\xfe\x55\xb3\xb0\xc3\xef\x68\xba\xdb\x05\x5f\xc7\xd9\xd6\xe1\xba\x4c\xd7\xc3\x53\x5c\x17\x97\xc5\x8d\x2b\x67\x71\xe5\x1a\x3e\x7e\x81\xcb\xdc\xba\xea\xca\xf3\xfb\xd6\x95\x77\xef\x5c\xe6\xe6\x75\x51\xbe\xc8\xb2\xe1\x49\x5d\x62\x08\x85\x46\xa4\x23\xbf\x02\x2f\x8a\xf2\x55\x3c\x5f\x0d\x5b\x61\x86\x2e\x6b\xe5\x38\x4a\x17\x68\xf8\xc5\xef\xbb\xdb\x23\x97\x61\xff\xc2\x18\x6e\xde\xfb\x43\x53\x74\x72\x72\xde\x0c\x96\xae\xde\x96\x79\x73\xd7\xd8\x18\x04\x83\x28\xf2\x96\x72\x59\x5f\xa6\xe1\x89\x7f\x5b\xb4\x15\x67\x37\xf9\xe7\x18\x66\x87\x65\x18\xea\xab\x59\xf8\x6b\x85\xcf\x18\xc0\x4b\xda\xac\xc5\x69\x9e\xb8\xdb\x1f\x16\xc3\xdf\x4f\xd1\x17\xd3\x29\x1a\xd1\x3f\xa7\xc0\x83\x07\xe3\x67\xa7\xe6\x45\xee\x4e\x7a\x1a\x3e\x04\x01\x1e\x7a\xee\xcc\x8a\x79\x9c\xa5\x9f\xdc\xb7\x4d\x64\x0c\x5d\x84\xbc\x50\x11\x8a\xcb\x56\x18\x90\xd8\x75\xd5\x43\xd3\xe9\xd4\xbf\xc1\xbe\x48\x73\x97\xec\x64\xee\x9a\xf5\x61\xe7\xed\x04\x2c\xe4\x6e\x10\x6c\x31\x74\x80\xbd\x17\x75\xf3\xd7\x38\xc3\x93\x36\x22\x4f\x4e\x1b\xf3\xc0\x5e\x69\xf5\x36\x7e\x3b\x4c\x4e\x77\x8c\x1f\xb1\xe8\x48\xd2\x35\xea\x93\x65\x87\xfc\x1c\x3e\x1f\x69\x83\x12\xef\x29\x68\x41\xdf\xd5\x65\x9a\x2f\x87\xbf\xfe\x16\xa1\xfb\x24\xbe\x3b\x43\x03\x36\x4a\xd2\x65\x5a\x0f\x22\xb4\x2e\xf2\x7a\xd5\xa3\xdc\xb9\xb8\x3c\x43\x83\x7c\xbb\x76\x65\x3a\x1f\x44\x68\x55\x6c\xcb\xfe\x9a\x34\xdf\xd6\xae\x47\xaa\xdc\xbc\xc8\x93\x0e\xa9\xeb\x19\xb0\x18\x18\xe4\x32\xad\x40\xb0\x17\x65\x19\xdf\xe1\x4d\x59\xd4\x05\x14\xf1\xb8\xca\xd2\xb9\xc3\xf3\x38\xcb\x86\x07\xa2\xb9\x7a\x79\xf7\x3e\x5e\x42\x31\x36\x1c\x00\x93\x41\x63\xd5\x96\xe1\x2e\x82\x1e\xbb\xfd\xf4\xfc\xb8\xdd\x7c\xe9\xea\x0f\x65\xf6\x63\x5c\xc6\x6b\x57\xbb\x12\x62\xbb\x05\xcb\xa3\xa1\x61\xe5\x2f\xbb\xa9\xa0\xfa\x31\x5e\xba\x0f\x3f\x5d\xa2\x29\xba\x49\xf3\xa4\xb8\xc1\xb0\x13\x2c\xc6\x95\x8b\xcb\xf9\x0a\x57\xdb\xab\x2a\x98\x98\x9e\x46\x7e\x5d\xf5\xe1\xa7\xcb\x9f\xa1\x41\xb8\xca\x1c\x64\x85\x96\x07\xae\x36\x59\x5a\x0f\x4f\xfe\x7a\xd2\x4e\xdc\xed\xfc\xd6\xbf\xb9\xed\xfd\x1e\x04\x3f\x5a\x14\x25\x1a\xa6\x68\x8a\xc8\x39\x4a\xd1\x04\xf5\x98\xe2\xcc\xe5\xcb\x7a\x75\x8e\xd2\x6f\xbe\xd9\x81\xa3\xcf\x0d\xf6\xed\x2e\xf9\x35\xfd\xad\xdd\x7f\x7a\xd2\x58\x27\xa0\xac\xbf\xee\x57\xf2\x9b\x0f\x86\xbe\x29\x5a\xe4\xa1\x47\x93\xe9\x6f\xfd\xc8\x41\x7f\x43\x75\xb9\x75\xe8\x0c\x25\x6e\x5e\x24\xee\xc3\x4f\x6f\x66\xc5\x7a\x53\xe4\x2e\xaf\x1f\x6f\x44\x7f\x3b\x7d\x0a\xe4\x87\x7e\x72\x6e\xde\xdc\xf5\x87\x0c\x2c\x3a\xdd\x7b\xc6\x9f\xbf\x68\xfa\xc4\x87\x27\x7e\xe0\xe4\x51\x76\x06\x2c\x1d\x3e\x30\xaa\x97\x77\xb3\x96\x7d\x67\xa3\xf3\xd6\x0b\x43\x60\xe1\x1d\x11\xa1\x60\x76\x9f\x4e\xc3\xda\xbd\x23\xd0\x04\x1d\x72\x0a\x2c\x9e\x6f\xcb\xf2\x75\xe9\x16\x9d\x75\xe0\x0d\x28\x6c\x76\xc1\x3e\x0c\xe5\xc4\xf4\x04\x7a\xca\x93\xd3\x7b\xd4\x98\xdd\xaf\x5f\x2d\xd1\x74\xc7\x05\x97\xce\x77\xbc\xc3\x30\x35\x42\x27\x31\xac\x38\xdf\xa5\xce\xfe\x0e\xb0\x72\xb5\xec\x9f\x0c\x9d\xed\xe2\x3f\xbd\x5b\x1c\x36\x0b\xf2\xfd\x1b\xbb\x1d\x72\x6b\xe9\xe2\x04\x50\xf9\x5d\x9a\x85\xd7\xb0\xa1\x52\xea\x86\xdd\x36\x4f\xbd\xbf\x7e\x3d\x79\x09\x9b\xfe\xc3\x7f\x7e\xef\x3f\xff\xee\x3f\xdf\xfb\xcf\x1f\xfd\xe7\x2b\xff\xf9\x2f\xff\xf9\xcb\xcb\x93\xdf\xf6\x9e\x0f\xf1\xe3\x6f\x6f\x56\x69\x16\xf6\x41\x17\x53\x44\x09\x13\xfb\xc0\x01\xe2\x38\x10\x1b\xd1\xbf\xf9\x26\xed\x66\xfd\x06\xfc\x9b\xb8\xac\xdc\x77\x59\x11\xd7\x41\x60\x5c\x17\xdf\xa5\xb7\xce\xbf\x47\xff\x0d\x3a\x41\x27\xe8\x9b\x20\xf9\xaf\xe9\x6f\x4d\x02\xec\xa9\xdd\x7d\xef\xbc\x9b\x63\xd2\x4f\xee\x79\x70\xee\xf2\x1f\x4c\x1b\x9c\x76\xd3\xc3\x5e\xc5\x90\x22\x80\xcf\xc1\xd4\xb0\xda\xae\xe3\x1c\xf6\x45\xd3\xc3\xb6\xf7\x0e\x4c\xf3\xdc\x95\xaf\xdf\x7f\x7f\xd9\xba\xf7\xe9\x08\x9a\xa2\x1d\xaf\x8e\x77\xc3\x63\xa9\xb6\x4c\x9b\x8c\x43\x6d\x37\x19\x87\x3f\xc8\xfc\x7f\x01\x00\x00\xff\xff\xd1\xe7\x7f\xef\x40\x3c\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xbc\x7c\xeb\x76\xe3\xc6\xd1\xe0\x6f\xea\x29\xda\x74\x12\x51\x31\xd8\xec\xfb\x45\x22\x95\x1d\xd3\xe3\x6f\xe6\x7c\xf2\x38\x67\x2e\xce\xc9\xe7\xf5\x0f\x88\x68\x89\xd8\x01\x01\x1a\x00\x75\x19\x45\xe7\xec\x43\xec\x13\xee\x93\xec\xa9\x6e\x80\x04\x28\x6a\x66\xb2\x9b\xec\x38\x87\x02\x0a\x5d\xd5\x75\xef\xaa\x46\x23\xd3\x6f\xc6\x63\x74\x34\x99\xa0\x79\xb1\xbe\x2f\xd3\xeb\x65\x8d\x18\xa1\x12\xfd\x14\xd7\xf5\xd2\xdd\xa2\x57\x45\x56\xa3\x38\x4f\xd0\xfb\xa5\x43\xf3\x38\x49\xee\xd1\x8b\x4d\xbd\x2c\xca\xea\x68\x32\x01\xbc\x8b\x74\xe1\xf2\xca\x25\x68\x93\x27\xae\x44\xf5\xd2\xa1\x17\xeb\x78\xb1\x74\xed\x93\x08\xfd\xe2\xca\x2a\x2d\x72\xc4\x30\x41\x23\x18\x30\x6c\x1e\x0d\x4f\xce\x80\xc4\x7d\xb1\x41\xab\xf8\x1e\xe5\x45\x8d\x36\x95\x43\xf5\x32\xad\xd0\x55\x9a\x39\xe4\xee\x16\x6e\x5d\xa3\x34\x47\x8b\x62\xb5\xce\xd2\x38\x5f\x38\x74\x9b\xd6\x4b\x3f\x4f\x43\x05\x03\x8d\xbf\x37\x34\x8a\xcb\x3a\x4e\x73\x14\xa3\x45\xb1\xbe\x47\xc5\x55\x77\x20\x8a\xeb\x86\x69\xf8\xb7\xac\xeb\xf5\xe9\x64\x72\x7b\x7b\x8b\x63\xcf\x30\x2e\xca\xeb\x49\x16\x86\x56\x93\x8b\xd7\xf3\x97\x6f\xde\xbd\x1c\x33\x4c\x1a\xa4\x0f\x79\xe6\xaa\x0a\x95\xee\xf7\x4d\x5a\xba\x04\x5d\xde\xa3\x78\xbd\xce\xd2\x45\x7c\x99\x39\x94\xc5\xb7\xa8\x28\x51\x7c\x5d\x3a\x97\xa0\xba\x00\xa6\x6f\xcb\xb4\x4e\xf3\xeb\x08\x55\xc5\x55\x7d\x1b\x97\x0e\xc8\x24\x69\x55\x97\xe9\xe5\xa6\xee\xe9\xac\x65\x31\xad\x7a\x03\x8a\x1c\xc5\x39\x1a\xbe\x78\x87\x5e\xbf\x1b\xa2\xef\x5f\xbc\x7b\xfd\x2e\x02\x22\x7f\x7b\xfd\xfe\xd5\xcf\x1f\xde\xa3\xbf\xbd\x78\xfb\xf6\xc5\x9b\xf7\xaf\x5f\xbe\x43\x3f\xbf\x45\xf3\x9f\xdf\xfc\xf0\xfa\xfd\xeb\x9f\xdf\xbc\x43\x3f\xff\x88\x5e\xbc\xf9\x3b\xfa\xcf\xd7\x6f\x7e\x88\x90\x4b\xeb\xa5\x2b\x91\xbb\x5b\x97\x20\x41\x51\xa2\x14\xb4\xe9\x12\xaf\xba\x77\xce\xf5\x58\xb8\x2a\x02\x4b\xd5\xda\x2d\xd2\xab\x74\x81\xb2\x38\xbf\xde\xc4\xd7\x0e\x5d\x17\x37\xae\xcc\xd3\xfc\x1a\xad\x5d\xb9\x4a\x2b\xb0\x6a\x05\xde\x01\x64\xb2\x74\x95\xd6\x71\xed\x41\x4f\xe4\xc2\xe8\xe8\xa7\x22\x01\x6a\x61\xc4\x29\x42\x2f\x92\x78\x5d\x07\x55\x95\x8b\xac\xc8\x1d\x5a\xc5\xe5\xc7\xcd\x1a\x8d\xc7\xe7\x47\x47\xd3\x6f\x7e\xf8\x79\xfe\xfe\xef\x7f\x7d\x89\x96\xf5\x2a\x3b\x3f\x9a\x86\x3f\x83\xe9\xd2\xc5\xc9\xf9\xd1\x60\x30\xad\xd3\x3a\x73\xe7\x0f\x0f\xf0\x00\xe1\x37\xf1\xca\x3d\x3e\x4e\x27\x01\x0a\xcf\x57\xae\x8e\xd1\x62\x19\x97\x95\xab\x67\xc3\x4d\x7d\x35\x36\xc3\xdd\x83\x3c\x5e\xb9\xd9\xf0\x26\x75\xb7\xeb\xa2\xac\x87\x68\x51\xe4\xb5\xcb\xeb\xd9\xf0\x36\x4d\xea\xe5\x2c\x71\x37\xe9\xc2\x8d\xfd\x4d\x84\xd2\x3c\xad\xd3\x38\x1b\x57\x8b\x38\x73\x33\x8a\xc9\x13\x42\xd7\x45\x71\x9d\xb9\x0e\x99\xbc\xa8\xcb\x38\xaf\xb2\xb8\x76\xc3\xf3\xa3\x69\x55\xdf\x03\x5b\x7f\x46\x0f\x68\x1d\x27\x49\x9a\x5f\x9f\x22\x72\x06\x12\x5f\xa7\xb9\xbf\x7c\x3c\xba\x2c\x92\x7b\xf4\x70\x34\xb8\x2a\xf2\x7a\x7c\x15\xaf\xd2\xec\xfe\x14\x55\x71\x5e\x8d\x2b\x57\xa6\x57\x67\x47\x83\xda\xdd\xd5\xe3\xd2\x81\x72\x3d\x85\x62\x5d\xa7\xab\xf4\x93\xab\xd6\xce\x25\x67\x47\x83\xcb\x78\xf1\xf1\xba\x2c\x36\x79\x32\x5e\x14\x59\x51\x9e\xa2\x6f\xaf\xfc\xbf\xb3\xa3\xc7\xa3\x18\x68\xb7\x60\x42\x94\x4b\x78\x4b\x32\x71\x8b\xa2\xf4\x86\x39\x45\x79\x91\x3b\x3f\xfc\x74\x09\xd6\x8e\x8e\x96\x14\x35\xd7\x5d\x02\x9c\xda\x45\xa0\x0b\x06\x81\x71\xdf\x56\x9b\xd5\x2a\x2e\xbd\x08\x8d\x8c\xe3\xcc\x5d\xd5\xa7\x48\xfe\xf1\x6c\x07\xf2\x39\x26\xc0\x1e\x8f\xea\xe5\xe9\x55\x5a\x56\xf5\x78\xb1\x4c\xb3\x24\x3a\xaa\x93\xee\x3d\x50\xf2\x16\x38\x45\xf4\x8f\x67\x68\xf2\x67\x54\x03\xb2\x2b\xbd\x8b\xae\x8a\x4b\x48\x11\x7f\x9e\x04\x3a\x59\xdc\x23\x93\xc5\xff\x3c\x95\x20\x49\x97\xff\xba\x58\x9f\x22\x26\xd7\x77\x1d\x01\x2e\x8b\xba\x2e\x56\xa7\x88\x06\xf0\x21\x9d\x33\xf8\xcf\xeb\x86\x6e\x0d\x5a\xa5\x9f\xdc\x29\x62\xc4\x23\x79\xc8\xad\x0b\xaa\xc8\x8b\x72\x15\x67\x67\x47\x83\xdb\x65\x5a\xbb\x71\xb5\x8e\x17\x0e\xa0\xb7\x65\xbc\x3e\x3b\x1a\x80\xe6\xaf\xb2\xe2\x76\x7c\x77\x8a\x96\x69\x92\xb8\xbc\x35\x5b\xfb\xe4\x14\xb9\x2c\x4b\xd7\x55\x5a\x9d\xed\x0c\x64\xad\x6d\x38\xd8\x33\x3c\x39\x3b\x1a\x6c\xfd\x0e\x89\xf5\x5d\x3b\x6c\x67\xe4\x27\x4e\xe1\xe3\x39\x4b\x73\xb7\x1d\xbb\x67\xa6\x9d\x23\x1f\x3d\x1e\xad\x20\x03\x3f\x1c\x0d\x92\xb4\x5a\x67\xf1\xfd\x29\xba\xcc\x8a\xc5\x47\x78\x82\x7d\xc8\xf4\x55\x42\xd9\x4e\x25\xad\xd7\xff\xe2\xca\x24\xce\xe3\xa8\xef\xfe\x97\x45\x99\xb8\x72\x67\x80\xf5\x1d\xaa\x8a\x2c\x4d\xd0\xb7\x76\x0e\xff\x9d\xed\x19\x8e\x92\xc3\x86\x23\xeb\xbb\x2d\x33\xe3\xb4\x76\xab\x9d\x04\xad\x7b\x52\xb7\x82\x21\xdf\x5e\xa5\x59\xdd\x73\x89\xd3\xa0\xb1\x86\x97\x1e\x13\xf3\xf9\xdc\xfb\xb4\x5f\x0e\x3a\x4e\x47\xc8\x1f\x77\xcc\x2f\x8a\x2c\x8b\xd7\x95\x3b\x45\xed\x95\xc7\xf1\x53\x1c\x90\x2f\x89\xab\xa5\x4b\xd0\xb7\x49\x0c\xff\xf9\xa1\x3e\x4d\xd4\xe5\xce\x5a\xcf\x44\xbd\x5b\x84\x08\x83\x70\xd8\x1a\x35\xce\xd2\xeb\xfc\x14\x41\x5c\x9e\x75\x64\x02\x95\x20\xb0\x03\x84\xc7\x7c\x19\xe7\xd7\x2e\x41\x57\x65\xb1\x42\x04\xf2\x33\x6b\xa3\xec\x49\x6c\x7c\x56\xc5\x3d\x2b\xab\xf5\xdd\x73\x2e\xee\x29\x77\xbd\xf4\x32\x8b\x83\xbf\xd4\x4b\x54\xdd\x5c\xc3\x93\x1b\x57\xd6\xe9\x22\xce\x5a\x09\x56\x69\x92\x64\x41\x77\x21\xc2\x0f\xc6\x4e\x97\x81\xc6\xd3\xeb\xe4\x34\xaf\x97\xc1\x73\x47\xec\xa4\x63\x28\x43\xfe\xf8\x64\x00\x3f\xe9\xd9\x9e\xf8\x00\x6e\xfe\x34\x09\x6c\x37\x58\x9c\x44\x7d\x6c\x71\xb2\xaf\x78\xef\x5e\x87\xd8\x68\xc4\x5c\x17\x55\x1a\x42\x2e\xbe\xac\x8a\x6c\x53\xb7\x22\x62\x58\x67\xbc\x29\xf1\x75\xb1\x59\x77\x3c\x36\xe4\x58\x8a\xb5\x04\x9f\x1d\xdc\x16\x65\x32\xbe\x2c\x5d\xfc\xf1\x14\xf9\x3f\xe3\x38\xcb\xba\x69\x04\x54\xd3\x3e\x82\xc1\xfb\x56\x59\x97\x6e\xdc\xda\x05\xa7\x8b\x22\x7f\x1a\x1d\x72\x7d\xb7\x7d\x8a\xab\xa2\xac\x7b\xd1\x9e\xe6\x90\x29\xc6\x4d\xd0\x6f\xc3\xc0\x73\xb7\x74\x9d\xf8\xea\x48\x5b\xba\x2c\xae\xd3\x1b\x07\xa9\x0d\xfc\x0a\x33\xb7\xda\x9b\x02\xd7\xc5\xfa\x39\x15\x0d\x82\x12\x48\x8b\x3e\xa6\x4f\x38\xc4\xc1\x37\x9f\xa5\xd0\xba\x6e\x40\xdd\x11\x7c\x3c\xba\x2a\x8a\x27\x39\xc0\xc7\xcb\x53\x27\x0f\xa9\xac\x6b\xf0\x85\xcb\x6b\x57\x02\x99\xff\xb6\x72\x49\x1a\xa3\xd1\x2a\xbe\x1b\x37\x3a\x51\x84\xac\xef\xc0\x47\x26\x7f\x1e\xe0\x65\x9a\xb8\x36\x75\xec\x94\x19\x96\xe3\xc1\x23\x2a\xdd\xaa\xb8\x09\xe5\xd2\x47\xe7\xd6\x28\x89\x6b\x57\x41\x7d\xb8\x5b\xc1\x06\x07\x7c\xbb\x55\x7f\xbc\xa9\x0b\xa0\x73\x34\xe8\xb9\x2c\x3f\x89\x8e\x06\x07\x3c\xfe\xd0\x72\x3d\x38\xe4\xc9\x40\x31\xac\x72\x7b\x4b\x4c\x80\xfb\xa8\xee\xae\x0e\x00\xef\x64\xd5\x41\x47\x1b\x94\x04\x85\x3e\x42\xe2\x8d\x17\xa1\x92\xbc\x2a\xca\xd5\xe7\xbc\xeb\x60\xda\xae\x13\xfc\x05\x7c\x18\x35\x9d\x34\x55\xd9\x60\x3a\x69\xaa\xca\xa9\x4f\xae\x45\x9e\x15\x71\x32\x3b\x0e\x6c\x8e\x4e\xce\xea\xe2\xfa\x3a\x73\xa3\xa1\xcf\xcf\xc3\x93\xb3\x85\xcf\x90\xef\xd2\x4f\x6e\x74\x72\xec\x4b\x41\x08\xdf\x9b\xd0\xe6\xcc\x86\x14\xd3\x21\xba\x5b\x65\x79\x35\x1b\x76\xba\x8c\x5b\xee\x3b\x0c\x46\x08\x99\x54\x37\xd7\xcd\x90\xd3\xbb\x2c\xcd\x3f\x1e\x1a\x48\xad\xb5\x13\xff\x74\x88\x42\xdc\xcc\x86\x64\x88\x42\x81\x0a\x57\x9e\xfd\xd9\xf0\x80\x3b\xfb\xfa\x74\x30\x4d\xdc\x55\xe5\xaf\x06\xbe\xcd\xfb\xb1\xc8\xa0\xbe\x19\x8f\x1b\xd8\x35\x4a\x93\xd9\xf0\xca\x43\x87\xd0\x70\x65\xe3\x72\x03\x14\xf3\x22\xff\xe4\xca\x22\xc0\xfc\xad\x0b\x14\x07\x83\xe9\x3a\xae\x97\x28\x99\x0d\x7f\x62\x46\x62\xc6\x10\xd7\x58\xca\xe5\x98\x0a\x86\xd5\x05\xa5\x04\x5b\x44\x5e\x71\x8a\xf5\x9c\x0a\xcc\x24\x22\x88\x20\xaa\x00\x1a\x86\xde\x68\x89\xe9\x92\x03\x88\xfd\x02\xd7\x0b\x32\x66\x04\x2b\x39\x86\xf1\x6a\xec\x07\x8d\x81\x40\xb8\xfc\xd4\x72\xf1\xed\x8f\x3f\xbe\x20\x84\x0c\x27\xcf\x72\xa2\xba\xf3\x72\x85\x08\x92\x04\x33\x83\x08\x52\x1a\x6b\x71\x43\xa5\xc1\x7a\x41\x10\xd5\x58\x68\xe4\xa7\x43\x80\x21\xfd\x6f\xb8\x7c\xe5\x89\x2d\x60\x88\x00\x96\x81\x0f\x2a\x30\x0f\x57\x7e\xc8\x2f\x40\x4d\x2e\xc8\xd8\xd3\x69\xd9\x86\x27\xe3\xdd\xa0\x2e\xdb\xf3\x17\xcc\xb4\x6c\x4f\x27\xd7\x07\xb4\x3f\xae\x96\x45\x59\x2f\x36\x35\x18\xb5\x2c\x3e\xba\x46\xe9\xcd\xdd\xb8\xb1\x39\xed\x59\xa4\x6b\x31\x77\xe3\xf2\x22\x49\xb6\x56\x3a\x48\x7c\x0c\x55\xc2\xfa\xa0\xa5\x1b\xbc\xe7\x10\xab\x65\xbc\xde\xba\xc0\x53\xd5\x0b\xa3\x55\x04\xd6\x12\x46\x59\xc2\xd0\x85\xf7\x06\xca\x04\x37\x7d\x30\xb8\x07\x23\xda\xc8\x88\xa0\x0b\x4e\xb1\xb2\x54\x49\x66\x23\x82\xbc\xd5\x1a\x14\x82\x48\x44\x15\x36\x56\x59\x4a\x14\x22\x3d\x1a\x24\xa2\x94\x61\x25\x14\xd1\x14\x68\x28\xdc\xd0\x78\x06\xac\x25\x26\x56\x73\x43\x24\x9a\x77\xc0\x52\x60\x21\xa4\x22\xc4\x20\x4e\x18\x56\x52\x32\x23\xbb\x13\x1d\x96\xec\xbf\x86\x5e\x3f\xef\xbc\x3e\xf6\x1c\xf3\x7c\x3a\x01\xbd\x7c\x41\x4b\xaa\x27\x38\x57\x3d\xc9\xc1\x69\x23\xef\xb4\xdc\x48\x65\x10\x89\xbc\xe7\x52\x4b\xb8\x05\xd1\x19\x53\x58\x48\x2a\x98\x40\x73\x12\x31\xc1\xb1\x25\x56\x68\x8a\x3a\x34\x98\x34\x98\x5a\xce\x99\x41\x9d\x89\x3a\xd0\x8b\x0e\x3b\x1d\xf0\xbc\xa3\x87\x1e\x8d\xad\xce\x3a\xf3\x75\xa1\x3b\x9e\xba\x7a\xef\x30\xde\xd3\xfb\x4e\xb8\xae\xde\x15\xea\xeb\xe8\x19\x3d\xfb\x48\xda\xd3\xf3\x36\xa2\xba\x1a\xa7\x4c\x61\x2a\x05\xe5\x22\x62\x92\x60\x29\x2d\x35\x02\xcd\x01\x6c\x24\xb1\x1a\xc0\x14\x1b\xc3\x95\xe6\x88\x32\x8d\x39\x21\x52\x80\x9a\x38\x26\x44\x51\xc6\x3c\x54\x6b\xa6\x08\x8b\x98\x14\x98\x06\xe8\x9c\x32\x83\x85\xb2\x42\x00\x58\x62\xd6\x0e\x36\xd8\x52\x4b\x28\xa8\x54\x61\x4a\x04\x31\x00\xb5\x58\x71\xc3\x39\x68\x54\x63\x42\x18\x11\x14\xcd\x29\xf7\x1c\x59\xc5\xbc\xa2\x39\x53\x92\x53\x44\x21\x6f\x30\x63\x24\x0c\xb6\x88\x72\x8e\x29\x21\x44\x6a\x7f\x3b\xa7\x5c\x60\x61\xb9\xe6\xba\x79\x2c\xb1\xa0\x92\x2b\xe1\x69\x48\x49\x09\x43\x94\x2b\x4c\x29\x63\x44\xf8\xf9\x94\x96\xd2\x4f\xa7\xb0\x21\x96\x08\xd1\xe5\x82\x72\x8d\x99\x34\x8a\x5a\x2f\x87\x3d\x00\x15\x58\xea\x96\x44\x07\x0c\x4e\x10\xc4\xeb\x42\x19\x36\x3b\x28\xe1\xdc\x70\xe6\x75\x2c\xa4\xa6\x82\x07\x2e\xb4\x51\x52\xa9\x88\x09\x8b\x2d\x31\x54\x71\xcf\xb1\x54\x54\x6b\xeb\xa1\xc4\xeb\xa2\x0f\x35\x58\x06\x33\x79\x12\xc4\x58\xcd\x80\x04\xc3\x86\x0a\x66\x94\xd7\x84\x51\xc2\x72\x1b\x31\xae\x21\xbf\x08\x62\xfa\x50\x8e\x99\xe6\x42\x79\x2d\xee\xc0\x4c\x62\x12\x98\xeb\xf2\x46\x35\x96\x2d\x61\x83\xa9\x21\x4c\x00\x94\x60\x23\x94\xe5\x9e\x84\xc5\xda\x1a\x4d\x79\xc4\x88\xc0\xac\x51\x9c\x00\x77\xb2\x8c\x8b\x88\x5a\x83\x15\x4c\x27\x10\x15\x02\x0b\x66\x39\x33\x11\xb5\x1c\x6b\x05\xf2\x41\xc4\x6b\xcc\xa8\x52\xc6\x46\xd4\x18\x6c\x94\xe5\xc6\x20\x2a\x09\x56\xda\x08\x4a\x23\x6a\x04\x36\x81\x65\x2a\x05\x36\x5c\x59\xcd\x23\x6a\x68\xeb\x2c\x73\x58\xcb\xac\x95\x92\xcb\x88\x6a\x70\x54\x2b\x2d\x43\x54\x71\xac\x98\xa2\xc2\x46\x54\x8b\xad\x7f\x2b\x83\x85\xa1\x52\xb2\x88\x6a\x86\x15\x78\x2c\x04\x83\xe6\x98\x73\x65\xa5\x88\xa8\x26\x58\x70\xa3\xb5\x42\x54\x5b\x4c\x29\xb7\x86\x47\x80\xa7\x94\xe4\x44\x21\x6a\x24\x96\x46\x1b\x20\xa1\x34\xe6\x02\xcc\x87\xe6\xd4\x32\x4c\x14\xd5\x0c\xc0\x0a\x33\x0a\x13\x22\x50\x80\x56\x84\x6b\x13\x51\x25\x31\x17\xcc\x48\x8d\x18\x91\x9e\x0b\x2a\x22\xaa\x04\x56\x41\xe8\x39\xa3\x0c\xb4\x4c\xb5\x87\xb2\x60\x3d\x46\x2d\x96\xd6\x50\xa1\x22\x10\xc9\x5a\x88\x5f\xc4\x98\xc1\x54\x31\x2f\xf3\x0e\x7a\xc1\x84\xc2\x44\x42\x88\x3f\x0b\xb6\xb2\x35\xea\xbc\x07\xd6\x58\x83\x86\x24\x02\xa8\x96\x4c\x70\x80\x5a\x0c\xd1\x44\x04\x02\xe7\xe3\x9a\x18\x6b\x23\x46\x28\x26\x4d\x16\x81\x8c\xc2\x28\x44\x5f\xc4\x20\x87\x05\x57\x86\x10\x20\xda\x1a\xa3\x22\x46\x38\x96\x21\x31\x40\x14\x71\xcd\x34\x95\x5d\xe8\x1c\x92\x84\x50\x9c\xf1\xbd\xc1\x06\x4b\x4e\x99\xd6\x3d\xc2\x8a\x60\x50\x31\xe3\x5d\x2e\x2e\x38\xa4\x38\xc2\x18\x38\x11\xd7\x58\x5b\x70\x01\x34\xe7\x90\xb6\x18\xd1\x52\x47\xe0\xd6\x4c\x18\x6b\x10\x67\x06\x2b\xc1\xb8\x11\x91\xcf\x23\x3e\xaa\x7b\x40\x86\x19\x18\x9a\xa2\x79\x0f\x4c\x30\x01\x28\x43\x5d\xb2\xcc\x60\x16\x02\xa7\xcb\x03\x53\x58\x07\x86\x2f\x3a\x1c\x2b\x8e\x85\x68\x4d\xed\x13\x15\xd7\x52\x45\x8a\x62\x63\x83\xcf\x76\x54\xa1\x68\xd0\x97\x95\xd4\x0a\xb8\x9b\x77\x94\xea\x1f\x12\xcc\xb8\x52\x9c\xf5\x08\x80\x95\x2c\xe7\x5a\xf7\x67\x03\x93\x6a\x61\x69\xa4\x44\xe3\x14\xc2\xdb\x99\x68\x43\x74\xa4\x14\xd6\x30\x52\x9b\x2e\x10\x82\x2a\x38\xf1\xc5\x0e\x4a\x09\x69\x3d\xe2\xa2\xeb\x83\x3b\xf0\x1c\xbc\xdf\x28\x4e\x8c\xe8\x82\x21\xff\x53\xa5\x98\x61\x11\xa5\x1a\x53\xa5\x39\x14\x9e\x54\x62\xa6\x85\xe0\x3a\x82\x90\x17\xed\xda\x44\x09\x56\x4a\x71\x02\x6e\x4c\xa1\xe2\xb0\x06\x51\x62\x30\x97\xc4\x5a\x1e\x51\x2d\x31\x6f\xe8\x76\xa0\x96\x62\x1d\x02\x6c\xde\x01\x43\xb0\xb1\x26\x27\x50\x48\xd8\xc1\xd5\x18\xc7\x16\x82\x5f\x22\xca\x04\xc4\xa8\x90\x22\x62\x42\xb7\xc1\x3f\xa7\x90\x14\x89\xd6\xcc\x27\x5e\xda\x8e\x95\x90\xc6\x99\x15\x21\x49\xb7\xc2\x1d\x5a\x62\x9f\x59\xb8\xe1\xdf\x10\xf9\x2d\x71\xe8\xc8\x66\xc3\xed\xee\xf8\x88\x51\x83\x85\xf5\xc9\x10\x51\x45\x30\xf1\xff\x4e\x90\xdf\x6c\x1f\x8d\x69\x84\xe8\x09\xda\x0d\x1f\x77\xc7\x8f\xbb\x08\x7b\x85\xc1\xae\xd2\x9e\x5c\x77\x9b\x20\x68\x96\xf7\x5b\xa0\x34\x73\xbb\xca\x1b\x1a\xd8\xfd\xca\x9b\xc9\xae\x30\x07\x4b\xef\x16\x03\xda\xcb\x45\xbc\x9e\x0d\xfd\x96\x5c\x0f\xfc\x3f\x8a\x34\x6f\xe1\x4f\xba\x18\xca\x11\x13\x98\xb2\x1b\xa6\xc1\x34\x0b\x82\x14\xa6\x0a\x49\x6c\xc0\x65\x30\x85\x95\x15\xd3\xe6\xfa\x15\xe3\x76\xa1\x31\x47\x24\x40\xc7\x02\x5b\xd5\x5c\xfa\x01\xbf\xf8\x5a\x40\xbe\x83\xc8\x86\x07\xbe\x42\xe1\x1a\x51\xfe\x0a\xec\xa6\xe7\xd4\x78\xc2\xdc\xff\x4f\x07\xec\xc0\xc0\xa7\x03\x2d\x16\x78\xb2\xc7\xbe\xa0\xcc\x06\x97\x82\x46\x8a\x60\x69\x90\x86\x3e\x8a\x5a\x4c\xa1\xcf\x63\xda\x5f\xbe\x62\xc2\x5e\x6c\x91\x3e\x3d\xdb\xfd\xa4\x99\xfb\x77\xf5\x3e\x5d\xd2\x6d\xe7\x73\xd0\x01\x29\x6f\x5c\x28\x42\xdb\xcb\x93\x27\x1d\x51\x8f\x5c\xe8\x87\x7a\x1e\xf3\x45\xa7\xf1\x7e\xf3\x7f\xe5\x23\x5d\x43\x40\xfb\x83\x29\xa3\xc2\x18\xe5\x3b\x02\x03\x0e\x62\x84\xd6\xbe\x23\x80\xf5\x98\x5b\xcb\x84\xf7\x1b\x61\x8d\x2f\xf2\xad\xc2\xd6\x5a\x6b\x78\xf0\x10\x66\xb8\xe6\x5d\xe8\x05\xd4\x42\xd6\x6a\x6b\x7a\xe0\xb9\xaf\x9c\xac\xf4\xe5\xf2\x0e\xcc\xb8\xc5\x54\x13\xc3\xb6\xd3\x09\xd6\x05\xee\x38\xba\xd8\x41\x29\xe3\x98\x0a\x19\x32\xf3\x21\x28\x85\x25\xdf\x48\x41\x23\x86\x8d\x60\x54\x13\x2b\xdc\x98\x0a\x9f\x2e\xb9\xb2\x82\xf1\xfd\x27\x17\x8d\x34\x52\xab\xfd\x47\x73\xe0\x41\x12\x62\x89\x8e\xc6\x14\x6b\x2a\xb4\xb5\x86\xb9\x31\x91\x88\x44\x10\x2c\x84\x31\x6b\x25\xea\xe9\xb3\x49\x5e\xa5\x5b\xd4\x94\x6a\xfa\x99\x8e\x8e\x52\x05\x95\x01\xf1\x8d\x2c\xa5\xca\x67\x7d\x4b\x84\x55\x3e\x93\xab\x88\x52\x8a\x85\x81\xb5\x0a\x81\x90\x4c\x1a\x42\x4c\x44\x19\xc4\x2b\x83\x72\x14\x96\x2b\xb8\xbd\x80\xc4\xec\x2f\xf6\x69\x6e\x6f\xba\x6c\x69\x2b\xbe\xaa\x01\x12\x1a\x1b\xc2\x29\xa8\xd3\x0a\x4c\xc2\x42\x37\x17\x90\x3a\xad\x35\x14\xc0\x12\xd3\x50\xde\x0b\x83\xad\xb0\x52\xca\x60\x7d\x12\x0a\x28\x61\xb1\x60\x54\x91\xad\x4f\x78\xe8\x5c\x12\x4c\xa9\x11\xc2\x80\x4f\x68\x6c\x02\x18\x16\x00\x65\x08\x63\x3a\x62\xbe\xfc\xf5\x45\x83\xa4\x98\x41\x19\x0b\xc5\x8f\xb5\x98\x87\x5a\x72\x2e\x19\x66\xc4\x58\x65\x4c\xc4\x09\xc1\xc2\xaf\x74\x92\x63\xae\xb5\x6f\x26\x38\xa1\x48\x0a\xac\x85\x25\x8a\x0b\x7f\x3b\x87\xa6\x4a\x30\x2d\xb8\x0a\x8f\x35\x26\x4a\x70\x4d\xac\x27\xa1\x42\xdf\x20\x35\xd6\x8a\x32\x2f\x9d\x85\x8e\x93\x33\x8d\xe6\xd2\x60\x21\x0d\x91\x94\x76\xb9\x80\xfa\x99\x68\xc5\x60\x01\xb4\xd0\xd2\x3d\x85\x6a\xcc\xa1\x9e\xe1\x68\xde\x03\x2b\x6c\x1a\xf1\xba\x50\x89\xed\x16\xaa\x0c\xf4\xb8\xcc\xab\xde\x80\x7f\xd2\xc0\x05\x97\x52\x33\x18\xcc\xb1\x0c\x45\xb8\x34\x98\x51\xe2\xeb\x6a\x08\x26\xd3\xe8\xa2\x0f\x15\x4d\x17\x06\xe2\x71\xa3\x39\x44\x82\x81\x3d\x28\x5f\x83\x49\x68\x58\xb8\x15\x92\x46\xcc\x70\xac\x43\x19\xd7\x85\x6a\x8b\xad\xef\x0f\xe7\x3d\x28\xc7\x2c\xf0\xd6\x65\x4d\xe9\xb6\x29\x92\x16\x1b\x66\x99\x84\x6e\x4b\x51\xac\x9a\xe6\x55\x51\x2c\x84\x2f\x10\xc0\x24\x41\x6b\x8a\x63\xc9\x0d\x13\x84\xfb\x96\x4f\x85\x02\x41\xf9\xfa\x89\x73\x68\xab\x85\xc6\x8a\x09\x61\xd1\x5c\x41\xbf\x23\x7d\xd7\x01\xfb\x09\x2a\x14\xfc\x9a\x61\xce\xb4\xa0\x40\x57\x10\xcc\x3d\xbb\x5a\x61\x61\xa4\xd5\x96\xf9\xce\x2e\xe8\x66\x6e\x08\x56\x42\x48\x41\x01\x2a\xb0\x0c\x6d\x19\xec\x1e\x68\x49\xa5\xa2\x11\xe3\xac\xf5\x6c\x4b\x30\xe5\x44\x4a\xb0\x05\x07\xb2\xa1\xd4\xb2\x02\x5b\x23\xad\x02\x7e\x99\xc1\x32\x74\x33\x10\xc2\x5a\x31\x28\xf6\x99\xf6\x8d\x26\x2c\x8a\x44\x43\xc9\x69\x64\xd8\xe8\x20\xed\x2e\x00\xe5\x58\x53\xa2\x99\x09\x7d\xa4\x81\xf9\x10\x65\x04\x0b\x88\x35\x19\x31\x06\x75\x3f\x15\xb0\x5a\x32\xed\xb9\x60\xbe\xfe\x32\x41\xe0\x39\xf4\xf7\x86\x59\x0a\xb5\x3e\xe3\x58\x04\xb3\x41\x1b\xc9\x84\xa6\xcd\x60\xd6\x74\x86\xc2\x62\x43\xa9\x14\x3d\xe8\x05\x74\x62\x9a\x08\x69\xcd\xb3\x60\x61\x5b\x73\xce\xbb\x60\x49\x20\x3d\x4a\x1a\x5a\x43\x42\xc3\xbe\x11\xdb\x6e\x45\x68\x82\x09\xb5\x96\x48\xdf\xee\xcb\x26\x7b\x50\x4d\xa1\xc8\xf5\x7b\x1c\x02\x9b\xe0\xc1\xd0\x45\xc2\xb6\x05\x14\x9d\x52\x62\x19\xf2\x01\xd5\x0a\x13\xe6\x1b\xc3\x0e\x74\x4e\x75\x53\x54\xf6\xc0\xd4\x10\xdf\x68\x1b\xd1\x23\x6c\x28\x36\x8c\x32\xde\xe5\xe1\x02\x3c\x49\x4b\xca\xac\xf2\xcd\x90\x09\x7d\xf6\x1c\x04\x85\x26\x59\x41\xe9\x0b\xb9\xc8\x57\xda\xbe\x5f\xb0\x94\xdb\xd0\xd5\xd1\x90\x10\x7a\x50\x8d\x59\xd3\x38\xf5\xc0\x12\x8b\xb6\xb9\xd8\x12\xa6\x90\x48\x43\xc4\x74\xb8\x80\x16\x58\x07\x8e\x2f\x76\x2c\x83\x1d\xb7\xdb\x3d\x86\xc0\x2e\x81\x27\x01\x7b\x07\x81\xe5\x8e\x2a\x28\xb7\x41\x61\x42\x30\xa8\xfe\xfd\x2e\xc3\x4e\xad\xe1\x31\x6c\x2f\x48\xc5\x6d\x9f\x06\xc1\xa4\x69\xd5\xba\x13\x82\x51\x19\xb7\xd0\x53\x0b\xb6\x75\x22\xb0\x3f\xd3\x04\xd6\x1d\x01\xc4\xc3\x3e\x49\x17\x2a\xb1\x0c\x7d\xc0\x45\x17\xac\xb7\xbb\x0e\x17\x1d\x47\xec\x80\xe7\xc6\x60\x49\x19\xb1\xc4\x74\xc1\xe0\x64\x54\x32\xe8\xdd\x60\x3f\xc3\x86\x3d\x2a\x0e\x1b\xff\xdc\x77\x3f\xbe\xf5\x6f\x7c\x8b\x33\xcc\xa9\xe4\x44\x43\xe8\x50\xcc\x82\x01\x39\xf1\xd1\x2c\x03\x41\xb8\x13\x12\xdb\x10\x56\x73\xb8\x85\x75\x20\x24\x00\x2e\xb1\x94\xa0\x4e\x16\x31\xcd\xc0\x92\x86\x6b\x24\x14\x04\x24\xec\x0a\x47\xcc\xd2\x36\xd2\xe7\x42\x61\x25\x95\x66\x4a\x85\x1a\xa6\x19\xac\x61\x97\x8f\x13\x62\x3d\xd4\x84\x59\x0f\xae\xa4\xdd\x36\x67\x0c\x47\xe7\xb6\x95\x5e\x5b\x0a\x1e\x7a\x9d\x72\xb8\xfc\x14\x04\x6a\x20\x65\x65\x84\x18\xfb\x72\xff\xd3\x1d\x3f\xee\x22\x7c\x5d\xff\xf3\x61\x8d\xe2\xb2\x2c\x6e\xf7\x7b\xa0\xcd\x7a\xec\xe1\xcf\x70\x39\x86\x55\x84\x31\x34\x66\xc4\x60\xca\x4e\xfa\xfd\x4b\x07\x65\x15\xd7\x65\x7a\x37\x82\xbd\x5c\xca\xfd\xdb\x1f\x4c\x61\xb5\x47\x9c\x4b\x0c\x9b\x43\x4a\x60\x2e\x4f\xf6\x6b\x65\x32\x84\xb2\x65\x35\x86\x20\xa3\x1a\x09\xca\x30\xa1\xcb\x31\x33\xd8\x30\xdd\xfc\xc9\xa8\xc0\x82\x8a\x31\x83\xfa\x4d\xa2\x43\x77\x28\xdc\x1d\x68\x38\x40\xf6\x1f\x8a\xdb\xfc\xb0\xf4\x49\x71\x9b\xff\xbb\xe4\x1f\xf7\x15\x00\x3e\x6b\xf9\xff\x6f\x05\x4c\x27\xed\xcb\xc0\x29\xbc\x7c\xf4\x17\xe1\xbc\x53\x78\xbc\xa4\x61\xfc\xc3\x43\x09\xef\x36\xd1\x1f\xd2\x08\xfd\x61\x51\x6e\x56\x97\xe8\x74\x86\xf0\xf7\xa5\x8b\x13\x7f\xfb\xf8\x38\x8d\xd1\xb2\x74\x57\xb3\x61\x73\xf6\x2e\x0c\xc3\x17\x69\xfe\xf1\xf1\x71\x78\xde\x87\xbe\x77\x77\x35\x9c\xcb\x8b\xcf\x1f\x1e\xd2\x2b\x94\x03\x65\x44\x1e\x1f\x27\x0f\x0f\x2e\x4f\x1e\x1f\x9b\x3f\x81\xc5\xc0\xc4\x74\xb2\x63\x6c\x0a\x67\x89\x9a\x97\x99\xe9\x0d\x5a\x64\x71\x55\xcd\x86\x70\x70\xa7\x31\x80\x07\x83\x05\x9b\xd3\x67\x5b\xbb\x54\xeb\x38\xef\x8e\xf7\x07\x7d\x86\xe7\xd3\x34\x5f\x6f\x6a\x54\xdf\xaf\xdd\x6c\x08\xef\xb3\x87\x68\x9d\xc5\x0b\xb7\xf4\x6f\xbc\x7c\x9f\x57\xc3\xdb\xd0\x34\xd9\x5d\x17\xf9\x47\x77\xbf\x59\xef\x5e\x08\x1f\x9f\x4f\x27\x40\xbf\x99\xeb\xe1\x61\x8c\xd2\x2b\x84\x5f\x94\x8b\x65\x7a\xe3\x1e\x1f\xbf\xc0\x42\xab\xbf\xbf\x80\xd7\xf9\x57\xcd\x9f\xd2\xf5\xf0\xfc\x87\xe6\x0e\x7d\x4a\xd7\xa0\x30\xf4\x27\x38\xe4\x52\xd4\x67\xe8\x00\x46\x1d\x97\xf8\xfa\x53\x07\x29\x00\x00\xef\x09\x6f\x5b\x0d\x83\x17\xa4\x37\xe7\x47\xbd\xab\x96\xfb\xb7\x2e\x4e\xfe\x56\xa6\x75\xc3\xff\x13\x7d\x7b\x95\x34\xaf\xd5\x5b\xe5\xfb\xb7\xeb\x2b\x57\x2f\x8b\xc4\xbf\x87\xae\x87\xc8\xe5\x8b\xa0\xdc\xd5\x26\xab\xd3\x75\x5c\xd6\x13\x18\x35\x4e\xe2\xad\xcd\x06\x3d\x23\x84\xcd\x97\x70\x8c\x32\x5c\x07\xcc\xcc\x6d\x8f\xda\xb6\x68\x97\x9b\xba\x2e\xf2\x06\xaf\xda\x5c\xae\xd2\x7a\x78\xfe\x61\x0d\xf2\x4f\x27\xe1\x61\xeb\xf5\x30\xe7\xb3\x4c\x1e\x62\x23\x1c\xad\x6b\x19\x09\x72\x0e\xd1\x4d\x9c\x6d\x40\x96\x8f\x49\x5a\x1e\x44\x0b\x2e\x14\x90\xe0\x77\xcf\x9d\x72\x77\x8b\x92\x14\x3a\xb7\xa2\xbc\x1f\x7e\x9d\x40\xf3\xd2\xc5\xb5\xdb\xa1\x3d\x2b\x5a\xdf\x86\xbb\x38\xea\x58\x2e\x4b\x2b\x38\x81\xdc\xda\x2b\x9c\x4d\x8b\xcb\x34\x1e\x27\xae\x5a\x94\xe9\xa5\x4b\x2e\xef\x9f\x06\x4f\xdd\x9e\xb2\xf5\x37\xe5\xb6\xbd\xac\x97\xe7\xd3\x49\xa7\xf3\xec\xf6\xc6\x5b\x1f\x85\xe3\x36\x33\xd0\x45\x92\x96\xfe\x98\xe0\x9f\xfc\xb9\x89\x59\x5c\x2d\x86\x2d\x5f\xfe\x5c\x11\x0c\x44\xfe\xd9\xf0\xdc\x9f\xa0\x68\x1e\xd6\xc5\x7a\x7b\xcc\x81\xba\xd5\xee\xf4\x03\x96\x70\xd7\x3f\x67\x01\x47\x78\xbf\x2f\xee\x66\x43\x7f\xd0\x80\x61\xcb\x18\xb5\x02\x29\x4c\xb8\x34\xc6\xda\xe1\xf9\x14\xce\x94\xfb\x73\x14\xa7\x81\xc3\x6f\xb7\x6b\xdd\xf9\x74\xb2\xa9\xdc\x79\x48\x89\x5d\x16\xc2\x69\xa0\x7f\x2f\x17\x9d\x35\xa7\xcf\xc7\x24\xde\x6a\xf5\x33\xda\x3d\xa0\xd5\x46\x97\x70\x16\xba\x43\xe4\x2b\x2d\x06\x47\x98\x9e\xa7\x09\x87\x5d\x9e\xa7\xd9\x0e\x6e\xcf\x30\x0d\x9f\x9b\xa4\x4e\x3f\xc7\x78\x38\x22\xee\x92\x7f\x66\xa2\xce\x80\xe9\x64\xeb\xaa\xd3\x49\xdf\x85\xe1\x5c\xcf\x21\x7f\x4e\x00\x3f\xe9\xde\x3f\x61\x1c\xe3\x9d\x34\xfd\x9c\x0e\x07\xf1\x86\xe7\xff\x51\xa0\xcd\xba\x97\x73\x07\x83\xbe\x00\x3d\xfa\x7f\x5a\xc1\x99\xce\xb3\x3d\xf0\x53\xb9\xbe\x76\x5c\x67\x40\x47\x7e\x48\x08\x61\x21\xc7\x2f\xf3\xba\x4c\x5d\xb5\x5d\x95\xea\xb2\x25\xe2\xb3\xed\x01\xd9\x9f\x53\x49\xbb\x52\xbc\xae\x7e\x48\xcb\x96\x5e\x73\xf8\xa9\x0d\x14\x2c\xbb\xa1\x42\xbf\x10\x29\x9c\x42\x3d\x74\x30\x3a\x9a\x63\x49\xbd\xc8\xe8\x32\xe2\xb2\xca\xfd\x4b\x78\x80\x37\xb2\x9c\xf1\x83\x3c\xa4\x99\xfb\x0c\x07\x79\xd2\x65\xa0\xe3\x18\x7e\x21\x38\xdf\xaf\x93\xf0\x87\xb7\x17\x9d\x02\x09\x5f\xb8\xf8\x2a\x94\x46\x7d\xef\xe9\xaa\xff\xb0\xca\xc1\x11\x60\x3d\x1d\x87\x48\x1a\x8e\xe9\x41\x7f\x79\xa2\xa6\x7d\xbc\x87\x07\x0c\x71\x0d\x4c\x4d\x21\xfc\xcf\xb7\x80\xe9\xc4\xdf\x3f\xa1\xd6\x11\xb9\x65\xed\xa7\x22\x79\x9f\xae\x1c\xfa\x07\x8a\xaf\x6a\x57\xbe\x5c\x17\x8b\x25\xea\x4d\xf9\xd4\x67\x21\x0d\x00\x27\x0e\x2e\x3c\x1f\x2d\x95\xa0\xa0\xce\x2d\x7c\xd2\xb1\x72\xe7\x5f\x94\xeb\xc9\x24\xff\xfb\x7f\xfe\xaf\xaf\x60\xff\x0f\xfb\x75\xcf\x61\x72\xa8\x5f\xf8\x3c\x53\xfb\x14\x79\x58\xc3\x61\xaf\xb6\xde\x94\x39\x2a\x1d\xb8\xc2\x08\x3e\x68\x3a\xe9\x66\x91\xaf\x2e\x3c\x02\x81\xaf\x43\xf5\x23\x5b\xc4\x87\x87\xc6\xbf\xbe\x12\xd7\xdd\xee\x4f\x74\xb0\x36\x79\xeb\xf9\xe9\x57\x24\x7b\xf5\xd6\xd7\xeb\x06\x0e\xb4\xfe\x3f\xe8\x26\x71\x99\xab\xff\x65\xba\x39\x28\xee\x0f\x7e\x8a\x2f\x88\xfb\xcf\xbb\xe6\x67\xfc\x72\x2f\x87\xf7\x9f\xec\x56\xb1\xe9\xc4\x97\x72\xfd\x32\x70\x3a\x69\xbb\xa5\x29\xd4\x76\xeb\xda\x3f\xbe\x89\x4b\x14\x1a\x97\x97\x19\x9a\xa1\xa4\x58\x6c\x56\x2e\xaf\xf1\xb5\xab\x5f\x66\x0e\x2e\xbf\xbf\x7f\x9d\x8c\x9a\xe6\xe6\xf8\x04\x0e\xfa\x0e\x5a\x04\x7c\x55\x2c\x36\xd5\xa8\x01\x6e\x72\x6f\x01\xd4\xf6\x41\xfe\x04\x6f\x98\xe1\x77\x34\xdb\xce\x82\xbd\x9e\x71\x5d\xa6\xab\xd1\x09\xae\x8b\x8b\xe2\xd6\x95\xf3\xb8\x72\x0d\x1d\x8f\xe0\x32\xb7\xaa\xba\xfc\xfc\xbe\x71\xe5\xfd\x3b\x97\xf9\xa2\xf7\x45\x96\x8d\x8e\xeb\x12\x43\x06\x6e\x58\x1a\x78\x0c\x7c\x55\x94\x2f\xe3\xc5\x72\xd4\x32\x33\x72\x59\xcb\xc7\x20\xbd\x42\xa3\x6f\x7e\xdf\xde\x0e\x5c\x86\xfd\x19\x59\xdc\x9c\xfe\x45\x33\x74\x7c\x7c\xd6\x3c\x0c\x6e\xd8\xdc\x35\x3a\x06\xc6\xc0\x55\xbc\xa6\x5c\xd6\xe7\x69\x74\xec\x0f\xe1\xb7\xec\x6c\x07\xff\x12\xc3\xe8\x80\x86\xa1\x1f\x98\x87\x8f\xc0\x3e\xa3\x00\xcf\x69\x83\x8b\xd3\x3c\x71\x77\x3f\x5f\x8d\x7e\x3f\x41\xdf\xcc\x66\x68\x4c\xbf\x4e\x80\x47\xef\x68\x9f\x1d\x0a\xaf\x4a\x8f\x7b\x12\x3e\x06\x06\x1e\x7b\xe6\xcc\x8a\x45\x9c\xa5\x9f\xdc\x0f\x4d\x42\x1e\x39\xf8\xe4\x2d\x71\x77\x11\x8a\xcb\x96\x19\xe0\xd8\x75\xc5\x43\xb3\xd9\xcc\x7f\x18\x74\x95\xe6\x2e\xd9\xf2\xdc\x55\xeb\xe3\xd6\xda\x09\x68\xc8\xdd\x22\x98\x62\xe4\xc0\xf7\x5e\xd4\xcd\x47\x8e\xa3\xe3\x76\x21\x38\x3e\x39\x39\xdb\xce\x95\x56\x6f\xe2\x37\xa3\xe4\x64\x4b\x78\x8f\x44\x87\x93\xae\x52\x9f\xa0\x1d\xb2\x73\xf8\xdd\x93\x06\x25\xde\x52\xb0\xeb\xf6\xae\x86\xef\xeb\x46\xbf\xfe\x16\xa1\x87\x04\x0e\x8d\x0f\xd9\x38\x49\xaf\xd3\x7a\x18\xa1\x55\x91\xd7\xcb\x1e\xe4\xde\xc5\xe5\x29\x1a\xe6\x9b\x95\x2b\xd3\xc5\x30\x42\xcb\x62\x53\xf6\x71\xd2\x7c\x53\xbb\x1e\xa8\x72\x8b\x22\x4f\x3a\xa0\xae\x65\x40\x63\xa0\x90\x8b\xb4\x02\xc6\x5e\x94\x65\x7c\x8f\xd7\x65\x51\x17\x90\x9e\x70\x05\x1f\xa9\xe2\x45\x9c\x65\xa3\x03\xd1\x5c\x7d\x7f\xff\x3e\xbe\x86\x1e\x60\x34\x04\x22\xc3\x46\xab\x2d\xc1\x6d\x04\xed\x9b\xfd\xe4\xec\xa8\x9d\xfc\xda\xd5\x1f\xca\xec\xaf\x71\x19\xaf\x5c\xed\x4a\x88\xed\xd6\x59\xf6\x1e\x8d\x2a\x7f\xd9\x4d\x05\xd5\x5f\xe3\x6b\xf7\xe1\xed\x05\x9a\xa1\xdb\x34\x4f\x8a\x5b\x0c\x33\x01\x32\xae\x5c\x5c\x2e\x96\xb8\xda\x5c\x56\x41\xc5\x14\xbe\x3f\x18\x0c\x06\xd5\x87\xb7\x17\xbf\x40\x5f\x7a\x99\x39\xc8\x0a\x2d\x0d\x5c\xad\xb3\xb4\x1e\x1d\xff\xe9\xb8\x1d\xb8\x9d\xf9\x8d\xff\x20\xc6\xdb\x3d\x30\x3e\x80\xef\xf9\x46\x29\x9a\xc1\xe7\x94\x29\x9a\xa2\x1e\x51\x9c\xb9\xfc\xba\x5e\x9e\xa1\xf4\xbb\xef\xb6\xce\xd1\xa7\x86\x66\x7d\x94\x5f\xd3\xdf\xda\xf9\x67\xc7\x8d\x76\x82\x97\xf5\xf1\x7e\x25\xbf\xf9\x60\xe8\xab\xa2\xf5\x3c\xb4\x37\x98\xfe\xd6\x8f\x1c\xf4\x17\x54\x97\x1b\x87\x4e\x11\x7c\x71\x97\xb8\x0f\x6f\x5f\xcf\x8b\xd5\xba\xc8\x5d\x5e\x8f\x9e\xe0\x9e\x3c\x75\xe4\xc7\x7e\x72\x6e\x3e\x56\xf0\x8b\x0e\x20\x9d\xec\x2c\xe3\xcb\x3e\x34\x7b\x62\xc3\x63\xff\xe0\x78\x2f\x3b\x83\x2f\x1d\x5e\x30\xaa\xef\xef\xe7\x2d\xf9\xce\x44\x67\xad\x15\x46\x40\xc2\x1b\x22\x42\x41\xed\x3e\x9d\x06\xdc\x9d\x21\xd0\x14\x1d\x32\x0a\x20\x2f\x36\x65\xf9\xaa\x74\x57\x1d\x3c\xb0\x06\xd4\xd3\xdb\x60\x1f\x85\x2a\x76\x76\x0c\x5b\x19\xc7\x27\x0f\xe8\x68\xb0\xc3\x5f\x5e\xa3\xd9\x96\x0a\x2e\x9d\xdf\x95\x19\x85\xa1\x11\x3a\x8e\x01\xe3\x6c\x9b\x3a\xfb\x33\x00\xe6\xf2\xba\xbf\x32\x74\xa6\x8b\xbf\x7a\xb6\x38\x4c\x16\xf8\xfb\x27\x66\x3b\x64\x56\xd8\x80\x05\xaf\x84\xf3\x4b\xfe\xcb\x13\x28\xd0\xbb\x61\xb7\xc9\x53\x6f\xaf\x5f\x8f\xbf\x87\x49\xff\xd3\xff\xfe\xe4\x7f\xff\xc3\xff\xbe\xf7\xbf\x7f\xf5\xbf\x2f\xfd\xef\x7f\xf9\xdf\xbf\x7f\x7f\xfc\xdb\xce\xf2\x21\x7e\xfc\xed\xed\x32\xcd\xc2\x3c\xe8\x7c\x86\x28\x61\x62\x17\x38\x00\x9c\x04\x60\xc3\xfa\x77\xdf\xa5\xdd\xac\xdf\x38\xff\x1a\xbe\xbf\xfe\x31\x2b\xe2\x3a\x30\x8c\xeb\xe2\xc7\xf4\xce\xf9\xcf\x93\xbe\x43\xc7\xe8\x18\x7d\x17\x38\xff\x35\xfd\xad\x49\x80\x7b\x62\xfb\x0a\x1a\xea\xad\xae\xb0\x79\x08\x57\x00\xe3\xad\x42\x8f\xfd\xf2\xfc\x5b\x53\x7e\xb4\x56\x98\xfc\xf7\xc9\x1f\x26\x11\x3a\xee\xba\x77\xee\x6e\x9b\x80\x5f\x97\xc5\x6a\x5d\x8f\x8e\x43\x61\xeb\xd9\xf1\x17\xc0\x5b\x5d\x1c\x47\xfe\xae\xb3\x2c\x7d\xd3\xa2\xfe\xe3\x1f\x3b\x2a\xb3\xb0\xfc\xef\xad\x81\xe8\x2a\xce\xe0\xf3\xce\x9d\x4e\xf6\xd9\x75\xb7\x5d\x8e\xd1\xac\xa5\x78\xd6\xd5\x20\x24\x87\x03\x8b\x76\x53\x3e\x77\x15\xd3\x20\x2c\x8a\xfc\x2a\x2d\x57\xa3\xe3\x50\xbd\x7a\x99\x3e\xa3\x28\x10\xf5\x2f\xc7\x27\x87\x94\xdf\xfd\xce\xa9\x9b\xe0\xe1\x93\xf3\x67\x33\xc3\x76\xf1\x81\x61\xc3\x93\x6e\x6e\xde\xf9\x57\xc8\xcf\x40\xe7\x60\x5e\x5e\x6e\x56\x71\x0e\xf3\xa2\xd9\x61\xc7\xf7\xd1\x93\xe6\xb9\x2b\x5f\xbd\xff\xe9\xa2\x8d\xad\xa7\x4f\xd0\x0c\x6d\x69\x75\x42\x2b\xbc\x06\x69\x6b\xe4\xe9\x24\x14\xd6\xd3\x49\xf8\x3f\x19\xf8\x3f\x03\x00\x6f\x2e\x28\xa6\x14\x43\x00\x00"),
 		},
 	}
 	fs["/"].(*vfsgen۰DirInfo).entries = []os.FileInfo{
@@ -2,12 +2,15 @@ package serve
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,12 +21,14 @@ import (
 
 // DirEntry is a directory entry
 type DirEntry struct {
-	remote  string
-	URL     string
-	Leaf    string
-	IsDir   bool
-	Size    int64
-	ModTime time.Time
+	remote   string
+	URL      string
+	Leaf     string
+	IsDir    bool
+	Size     int64
+	ModTime  time.Time
+	MimeType string
+	Hashes   map[string]string // hashes by name if requested
 }
 
 // Directory represents a directory
@@ -37,6 +42,12 @@ type Directory struct {
 	Breadcrumb   []Crumb
 	Sort         string
 	Order        string
+	ReadWrite    bool // show the forms to change the directory
+	Archive      bool // show the links to download the directory
+	Recursive    bool // entries are from subdirectories too so Leaf is the path
+	Total        int  // number of entries before pagination
+	Offset       int  // index of first entry shown
+	Limit        int  // maximum number of entries shown or 0 for all
 }
 
 // Crumb is a breadcrumb entry
@@ -86,7 +97,18 @@ func (d *Directory) SetQuery(queryParams url.Values) *Directory {
 
 // AddHTMLEntry adds an entry to that directory
 func (d *Directory) AddHTMLEntry(remote string, isDir bool, size int64, modTime time.Time) {
+	d.AddHTMLEntryDetails(remote, isDir, size, modTime, "", nil)
+}
+
+// AddHTMLEntryDetails adds an entry to that directory with its MIME
+// type and hashes which are shown in JSON listings
+func (d *Directory) AddHTMLEntryDetails(remote string, isDir bool, size int64, modTime time.Time, mimeType string, hashes map[string]string) {
 	leaf := path.Base(remote)
+	if d.Recursive && d.DirRemote != "" {
+		leaf = strings.TrimPrefix(remote, d.DirRemote+"/")
+	} else if d.Recursive {
+		leaf = remote
+	}
 	if leaf == "." {
 		leaf = ""
 	}
@@ -96,12 +118,14 @@ func (d *Directory) AddHTMLEntry(remote string, isDir bool, size int64, modTime
 		urlRemote += "/"
 	}
 	d.Entries = append(d.Entries, DirEntry{
-		remote:  remote,
-		URL:     rest.URLPathEscape(urlRemote) + d.Query,
-		Leaf:    leaf,
-		IsDir:   isDir,
-		Size:    size,
-		ModTime: modTime,
+		remote:   remote,
+		URL:      rest.URLPathEscape(urlRemote) + d.Query,
+		Leaf:     leaf,
+		IsDir:    isDir,
+		Size:     size,
+		ModTime:  modTime,
+		MimeType: mimeType,
+		Hashes:   hashes,
 	})
 }
 
@@ -162,6 +186,34 @@ func (d *Directory) ProcessQueryParams(sortParm string, orderParm string) *Direc
 
 }
 
+// ProcessPageParams shows only the entries from offsetParm up to a
+// maximum of limitParm. Call it after ProcessQueryParams so the pages
+// are of the sorted entries.
+func (d *Directory) ProcessPageParams(offsetParm string, limitParm string) error {
+	d.Total = len(d.Entries)
+	var err error
+	if offsetParm != "" {
+		d.Offset, err = strconv.Atoi(offsetParm)
+		if err != nil || d.Offset < 0 {
+			return fmt.Errorf("bad offset %q", offsetParm)
+		}
+	}
+	if limitParm != "" {
+		d.Limit, err = strconv.Atoi(limitParm)
+		if err != nil || d.Limit < 0 {
+			return fmt.Errorf("bad limit %q", limitParm)
+		}
+	}
+	if d.Offset > len(d.Entries) {
+		d.Offset = len(d.Entries)
+	}
+	d.Entries = d.Entries[d.Offset:]
+	if d.Limit > 0 && d.Limit < len(d.Entries) {
+		d.Entries = d.Entries[:d.Limit]
+	}
+	return nil
+}
+
 type byName Directory
 type byNameDirFirst Directory
 type bySize Directory
@@ -221,6 +273,82 @@ const (
 	sortByTime         = "time"
 )
 
+// WantsJSON returns true if the client asked for a JSON listing
+// with ?format=json or by preferring application/json to text/html in
+// its Accept header
+func WantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return true
+		case "text/html":
+			return false
+		}
+	}
+	return false
+}
+
+// jsonEntry is a directory entry in a JSON listing
+type jsonEntry struct {
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Size     int64             `json:"size"`
+	ModTime  *time.Time        `json:"modTime,omitempty"`
+	IsDir    bool              `json:"isDir"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+}
+
+// jsonDirectory is a JSON listing
+type jsonDirectory struct {
+	Name    string      `json:"name"`
+	Entries []jsonEntry `json:"entries"`
+	Total   int         `json:"total"`
+	Offset  int         `json:"offset"`
+	Limit   int         `json:"limit,omitempty"`
+}
+
+// serveJSON serves the directory as JSON
+func (d *Directory) serveJSON(w http.ResponseWriter) {
+	out := jsonDirectory{
+		Name:    d.Name,
+		Entries: make([]jsonEntry, 0, len(d.Entries)),
+		Total:   d.Total,
+		Offset:  d.Offset,
+		Limit:   d.Limit,
+	}
+	if out.Total < len(d.Entries) {
+		out.Total = len(d.Entries)
+	}
+	for i := range d.Entries {
+		entry := &d.Entries[i]
+		item := jsonEntry{
+			Name:     strings.TrimSuffix(entry.Leaf, "/"),
+			URL:      entry.URL,
+			Size:     entry.Size,
+			IsDir:    entry.IsDir,
+			MimeType: entry.MimeType,
+			Hashes:   entry.Hashes,
+		}
+		if !entry.ModTime.IsZero() {
+			item.ModTime = &entry.ModTime
+		}
+		out.Entries = append(out.Entries, item)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(out)
+	if err != nil {
+		Error(d.DirRemote, nil, "Failed to write JSON listing", err)
+	}
+}
+
 // Serve serves a directory
 func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 	// Account the transfer
@@ -229,6 +357,11 @@ func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 
 	fs.Infof(d.DirRemote, "%s: Serving directory", r.RemoteAddr)
 
+	if WantsJSON(r) {
+		d.serveJSON(w)
+		return
+	}
+
 	buf := &bytes.Buffer{}
 	err := d.HTMLTemplate.Execute(buf, d)
 	if err != nil {
@@ -122,3 +122,84 @@ func TestServe(t *testing.T) {
 </html>
 `, string(body))
 }
+
+func TestProcessPageParams(t *testing.T) {
+	newDir := func() *Directory {
+		d := NewDirectory("z", GetTemplate(t))
+		for _, name := range []string{"a", "b", "c", "d"} {
+			d.AddEntry(name, false)
+		}
+		return d
+	}
+	leaves := func(d *Directory) (out []string) {
+		for _, entry := range d.Entries {
+			out = append(out, entry.Leaf)
+		}
+		return out
+	}
+
+	d := newDir()
+	require.NoError(t, d.ProcessPageParams("1", "2"))
+	assert.Equal(t, []string{"b", "c"}, leaves(d))
+	assert.Equal(t, 4, d.Total)
+
+	d = newDir()
+	require.NoError(t, d.ProcessPageParams("3", ""))
+	assert.Equal(t, []string{"d"}, leaves(d))
+
+	d = newDir()
+	require.NoError(t, d.ProcessPageParams("10", "2"))
+	assert.Equal(t, 0, len(d.Entries))
+
+	assert.Error(t, newDir().ProcessPageParams("-1", ""))
+	assert.Error(t, newDir().ProcessPageParams("", "potato"))
+}
+
+func TestAddHTMLEntryRecursive(t *testing.T) {
+	d := NewDirectory("z", GetTemplate(t))
+	d.Recursive = true
+	d.AddHTMLEntry("z/a", true, 0, time.Time{})
+	d.AddHTMLEntry("z/a/b c.txt", false, 1, time.Time{})
+	assert.Equal(t, "a/", d.Entries[0].Leaf)
+	assert.Equal(t, "a/b c.txt", d.Entries[1].Leaf)
+	assert.Equal(t, "a/b%20c.txt", d.Entries[1].URL)
+}
+
+func TestWantsJSON(t *testing.T) {
+	for _, test := range []struct {
+		url    string
+		accept string
+		want   bool
+	}{
+		{"/", "", false},
+		{"/?format=json", "", true},
+		{"/?format=html", "application/json", false},
+		{"/", "application/json", true},
+		{"/", "text/html,application/xhtml+xml,application/json;q=0.9", false},
+		{"/", "application/json, text/plain, */*", true},
+	} {
+		r := httptest.NewRequest("GET", "http://example.com"+test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		assert.Equal(t, test.want, WantsJSON(r), test)
+	}
+}
+
+func TestServeJSON(t *testing.T) {
+	modTime := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := NewDirectory("aDirectory", GetTemplate(t))
+	d.AddHTMLEntryDetails("aDirectory/file", false, 64, modTime, "text/plain", map[string]string{"md5": "123"})
+	d.AddHTMLEntry("aDirectory/dir", true, 0, time.Time{})
+	require.NoError(t, d.ProcessPageParams("", "1"))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aDirectory/?format=json", nil)
+	d.Serve(w, r)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, `{"name":"/aDirectory","entries":[{"name":"file","url":"file","size":64,"modTime":"2000-01-02T03:04:05Z","isDir":false,"mimeType":"text/plain","hashes":{"md5":"123"}}],"total":2,"offset":0,"limit":1}
+`, string(body))
+}
@@ -0,0 +1,523 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/vfs"
+)
+
+// limitFs wraps an Fs to enforce the limits of a User
+type limitFs struct {
+	fs.Fs
+	u        *User
+	features *fs.Features
+}
+
+// newLimitFs makes an Fs which enforces the limits of u on f
+func newLimitFs(f fs.Fs, u *User) *limitFs {
+	lf := &limitFs{
+		Fs: f,
+		u:  u,
+	}
+	lf.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          true,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		CanHaveEmptyDirectories: true,
+		BucketBased:             true,
+		BucketBasedRootOK:       true,
+		SetTier:                 true,
+		GetTier:                 true,
+		ServerSideAcrossConfigs: false,
+		SlowModTime:             true,
+		SlowHash:                true,
+	}).Fill(lf).Mask(f)
+	// OpenWriterAt and Command aren't proxied as they would write
+	// to f without the limits being checked.
+	// About reports the quota even if f can't
+	if u.Limits().Quota > 0 {
+		lf.features.About = lf.About
+	}
+	return lf
+}
+
+// sizeOf returns the total size of the objects in f
+func sizeOf(ctx context.Context, f fs.Fs) (size int64, err error) {
+	_, size, err = operations.Count(ctx, f)
+	return size, err
+}
+
+// Features returns the optional features of this Fs
+func (f *limitFs) Features() *fs.Features {
+	return f.features
+}
+
+// String returns a description of the FS
+func (f *limitFs) String() string {
+	return f.Fs.String()
+}
+
+// wrapEntries wraps the objects in entries
+func (f *limitFs) wrapEntries(entries fs.DirEntries) fs.DirEntries {
+	for i, entry := range entries {
+		if o, ok := entry.(fs.Object); ok {
+			entries[i] = f.newObject(o)
+		}
+	}
+	return entries
+}
+
+// List the objects and directories in dir into entries.
+func (f *limitFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return f.wrapEntries(entries), nil
+}
+
+// NewObject finds the Object at remote.
+func (f *limitFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// oldSize returns the size of the object at remote which is about
+// to be replaced or 0 if it doesn't exist or there is no quota.
+func (f *limitFs) oldSize(ctx context.Context, remote string) int64 {
+	if f.u.Limits().Quota <= 0 {
+		return 0
+	}
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil || o.Size() < 0 {
+		return 0
+	}
+	return o.Size()
+}
+
+// putFn is the signature of Put and PutStream
+type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
+
+// upload does a Put or Update with put checking the limits. oldSize
+// is the size of the object being replaced.
+func (f *limitFs) upload(ctx context.Context, put putFn, in io.Reader, src fs.ObjectInfo, oldSize int64, options ...fs.OpenOption) (fs.Object, error) {
+	err := f.u.measureUsed(ctx, f.Fs)
+	if err != nil {
+		return nil, err
+	}
+	err = f.u.checkUpload(src.Size(), oldSize)
+	if err != nil {
+		return nil, limitError(err)
+	}
+	r := &uploadReader{
+		ctx:     ctx,
+		in:      in,
+		u:       f.u,
+		oldSize: oldSize,
+	}
+	o, err := put(ctx, r, src, options...)
+	if r.err != nil {
+		// return the limit error rather than the one from put so
+		// the servers can turn it into the correct protocol error
+		err = r.err
+	}
+	newSize := r.total
+	if err == nil && o != nil && o.Size() >= 0 {
+		newSize = o.Size()
+	}
+	f.u.finishUpload(r.total, err == nil, newSize-oldSize)
+	if err != nil {
+		return o, limitError(err)
+	}
+	return o, nil
+}
+
+// limitError marks err so it won't be retried if it is one of the
+// limit errors, eg when the VFS cache uploads a file written with
+// --vfs-cache-mode writes. The servers find the limit error with
+// errors.Cause.
+func limitError(err error) error {
+	switch err {
+	case vfs.ENOSPC, vfs.EFBIG, vfs.EROFS:
+		return fserrors.NoRetryError(err)
+	}
+	return err
+}
+
+// Put in to the remote path with the modTime given of the given size
+func (f *limitFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o, err := f.upload(ctx, f.Fs.Put, in, src, f.oldSize(ctx, src.Remote()), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *limitFs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	do := f.Fs.Features().PutStream
+	if do == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	o, err := f.upload(ctx, do, in, src, f.oldSize(ctx, src.Remote()), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// PutUnchecked uploads the object without checking whether it
+// exists already
+func (f *limitFs) PutUnchecked(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	do := f.Fs.Features().PutUnchecked
+	if do == nil {
+		return nil, errors.New("can't PutUnchecked")
+	}
+	o, err := f.upload(ctx, do, in, src, 0, options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// Mkdir makes the directory (container, bucket)
+func (f *limitFs) Mkdir(ctx context.Context, dir string) error {
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return f.Fs.Mkdir(ctx, dir)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+func (f *limitFs) Rmdir(ctx context.Context, dir string) error {
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return f.Fs.Rmdir(ctx, dir)
+}
+
+// Purge all files in the root and the root directory
+func (f *limitFs) Purge(ctx context.Context) error {
+	do := f.Fs.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	err = do(ctx)
+	// measure what is left when it is next needed
+	f.u.forgetUsed()
+	return err
+}
+
+// MergeDirs merges the contents of all the directories passed
+// in into the first one and rmdirs the other directories.
+func (f *limitFs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
+	do := f.Fs.Features().MergeDirs
+	if do == nil {
+		return errors.New("MergeDirs not supported")
+	}
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return do(ctx, dirs)
+}
+
+// CleanUp the trash in the Fs
+func (f *limitFs) CleanUp(ctx context.Context) error {
+	do := f.Fs.Features().CleanUp
+	if do == nil {
+		return errors.New("can't CleanUp")
+	}
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return do(ctx)
+}
+
+// ListR lists the objects and directories of the Fs starting
+// from dir recursively into out.
+func (f *limitFs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) error {
+	do := f.Fs.Features().ListR
+	if do == nil {
+		return errors.New("can't ListR")
+	}
+	return do(ctx, dir, func(entries fs.DirEntries) error {
+		return callback(f.wrapEntries(entries))
+	})
+}
+
+// unwrapObject returns the underlying object of src or nil if src
+// isn't from this Fs
+func (f *limitFs) unwrapObject(src fs.Object) fs.Object {
+	o, ok := src.(*limitObject)
+	if !ok || o.f != f {
+		return nil
+	}
+	return o.Object
+}
+
+// Copy src to this remote using server side copy operations.
+func (f *limitFs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	do := f.Fs.Features().Copy
+	srcObj := f.unwrapObject(src)
+	if do == nil || srcObj == nil {
+		return nil, fs.ErrorCantCopy
+	}
+	return f.serverSideCopy(ctx, do, srcObj, remote)
+}
+
+// serverSideCopy makes a new object at remote from srcObj with do
+// checking the limits
+func (f *limitFs) serverSideCopy(ctx context.Context, do func(ctx context.Context, src fs.Object, remote string) (fs.Object, error), srcObj fs.Object, remote string) (fs.Object, error) {
+	err := f.u.measureUsed(ctx, f.Fs)
+	if err != nil {
+		return nil, err
+	}
+	oldSize := f.oldSize(ctx, remote)
+	err = f.u.checkUpload(srcObj.Size(), oldSize)
+	if err != nil {
+		return nil, err
+	}
+	o, err := do(ctx, srcObj, remote)
+	if err != nil {
+		return nil, err
+	}
+	f.u.addUsed(o.Size() - oldSize)
+	return f.newObject(o), nil
+}
+
+// Hardlink makes src available at remote as a hard link. This
+// counts against the quota like a copy as that is how the storage
+// used is measured.
+func (f *limitFs) Hardlink(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	do := f.Fs.Features().Hardlink
+	srcObj := f.unwrapObject(src)
+	if do == nil || srcObj == nil {
+		return nil, fs.ErrorCantHardlink
+	}
+	return f.serverSideCopy(ctx, do, srcObj, remote)
+}
+
+// Move src to this remote using server side move operations.
+func (f *limitFs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	do := f.Fs.Features().Move
+	srcObj := f.unwrapObject(src)
+	if do == nil || srcObj == nil {
+		return nil, fs.ErrorCantMove
+	}
+	err := f.u.checkWrite()
+	if err != nil {
+		return nil, err
+	}
+	oldSize := f.oldSize(ctx, remote)
+	o, err := do(ctx, srcObj, remote)
+	if err != nil {
+		return nil, err
+	}
+	f.u.addUsed(-oldSize)
+	return f.newObject(o), nil
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote
+// using server side move operations.
+func (f *limitFs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
+	do := f.Fs.Features().DirMove
+	srcFs, ok := src.(*limitFs)
+	if do == nil || !ok || srcFs != f {
+		return fs.ErrorCantDirMove
+	}
+	err := f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return do(ctx, srcFs.Fs, srcRemote, dstRemote)
+}
+
+// ChangeNotify calls the passed function with a path that has had changes.
+func (f *limitFs) ChangeNotify(ctx context.Context, notifyFunc func(string, fs.EntryType), pollIntervalChan <-chan time.Duration) {
+	do := f.Fs.Features().ChangeNotify
+	if do == nil {
+		return
+	}
+	do(ctx, notifyFunc, pollIntervalChan)
+}
+
+// DirCacheFlush resets the directory cache
+func (f *limitFs) DirCacheFlush() {
+	do := f.Fs.Features().DirCacheFlush
+	if do != nil {
+		do()
+	}
+}
+
+// PublicLink generates a public link to the remote path
+func (f *limitFs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (string, error) {
+	do := f.Fs.Features().PublicLink
+	if do == nil {
+		return "", errors.New("PublicLink not supported")
+	}
+	return do(ctx, remote, expire, unlink)
+}
+
+// UserInfo returns info about the connected user
+func (f *limitFs) UserInfo(ctx context.Context) (map[string]string, error) {
+	do := f.Fs.Features().UserInfo
+	if do == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
+// Disconnect the current user
+func (f *limitFs) Disconnect(ctx context.Context) error {
+	do := f.Fs.Features().Disconnect
+	if do == nil {
+		return fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
+// About gets quota information from the Fs, reporting the user's
+// quota if they have one.
+func (f *limitFs) About(ctx context.Context) (*fs.Usage, error) {
+	err := f.u.measureUsed(ctx, f.Fs)
+	if err != nil {
+		return nil, err
+	}
+	f.u.mu.Lock()
+	quota, used := int64(f.u.limits.Quota), f.u.used
+	f.u.mu.Unlock()
+	if quota > 0 {
+		free := quota - used
+		if free < 0 {
+			free = 0
+		}
+		return &fs.Usage{
+			Total: &quota,
+			Used:  &used,
+			Free:  &free,
+		}, nil
+	}
+	do := f.Fs.Features().About
+	if do == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
+// limitObject wraps an Object to enforce the limits of a User
+type limitObject struct {
+	fs.Object
+	f *limitFs
+}
+
+// newObject wraps o
+func (f *limitFs) newObject(o fs.Object) *limitObject {
+	return &limitObject{
+		Object: o,
+		f:      f,
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *limitObject) Fs() fs.Info {
+	return o.f
+}
+
+// Open opens the file for read, limiting it to the user's download
+// bandwidth.
+func (o *limitObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	in, err := o.Object.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &downloadReader{
+		ctx:        ctx,
+		ReadCloser: in,
+		u:          o.f.u,
+	}, nil
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *limitObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	oldSize := o.Object.Size()
+	if oldSize < 0 {
+		oldSize = 0
+	}
+	update := func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+		return o.Object, o.Object.Update(ctx, in, src, options...)
+	}
+	_, err := o.f.upload(ctx, update, in, src, oldSize, options...)
+	return err
+}
+
+// Remove an object
+func (o *limitObject) Remove(ctx context.Context) error {
+	err := o.f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	size := o.Object.Size()
+	err = o.Object.Remove(ctx)
+	if err != nil {
+		return err
+	}
+	if size > 0 {
+		o.f.u.addUsed(-size)
+	}
+	return nil
+}
+
+// SetModTime sets the modification time of the object
+func (o *limitObject) SetModTime(ctx context.Context, modTime time.Time) error {
+	err := o.f.u.checkWrite()
+	if err != nil {
+		return err
+	}
+	return o.Object.SetModTime(ctx, modTime)
+}
+
+// UnWrap returns the wrapped Object
+func (o *limitObject) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*limitFs)(nil)
+	_ fs.PutStreamer     = (*limitFs)(nil)
+	_ fs.PutUncheckeder  = (*limitFs)(nil)
+	_ fs.Purger          = (*limitFs)(nil)
+	_ fs.Copier          = (*limitFs)(nil)
+	_ fs.Hardlinker      = (*limitFs)(nil)
+	_ fs.Mover           = (*limitFs)(nil)
+	_ fs.DirMover        = (*limitFs)(nil)
+	_ fs.MergeDirser     = (*limitFs)(nil)
+	_ fs.CleanUpper      = (*limitFs)(nil)
+	_ fs.ListRer         = (*limitFs)(nil)
+	_ fs.ChangeNotifier  = (*limitFs)(nil)
+	_ fs.DirCacheFlusher = (*limitFs)(nil)
+	_ fs.PublicLinker    = (*limitFs)(nil)
+	_ fs.UserInfoer      = (*limitFs)(nil)
+	_ fs.Disconnecter    = (*limitFs)(nil)
+	_ fs.Abouter         = (*limitFs)(nil)
+	_ fs.Object          = (*limitObject)(nil)
+	_ fs.ObjectUnWrapper = (*limitObject)(nil)
+)
@@ -0,0 +1,441 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/vfs"
+	"golang.org/x/time/rate"
+)
+
+// ErrTooManyConnections is returned by User.Connect if the user has
+// reached their connection limit
+var ErrTooManyConnections = errors.New("too many connections for user")
+
+// Limits are the per user limits returned by the auth proxy
+type Limits struct {
+	Quota           fs.SizeSuffix // total size of files the user may store - 0 for unlimited
+	MaxFileSize     fs.SizeSuffix // largest file the user may upload - 0 for unlimited
+	BwLimitUpload   fs.SizeSuffix // upload bandwidth in bytes/s - 0 for unlimited
+	BwLimitDownload fs.SizeSuffix // download bandwidth in bytes/s - 0 for unlimited
+	MaxConnections  int           // concurrent connections - 0 for unlimited
+	ReadOnly        bool          // if set the user may only read
+}
+
+// parseLimits reads the limits from the config returned by the proxy
+func parseLimits(config configmap.Simple) (limits Limits, err error) {
+	for _, size := range []struct {
+		key   string
+		value *fs.SizeSuffix
+	}{
+		{"_quota", &limits.Quota},
+		{"_max_file_size", &limits.MaxFileSize},
+		{"_bwlimit_upload", &limits.BwLimitUpload},
+		{"_bwlimit_download", &limits.BwLimitDownload},
+	} {
+		if value, ok := config.Get(size.key); ok && value != "" {
+			err = size.value.Set(value)
+			if err != nil {
+				return limits, errors.Wrapf(err, "proxy: bad %s", size.key)
+			}
+		}
+	}
+	if value, ok := config.Get("_max_connections"); ok && value != "" {
+		limits.MaxConnections, err = strconv.Atoi(value)
+		if err != nil {
+			return limits, errors.Wrap(err, "proxy: bad _max_connections")
+		}
+	}
+	if value, ok := config.Get("_read_only"); ok && value != "" {
+		limits.ReadOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			return limits, errors.Wrap(err, "proxy: bad _read_only")
+		}
+	}
+	return limits, nil
+}
+
+// User holds the limits and usage of a user authenticated by the
+// auth proxy
+//
+// Users are shared between all the servers using an auth proxy so
+// the limits apply across all of them.
+type User struct {
+	Name string
+
+	measureMu          sync.Mutex // held while measuring used
+	mu                 sync.Mutex
+	limits             Limits
+	upload             *rate.Limiter // nil if no upload limit
+	download           *rate.Limiter // nil if no download limit
+	usedKnown          bool          // set if used has been measured
+	used               int64         // bytes stored by the user
+	pending            int64         // bytes in uploads in progress
+	connections        int           // current connections
+	bytesIn            int64         // bytes uploaded
+	bytesOut           int64         // bytes downloaded
+	quotaExceeded      int64         // uploads refused for quota or file size
+	connectionsRefused int64         // connections refused
+	vfsRefs            int           // cached VFS enforcing the limits
+	lastUsed           time.Time     // when the user was last active
+}
+
+var (
+	usersMu sync.Mutex
+	users   = map[string]*User{}
+
+	// userExpire is how long a user with nothing in use is kept
+	// for before being forgotten along with their metrics
+	userExpire = time.Hour
+)
+
+// touch records the user as active - call with u.mu held
+func (u *User) touch() {
+	u.lastUsed = time.Now()
+}
+
+// idle returns true if nothing is using u and it hasn't been used
+// since before cutoff
+func (u *User) idle(cutoff time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.connections == 0 && u.pending == 0 && u.vfsRefs == 0 && u.lastUsed.Before(cutoff)
+}
+
+// pruneUsers forgets the users which have been idle for longer than
+// userExpire - call with usersMu held
+func pruneUsers() {
+	cutoff := time.Now().Add(-userExpire)
+	for name, u := range users {
+		if u.idle(cutoff) {
+			delete(users, name)
+		}
+	}
+}
+
+// getUser finds or makes the User called name
+func getUser(name string) *User {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	pruneUsers()
+	u := users[name]
+	if u == nil {
+		u = &User{Name: name}
+		users[name] = u
+	}
+	u.mu.Lock()
+	u.touch()
+	u.mu.Unlock()
+	return u
+}
+
+// GetUser returns the User called name or nil if the user hasn't
+// been authenticated by an auth proxy
+func GetUser(name string) *User {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	return users[name]
+}
+
+// allUsers returns the users sorted by name
+func allUsers() (out []*User) {
+	usersMu.Lock()
+	pruneUsers()
+	for _, u := range users {
+		out = append(out, u)
+	}
+	usersMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// newLimiter makes a rate limiter for bandwidth bytes/s or returns
+// nil if there is no limit
+func newLimiter(bandwidth fs.SizeSuffix) *rate.Limiter {
+	if bandwidth <= 0 {
+		return nil
+	}
+	burst := int(bandwidth)
+	if burst < 64*1024 {
+		burst = 64 * 1024
+	}
+	return rate.NewLimiter(rate.Limit(bandwidth), burst)
+}
+
+// setLimits sets the limits for the user
+func (u *User) setLimits(limits Limits) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if limits.BwLimitUpload != u.limits.BwLimitUpload || u.upload == nil {
+		u.upload = newLimiter(limits.BwLimitUpload)
+	}
+	if limits.BwLimitDownload != u.limits.BwLimitDownload || u.download == nil {
+		u.download = newLimiter(limits.BwLimitDownload)
+	}
+	u.limits = limits
+}
+
+// Limits returns the current limits for the user
+func (u *User) Limits() Limits {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.limits
+}
+
+// hold records a cached VFS enforcing the limits of the user so it
+// isn't pruned while the VFS is in use
+func (u *User) hold() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.vfsRefs++
+}
+
+// release records the end of a VFS recorded with hold
+func (u *User) release() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.vfsRefs > 0 {
+		u.vfsRefs--
+	}
+	u.touch()
+}
+
+// measureUsed sets the storage used by the user from f if there is
+// a quota and it hasn't been measured already.
+//
+// This is done the first time the quota is needed rather than when
+// the user logs in as it lists the whole of f.
+func (u *User) measureUsed(ctx context.Context, f fs.Fs) error {
+	u.measureMu.Lock()
+	defer u.measureMu.Unlock()
+	u.mu.Lock()
+	needed := u.limits.Quota > 0 && !u.usedKnown
+	u.mu.Unlock()
+	if !needed {
+		return nil
+	}
+	used, err := sizeOf(ctx, f)
+	if err != nil {
+		return errors.Wrap(err, "proxy: failed to measure storage used")
+	}
+	u.mu.Lock()
+	u.used = used
+	u.usedKnown = true
+	u.mu.Unlock()
+	fs.Debugf(nil, "proxy: user %q is using %v of quota %v", u.Name, fs.SizeSuffix(used), u.limits.Quota)
+	return nil
+}
+
+// Connect records a new connection for the user returning
+// ErrTooManyConnections if the user has too many already.
+//
+// Call Disconnect when the connection is finished.
+func (u *User) Connect() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.limits.MaxConnections > 0 && u.connections >= u.limits.MaxConnections {
+		u.connectionsRefused++
+		return ErrTooManyConnections
+	}
+	u.connections++
+	return nil
+}
+
+// Disconnect records the end of a connection made with Connect
+func (u *User) Disconnect() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.connections > 0 {
+		u.connections--
+	}
+	u.touch()
+}
+
+// ConnectUser records a new connection for the user called name if
+// they were authenticated by the auth proxy, returning a function to
+// call when the connection is finished.
+//
+// It returns ErrTooManyConnections if the user has too many already.
+func ConnectUser(name string) (disconnect func(), err error) {
+	u := GetUser(name)
+	if u == nil {
+		return func() {}, nil
+	}
+	err = u.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return u.Disconnect, nil
+}
+
+// checkWrite returns EROFS if the user is read only
+func (u *User) checkWrite() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.limits.ReadOnly {
+		return vfs.EROFS
+	}
+	return nil
+}
+
+// checkUpload checks an upload of size bytes replacing a file of
+// oldSize bytes is within the limits. size may be -1 if unknown.
+func (u *User) checkUpload(size, oldSize int64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.limits.ReadOnly {
+		return vfs.EROFS
+	}
+	if size < 0 {
+		return nil
+	}
+	if u.limits.MaxFileSize > 0 && size > int64(u.limits.MaxFileSize) {
+		u.quotaExceeded++
+		return vfs.EFBIG
+	}
+	if u.limits.Quota > 0 && u.used+u.pending+size-oldSize > int64(u.limits.Quota) {
+		u.quotaExceeded++
+		return vfs.ENOSPC
+	}
+	return nil
+}
+
+// addPending adds n bytes to an upload of total bytes so far which
+// is replacing a file of oldSize bytes, checking it is still within
+// the limits.
+func (u *User) addPending(n, total, oldSize int64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pending += n
+	u.bytesIn += n
+	if u.limits.MaxFileSize > 0 && total > int64(u.limits.MaxFileSize) {
+		u.quotaExceeded++
+		return vfs.EFBIG
+	}
+	if u.limits.Quota > 0 && u.used+u.pending-oldSize > int64(u.limits.Quota) {
+		u.quotaExceeded++
+		return vfs.ENOSPC
+	}
+	return nil
+}
+
+// finishUpload records the end of an upload of total bytes. If it
+// succeeded then the stored size changes by delta.
+func (u *User) finishUpload(total int64, ok bool, delta int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pending -= total
+	u.touch()
+	if ok {
+		u.used += delta
+		if u.used < 0 {
+			u.used = 0
+		}
+	}
+}
+
+// forgetUsed marks the storage used as unknown so it is measured
+// again when next needed
+func (u *User) forgetUsed() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usedKnown = false
+}
+
+// addUsed changes the storage used by delta
+func (u *User) addUsed(delta int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.used += delta
+	if u.used < 0 {
+		u.used = 0
+	}
+}
+
+// limiter returns the rate limiter for uploads or downloads
+func (u *User) limiter(upload bool) *rate.Limiter {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if upload {
+		return u.upload
+	}
+	return u.download
+}
+
+// wait waits for the bandwidth limit to allow n bytes
+func (u *User) wait(ctx context.Context, upload bool, n int) error {
+	limiter := u.limiter(upload)
+	if limiter == nil {
+		return nil
+	}
+	// WaitN can't wait for more than the burst at once
+	for n > 0 {
+		chunk := n
+		if burst := limiter.Burst(); chunk > burst {
+			chunk = burst
+		}
+		err := limiter.WaitN(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// uploadReader limits an upload to the user's bandwidth, quota and
+// maximum file size
+type uploadReader struct {
+	ctx     context.Context
+	in      io.Reader
+	u       *User
+	oldSize int64 // size of the file being replaced
+	total   int64 // bytes read so far
+	err     error // set if the upload exceeded the limits
+}
+
+// Read bytes from the upload
+func (r *uploadReader) Read(p []byte) (n int, err error) {
+	n, err = r.in.Read(p)
+	if n > 0 {
+		r.total += int64(n)
+		r.err = r.u.addPending(int64(n), r.total, r.oldSize)
+		if r.err != nil {
+			return n, r.err
+		}
+		if waitErr := r.u.wait(r.ctx, true, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// downloadReader limits a download to the user's bandwidth
+type downloadReader struct {
+	ctx context.Context
+	io.ReadCloser
+	u *User
+}
+
+// Read bytes from the download
+func (r *downloadReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 {
+		r.u.mu.Lock()
+		r.u.bytesOut += int64(n)
+		r.u.mu.Unlock()
+		if waitErr := r.u.wait(r.ctx, false, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
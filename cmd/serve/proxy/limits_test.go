@@ -0,0 +1,274 @@
+package proxy
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/lib/errors"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := parseLimits(configmap.Simple{
+		"type":              "local",
+		"_quota":            "1G",
+		"_max_file_size":    "100M",
+		"_bwlimit_upload":   "1M",
+		"_bwlimit_download": "10k",
+		"_max_connections":  "3",
+		"_read_only":        "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Limits{
+		Quota:           1 << 30,
+		MaxFileSize:     100 << 20,
+		BwLimitUpload:   1 << 20,
+		BwLimitDownload: 10 << 10,
+		MaxConnections:  3,
+		ReadOnly:        true,
+	}, limits)
+
+	limits, err = parseLimits(configmap.Simple{"type": "local"})
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, limits)
+
+	for _, key := range []string{"_quota", "_max_file_size", "_bwlimit_upload", "_bwlimit_download", "_max_connections", "_read_only"} {
+		_, err = parseLimits(configmap.Simple{key: "potato"})
+		assert.Error(t, err, key)
+		assert.Contains(t, err.Error(), key)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	u := getUser("connect-test")
+	u.setLimits(Limits{MaxConnections: 1})
+
+	disconnect, err := ConnectUser("connect-test")
+	require.NoError(t, err)
+	_, err = ConnectUser("connect-test")
+	assert.Equal(t, ErrTooManyConnections, err)
+	disconnect()
+	disconnect, err = ConnectUser("connect-test")
+	require.NoError(t, err)
+	disconnect()
+
+	// Users the proxy doesn't know about aren't limited
+	disconnect, err = ConnectUser("connect-test-unknown")
+	require.NoError(t, err)
+	disconnect()
+}
+
+// isError returns true if target is in the chain of err
+func isError(err, target error) (found bool) {
+	errors.Walk(err, func(err error) bool {
+		found = err == target
+		return found
+	})
+	return found
+}
+
+func TestLimitFs(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-limits")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "existing.txt"), []byte("0123456789"), 0666))
+
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	u := getUser("limitfs-test")
+	u.setLimits(Limits{Quota: 100, MaxFileSize: 50})
+	used := func() int64 {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		return u.used
+	}
+
+	// The features of f are kept except those bypassing the limits
+	lf := newLimitFs(f, u)
+	assert.NotNil(t, lf.Features().Purge)
+	assert.NotNil(t, lf.Features().Hardlink)
+	assert.Nil(t, lf.Features().ListR)
+	assert.Nil(t, lf.Features().OpenWriterAt)
+	assert.Nil(t, lf.Features().Command)
+
+	// The storage used isn't measured until it is needed
+	vfsOpt := vfsflags.Opt
+	VFS := vfs.New(lf, &vfsOpt)
+	defer VFS.Shutdown()
+	assert.Equal(t, int64(0), used())
+	require.NoError(t, u.measureUsed(ctx, f))
+	assert.Equal(t, int64(10), used())
+
+	write := func(name string, size int) error {
+		fd, err := VFS.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+		if err != nil {
+			return err
+		}
+		_, err = fd.Write([]byte(strings.Repeat("x", size)))
+		closeErr := fd.Close()
+		if err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	// Within the limits
+	require.NoError(t, write("a.txt", 40))
+	assert.Equal(t, int64(50), used())
+
+	// Too big
+	err = write("big.txt", 60)
+	assert.True(t, isError(err, vfs.EFBIG), err)
+	assert.False(t, exists("big.txt"))
+	assert.Equal(t, int64(50), used())
+
+	require.NoError(t, write("b.txt", 40))
+	assert.Equal(t, int64(90), used())
+
+	// Over quota
+	err = write("c.txt", 20)
+	assert.True(t, isError(err, vfs.ENOSPC), err)
+	assert.True(t, fserrors.IsNoRetryError(err), "limit errors shouldn't be retried")
+	assert.False(t, exists("c.txt"))
+	assert.Equal(t, int64(90), used())
+
+	// Replacing a file only counts the difference
+	require.NoError(t, write("b.txt", 45))
+	assert.Equal(t, int64(95), used())
+
+	// About reports the quota
+	total, _, free := VFS.Statfs()
+	assert.Equal(t, int64(100), total)
+	assert.Equal(t, int64(5), free)
+
+	// Removing frees space
+	require.NoError(t, VFS.Remove("a.txt"))
+	assert.Equal(t, int64(55), used())
+	require.NoError(t, write("c.txt", 20))
+	assert.Equal(t, int64(75), used())
+
+	// Reads are counted
+	u.mu.Lock()
+	bytesOut := u.bytesOut
+	u.mu.Unlock()
+	data, err := VFS.ReadFile("existing.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+	u.mu.Lock()
+	assert.Equal(t, bytesOut+10, u.bytesOut)
+	u.mu.Unlock()
+
+	// Read only
+	u.setLimits(Limits{ReadOnly: true})
+	err = write("d.txt", 1)
+	assert.True(t, isError(err, vfs.EROFS), err)
+	assert.False(t, exists("d.txt"))
+	assert.True(t, isError(VFS.Mkdir("dir", 0777), vfs.EROFS))
+	assert.True(t, isError(VFS.Remove("c.txt"), vfs.EROFS))
+	assert.True(t, exists("c.txt"))
+	assert.True(t, isError(lf.Purge(ctx), vfs.EROFS))
+	assert.True(t, exists("c.txt"))
+
+	// Purge makes the storage used be measured again
+	u.setLimits(Limits{Quota: 100})
+	require.NoError(t, lf.Purge(ctx))
+	assert.False(t, exists("c.txt"))
+	require.NoError(t, os.MkdirAll(dir, 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "new.txt"), []byte("0123"), 0666))
+	_, err = lf.About(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), used())
+}
+
+func TestPruneUsers(t *testing.T) {
+	defer func(old time.Duration) { userExpire = old }(userExpire)
+	userExpire = time.Minute
+	idle := time.Now().Add(-2 * userExpire)
+	setLastUsed := func(u *User) {
+		u.mu.Lock()
+		u.lastUsed = idle
+		u.mu.Unlock()
+	}
+
+	connected := getUser("prune-connected")
+	require.NoError(t, connected.Connect())
+	setLastUsed(connected)
+	held := getUser("prune-held")
+	held.hold()
+	setLastUsed(held)
+	expired := getUser("prune-expired")
+	setLastUsed(expired)
+	recent := getUser("prune-recent")
+
+	usersMu.Lock()
+	pruneUsers()
+	usersMu.Unlock()
+	assert.True(t, GetUser("prune-connected") == connected)
+	assert.True(t, GetUser("prune-held") == held)
+	assert.Nil(t, GetUser("prune-expired"))
+	assert.True(t, GetUser("prune-recent") == recent)
+
+	// Users are kept for userExpire after they are released
+	connected.Disconnect()
+	held.release()
+	usersMu.Lock()
+	pruneUsers()
+	usersMu.Unlock()
+	assert.NotNil(t, GetUser("prune-connected"))
+	assert.NotNil(t, GetUser("prune-held"))
+	setLastUsed(connected)
+	setLastUsed(held)
+	for _, u := range allUsers() {
+		assert.NotContains(t, []string{"prune-connected", "prune-held"}, u.Name)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	u := getUser("metrics-test")
+	u.setLimits(Limits{Quota: 1000, MaxConnections: 2})
+	require.NoError(t, u.Connect())
+	defer u.Disconnect()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newUserCollector())
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "user" && label.GetValue() == "metrics-test" {
+					if metric.GetGauge() != nil {
+						values[family.GetName()] = metric.GetGauge().GetValue()
+					} else {
+						values[family.GetName()] = metric.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, float64(1000), values["rclone_serve_user_quota_bytes"])
+	assert.Equal(t, float64(1), values["rclone_serve_user_connections"])
+	assert.Equal(t, float64(2), values["rclone_serve_user_max_connections"])
+	assert.Equal(t, float64(0), values["rclone_serve_user_uploaded_bytes_total"])
+	assert.Len(t, values, 8)
+}
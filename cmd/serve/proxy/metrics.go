@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var namespace = "rclone_serve_user_"
+
+// userCollector is a Prometheus collector for the limits and usage
+// of the users authenticated by the auth proxy
+type userCollector struct {
+	quota              *prometheus.Desc
+	used               *prometheus.Desc
+	connections        *prometheus.Desc
+	maxConnections     *prometheus.Desc
+	uploaded           *prometheus.Desc
+	downloaded         *prometheus.Desc
+	quotaExceeded      *prometheus.Desc
+	connectionsRefused *prometheus.Desc
+}
+
+// newUserCollector makes a new userCollector
+func newUserCollector() *userCollector {
+	labels := []string{"user"}
+	return &userCollector{
+		quota: prometheus.NewDesc(namespace+"quota_bytes",
+			"Storage quota of the user in bytes, 0 for unlimited",
+			labels, nil,
+		),
+		used: prometheus.NewDesc(namespace+"used_bytes",
+			"Storage used by the user in bytes, only measured if the user has a quota",
+			labels, nil,
+		),
+		connections: prometheus.NewDesc(namespace+"connections",
+			"Number of connections the user has open",
+			labels, nil,
+		),
+		maxConnections: prometheus.NewDesc(namespace+"max_connections",
+			"Maximum number of connections the user may have open, 0 for unlimited",
+			labels, nil,
+		),
+		uploaded: prometheus.NewDesc(namespace+"uploaded_bytes_total",
+			"Total bytes uploaded by the user",
+			labels, nil,
+		),
+		downloaded: prometheus.NewDesc(namespace+"downloaded_bytes_total",
+			"Total bytes downloaded by the user",
+			labels, nil,
+		),
+		quotaExceeded: prometheus.NewDesc(namespace+"quota_exceeded_total",
+			"Number of uploads refused as they exceeded the quota or maximum file size",
+			labels, nil,
+		),
+		connectionsRefused: prometheus.NewDesc(namespace+"connections_refused_total",
+			"Number of connections refused as the user had too many open",
+			labels, nil,
+		),
+	}
+}
+
+// Describe is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+func (c *userCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.quota
+	ch <- c.used
+	ch <- c.connections
+	ch <- c.maxConnections
+	ch <- c.uploaded
+	ch <- c.downloaded
+	ch <- c.quotaExceeded
+	ch <- c.connectionsRefused
+}
+
+// Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+func (c *userCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, u := range allUsers() {
+		u.mu.Lock()
+		limits := u.limits
+		used, bytesIn, bytesOut := u.used, u.bytesIn, u.bytesOut
+		connections, quotaExceeded, connectionsRefused := u.connections, u.quotaExceeded, u.connectionsRefused
+		u.mu.Unlock()
+		ch <- prometheus.MustNewConstMetric(c.quota, prometheus.GaugeValue, float64(limits.Quota), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.used, prometheus.GaugeValue, float64(used), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(connections), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.maxConnections, prometheus.GaugeValue, float64(limits.MaxConnections), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.uploaded, prometheus.CounterValue, float64(bytesIn), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.downloaded, prometheus.CounterValue, float64(bytesOut), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.quotaExceeded, prometheus.CounterValue, float64(quotaExceeded), u.Name)
+		ch <- prometheus.MustNewConstMetric(c.connectionsRefused, prometheus.CounterValue, float64(connectionsRefused), u.Name)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(newUserCollector())
+}
@@ -47,8 +47,43 @@ config.
 This config generated must have this extra parameter
 - |_root| - root to use for the backend
 
-And it may have this parameter
+And it may have these parameters
 - |_obscure| - comma separated strings for parameters to obscure
+- |_quota| - total size of files the user may store, eg |10G|
+- |_max_file_size| - size of the largest file the user may upload, eg |1G|
+- |_bwlimit_upload| - upload bandwidth limit in bytes/s, eg |1M|
+- |_bwlimit_download| - download bandwidth limit in bytes/s, eg |10M|
+- |_max_connections| - number of connections the user may have open at once
+- |_read_only| - set to |true| to only allow the user to read
+
+If |_quota| or |_max_file_size| are exceeded then the upload will fail
+with the "no space" error of the protocol being served where it has
+one - |507 Insufficient Storage| for webdav and http, or
+|SSH_FX_FAILURE| with "No space left on device" for sftp. The ftp
+library rclone uses always replies |450| to a failed upload, so ftp
+clients will see "No space left on device" or "File too large" in
+the message.
+
+The storage used is measured the first time the quota is needed
+after the user logs in, by listing their whole remote, then tracked
+by rclone. Changes made to the backend outside of rclone won't be
+noticed until the user has been idle for an hour and is forgotten,
+or rclone is restarted.
+
+With |--vfs-cache-mode writes| or |full| the limits are only checked
+when the file is uploaded from the cache after the client has closed
+it, so the client won't see the error. Instead the failure is logged,
+the upload isn't retried and the file is left in the cache. Use
+|--vfs-cache-mode off| or |minimal| if clients need to see the error.
+
+For sftp and ftp |_max_connections| limits the number of logged in
+connections. For http and webdav it limits the number of requests in
+progress and further requests get |429 Too Many Requests|.
+
+The limits apply to each user across all the servers using an
+|--auth-proxy| in one rclone process. The limits and usage of each
+user are reported at |/metrics| if |--rc-enable-metrics| is set,
+until the user is forgotten.
 
 If password authentication was used by the client, input to the proxy
 process (on STDIN) would look similar to this:
@@ -119,21 +154,34 @@ type Proxy struct {
 	cmdLine  []string // broken down command line
 	vfsCache *libcache.Cache
 	Opt      Options
+	onExpire func(VFS *vfs.VFS) // if set called when a VFS expires
 }
 
 // cacheEntry is what is stored in the vfsCache
 type cacheEntry struct {
 	vfs    *vfs.VFS          // stored VFS
 	pwHash [sha256.Size]byte // sha256 hash of the password/publicKey
+	user   *User             // user the VFS enforces the limits of
 }
 
 // New creates a new proxy with the Options passed in
 func New(opt *Options) *Proxy {
-	return &Proxy{
+	p := &Proxy{
 		Opt:      *opt,
 		cmdLine:  strings.Fields(opt.AuthProxy),
 		vfsCache: libcache.New(),
 	}
+	// Release the user when their VFS expires so they can be pruned
+	// once idle
+	p.vfsCache.SetFinalizer(func(value interface{}) {
+		if entry, ok := value.(cacheEntry); ok {
+			entry.user.release()
+			if p.onExpire != nil {
+				p.onExpire(entry.vfs)
+			}
+		}
+	})
+	return p
 }
 
 // run the proxy command returning a config map
@@ -206,6 +254,12 @@ func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, er
 	if !ok {
 		return nil, errors.New("proxy: _root not set in result")
 	}
+	limits, err := parseLimits(config)
+	if err != nil {
+		return nil, err
+	}
+	u := getUser(user)
+	u.setLimits(limits)
 
 	// Find the backend
 	fsInfo, err := fs.Find(fsName)
@@ -233,14 +287,18 @@ func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, er
 		if err != nil {
 			return nil, false, err
 		}
+		vfsOpt := vfsflags.Opt
+		vfsOpt.ReadOnly = vfsOpt.ReadOnly || limits.ReadOnly
 
 		// We hash the auth here so we don't copy the auth more than we
 		// need to in memory. An attacker would find it easier to go
 		// after the unencrypted password in memory most likely.
 		entry := cacheEntry{
-			vfs:    vfs.New(f, &vfsflags.Opt),
+			vfs:    vfs.New(newLimitFs(f, u), &vfsOpt),
 			pwHash: sha256.Sum256([]byte(auth)),
+			user:   u,
 		}
+		u.hold()
 		return entry, true, nil
 	})
 	if err != nil {
@@ -285,6 +343,15 @@ func (p *Proxy) Call(user, auth string, isPublicKey bool) (VFS *vfs.VFS, vfsKey
 	return entry.vfs, user, nil
 }
 
+// OnExpire sets fn to be called with each VFS when it expires from
+// the cache after it hasn't been used for a while, so servers can
+// drop anything they hold for it.
+//
+// Call this before using the Proxy.
+func (p *Proxy) OnExpire(fn func(VFS *vfs.VFS)) {
+	p.onExpire = fn
+}
+
 // Get VFS from the cache using key - returns nil if not found
 func (p *Proxy) Get(key string) *vfs.VFS {
 	value, ok := p.vfsCache.GetMaybe(key)
@@ -12,6 +12,7 @@ import (
 	_ "github.com/rclone/rclone/backend/local"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/vfs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
@@ -146,6 +147,18 @@ func TestRun(t *testing.T) {
 
 	})
 
+	t.Run("OnExpire", func(t *testing.T) {
+		VFS, _, err := p.Call(testUser, testPass, false)
+		require.NoError(t, err)
+		var expired []*vfs.VFS
+		p.OnExpire(func(VFS *vfs.VFS) {
+			expired = append(expired, VFS)
+		})
+		defer p.vfsCache.SetFinalizer(nil)
+		p.vfsCache.Clear()
+		assert.Equal(t, []*vfs.VFS{VFS}, expired)
+	})
+
 	privateKey, privateKeyErr := rsa.GenerateKey(rand.Reader, 2048)
 	if privateKeyErr != nil {
 		log.Fatal("error generating test private key " + privateKeyErr.Error())
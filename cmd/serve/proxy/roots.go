@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/fspath"
+	libcache "github.com/rclone/rclone/lib/cache"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// Roots makes a VFS for each root directory within f so that users
+// can be given their own VFS, eg from a claim in a bearer token.
+type Roots struct {
+	f        fs.Fs
+	vfsOpt   vfscommon.Options
+	vfsCache *libcache.Cache
+	onExpire func(VFS *vfs.VFS) // if set called when a VFS expires
+}
+
+// NewRoots makes a Roots for f using vfsOpt to make each VFS
+func NewRoots(f fs.Fs, vfsOpt *vfscommon.Options) *Roots {
+	r := &Roots{
+		f:        f,
+		vfsOpt:   *vfsOpt,
+		vfsCache: libcache.New(),
+	}
+	// Shut down each VFS when it hasn't been used for a while
+	r.vfsCache.SetFinalizer(func(value interface{}) {
+		if VFS, ok := value.(*vfs.VFS); ok {
+			VFS.Shutdown()
+			if r.onExpire != nil {
+				r.onExpire(VFS)
+			}
+		}
+	})
+	return r
+}
+
+// OnExpire sets fn to be called with each VFS when it expires from
+// the cache after it hasn't been used for a while, so servers can
+// drop anything they hold for it.
+//
+// Call this before using the Roots.
+func (r *Roots) OnExpire(fn func(VFS *vfs.VFS)) {
+	r.onExpire = fn
+}
+
+// Hold marks the VFS for root as in use until release is called so
+// it isn't shut down while a request is using it.
+func (r *Roots) Hold(root string) (release func()) {
+	root, err := httplib.CleanRoot(root)
+	if err == nil {
+		_, err = r.Get(root)
+	}
+	if err != nil {
+		// Get will return the error when the VFS is used
+		return func() {}
+	}
+	r.vfsCache.Pin(root)
+	return func() {
+		r.vfsCache.Unpin(root)
+	}
+}
+
+// Get returns the VFS for root which is a directory within f
+func (r *Roots) Get(root string) (VFS *vfs.VFS, err error) {
+	root, err = httplib.CleanRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.vfsCache.Get(root, func(root string) (value interface{}, ok bool, err error) {
+		f, err := cache.Get(fspath.JoinRootPath(fs.ConfigString(r.f), root))
+		if err == fs.ErrorIsFile {
+			return nil, false, errors.Errorf("root %q is a file", root)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return vfs.New(f, &r.vfsOpt), true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to make VFS for root %q", root)
+	}
+	return value.(*vfs.VFS), nil
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-roots")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "served", "alice", "files"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "served", "alice", "files", "a.txt"), []byte("a"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "served", "top.txt"), []byte("top"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0666))
+
+	f, err := fs.NewFs(filepath.Join(dir, "served"))
+	require.NoError(t, err)
+	roots := NewRoots(f, &vfsflags.Opt)
+
+	ls := func(root string) (names []string) {
+		VFS, err := roots.Get(root)
+		require.NoError(t, err, root)
+		nodes, err := VFS.ReadDir("/")
+		require.NoError(t, err, root)
+		for _, node := range nodes {
+			names = append(names, node.Name())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"a.txt"}, ls("alice/files"))
+	assert.Equal(t, []string{"a.txt"}, ls("/alice/files/"))
+	assert.Equal(t, []string{"files"}, ls("alice/./"))
+
+	// Can't have the whole remote or escape it
+	for _, root := range []string{"", "/", ".", "..", "../", "../../alice/files", "alice/../alice"} {
+		_, err := roots.Get(root)
+		assert.Error(t, err, root)
+	}
+
+	// VFS are cached
+	v1, err := roots.Get("alice/files")
+	require.NoError(t, err)
+	v2, err := roots.Get("/alice/files")
+	require.NoError(t, err)
+	assert.True(t, v1 == v2)
+
+	// Root is a file
+	_, err = roots.Get("top.txt")
+	assert.Error(t, err)
+
+	// Expired VFS are shut down and OnExpire is called
+	var expired []*vfs.VFS
+	roots.OnExpire(func(VFS *vfs.VFS) {
+		expired = append(expired, VFS)
+	})
+	roots.vfsCache.Clear()
+	assert.Len(t, expired, 2)
+	assert.Contains(t, expired, v1)
+	v3, err := roots.Get("alice/files")
+	require.NoError(t, err)
+	assert.False(t, v3 == v1)
+}
@@ -15,12 +15,16 @@ import (
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
 	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fs/walk"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 	"golang.org/x/net/http2"
@@ -38,6 +42,7 @@ func init() {
 	flags.BoolVarP(flagSet, &stdio, "stdio", "", false, "run an HTTP2 server on stdin/stdout")
 	flags.BoolVarP(flagSet, &appendOnly, "append-only", "", false, "disallow deletion of repository data")
 	flags.BoolVarP(flagSet, &privateRepos, "private-repos", "", false, "users can only access their private repo")
+	servelib.AddRc("restic", startRc)
 }
 
 // Command definition for cobra
@@ -183,12 +188,51 @@ func (s *Server) Serve() error {
 	if err != nil {
 		return err
 	}
-	fs.Logf(s.f, "Serving restic REST API on %s", s.URL())
+	fs.Logf(s.f, "Serving restic REST API on %s", strings.Join(s.URLs(), ", "))
+	return nil
+}
+
+// startRc starts a server for serve/start
+//
+// The restic server doesn't use a VFS so vfsOpt is ignored
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	if proxyOpt.AuthProxy != "" {
+		return nil, errors.New("auth proxy not supported")
+	}
+	opt := httplib.DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = []string{addr}
+	s := NewServer(f, &opt)
+	err = s.Serve()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the URL the server is listening on
+func (s *Server) Addr() string {
+	return s.URL()
+}
+
+// Shutdown stops the server
+func (s *Server) Shutdown() error {
+	s.Close()
 	return nil
 }
 
 var matchData = regexp.MustCompile("(?:^|/)data/([^/]{2,})$")
 
+// rootRemote returns remote inside the root for the user making sure
+// it can't point outside the remote being served
+func rootRemote(root, remote string) string {
+	root = strings.Trim(path.Clean("/"+root), "/")
+	return strings.Trim(root+"/"+remote, "/")
+}
+
 // Makes a remote from a URL path.  This implements the backend layout
 // required by restic.
 func makeRemote(path string) string {
@@ -216,6 +260,9 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	remote := makeRemote(path)
+	if root, ok := r.Context().Value(httplib.ContextRootKey).(string); ok {
+		remote = rootRemote(root, remote)
+	}
 	fs.Debugf(s.f, "%s %s", r.Method, path)
 
 	v := r.Context().Value(httplib.ContextUserKey)
@@ -29,7 +29,7 @@ func TestRestic(t *testing.T) {
 	}
 
 	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
+	opt.ListenAddr = []string{testBindAddress}
 
 	fstest.Initialise()
 
@@ -0,0 +1,326 @@
+// Package servelib implements the rc calls to start and stop the
+// servers in cmd/serve.
+package servelib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsflags"
+)
+
+// Server is a running server started from the rc
+type Server interface {
+	// Wait blocks until the server has stopped
+	Wait()
+	// Addr returns the URL or address the server is listening on
+	Addr() string
+	// Shutdown stops the server and its VFS
+	Shutdown() error
+}
+
+// StartFn makes a new server to serve f on addr with its own VFS
+// made with vfsOpt and starts it in the background. If
+// proxyOpt.AuthProxy is set the server should use the auth proxy
+// instead of f if it supports it.
+//
+// The server specific options should be read from the "opt"
+// parameter of in.
+type StartFn func(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (Server, error)
+
+// ServerInfo defines the configuration for a running server
+type ServerInfo struct {
+	server    Server
+	ID        string             `json:"ID"`
+	Type      string             `json:"Type"`
+	Fs        string             `json:"Fs"`
+	Addr      string             `json:"Addr"`
+	StartedOn time.Time          `json:"StartedOn"`
+	VFSOpt    *vfscommon.Options `json:"VFSOpt"`
+}
+
+var (
+	// mutex to protect all the variables in this block
+	serveMu sync.Mutex
+	// Start functions available
+	startFns = map[string]StartFn{}
+	// Map of ID => ServerInfo
+	liveServers = map[string]*ServerInfo{}
+	// number of servers started, used to make the IDs
+	serverCount int
+	// handle for the exit function
+	atexitHandle atexit.FnHandle
+)
+
+// AddRc adds a server type which can be started with serve/start
+func AddRc(serverType string, startFunction StartFn) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	startFns[serverType] = startFunction
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/start",
+		AuthRequired: true,
+		Fn:           startRc,
+		Title:        "Start a server serving a remote",
+		Help: `This starts any of the servers in "rclone serve" serving a remote.
+Each server has its own VFS.
+
+This takes the following parameters
+
+- type - the type of server, see serve/types (required)
+- fs - a remote path to be served (required)
+- addr - the ip:port to listen on, eg "localhost:8080" (required)
+- opt - a JSON object with the options for the server type
+- vfsOpt - a JSON object with VFS options in
+- proxyOpt - a JSON object with the auth proxy options in, eg
+  {"AuthProxy": ""} to serve without the auth proxy
+
+and returns
+
+- id - the ID of the server to pass to serve/stop
+- addr - the URL or address the server is listening on
+
+Eg
+
+    rclone rc serve/start type=webdav fs=remote:path addr=localhost:8080
+    rclone rc serve/start type=ftp fs=remote: addr=:2121 opt='{"BasicUser": "user", "BasicPass": "pass"}' vfsOpt='{"CacheMode": 2}'
+
+The opt are the same as the flags for the server type, with the
+names of the fields in the options struct, so each server started
+has its own. The vfsOpt are as
+described in options/get and can be seen in the "vfs" section when
+running
+
+    rclone rc options/get
+
+Since the auth proxy runs a program, the rc can't choose it.  The
+AuthProxy in proxyOpt must be either empty or the same as the
+--auth-proxy flag given on the command line which is used by default.
+`,
+	})
+}
+
+// startRc starts a server from the rc
+func startRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	serverType, err := in.GetString("type")
+	if err != nil {
+		return nil, err
+	}
+	addr, err := in.GetString("addr")
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt := vfsflags.Opt
+	err = in.GetStructMissingOK("vfsOpt", &vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	proxyOpt := proxyflags.Opt
+	err = in.GetStructMissingOK("proxyOpt", &proxyOpt)
+	if err != nil {
+		return nil, err
+	}
+	// Don't let the rc run arbitrary programs
+	if proxyOpt.AuthProxy != "" && proxyOpt.AuthProxy != proxyflags.Opt.AuthProxy {
+		return nil, errors.New("AuthProxy in proxyOpt must be empty or the same as the --auth-proxy flag")
+	}
+	f, err := rc.GetFs(in)
+	if err != nil {
+		return nil, err
+	}
+
+	serveMu.Lock()
+	startFn := startFns[serverType]
+	serveMu.Unlock()
+	if startFn == nil {
+		return nil, errors.Errorf("server type %q not found - see serve/types", serverType)
+	}
+
+	server, err := startFn(f, addr, &vfsOpt, &proxyOpt, in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start %s server", serverType)
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	serverCount++
+	info := &ServerInfo{
+		server:    server,
+		ID:        fmt.Sprintf("%s-%d", serverType, serverCount),
+		Type:      serverType,
+		Fs:        fs.ConfigString(f),
+		Addr:      server.Addr(),
+		StartedOn: time.Now(),
+		VFSOpt:    &vfsOpt,
+	}
+	liveServers[info.ID] = info
+	if atexitHandle == nil {
+		atexitHandle = atexit.Register(func() {
+			_ = stopAll()
+		})
+	}
+
+	// Forget about the server if it stops by itself
+	go func() {
+		server.Wait()
+		serveMu.Lock()
+		defer serveMu.Unlock()
+		if liveServers[info.ID] == info {
+			fs.Errorf(nil, "%s server %s stopped unexpectedly", info.Type, info.ID)
+			delete(liveServers, info.ID)
+		}
+	}()
+
+	fs.Debugf(nil, "Started %s server %s for %s on %s", serverType, info.ID, info.Fs, info.Addr)
+	return rc.Params{
+		"id":   info.ID,
+		"addr": info.Addr,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/stop",
+		AuthRequired: true,
+		Fn:           stopRc,
+		Title:        "Stop a server started with serve/start",
+		Help: `This stops a server started with serve/start.
+
+This takes the following parameters
+
+- id - the ID of the server returned by serve/start (required)
+
+Eg
+
+    rclone rc serve/stop id=webdav-1
+`,
+	})
+}
+
+// stopRc stops a server from the rc
+func stopRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	id, err := in.GetString("id")
+	if err != nil {
+		return nil, err
+	}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	return nil, stop(id)
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/list",
+		AuthRequired: true,
+		Fn:           listRc,
+		Title:        "Show the servers started with serve/start",
+		Help: `This shows the running servers started with serve/start.
+
+This takes no parameters and returns
+
+- servers: list of running servers with their ID, Type, Fs, Addr,
+  StartedOn and VFSOpt
+
+Eg
+
+    rclone rc serve/list
+`,
+	})
+}
+
+// listRc returns a list of the running servers
+func listRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	var servers = []*ServerInfo{}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	for _, info := range liveServers {
+		servers = append(servers, info)
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].StartedOn.Before(servers[j].StartedOn)
+	})
+	return rc.Params{
+		"servers": servers,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/types",
+		AuthRequired: true,
+		Fn:           typesRc,
+		Title:        "Show all possible server types",
+		Help: `This shows all the server types which can be started with
+serve/start and returns them as a list.
+
+This takes no parameters and returns
+
+- types: list of server types
+
+The server types are strings like "http", "webdav", "ftp" and can be
+passed to serve/start as the type parameter.
+
+Eg
+
+    rclone rc serve/types
+`,
+	})
+}
+
+// typesRc returns a list of the server types
+func typesRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	var types = []string{}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	for serverType := range startFns {
+		types = append(types, serverType)
+	}
+	sort.Strings(types)
+	return rc.Params{
+		"types": types,
+	}, nil
+}
+
+// stop shuts down the server with the given ID
+//
+// Call with serveMu held
+func stop(id string) error {
+	info, ok := liveServers[id]
+	if !ok {
+		return errors.New("server not found")
+	}
+	delete(liveServers, id)
+	err := info.server.Shutdown()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stop server %s", id)
+	}
+	fs.Debugf(nil, "Stopped %s server %s on %s", info.Type, info.ID, info.Addr)
+	return nil
+}
+
+// stopAll shuts down all the running servers
+func stopAll() (err error) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	for id := range liveServers {
+		stopErr := stop(id)
+		if stopErr != nil {
+			fs.Errorf(nil, "%v", stopErr)
+			err = stopErr
+		}
+	}
+	return err
+}
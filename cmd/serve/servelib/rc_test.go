@@ -0,0 +1,133 @@
+package servelib_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	_ "github.com/rclone/rclone/cmd/serve/http"
+	_ "github.com/rclone/rclone/cmd/serve/webdav"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRc(t *testing.T) {
+	ctx := context.Background()
+	start := rc.Calls.Get("serve/start")
+	require.NotNil(t, start)
+	stop := rc.Calls.Get("serve/stop")
+	require.NotNil(t, stop)
+	list := rc.Calls.Get("serve/list")
+	require.NotNil(t, list)
+	types := rc.Calls.Get("serve/types")
+	require.NotNil(t, types)
+
+	localDir, err := ioutil.TempDir("", "rclone-servelib")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(localDir) }()
+	err = ioutil.WriteFile(filepath.Join(localDir, "file.txt"), []byte("hello"), 0666)
+	require.NoError(t, err)
+
+	out, err := types.Fn(ctx, nil)
+	require.NoError(t, err)
+	var serverTypes []string
+	require.NoError(t, out.GetStruct("types", &serverTypes))
+	assert.Equal(t, []string{"http", "webdav"}, serverTypes)
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := start.Fn(ctx, rc.Params{})
+		assert.Error(t, err)
+
+		_, err = start.Fn(ctx, rc.Params{"type": "http", "addr": "localhost:0"})
+		assert.Error(t, err)
+
+		_, err = start.Fn(ctx, rc.Params{"type": "potato", "fs": localDir, "addr": "localhost:0"})
+		assert.Error(t, err)
+
+		_, err = start.Fn(ctx, rc.Params{"type": "http", "fs": localDir, "addr": "localhost:-1"})
+		assert.Error(t, err)
+
+		_, err = stop.Fn(ctx, rc.Params{"id": "http-999"})
+		assert.Error(t, err)
+
+		// The rc can't choose the auth proxy program
+		_, err = start.Fn(ctx, rc.Params{"type": "webdav", "fs": localDir, "addr": "localhost:0", "proxyOpt": rc.Params{"AuthProxy": "/bin/sh -c potato"}})
+		assert.EqualError(t, err, "AuthProxy in proxyOpt must be empty or the same as the --auth-proxy flag")
+	})
+
+	listServers := func() []map[string]interface{} {
+		out, err := list.Fn(ctx, nil)
+		require.NoError(t, err)
+		var servers []map[string]interface{}
+		require.NoError(t, out.GetStruct("servers", &servers))
+		return servers
+	}
+
+	t.Run("StartStop", func(t *testing.T) {
+		// The cache password mustn't be shown by serve/list
+		vfsflags.Opt.CacheEncryptPass = "potato"
+		defer func() {
+			vfsflags.Opt.CacheEncryptPass = ""
+		}()
+
+		out, err := start.Fn(ctx, rc.Params{
+			"type": "http",
+			"fs":   localDir,
+			"addr": "localhost:0",
+			"opt": rc.Params{
+				"BaseURL": "/files",
+			},
+			"vfsOpt": rc.Params{
+				"NoModTime": true,
+			},
+		})
+		require.NoError(t, err)
+		id, err := out.GetString("id")
+		require.NoError(t, err)
+		addr, err := out.GetString("addr")
+		require.NoError(t, err)
+		assert.Regexp(t, `^http://127\.0\.0\.1:\d+/files/$`, addr)
+
+		// The server is serving the files
+		resp, err := http.Get(addr + "file.txt")
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello", string(body))
+
+		// and is in the list with its VFS options
+		servers := listServers()
+		require.Len(t, servers, 1)
+		assert.Equal(t, id, servers[0]["ID"])
+		assert.Equal(t, "http", servers[0]["Type"])
+		assert.Equal(t, addr, servers[0]["Addr"])
+		vfsOpt, ok := servers[0]["VFSOpt"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, true, vfsOpt["NoModTime"])
+		assert.NotContains(t, vfsOpt, "CacheEncryptPass")
+		out, err = list.Fn(ctx, nil)
+		require.NoError(t, err)
+		listJSON, err := json.Marshal(out)
+		require.NoError(t, err)
+		assert.NotContains(t, string(listJSON), "potato")
+
+		// Stopping it stops the server
+		_, err = stop.Fn(ctx, rc.Params{"id": id})
+		require.NoError(t, err)
+		_, err = http.Get(addr + "file.txt")
+		assert.Error(t, err)
+		assert.Len(t, listServers(), 0)
+
+		_, err = stop.Fn(ctx, rc.Params{"id": id})
+		assert.Error(t, err)
+	})
+}
@@ -226,7 +226,7 @@ func (c *conn) handleChannel(newChannel ssh.NewChannel) {
 	// Wait for either subsystem "sftp" or "exec" request
 	if <-isSFTP {
 		fs.Debugf(c.what, "Starting SFTP server")
-		server := sftp.NewRequestServer(channel, c.handlers)
+		server := sftp.NewRequestServer(newStatvfsChannel(channel, vfsHandler{VFS: c.vfs}), c.handlers)
 		defer func() {
 			err := server.Close()
 			if err != nil && err != io.EOF {
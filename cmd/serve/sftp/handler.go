@@ -5,6 +5,8 @@ package sftp
 import (
 	"io"
 	"os"
+	"path"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +20,16 @@ type vfsHandler struct {
 	*vfs.VFS
 }
 
+// Only advertise the extensions which are implemented. The request
+// server turns posix-rename@openssh.com into a Rename and
+// statvfs@openssh.com is added and answered by statvfsChannel.
+func init() {
+	err := sftp.SetSFTPExtensions("posix-rename@openssh.com")
+	if err != nil {
+		panic(err)
+	}
+}
+
 // vfsHandler returns a Handlers object with the test handlers.
 func newVFSHandler(vfs *vfs.VFS) sftp.Handlers {
 	v := vfsHandler{VFS: vfs}
@@ -73,19 +85,78 @@ func (v vfsHandler) Filecmd(r *sftp.Request) error {
 			return err
 		}
 	case "Symlink":
-		// FIXME
-		// _, err := v.fetch(r.Filepath)
-		// if err != nil {
-		// 	return err
-		// }
-		// link := newMemFile(r.Target, false)
-		// link.symlink = r.Filepath
-		// v.files[r.Target] = link
-		return sftp.ErrSshFxOpUnsupported
+		// r.Filepath is the target and r.Target is the new link
+		err := v.Symlink(linkTarget(r.Filepath, r.Target), r.Target)
+		if err != nil {
+			return err
+		}
+	case "Link":
+		return sftp.ErrSSHFxOpUnsupported
 	}
 	return nil
 }
 
+// linkTarget returns the target to store in the symlink at link.
+//
+// pkg/sftp cleans the target into an absolute path from the root so
+// a relative target such as "file1" arrives as "/file1". The original
+// target is lost so make the link relative to its own directory so
+// it points to the same file whichever directory the tree is served
+// or mounted at.
+func linkTarget(target, link string) string {
+	split := func(p string) []string {
+		p = strings.Trim(p, "/")
+		if p == "" || p == "." {
+			return nil
+		}
+		return strings.Split(p, "/")
+	}
+	from, to := split(path.Dir(link)), split(target)
+	i := 0
+	for i < len(from) && i < len(to) && from[i] == to[i] {
+		i++
+	}
+	var parts []string
+	for range from[i:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, to[i:]...)
+	if len(parts) == 0 {
+		return "."
+	}
+	return strings.Join(parts, "/")
+}
+
+// StatVFS implements the statvfs@openssh.com extension using the
+// About of the backend.
+//
+// The sftp request server doesn't pass this to the handlers so it is
+// called by statvfsChannel instead.
+func (v vfsHandler) StatVFS() (*sftp.StatVFS, error) {
+	if v.Fs().Features().About == nil {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+	const blockSize = 4096
+	total, _, free := v.Statfs()
+	if total < 0 {
+		total = 0
+	}
+	if free < 0 {
+		free = 0
+	}
+	return &sftp.StatVFS{
+		Bsize:   blockSize,
+		Frsize:  blockSize,
+		Blocks:  uint64(total) / blockSize,
+		Bfree:   uint64(free) / blockSize,
+		Bavail:  uint64(free) / blockSize,
+		Files:   1e9,
+		Ffree:   1e9,
+		Favail:  1e9,
+		Namemax: 255,
+	}, nil
+}
+
 type listerat []os.FileInfo
 
 // Modeled after strings.Reader's ReadAt() implementation
@@ -101,6 +172,19 @@ func (f listerat) ListAt(ls []os.FileInfo, offset int64) (int, error) {
 	return n, nil
 }
 
+// linkInfo is returned from Readlink - the sftp library only uses
+// the Name which is the target of the link
+type linkInfo struct {
+	target string
+}
+
+func (l linkInfo) Name() string       { return l.target }
+func (l linkInfo) Size() int64        { return int64(len(l.target)) }
+func (l linkInfo) Mode() os.FileMode  { return os.ModeSymlink | 0777 }
+func (l linkInfo) ModTime() time.Time { return time.Time{} }
+func (l linkInfo) IsDir() bool        { return false }
+func (l linkInfo) Sys() interface{}   { return nil }
+
 func (v vfsHandler) Filelist(r *sftp.Request) (l sftp.ListerAt, err error) {
 	var node vfs.Node
 	var handle vfs.Handle
@@ -130,14 +214,11 @@ func (v vfsHandler) Filelist(r *sftp.Request) (l sftp.ListerAt, err error) {
 		}
 		return listerat([]os.FileInfo{node}), nil
 	case "Readlink":
-		// FIXME
-		// if file.symlink != "" {
-		// 	file, err = v.fetch(file.symlink)
-		// 	if err != nil {
-		// 		return nil, err
-		// 	}
-		// }
-		// return listerat([]os.FileInfo{file}), nil
+		target, err := v.Readlink(r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		return listerat([]os.FileInfo{linkInfo{target}}), nil
 	}
-	return nil, sftp.ErrSshFxOpUnsupported
+	return nil, sftp.ErrSSHFxOpUnsupported
 }
@@ -0,0 +1,25 @@
+// +build !plan9
+
+package sftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkTarget(t *testing.T) {
+	for _, test := range []struct {
+		target, link, want string
+	}{
+		{"/file1", "/link", "file1"},
+		{"/dir/file1", "/dir/link", "file1"},
+		{"/file1", "/dir/link", "../file1"},
+		{"/dir/sub/file1", "/dir/link", "sub/file1"},
+		{"/other/file1", "/dir/sub/link", "../../other/file1"},
+		{"/dir", "/dir/link", "."},
+		{"/", "/dir/link", ".."},
+	} {
+		assert.Equal(t, test.want, linkTarget(test.target, test.link), test)
+	}
+}
@@ -19,12 +19,11 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd/serve/proxy"
-	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/lib/env"
 	"github.com/rclone/rclone/vfs"
-	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -39,16 +38,16 @@ type server struct {
 	proxy    *proxy.Proxy
 }
 
-func newServer(f fs.Fs, opt *Options) *server {
+func newServer(f fs.Fs, opt *Options, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options) *server {
 	s := &server{
 		f:        f,
 		opt:      *opt,
 		waitChan: make(chan struct{}),
 	}
-	if proxyflags.Opt.AuthProxy != "" {
-		s.proxy = proxy.New(&proxyflags.Opt)
+	if proxyOpt.AuthProxy != "" {
+		s.proxy = proxy.New(proxyOpt)
 	} else {
-		s.vfs = vfs.New(f, &vfsflags.Opt)
+		s.vfs = vfs.New(f, vfsOpt)
 	}
 	return s
 }
@@ -108,6 +107,18 @@ func (s *server) acceptConnections() {
 			_ = nConn.Close()
 			continue
 		}
+		if s.proxy != nil {
+			disconnect, err := proxy.ConnectUser(sshConn.User())
+			if err != nil {
+				fs.Infof(what, "Closing connection: %v", err)
+				_ = sshConn.Close()
+				continue
+			}
+			go func() {
+				_ = sshConn.Wait()
+				disconnect()
+			}()
+		}
 		c.handlers = newVFSHandler(c.vfs)
 
 		// Accept all channels
@@ -120,12 +131,12 @@ func (s *server) serve() (err error) {
 	var authorizedKeysMap map[string]struct{}
 
 	// ensure the user isn't trying to use conflicting flags
-	if proxyflags.Opt.AuthProxy != "" && s.opt.AuthorizedKeys != "" && s.opt.AuthorizedKeys != DefaultOpt.AuthorizedKeys {
+	if s.proxy != nil && s.opt.AuthorizedKeys != "" && s.opt.AuthorizedKeys != DefaultOpt.AuthorizedKeys {
 		return errors.New("--auth-proxy and --authorized-keys cannot be used at the same time")
 	}
 
 	// Load the authorized keys
-	if s.opt.AuthorizedKeys != "" && proxyflags.Opt.AuthProxy == "" {
+	if s.opt.AuthorizedKeys != "" && s.proxy == nil {
 		authKeysFile := env.ShellExpand(s.opt.AuthorizedKeys)
 		authorizedKeysMap, err = loadAuthorizedKeys(authKeysFile)
 		// If user set the flag away from the default then report an error
@@ -281,6 +292,20 @@ func (s *server) Close() {
 	close(s.waitChan)
 }
 
+// Shutdown stops the server and its VFS
+func (s *server) Shutdown() error {
+	s.Close()
+	s.shutdownVFS()
+	return nil
+}
+
+// shutdownVFS stops the VFS if it isn't from the proxy
+func (s *server) shutdownVFS() {
+	if s.vfs != nil {
+		s.vfs.Shutdown()
+	}
+}
+
 func loadPrivateKey(keyPath string) (ssh.Signer, error) {
 	privateBytes, err := ioutil.ReadFile(keyPath)
 	if err != nil {
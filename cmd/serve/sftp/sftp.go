@@ -8,10 +8,12 @@ import (
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -51,6 +53,7 @@ func init() {
 	vfsflags.AddFlags(Command.Flags())
 	proxyflags.AddFlags(Command.Flags())
 	AddFlags(Command.Flags(), &Opt)
+	servelib.AddRc("sftp", startRc)
 }
 
 // Command definition for cobra
@@ -79,6 +82,11 @@ that it can provide md5sum/sha1sum/df information for the rclone sftp
 backend.  This means that is can support SHA1SUMs, MD5SUMs and the
 about command when paired with the rclone sftp backend.
 
+It also supports the statvfs@openssh.com extension, backed by the
+about information of the remote, so "df" works in the OpenSSH sftp
+client, and posix-rename@openssh.com which renames over an existing
+file.
+
 If you don't supply a --key then rclone will generate one and cache it
 for later use.
 
@@ -98,7 +106,7 @@ sftp backend, but it may not be with other SFTP clients.
 			cmd.CheckArgs(0, 0, command, args)
 		}
 		cmd.Run(false, true, command, func() error {
-			s := newServer(f, &Opt)
+			s := newServer(f, &Opt, &vfsflags.Opt, &proxyflags.Opt)
 			err := s.Serve()
 			if err != nil {
 				return err
@@ -108,3 +116,20 @@ sftp backend, but it may not be with other SFTP clients.
 		})
 	},
 }
+
+// startRc starts a server for serve/start
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	opt := DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = addr
+	s := newServer(f, &opt, vfsOpt, proxyOpt)
+	err = s.Serve()
+	if err != nil {
+		s.shutdownVFS()
+		return nil, err
+	}
+	return s, nil
+}
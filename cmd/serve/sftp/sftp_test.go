@@ -8,16 +8,23 @@
 package sftp
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/pkg/sftp"
 	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/cmd/serve/servetest"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 const (
@@ -44,7 +51,7 @@ func TestSftp(t *testing.T) {
 		opt.User = testUser
 		opt.Pass = testPass
 
-		w := newServer(f, &opt)
+		w := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
 		require.NoError(t, w.serve())
 
 		// Read the host and port we started on
@@ -69,3 +76,68 @@ func TestSftp(t *testing.T) {
 
 	servetest.Run(t, "sftp", start)
 }
+
+// TestSftpExtensions checks the statvfs@openssh.com and
+// posix-rename@openssh.com extensions
+func TestSftpExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-sftp")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.ListenAddr = testBindAddress
+	opt.User = testUser
+	opt.Pass = testPass
+	w := newServer(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, w.serve())
+	defer func() {
+		w.Close()
+		w.Wait()
+	}()
+
+	conn, err := ssh.Dial("tcp", w.Addr(), &ssh.ClientConfig{
+		User:            testUser,
+		Auth:            []ssh.AuthMethod{ssh.Password(testPass)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+	c, err := sftp.NewClient(conn)
+	require.NoError(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+
+	// StatVFS should return the About of the local disk
+	usage, err := f.Features().About(context.Background())
+	require.NoError(t, err)
+	stat, err := c.StatVFS("/")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4096), stat.Bsize)
+	assert.Equal(t, uint64(*usage.Total)/4096, stat.Blocks)
+	assert.NotZero(t, stat.Bfree)
+
+	// PosixRename should replace an existing file
+	for _, name := range []string{"a", "b"} {
+		out, err := c.Create(name)
+		require.NoError(t, err)
+		_, err = out.Write([]byte(name))
+		require.NoError(t, err)
+		require.NoError(t, out.Close())
+	}
+	require.NoError(t, c.PosixRename("a", "b"))
+	_, err = c.Stat("a")
+	assert.True(t, os.IsNotExist(err))
+	in, err := c.Open("b")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, "a", string(data))
+}
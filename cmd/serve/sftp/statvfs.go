@@ -0,0 +1,170 @@
+// +build !plan9
+
+package sftp
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// sftp packet types used by statvfsChannel
+const (
+	sshFxpVersion  = 2
+	sshFxpStatus   = 101
+	sshFxpExtended = 200
+
+	sshFxOpUnsupported = 8
+
+	statvfsExtension = "statvfs@openssh.com"
+
+	// maxPacketSize is the largest packet statvfsChannel will read
+	// which is the same as the sftp library
+	maxPacketSize = 256 * 1024
+)
+
+// statvfsChannel wraps the channel the sftp request server reads and
+// writes to advertise and answer statvfs@openssh.com requests.
+//
+// The sftp request server doesn't know about this extension so it is
+// added to the version packet it sends and the requests are taken out
+// of the stream of packets before it sees them and the reply is
+// written directly.
+type statvfsChannel struct {
+	io.ReadWriteCloser
+	handler vfsHandler
+	mu      sync.Mutex // held while writing a packet
+	pending []byte     // packet read but not yet returned from Read
+}
+
+// newStatvfsChannel makes a new statvfsChannel serving the VFS in
+// handler
+func newStatvfsChannel(channel io.ReadWriteCloser, handler vfsHandler) *statvfsChannel {
+	return &statvfsChannel{
+		ReadWriteCloser: channel,
+		handler:         handler,
+	}
+}
+
+// Read returns the packets which aren't statvfs requests
+func (c *statvfsChannel) Read(p []byte) (n int, err error) {
+	for len(c.pending) == 0 {
+		packet, err := c.readPacket()
+		if err != nil {
+			return 0, err
+		}
+		id, ok := parseStatvfs(packet)
+		if !ok {
+			c.pending = packet
+			break
+		}
+		err = c.replyStatvfs(id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// readPacket reads a whole packet including its length
+func (c *statvfsChannel) readPacket() ([]byte, error) {
+	var length [4]byte
+	_, err := io.ReadFull(c.ReadWriteCloser, length[:])
+	if err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size == 0 || size > maxPacketSize {
+		return nil, errors.Errorf("bad sftp packet length %d", size)
+	}
+	packet := make([]byte, 4+size)
+	copy(packet, length[:])
+	_, err = io.ReadFull(c.ReadWriteCloser, packet[4:])
+	if err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+// parseStatvfs returns the request ID of packet if it is a statvfs
+// request
+func parseStatvfs(packet []byte) (id uint32, ok bool) {
+	// length, type, id, extension name length
+	if len(packet) < 4+1+4+4 || packet[4] != sshFxpExtended {
+		return 0, false
+	}
+	id = binary.BigEndian.Uint32(packet[5:])
+	nameLength := binary.BigEndian.Uint32(packet[9:])
+	name := packet[13:]
+	if uint32(len(name)) < nameLength || string(name[:nameLength]) != statvfsExtension {
+		return 0, false
+	}
+	return id, true
+}
+
+// replyStatvfs writes the reply to statvfs request id
+func (c *statvfsChannel) replyStatvfs(id uint32) error {
+	var body []byte
+	stat, err := c.handler.StatVFS()
+	if err == nil {
+		stat.ID = id
+		body, err = stat.MarshalBinary()
+		if err != nil {
+			return err
+		}
+	} else {
+		// the only error StatVFS returns is unsupported
+		body = statusPacket(id, sshFxOpUnsupported, err.Error())
+	}
+	packet := make([]byte, 4, 4+len(body))
+	binary.BigEndian.PutUint32(packet, uint32(len(body)))
+	packet = append(packet, body...)
+	_, err = c.Write(packet)
+	return err
+}
+
+// putString appends s to b as an sftp string
+func putString(b []byte, s string) []byte {
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(s)))
+	return append(b, s...)
+}
+
+// statusPacket makes the body of a status packet
+func statusPacket(id uint32, code uint32, message string) []byte {
+	b := make([]byte, 9, 9+4+len(message)+4)
+	b[0] = sshFxpStatus
+	binary.BigEndian.PutUint32(b[1:], id)
+	binary.BigEndian.PutUint32(b[5:], code)
+	b = putString(b, message)
+	b = putString(b, "")
+	return b
+}
+
+// Write writes a packet making sure it doesn't get mixed up with a
+// statvfs reply and adds the extension to the version packet.
+//
+// The sftp library writes each packet with a single Write.
+func (c *statvfsChannel) Write(p []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(p) < 5 || p[4] != sshFxpVersion {
+		return c.ReadWriteCloser.Write(p)
+	}
+	packet := append([]byte(nil), p...)
+	packet = putString(packet, statvfsExtension)
+	packet = putString(packet, "2")
+	binary.BigEndian.PutUint32(packet, uint32(len(packet)-4))
+	_, err = c.ReadWriteCloser.Write(packet)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// check interface
+var _ io.ReadWriteCloser = (*statvfsChannel)(nil)
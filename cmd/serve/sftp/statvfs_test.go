@@ -0,0 +1,95 @@
+// +build !plan9
+
+package sftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannel reads from Reader and writes to Buffer
+type fakeChannel struct {
+	io.Reader
+	bytes.Buffer
+}
+
+func (f *fakeChannel) Read(p []byte) (int, error) { return f.Reader.Read(p) }
+func (f *fakeChannel) Close() error               { return nil }
+
+// makePacket makes a packet with its length from body
+func makePacket(body []byte) []byte {
+	packet := make([]byte, 4, 4+len(body))
+	binary.BigEndian.PutUint32(packet, uint32(len(body)))
+	return append(packet, body...)
+}
+
+func TestStatvfsChannel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-serve-sftp")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	VFS := vfs.New(f, nil)
+	defer VFS.Shutdown()
+
+	statvfs := putString([]byte{sshFxpExtended, 0, 0, 0, 7}, statvfsExtension)
+	statvfs = putString(statvfs, "/")
+	other := makePacket(putString([]byte{sshFxpExtended, 0, 0, 0, 8}, "hardlink@openssh.com"))
+	ch := &fakeChannel{Reader: bytes.NewReader(append(makePacket(statvfs), other...))}
+	c := newStatvfsChannel(ch, vfsHandler{VFS: VFS})
+
+	// The version packet gets the extension added
+	version := []byte{sshFxpVersion, 0, 0, 0, 3}
+	n, err := c.Write(makePacket(version))
+	require.NoError(t, err)
+	assert.Equal(t, 9, n)
+	want := putString(putString(version, statvfsExtension), "2")
+	assert.Equal(t, makePacket(want), ch.Next(ch.Len()))
+
+	// Other packets are passed through
+	n, err = c.Write(makePacket([]byte{102}))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, makePacket([]byte{102}), ch.Next(ch.Len()))
+
+	// The statvfs request is answered and only the other packet read
+	got, err := ioutil.ReadAll(c)
+	require.NoError(t, err)
+	assert.Equal(t, other, got)
+	reply := ch.Bytes()
+	require.Equal(t, 4+1+4+11*8, len(reply))
+	assert.Equal(t, byte(201), reply[4])
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(reply[5:]))
+	assert.Equal(t, uint64(4096), binary.BigEndian.Uint64(reply[9:]))
+}
+
+func TestParseStatvfs(t *testing.T) {
+	for _, test := range []struct {
+		body []byte
+		ok   bool
+	}{
+		{putString([]byte{sshFxpExtended, 0, 0, 0, 1}, statvfsExtension), true},
+		{putString([]byte{sshFxpExtended, 0, 0, 0, 1}, "hardlink@openssh.com"), false},
+		{putString([]byte{sshFxpStatus, 0, 0, 0, 1}, statvfsExtension), false},
+		{[]byte{sshFxpExtended, 0, 0, 0, 1, 0, 0, 1, 0}, false},
+		{[]byte{sshFxpExtended}, false},
+	} {
+		id, ok := parseStatvfs(makePacket(test.body))
+		assert.Equal(t, test.ok, ok, test.body)
+		if ok {
+			assert.Equal(t, uint32(1), id)
+		}
+	}
+}
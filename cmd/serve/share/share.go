@@ -0,0 +1,263 @@
+// Package share implements signed, expiring links to files and
+// directories served by rclone's HTTP servers
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/lib/env"
+	"github.com/rclone/rclone/lib/rest"
+)
+
+// Help contains text describing share links to add to the command
+// help.
+var Help = `
+#### Share links
+
+Share links give access to a single file or directory without a user
+name or password. They are made with the "rclone share" command or
+the "share/create" rc call and can be read only or upload only, have
+an expiry time and optionally a limit on the number of downloads.
+Upload share links can only add new files - uploading a file with
+the name of an existing one is refused.
+
+To accept share links set --share-secret to a secret string which is
+used to sign them. The same secret must be used when making the
+links. The shares are kept in the file set by --share-store (by
+default shares.json next to the config file) which must also be the
+same. Removing a share from the store with "rclone share --revoke"
+stops its link working straight away.
+
+Share links are only accepted when authentication is configured with
+--htpasswd or --user and --pass. They can't be used with --auth-proxy.
+`
+
+// Mode is what a share link allows
+type Mode string
+
+// Share modes
+const (
+	ModeRead   Mode = "read"   // download and list only
+	ModeUpload Mode = "upload" // upload only
+)
+
+// Set a Mode from a string
+func (m *Mode) Set(s string) error {
+	switch mode := Mode(s); mode {
+	case ModeRead, ModeUpload:
+		*m = mode
+		return nil
+	}
+	return errors.Errorf("unknown share mode %q - use %q or %q", s, ModeRead, ModeUpload)
+}
+
+// methods are the HTTP methods each mode allows
+var methods = map[Mode][]string{
+	ModeRead:   {"GET", "HEAD", "PROPFIND"},
+	ModeUpload: {"POST", "PUT", "MKCOL"},
+}
+
+// Query parameters used in share links
+const (
+	paramID      = "share"
+	paramExpires = "expires"
+	paramMode    = "mode"
+	paramSig     = "sig"
+)
+
+// Options for share links
+type Options struct {
+	Secret string // secret to sign links with - shares are disabled if empty
+	Store  string // file to keep the shares in - blank for the default
+}
+
+// storePath returns the file the shares are kept in
+func (opt *Options) storePath() string {
+	if opt.Store == "" {
+		// rclone sets this when it loads the config
+		return filepath.Join(os.Getenv("RCLONE_CONFIG_DIR"), "shares.json")
+	}
+	return env.ShellExpand(opt.Store)
+}
+
+// Share describes a single share link
+type Share struct {
+	ID           string    `json:"id"`
+	Path         string    `json:"path"`                    // path shared relative to the server root
+	Dir          bool      `json:"dir"`                     // set if Path is a directory
+	Mode         Mode      `json:"mode"`                    // what the share allows
+	Created      time.Time `json:"created"`                 // when the share was made
+	Expires      time.Time `json:"expires"`                 // when the share stops working
+	MaxDownloads int       `json:"max_downloads,omitempty"` // 0 for unlimited
+	Downloads    int       `json:"downloads"`               // downloads so far
+}
+
+// Shares makes and checks share links
+type Shares struct {
+	opt   Options
+	store *Store
+}
+
+// New makes a Shares from the options
+func New(opt *Options) *Shares {
+	return &Shares{
+		opt:   *opt,
+		store: NewStore(opt.storePath()),
+	}
+}
+
+// Store returns the store the shares are kept in
+func (s *Shares) Store() *Store {
+	return s.store
+}
+
+// newID makes a random share ID
+func newID() (string, error) {
+	var id [12]byte
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to make share ID")
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// Create makes a new share of remote (a directory if it ends in /)
+// which lasts for expire and saves it in the store.
+func (s *Shares) Create(remote string, expire time.Duration, mode Mode, maxDownloads int) (*Share, error) {
+	if s.opt.Secret == "" {
+		return nil, errors.New("need a secret to make share links")
+	}
+	if expire <= 0 {
+		return nil, errors.New("share expiry must be positive")
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	sh := &Share{
+		ID:           id,
+		Path:         strings.Trim(path.Clean("/"+remote), "/"),
+		Dir:          remote == "" || strings.HasSuffix(remote, "/"),
+		Mode:         mode,
+		Created:      now,
+		Expires:      now.Add(expire),
+		MaxDownloads: maxDownloads,
+	}
+	if sh.Path == "" {
+		sh.Dir = true
+	}
+	if sh.Mode == ModeUpload && !sh.Dir {
+		return nil, errors.New("upload shares must be of a directory - end the path with /")
+	}
+	err = s.store.Add(sh)
+	if err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// sign returns the signature for sh
+func (s *Shares) sign(sh *Share) string {
+	mac := hmac.New(sha256.New, []byte(s.opt.Secret))
+	_, _ = fmt.Fprintf(mac, "%s\n%s\n%v\n%d\n%s\n%d", sh.ID, sh.Path, sh.Dir, sh.Expires.Unix(), sh.Mode, sh.MaxDownloads)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns the link for sh on the server at baseURL
+func (s *Shares) URL(sh *Share, baseURL string) string {
+	u := strings.TrimSuffix(baseURL, "/") + "/" + rest.URLPathEscape(sh.Path)
+	if sh.Dir && sh.Path != "" {
+		u += "/"
+	}
+	values := url.Values{}
+	values.Set(paramID, sh.ID)
+	values.Set(paramExpires, strconv.FormatInt(sh.Expires.Unix(), 10))
+	values.Set(paramMode, string(sh.Mode))
+	values.Set(paramSig, s.sign(sh))
+	return u + "?" + values.Encode()
+}
+
+// IsShare returns true if r is using a share link
+func IsShare(r *http.Request) bool {
+	return r.URL.Query().Get(paramID) != ""
+}
+
+// Query returns the share link parameters from query so they can be
+// added to links to other URLs in the share
+func Query(query url.Values) url.Values {
+	out := url.Values{}
+	for _, param := range []string{paramID, paramExpires, paramMode, paramSig} {
+		if value := query.Get(param); value != "" {
+			out.Set(param, value)
+		}
+	}
+	return out
+}
+
+// contains returns true if urlPath is within the share
+func (sh *Share) contains(urlPath string) bool {
+	if sh.Path == "" {
+		return true
+	}
+	p := strings.Trim(path.Clean("/"+urlPath), "/")
+	if p == sh.Path {
+		return true
+	}
+	return sh.Dir && strings.HasPrefix(p, sh.Path+"/")
+}
+
+// Check checks that the share link used by r allows access to urlPath
+// and returns the Share if so.
+//
+// Downloads are counted here.
+func (s *Shares) Check(r *http.Request, urlPath string) (*Share, error) {
+	query := r.URL.Query()
+	sh, err := s.store.Get(query.Get(paramID))
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(query.Get(paramSig)), []byte(s.sign(sh))) {
+		return nil, errors.New("bad signature")
+	}
+	if time.Now().After(sh.Expires) {
+		return nil, errors.New("share has expired")
+	}
+	if !sh.contains(urlPath) {
+		return nil, errors.New("path not in share")
+	}
+	allowed := false
+	for _, method := range methods[sh.Mode] {
+		if r.Method == method {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, errors.Errorf("%s not allowed by %s share", r.Method, sh.Mode)
+	}
+	// Count whole downloads of files and archives
+	isDownload := r.Method == "GET" && r.Header.Get("Range") == "" &&
+		(!strings.HasSuffix(urlPath, "/") || query.Get("download") != "")
+	if isDownload {
+		err = s.store.Download(sh.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sh, nil
+}
@@ -0,0 +1,181 @@
+package share
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShares(t *testing.T) (*Shares, func()) {
+	dir, err := ioutil.TempDir("", "rclone-share")
+	require.NoError(t, err)
+	s := New(&Options{
+		Secret: "potato",
+		Store:  filepath.Join(dir, "shares.json"),
+	})
+	return s, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// request makes a request for the share link with the path replaced
+func request(t *testing.T, method, link, urlPath string) *http.Request {
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	if urlPath != "" {
+		u.Path = urlPath
+	}
+	return httptest.NewRequest(method, u.String(), nil)
+}
+
+func TestShareCheck(t *testing.T) {
+	s, cleanup := newTestShares(t)
+	defer cleanup()
+
+	sh, err := s.Create("dir/", time.Hour, ModeRead, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "dir", sh.Path)
+	assert.True(t, sh.Dir)
+	link := s.URL(sh, "http://localhost:8080/")
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/dir/", u.Path)
+
+	check := func(method, urlPath string) error {
+		_, err := s.Check(request(t, method, link, urlPath), urlPath)
+		return err
+	}
+
+	// Listings and files in the directory are allowed
+	assert.NoError(t, check("GET", "/dir/"))
+	assert.NoError(t, check("HEAD", "/dir/file.txt"))
+	assert.NoError(t, check("PROPFIND", "/dir/sub/"))
+
+	// Things outside it aren't
+	assert.Error(t, check("GET", "/"))
+	assert.Error(t, check("GET", "/dirother/file.txt"))
+	assert.Error(t, check("GET", "/dir/../secret.txt"))
+
+	// Nor is changing things
+	assert.Error(t, check("PUT", "/dir/file.txt"))
+	assert.Error(t, check("DELETE", "/dir/file.txt"))
+
+	// Downloads are limited
+	assert.NoError(t, check("GET", "/dir/file.txt"))
+	assert.NoError(t, check("GET", "/dir/file.txt"))
+	assert.Error(t, check("GET", "/dir/file.txt"))
+	assert.NoError(t, check("HEAD", "/dir/file.txt"))
+
+	// A tampered link is refused
+	tampered := request(t, "GET", link, "/dir/")
+	q := tampered.URL.Query()
+	q.Set(paramSig, "AAAA")
+	tampered.URL.RawQuery = q.Encode()
+	_, err = s.Check(tampered, "/dir/")
+	assert.EqualError(t, err, "bad signature")
+
+	// A link signed with another secret is refused
+	other := New(&Options{Secret: "sausage", Store: s.store.path})
+	_, err = other.Check(request(t, "GET", link, "/dir/"), "/dir/")
+	assert.EqualError(t, err, "bad signature")
+
+	// Revoked links are refused
+	require.NoError(t, s.Store().Revoke(sh.ID))
+	assert.Equal(t, ErrNotFound, check("GET", "/dir/"))
+	assert.Equal(t, ErrNotFound, s.Store().Revoke(sh.ID))
+}
+
+func TestShareUpload(t *testing.T) {
+	s, cleanup := newTestShares(t)
+	defer cleanup()
+
+	_, err := s.Create("file.txt", time.Hour, ModeUpload, 0)
+	assert.Error(t, err)
+
+	sh, err := s.Create("incoming/", time.Hour, ModeUpload, 0)
+	require.NoError(t, err)
+	link := s.URL(sh, "http://localhost:8080")
+
+	_, err = s.Check(request(t, "POST", link, ""), "/incoming/")
+	assert.NoError(t, err)
+	_, err = s.Check(request(t, "PUT", link, "/incoming/file.txt"), "/incoming/file.txt")
+	assert.NoError(t, err)
+	_, err = s.Check(request(t, "GET", link, ""), "/incoming/")
+	assert.Error(t, err)
+}
+
+func TestShareExpired(t *testing.T) {
+	s, cleanup := newTestShares(t)
+	defer cleanup()
+
+	sh, err := s.Create("file.txt", time.Hour, ModeRead, 0)
+	require.NoError(t, err)
+	link := s.URL(sh, "http://localhost:8080/")
+
+	// Expire the share in the store
+	stored := s.store.shares[sh.ID]
+	stored.Expires = time.Now().Add(-time.Minute)
+	sh.Expires = stored.Expires
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	q := u.Query()
+	q.Set(paramSig, s.sign(sh))
+	u.RawQuery = q.Encode()
+	_, err = s.Check(httptest.NewRequest("GET", u.String(), nil), "/file.txt")
+	assert.EqualError(t, err, "share has expired")
+}
+
+func TestStore(t *testing.T) {
+	s, cleanup := newTestShares(t)
+	defer cleanup()
+
+	sh1, err := s.Create("", time.Hour, ModeRead, 0)
+	require.NoError(t, err)
+	assert.True(t, sh1.Dir)
+	_, err = s.Create("file.txt", time.Hour, ModeRead, 0)
+	require.NoError(t, err)
+
+	// A second store on the same file sees the shares
+	other := NewStore(s.store.path)
+	list, err := other.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	// and revoking from it is seen by the first
+	require.NoError(t, other.Revoke(sh1.ID))
+	_, err = s.Store().Get(sh1.ID)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestStoreConcurrent(t *testing.T) {
+	s, cleanup := newTestShares(t)
+	defer cleanup()
+
+	sh, err := s.Create("file.txt", time.Hour, ModeRead, 100)
+	require.NoError(t, err)
+
+	// Separate stores as used by rc calls mustn't lose downloads
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, NewStore(s.store.path).Download(sh.ID))
+		}()
+	}
+	wg.Wait()
+
+	got, err := NewStore(s.store.path).Get(sh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, n, got.Downloads)
+}
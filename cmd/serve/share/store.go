@@ -0,0 +1,232 @@
+package share
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/file"
+)
+
+// ErrNotFound is returned for shares which don't exist or have been
+// revoked
+var ErrNotFound = errors.New("share not found")
+
+// Store keeps the shares in a JSON file.
+//
+// The file is read again whenever it changes so that shares made or
+// revoked by other rclone processes are seen. Changes are made with
+// a lock file held so they don't overwrite each other.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time         // modification time of the file when read
+	size    int64             // size of the file when read
+	shares  map[string]*Share // shares by ID, nil if not read yet
+}
+
+// NewStore makes a Store keeping the shares in the file at path
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// _load reads the shares from the file if it has changed
+//
+// call with the lock held
+func (st *Store) _load() error {
+	fi, err := os.Stat(st.path)
+	if os.IsNotExist(err) {
+		st.shares = make(map[string]*Share)
+		st.modTime, st.size = time.Time{}, 0
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read share store")
+	}
+	if st.shares != nil && fi.ModTime().Equal(st.modTime) && fi.Size() == st.size {
+		return nil
+	}
+	data, err := ioutil.ReadFile(st.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read share store")
+	}
+	shares := make(map[string]*Share)
+	err = json.Unmarshal(data, &shares)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse share store %q", st.path)
+	}
+	st.shares = shares
+	st.modTime, st.size = fi.ModTime(), fi.Size()
+	return nil
+}
+
+// _save writes the shares to the file, dropping expired ones
+//
+// call with the lock held
+func (st *Store) _save() error {
+	now := time.Now()
+	for id, sh := range st.shares {
+		if now.After(sh.Expires) {
+			delete(st.shares, id)
+		}
+	}
+	data, err := json.MarshalIndent(st.shares, "", "\t")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(st.path)
+	// Write a temporary file and rename it so readers never see a
+	// partial file
+	tmp, err := ioutil.TempFile(dir, filepath.Base(st.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to write share store")
+	}
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), st.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "failed to write share store")
+	}
+	fi, err := os.Stat(st.path)
+	if err == nil {
+		st.modTime, st.size = fi.ModTime(), fi.Size()
+	}
+	return nil
+}
+
+// update reads the store, calls fn to change the shares and writes
+// the store if fn succeeded.
+//
+// The lock file is held throughout so that changes made by other
+// Stores, in this process or another one, aren't lost.
+func (st *Store) update(fn func() error) (err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	err = os.MkdirAll(filepath.Dir(st.path), 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make share store directory")
+	}
+	unlock, err := file.Lock(st.path + ".lock")
+	if err != nil {
+		return errors.Wrap(err, "failed to lock share store")
+	}
+	defer func() {
+		unlockErr := unlock()
+		if unlockErr != nil {
+			fs.Errorf(nil, "Failed to unlock share store: %v", unlockErr)
+		}
+	}()
+	// Always read the file as a change might not alter its size or
+	// modification time
+	st.shares = nil
+	err = st._load()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if err == nil {
+		err = st._save()
+	}
+	if err != nil {
+		// Read the file again next time as fn may have changed shares
+		st.shares = nil
+	}
+	return err
+}
+
+// Add adds sh to the store
+func (st *Store) Add(sh *Share) error {
+	return st.update(func() error {
+		st.shares[sh.ID] = sh
+		return nil
+	})
+}
+
+// Get returns a copy of the share with id
+func (st *Store) Get(id string) (*Share, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	err := st._load()
+	if err != nil {
+		return nil, err
+	}
+	sh, ok := st.shares[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	shCopy := *sh
+	return &shCopy, nil
+}
+
+// List returns copies of all the shares sorted by creation time
+func (st *Store) List() ([]*Share, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	err := st._load()
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*Share, 0, len(st.shares))
+	for _, sh := range st.shares {
+		shCopy := *sh
+		shares = append(shares, &shCopy)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].Created.Before(shares[j].Created)
+	})
+	return shares, nil
+}
+
+// Revoke removes the share with id from the store
+func (st *Store) Revoke(id string) error {
+	return st.update(func() error {
+		if _, ok := st.shares[id]; !ok {
+			return ErrNotFound
+		}
+		delete(st.shares, id)
+		return nil
+	})
+}
+
+// Download counts a download of the share with id returning an error
+// if it has run out of downloads
+func (st *Store) Download(id string) error {
+	st.mu.Lock()
+	err := st._load()
+	if err == nil {
+		sh, ok := st.shares[id]
+		if ok && sh.MaxDownloads <= 0 {
+			// Unlimited so no need to update the store
+			st.mu.Unlock()
+			return nil
+		}
+	}
+	st.mu.Unlock()
+	return st.update(func() error {
+		sh, ok := st.shares[id]
+		if !ok {
+			return ErrNotFound
+		}
+		if sh.MaxDownloads <= 0 {
+			return nil
+		}
+		if sh.Downloads >= sh.MaxDownloads {
+			return errors.New("share has no downloads left")
+		}
+		sh.Downloads++
+		return nil
+	})
+}
@@ -0,0 +1,545 @@
+package webdav
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs"
+	"golang.org/x/net/webdav"
+)
+
+// lockSystem is a webdav.LockSystem which keeps the WebDAV locks in a
+// file in the cache directory. This means locks survive a restart of
+// the server and are seen by other rclone processes serving the same
+// remote with the same cache directory.
+//
+// The file is changed with a lock file held so that processes
+// sharing it don't lose each other's changes.
+//
+// As well as keeping track of the WebDAV locks it takes an exclusive
+// advisory lock on the file in the VFS for each one, including those
+// made by other processes. This means WebDAV clients see locks taken
+// on a mount of the same VFS and the other way round.
+//
+// The temporary locks the webdav library takes for each write
+// without an If header are only kept in memory as they last for a
+// single request and writing the file for each would be slow.
+//
+// The semantics are the same as webdav.NewMemLS.
+type lockSystem struct {
+	vfs     *vfs.VFS
+	path    string // file the locks are kept in
+	timeout time.Duration
+	mu      sync.Mutex
+	modTime time.Time            // modification time of the file when read
+	size    int64                // size of the file when read
+	locks   map[string]*lock     // WebDAV locks by token, nil if not read yet
+	inUse   map[string]bool      // tokens confirmed and not yet released
+	held    map[string]*heldLock // VFS locks held by token
+}
+
+// lock is a WebDAV lock as stored in the file
+type lock struct {
+	Token     string        `json:"token"`
+	Root      string        `json:"root"`
+	Duration  time.Duration `json:"duration"` // negative for infinite
+	Expires   time.Time     `json:"expires"`  // zero for never
+	OwnerXML  string        `json:"owner_xml,omitempty"`
+	ZeroDepth bool          `json:"zero_depth,omitempty"`
+	implicit  bool          // set if only kept in memory
+}
+
+// heldLock is a VFS lock held for a WebDAV lock
+type heldLock struct {
+	file  *vfs.File
+	owner uint64
+}
+
+// check interface
+var _ webdav.LockSystem = (*lockSystem)(nil)
+
+// lockPath returns the file the locks for VFS are kept in
+func lockPath(VFS *vfs.VFS) string {
+	sum := md5.Sum([]byte(fs.ConfigString(VFS.Fs())))
+	return filepath.Join(config.CacheDir, "serve-webdav", "locks-"+hex.EncodeToString(sum[:])+".json")
+}
+
+// newLockSystem makes a lockSystem for VFS keeping the locks in the
+// file at path. Locks last at most timeout unless refreshed, or
+// forever if it is 0.
+//
+// Any locks on files already in the file take their VFS locks again.
+func newLockSystem(VFS *vfs.VFS, path string, timeout time.Duration) *lockSystem {
+	ls := &lockSystem{
+		vfs:     VFS,
+		path:    path,
+		timeout: timeout,
+		inUse:   make(map[string]bool),
+		held:    make(map[string]*heldLock),
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	err := ls._load()
+	if err != nil {
+		fs.Errorf(nil, "Failed to restore WebDAV locks: %v", err)
+		return ls
+	}
+	ls._expire(time.Now())
+	return ls
+}
+
+// tokenOwner returns the owner used for the VFS lock for token
+func tokenOwner(token string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(token))
+	return h.Sum64()
+}
+
+// newToken makes a random lock token
+func newToken() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to make lock token")
+	}
+	return fmt.Sprintf("opaquelocktoken:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// slashClean is equivalent to but slightly more efficient than
+// path.Clean("/" + name).
+func slashClean(name string) string {
+	if name == "" || name[0] != '/' {
+		name = "/" + name
+	}
+	return path.Clean(name)
+}
+
+// duration returns how long a lock asking for duration lasts
+func (ls *lockSystem) duration(duration time.Duration) time.Duration {
+	if ls.timeout > 0 && (duration < 0 || duration > ls.timeout) {
+		return ls.timeout
+	}
+	return duration
+}
+
+// expiry returns when a lock lasting duration taken at now expires
+func expiry(now time.Time, duration time.Duration) time.Time {
+	if duration < 0 {
+		return time.Time{}
+	}
+	return now.Add(duration)
+}
+
+// isImplicit returns true if details are for one of the temporary
+// locks the webdav library takes for a write without an If header.
+//
+// These are infinite, zero depth and have no owner which a LOCK
+// request without a Timeout header, Depth 0 and no owner also
+// matches - it is then only kept in memory too.
+func isImplicit(details webdav.LockDetails) bool {
+	return details.Duration < 0 && details.ZeroDepth && details.OwnerXML == ""
+}
+
+// covers returns true if l applies to name
+func (l *lock) covers(name string) bool {
+	if l.Root == name {
+		return true
+	}
+	if l.ZeroDepth {
+		return false
+	}
+	return l.Root == "/" || strings.HasPrefix(name, l.Root+"/")
+}
+
+// details returns the webdav.LockDetails for l
+func (l *lock) details() webdav.LockDetails {
+	return webdav.LockDetails{
+		Root:      l.Root,
+		Duration:  l.Duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}
+}
+
+// _load reads the locks from the file if it has changed
+//
+// call with the lock held
+func (ls *lockSystem) _load() error {
+	var locks []*lock
+	fi, err := os.Stat(ls.path)
+	if os.IsNotExist(err) {
+		ls.modTime, ls.size = time.Time{}, 0
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read locks")
+	} else {
+		if ls.locks != nil && fi.ModTime().Equal(ls.modTime) && fi.Size() == ls.size {
+			return nil
+		}
+		data, err := ioutil.ReadFile(ls.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read locks")
+		}
+		err = json.Unmarshal(data, &locks)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse locks %q", ls.path)
+		}
+		ls.modTime, ls.size = fi.ModTime(), fi.Size()
+	}
+	oldLocks := ls.locks
+	ls.locks = make(map[string]*lock, len(locks))
+	for _, l := range locks {
+		ls.locks[l.Token] = l
+	}
+	// Keep the locks which are only in memory
+	for token, l := range oldLocks {
+		if l.implicit {
+			ls.locks[token] = l
+		}
+	}
+	// Release the VFS locks of any locks removed by other processes
+	for token := range ls.held {
+		if ls.locks[token] == nil {
+			ls._unlockVFS(token)
+		}
+	}
+	// and take them for any they have added
+	for token, l := range ls.locks {
+		if !l.implicit && ls.held[token] == nil {
+			err = ls._lockVFS(l)
+			if err != nil {
+				fs.Debugf(l.Root, "Failed to take VFS lock: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// _lockFile takes the lock file which must be held while the locks
+// are read and changed, returning a function to release it
+//
+// call with the lock held
+func (ls *lockSystem) _lockFile() (unlock func(), err error) {
+	err = os.MkdirAll(filepath.Dir(ls.path), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make lock directory")
+	}
+	unlockFile, err := file.Lock(ls.path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		err := unlockFile()
+		if err != nil {
+			fs.Errorf(nil, "Failed to unlock WebDAV locks: %v", err)
+		}
+	}, nil
+}
+
+// _save writes the locks to the file
+//
+// call with the lock held
+func (ls *lockSystem) _save() error {
+	locks := make([]*lock, 0, len(ls.locks))
+	for _, l := range ls.locks {
+		if !l.implicit {
+			locks = append(locks, l)
+		}
+	}
+	data, err := json.MarshalIndent(locks, "", "\t")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(ls.path)
+	// Write a temporary file and rename it so readers never see a
+	// partial file
+	tmp, err := ioutil.TempFile(dir, filepath.Base(ls.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to write locks")
+	}
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), ls.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "failed to write locks")
+	}
+	fi, err := os.Stat(ls.path)
+	if err == nil {
+		ls.modTime, ls.size = fi.ModTime(), fi.Size()
+	}
+	return nil
+}
+
+// _expire removes the locks which have expired at now, returning
+// true if any which need saving were removed
+//
+// call with the lock held
+func (ls *lockSystem) _expire(now time.Time) (changed bool) {
+	for token, l := range ls.locks {
+		if !l.Expires.IsZero() && now.After(l.Expires) && !ls.inUse[token] {
+			delete(ls.locks, token)
+			ls._unlockVFS(token)
+			if !l.implicit {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// _prepare takes the lock file, reads any changes to the locks and
+// expires old ones. It returns a function to release the lock file.
+//
+// call with the lock held
+func (ls *lockSystem) _prepare(now time.Time) (unlock func(), err error) {
+	unlock, err = ls._lockFile()
+	if err != nil {
+		return nil, err
+	}
+	// Always read the file as a change might not alter its size or
+	// modification time
+	if ls.locks != nil {
+		ls.modTime = time.Time{}
+	}
+	err = ls._load()
+	if err == nil && ls._expire(now) {
+		err = ls._save()
+	}
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// _lockVFS takes the VFS lock for l if it is on a file
+//
+// Locks on directories and on names which don't exist yet are only
+// kept by WebDAV.
+//
+// call with the lock held
+func (ls *lockSystem) _lockVFS(l *lock) error {
+	node, err := ls.vfs.Stat(strings.Trim(l.Root, "/"))
+	if err != nil || !node.IsFile() {
+		return nil
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return nil
+	}
+	owner := tokenOwner(l.Token)
+	err = file.Lock(context.Background(), vfs.Lock{
+		Owner: owner,
+		Start: 0,
+		End:   vfs.LockEOF,
+		Type:  vfs.LockExclusive,
+	}, false)
+	if err != nil {
+		return err
+	}
+	ls.held[l.Token] = &heldLock{
+		file:  file,
+		owner: owner,
+	}
+	return nil
+}
+
+// _unlockVFS releases the VFS lock for token if there is one
+//
+// call with the lock held
+func (ls *lockSystem) _unlockVFS(token string) {
+	if hl := ls.held[token]; hl != nil {
+		hl.file.UnlockAll(hl.owner)
+		delete(ls.held, token)
+	}
+}
+
+// _canCreate returns true if a lock on name with zeroDepth doesn't
+// conflict with the existing locks
+//
+// call with the lock held
+func (ls *lockSystem) _canCreate(name string, zeroDepth bool) bool {
+	for _, l := range ls.locks {
+		if l.covers(name) {
+			return false
+		}
+		if !zeroDepth && (name == "/" || strings.HasPrefix(l.Root, name+"/")) {
+			return false
+		}
+	}
+	return true
+}
+
+// _lookup returns the lock which one of conditions names which
+// covers name, or nil if there isn't one
+//
+// call with the lock held
+func (ls *lockSystem) _lookup(name string, conditions ...webdav.Condition) *lock {
+	for _, c := range conditions {
+		l := ls.locks[c.Token]
+		if l == nil || ls.inUse[c.Token] {
+			continue
+		}
+		if l.covers(name) {
+			return l
+		}
+	}
+	return nil
+}
+
+// Confirm confirms that the caller can claim all of the locks
+// specified by the given conditions.
+func (ls *lockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (release func(), err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	unlock, err := ls._prepare(now)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	var l0, l1 *lock
+	if name0 != "" {
+		if l0 = ls._lookup(slashClean(name0), conditions...); l0 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	if name1 != "" {
+		if l1 = ls._lookup(slashClean(name1), conditions...); l1 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	var tokens []string
+	for _, l := range []*lock{l0, l1} {
+		if l != nil && !ls.inUse[l.Token] {
+			ls.inUse[l.Token] = true
+			tokens = append(tokens, l.Token)
+		}
+	}
+	return func() {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		for _, token := range tokens {
+			delete(ls.inUse, token)
+		}
+	}, nil
+}
+
+// Create creates a lock with the given depth, duration, owner and
+// root (name).
+func (ls *lockSystem) Create(now time.Time, details webdav.LockDetails) (token string, err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	unlock, err := ls._prepare(now)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	name := slashClean(details.Root)
+	if !ls._canCreate(name, details.ZeroDepth) {
+		return "", webdav.ErrLocked
+	}
+	token, err = newToken()
+	if err != nil {
+		return "", err
+	}
+	l := &lock{
+		Token:     token,
+		Root:      name,
+		Duration:  ls.duration(details.Duration),
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+		implicit:  isImplicit(details),
+	}
+	l.Expires = expiry(now, l.Duration)
+	err = ls._lockVFS(l)
+	if err == vfs.EAGAIN {
+		return "", webdav.ErrLocked
+	} else if err != nil {
+		fs.Errorf(name, "Failed to lock: %v", err)
+		return "", err
+	}
+	ls.locks[token] = l
+	if l.implicit {
+		return token, nil
+	}
+	err = ls._save()
+	if err != nil {
+		delete(ls.locks, token)
+		ls._unlockVFS(token)
+		return "", err
+	}
+	return token, nil
+}
+
+// Refresh refreshes the lock with the given token.
+func (ls *lockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	unlock, err := ls._prepare(now)
+	if err != nil {
+		return webdav.LockDetails{}, err
+	}
+	defer unlock()
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	if ls.inUse[token] {
+		return webdav.LockDetails{}, webdav.ErrLocked
+	}
+	l.Duration = ls.duration(duration)
+	l.Expires = expiry(now, l.Duration)
+	if l.implicit {
+		return l.details(), nil
+	}
+	err = ls._save()
+	if err != nil {
+		return webdav.LockDetails{}, err
+	}
+	return l.details(), nil
+}
+
+// Unlock unlocks the lock with the given token.
+func (ls *lockSystem) Unlock(now time.Time, token string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	unlock, err := ls._prepare(now)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.ErrNoSuchLock
+	}
+	if ls.inUse[token] {
+		return webdav.ErrLocked
+	}
+	delete(ls.locks, token)
+	ls._unlockVFS(token)
+	if l.implicit {
+		return nil
+	}
+	return ls._save()
+}
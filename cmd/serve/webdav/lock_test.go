@@ -0,0 +1,223 @@
+package webdav
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+func TestLockSystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-lock")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0600))
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	VFS := vfs.New(f, nil)
+	defer VFS.Shutdown()
+	// Keep the locks outside the served directory
+	lockDir, err := ioutil.TempDir("", "rclone-webdav-locks")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(lockDir))
+	}()
+	node, err := VFS.Stat("file")
+	require.NoError(t, err)
+	file := node.(*vfs.File)
+	other := vfs.Lock{Owner: 1, Start: 0, End: vfs.LockEOF, Type: vfs.LockShared}
+
+	lockFile := filepath.Join(lockDir, "locks.json")
+	ls := newLockSystem(VFS, lockFile, 0)
+	now := time.Now()
+	details := webdav.LockDetails{
+		Root:     "/file",
+		Duration: time.Minute,
+	}
+
+	// A WebDAV lock takes a VFS lock
+	token, err := ls.Create(now, details)
+	require.NoError(t, err)
+	_, found := file.TestLock(other)
+	assert.True(t, found)
+	assert.Equal(t, vfs.EAGAIN, file.Lock(context.Background(), other, false))
+
+	// Unlock releases it
+	require.NoError(t, ls.Unlock(now, token))
+	_, found = file.TestLock(other)
+	assert.False(t, found)
+
+	// A VFS lock stops a WebDAV lock being taken
+	require.NoError(t, file.Lock(context.Background(), other, false))
+	_, err = ls.Create(now, details)
+	assert.Equal(t, webdav.ErrLocked, err)
+	file.UnlockAll(other.Owner)
+
+	// The VFS lock is released when the WebDAV lock expires
+	_, err = ls.Create(now, details)
+	require.NoError(t, err)
+	_, _ = ls.Confirm(now.Add(2*time.Minute), "/file", "")
+	_, found = file.TestLock(other)
+	assert.False(t, found)
+
+	// Locks on names which don't exist are only kept by WebDAV
+	_, err = ls.Create(now, webdav.LockDetails{Root: "/new", Duration: time.Minute})
+	require.NoError(t, err)
+}
+
+func TestLockSystemPersistent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-lock")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	f, err := fs.NewFs(filepath.Join(dir, "root"))
+	require.NoError(t, err)
+	VFS := vfs.New(f, nil)
+	defer VFS.Shutdown()
+	lockFile := filepath.Join(dir, "locks.json")
+	now := time.Now()
+
+	ls := newLockSystem(VFS, lockFile, time.Hour)
+	token, err := ls.Create(now, webdav.LockDetails{
+		Root:     "/dir",
+		Duration: -1,
+		OwnerXML: "<owner>me</owner>",
+	})
+	require.NoError(t, err)
+
+	// Another lock system on the same file sees the lock
+	other := newLockSystem(VFS, lockFile, time.Hour)
+	_, err = other.Create(now, webdav.LockDetails{Root: "/dir/file", Duration: time.Minute})
+	assert.Equal(t, webdav.ErrLocked, err)
+	release, err := other.Confirm(now, "/dir/file", "", webdav.Condition{Token: token})
+	require.NoError(t, err)
+	release()
+	_, err = other.Confirm(now, "/file", "", webdav.Condition{Token: token})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+
+	// Infinite locks are limited by the timeout
+	details, err := other.Refresh(now, token, -1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, details.Duration)
+	assert.Equal(t, "/dir", details.Root)
+	assert.Equal(t, "<owner>me</owner>", details.OwnerXML)
+
+	// A lock in use can't be refreshed or unlocked
+	release, err = ls.Confirm(now, "/dir", "", webdav.Condition{Token: token})
+	require.NoError(t, err)
+	_, err = ls.Refresh(now, token, time.Minute)
+	assert.Equal(t, webdav.ErrLocked, err)
+	assert.Equal(t, webdav.ErrLocked, ls.Unlock(now, token))
+	release()
+
+	// Unlocking in one is seen by the other
+	require.NoError(t, other.Unlock(now, token))
+	assert.Equal(t, webdav.ErrNoSuchLock, ls.Unlock(now, token))
+	_, err = ls.Create(now, webdav.LockDetails{Root: "/dir/file", Duration: time.Minute})
+	require.NoError(t, err)
+
+	// Expired locks are removed
+	_, err = ls.Create(now.Add(2*time.Hour), webdav.LockDetails{Root: "/dir/file", Duration: time.Minute})
+	require.NoError(t, err)
+}
+
+func TestLockSystemOtherProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-lock")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "root"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "root", "file"), []byte("hello"), 0600))
+	f, err := fs.NewFs(filepath.Join(dir, "root"))
+	require.NoError(t, err)
+	lockFile := filepath.Join(dir, "locks.json")
+	now := time.Now()
+
+	// Separate VFSes stand in for separate processes
+	VFS1 := vfs.New(f, nil)
+	defer VFS1.Shutdown()
+	VFS2 := vfs.New(f, nil)
+	defer VFS2.Shutdown()
+	ls1 := newLockSystem(VFS1, lockFile, 0)
+	ls2 := newLockSystem(VFS2, lockFile, 0)
+
+	node, err := VFS2.Stat("file")
+	require.NoError(t, err)
+	file2 := node.(*vfs.File)
+	other := vfs.Lock{Owner: 1, Start: 0, End: vfs.LockEOF, Type: vfs.LockShared}
+
+	// A lock made by the first takes the VFS lock in the second
+	// when it next reads the locks
+	token, err := ls1.Create(now, webdav.LockDetails{Root: "/file", Duration: time.Minute})
+	require.NoError(t, err)
+	_, err = ls2.Confirm(now, "/file", "", webdav.Condition{Token: "potato"})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	_, found := file2.TestLock(other)
+	assert.True(t, found)
+
+	// and unlocking it releases it again
+	require.NoError(t, ls1.Unlock(now, token))
+	_, err = ls2.Confirm(now, "/file", "", webdav.Condition{Token: token})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	_, found = file2.TestLock(other)
+	assert.False(t, found)
+}
+
+func TestLockSystemImplicit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-lock")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	f, err := fs.NewFs(filepath.Join(dir, "root"))
+	require.NoError(t, err)
+	VFS := vfs.New(f, nil)
+	defer VFS.Shutdown()
+	lockFile := filepath.Join(dir, "locks.json")
+	now := time.Now()
+
+	// The lock the webdav library takes for a write without an If
+	// header isn't written to the file
+	ls := newLockSystem(VFS, lockFile, time.Hour)
+	implicit := webdav.LockDetails{Root: "/file", Duration: -1, ZeroDepth: true}
+	token, err := ls.Create(now, implicit)
+	require.NoError(t, err)
+	_, err = os.Stat(lockFile)
+	assert.True(t, os.IsNotExist(err))
+	_, err = ls.Create(now, implicit)
+	assert.Equal(t, webdav.ErrLocked, err)
+
+	// It is kept when the file is read again
+	other := newLockSystem(VFS, lockFile, time.Hour)
+	_, err = other.Create(now, webdav.LockDetails{Root: "/other", Duration: time.Minute})
+	require.NoError(t, err)
+	_, err = ls.Create(now, implicit)
+	assert.Equal(t, webdav.ErrLocked, err)
+
+	// but other lock systems don't see it
+	_, err = other.Create(now, implicit)
+	require.NoError(t, err)
+
+	// Unlocking it doesn't change the file
+	fi, err := os.Stat(lockFile)
+	require.NoError(t, err)
+	require.NoError(t, ls.Unlock(now, token))
+	fi2, err := os.Stat(lockFile)
+	require.NoError(t, err)
+	assert.Equal(t, fi.ModTime(), fi2.ModTime())
+	_, err = ls.Create(now, implicit)
+	require.NoError(t, err)
+}
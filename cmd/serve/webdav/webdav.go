@@ -3,9 +3,12 @@ package webdav
 
 import (
 	"context"
+	"encoding/xml"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rclone/rclone/cmd"
@@ -14,29 +17,48 @@ import (
 	"github.com/rclone/rclone/cmd/serve/httplib/serve"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
+	"github.com/rclone/rclone/cmd/serve/share"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/lib/errors"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/webdav"
 )
 
-var (
-	hashName      string
-	hashType      = hash.None
-	disableGETDir = false
-)
+// Options required for webdav server
+type Options struct {
+	httplib.Options
+	HashName      string      // hash to use for the ETag - auto or blank for off
+	DisableGETDir bool        // disable the HTML directory list on GET
+	LockTimeout   fs.Duration // maximum time a lock lasts unless refreshed
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	Options:       httplib.DefaultOpt,
+	HashName:      "",
+	DisableGETDir: false,
+	LockTimeout:   fs.Duration(time.Hour),
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
 
 func init() {
 	flagSet := Command.Flags()
 	httpflags.AddFlags(flagSet)
 	vfsflags.AddFlags(flagSet)
 	proxyflags.AddFlags(flagSet)
-	flags.StringVarP(flagSet, &hashName, "etag-hash", "", "", "Which hash to use for the ETag, or auto or blank for off")
-	flags.BoolVarP(flagSet, &disableGETDir, "disable-dir-list", "", false, "Disable HTML directory list on GET request for a directory")
+	flags.StringVarP(flagSet, &Opt.HashName, "etag-hash", "", Opt.HashName, "Which hash to use for the ETag, or auto or blank for off")
+	flags.BoolVarP(flagSet, &Opt.DisableGETDir, "disable-dir-list", "", Opt.DisableGETDir, "Disable HTML directory list on GET request for a directory")
+	flags.FVarP(flagSet, &Opt.LockTimeout, "lock-timeout", "", "Maximum time a lock lasts unless refreshed - 0 for no limit")
+	servelib.AddRc("webdav", startRc)
 }
 
 // Command definition for cobra
@@ -62,6 +84,36 @@ supported hash on the backend or you can use a named hash such as
 
 Use "rclone hashsum" to see the full list.
 
+The hash is also returned in the owncloud style "checksums" property
+so an rclone webdav remote with the vendor set to "rclone" can read
+it. The remote finds out which hash it is from the "hashtype"
+property of the directories.
+
+### Locking
+
+WebDAV LOCK and UNLOCK are supported. A lock on a file also takes an
+exclusive advisory lock on it in the VFS, so it conflicts with locks
+taken through a mount of the same VFS, or with --vfs-lock-remote by
+other rclone instances. See the VFS File Locking section below.
+
+The locks are kept in a file in the "serve-webdav" directory in the
+--cache-dir so they survive a restart of the server and are shared by
+servers of the same remote using the same cache directory. The
+temporary locks taken while a client without a lock writes a file
+are only kept in memory.
+
+#### --lock-timeout
+
+This sets the longest time a lock lasts unless the client refreshes
+it, 1h by default. Locks requested with a longer or infinite timeout
+are given this timeout instead. Set it to 0 to allow any timeout.
+
+### Quota
+
+If the remote supports "rclone about" then the quota-available-bytes
+and quota-used-bytes properties are returned for directories so
+clients can show the free space.
+
 ` + httplib.Help + vfs.Help + proxy.Help,
 	RunE: func(command *cobra.Command, args []string) error {
 		var f fs.Fs
@@ -71,20 +123,13 @@ Use "rclone hashsum" to see the full list.
 		} else {
 			cmd.CheckArgs(0, 0, command, args)
 		}
-		hashType = hash.None
-		if hashName == "auto" {
-			hashType = f.Hashes().GetOne()
-		} else if hashName != "" {
-			err := hashType.Set(hashName)
-			if err != nil {
-				return err
-			}
-		}
-		if hashType != hash.None {
-			fs.Debugf(f, "Using hash %v for ETag", hashType)
+		opt := Opt
+		opt.Options = httpflags.Opt
+		s, err := newWebDAV(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+		if err != nil {
+			return err
 		}
 		cmd.Run(false, false, command, func() error {
-			s := newWebDAV(f, &httpflags.Opt)
 			err := s.serve()
 			if err != nil {
 				return err
@@ -110,42 +155,91 @@ Use "rclone hashsum" to see the full list.
 // overwriting another existing file or directory is an error is OS-dependent.
 type WebDAV struct {
 	*httplib.Server
-	f             fs.Fs
-	_vfs          *vfs.VFS // don't use directly, use getVFS
-	webdavhandler *webdav.Handler
-	proxy         *proxy.Proxy
+	f          fs.Fs
+	opt        Options
+	hashType   hash.Type // hash to use for the ETag and checksums
+	_vfs       *vfs.VFS  // don't use directly, use getVFS
+	proxy      *proxy.Proxy
+	roots      *proxy.Roots // set if each user has their own root
+	handlersMu sync.Mutex
+	handlers   map[*vfs.VFS]*webdav.Handler // one for each VFS so each has its own locks
 }
 
 // check interface
 var _ webdav.FileSystem = (*WebDAV)(nil)
 
 // Make a new WebDAV to serve the remote
-func newWebDAV(f fs.Fs, opt *httplib.Options) *WebDAV {
+func newWebDAV(f fs.Fs, opt *Options, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options) (*WebDAV, error) {
 	w := &WebDAV{
-		f: f,
+		f:        f,
+		opt:      *opt,
+		hashType: hash.None,
+		handlers: make(map[*vfs.VFS]*webdav.Handler),
+	}
+	if opt.HashName == "auto" {
+		if f != nil {
+			w.hashType = f.Hashes().GetOne()
+		}
+	} else if opt.HashName != "" {
+		err := w.hashType.Set(opt.HashName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.hashType != hash.None {
+		fs.Debugf(f, "Using hash %v for ETag", w.hashType)
 	}
-	if proxyflags.Opt.AuthProxy != "" {
-		w.proxy = proxy.New(&proxyflags.Opt)
+	httpOpt := opt.Options
+	if proxyOpt.AuthProxy != "" {
+		w.proxy = proxy.New(proxyOpt)
+		w.proxy.OnExpire(w.removeHandler)
 		// override auth
-		copyOpt := *opt
-		copyOpt.Auth = w.auth
-		opt = &copyOpt
+		httpOpt.Auth = w.auth
 	} else {
-		w._vfs = vfs.New(f, &vfsflags.Opt)
+		w._vfs = vfs.New(f, vfsOpt)
+		if opt.JWTRootClaim != "" {
+			w.roots = proxy.NewRoots(f, vfsOpt)
+			w.roots.OnExpire(w.removeHandler)
+		}
+	}
+	w.Server = httplib.NewServer(http.HandlerFunc(w.handler), &httpOpt)
+	return w, nil
+}
+
+// Gets the webdav.Handler for the VFS in use for this request
+func (w *WebDAV) getHandler(ctx context.Context) (*webdav.Handler, error) {
+	VFS, err := w.getVFS(ctx)
+	if err != nil {
+		return nil, err
 	}
-	w.Server = httplib.NewServer(http.HandlerFunc(w.handler), opt)
-	webdavHandler := &webdav.Handler{
-		Prefix:     w.Server.Opt.BaseURL,
-		FileSystem: w,
-		LockSystem: webdav.NewMemLS(),
-		Logger:     w.logRequest, // FIXME
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+	webdavHandler := w.handlers[VFS]
+	if webdavHandler == nil {
+		webdavHandler = &webdav.Handler{
+			Prefix:     w.Server.Opt.BaseURL,
+			FileSystem: w,
+			LockSystem: newLockSystem(VFS, lockPath(VFS), time.Duration(w.opt.LockTimeout)),
+			Logger:     w.logRequest, // FIXME
+		}
+		w.handlers[VFS] = webdavHandler
 	}
-	w.webdavhandler = webdavHandler
-	return w
+	return webdavHandler, nil
+}
+
+// removeHandler drops the webdav.Handler for VFS when it is no longer
+// in use
+func (w *WebDAV) removeHandler(VFS *vfs.VFS) {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+	delete(w.handlers, VFS)
 }
 
 // Gets the VFS in use for this request
 func (w *WebDAV) getVFS(ctx context.Context) (VFS *vfs.VFS, err error) {
+	if root, ok := ctx.Value(httplib.ContextRootKey).(string); ok && w.roots != nil {
+		return w.roots.Get(root)
+	}
 	if w._vfs != nil {
 		return w._vfs, nil
 	}
@@ -174,13 +268,99 @@ func (w *WebDAV) handler(rw http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if w.proxy != nil {
+		user, _ := r.Context().Value(httplib.ContextUserKey).(string)
+		disconnect, err := proxy.ConnectUser(user)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		defer disconnect()
+	}
+	if root, ok := r.Context().Value(httplib.ContextRootKey).(string); ok && w.roots != nil {
+		defer w.roots.Hold(root)()
+	}
 	isDir := strings.HasSuffix(urlPath, "/")
 	remote := strings.Trim(urlPath, "/")
-	if !disableGETDir && (r.Method == "GET" || r.Method == "HEAD") && isDir {
+	if !w.opt.DisableGETDir && (r.Method == "GET" || r.Method == "HEAD") && isDir {
 		w.serveDir(rw, r, remote)
 		return
 	}
-	w.webdavhandler.ServeHTTP(rw, r)
+	webdavHandler, err := w.getHandler(r.Context())
+	if err != nil {
+		http.Error(rw, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve: %v", err)
+		return
+	}
+	if _, isShare := r.Context().Value(httplib.ContextShareKey).(*share.Share); isShare && r.Method == "PUT" {
+		// Report an existing file here as the webdav library
+		// returns Not Found if OpenFile fails
+		VFS, err := w.getVFS(r.Context())
+		if err == nil {
+			_, err = VFS.Stat(remote)
+		}
+		if err == nil {
+			http.Error(rw, "File exists", http.StatusConflict)
+			return
+		}
+	}
+	if r.Method == "PUT" {
+		upload := &uploadStatus{ResponseWriter: rw}
+		rw = upload
+		r = r.WithContext(context.WithValue(r.Context(), contextUploadKey, upload))
+	}
+	webdavHandler.ServeHTTP(rw, r)
+}
+
+// contextUploadType is the type of contextUploadKey
+type contextUploadType struct{}
+
+// contextUploadKey is the context key for the *uploadStatus of a PUT
+var contextUploadKey = &contextUploadType{}
+
+// uploadStatus corrects the status of a failed PUT.
+//
+// The webdav module returns 405 for all errors in a PUT, so this
+// replaces it if the upload failed because the user was over their
+// quota or maximum file size.
+type uploadStatus struct {
+	http.ResponseWriter
+	status   int  // status to use instead of 405 - 0 if not set
+	replaced bool // set if the status was replaced and the body not written
+}
+
+// setErr records err from writing the upload
+func (u *uploadStatus) setErr(err error) {
+	errors.Walk(err, func(err error) bool {
+		switch err {
+		case vfs.ENOSPC:
+			u.status = http.StatusInsufficientStorage
+		case vfs.EFBIG:
+			u.status = http.StatusRequestEntityTooLarge
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// WriteHeader writes the status code, replacing 405 if needed
+func (u *uploadStatus) WriteHeader(code int) {
+	if code == http.StatusMethodNotAllowed && u.status != 0 {
+		code = u.status
+		u.replaced = true
+	}
+	u.ResponseWriter.WriteHeader(code)
+}
+
+// Write the body, replacing the status text if the status was replaced
+func (u *uploadStatus) Write(p []byte) (n int, err error) {
+	if u.replaced {
+		u.replaced = false
+		_, err = u.ResponseWriter.Write([]byte(http.StatusText(u.status)))
+		return len(p), err
+	}
+	return u.ResponseWriter.Write(p)
 }
 
 // serveDir serves a directory index at dirRemote
@@ -216,7 +396,7 @@ func (w *WebDAV) serveDir(rw http.ResponseWriter, r *http.Request, dirRemote str
 	// Make the entries for display
 	directory := serve.NewDirectory(dirRemote, w.HTMLTemplate)
 	for _, node := range dirEntries {
-		if vfsflags.Opt.NoModTime {
+		if dir.VFS().Opt.NoModTime {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
 		} else {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
@@ -226,6 +406,11 @@ func (w *WebDAV) serveDir(rw http.ResponseWriter, r *http.Request, dirRemote str
 	sortParm := r.URL.Query().Get("sort")
 	orderParm := r.URL.Query().Get("order")
 	directory.ProcessQueryParams(sortParm, orderParm)
+	err = directory.ProcessPageParams(r.URL.Query().Get("offset"), r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	directory.Serve(rw, r)
 }
@@ -238,10 +423,49 @@ func (w *WebDAV) serve() error {
 	if err != nil {
 		return err
 	}
-	fs.Logf(w.f, "WebDav Server started on %s", w.URL())
+	fs.Logf(w.f, "WebDav Server started on %s", strings.Join(w.URLs(), ", "))
+	return nil
+}
+
+// startRc starts a server for serve/start
+func startRc(f fs.Fs, addr string, vfsOpt *vfscommon.Options, proxyOpt *proxy.Options, in rc.Params) (servelib.Server, error) {
+	opt := DefaultOpt
+	err := in.GetStructMissingOK("opt", &opt)
+	if err != nil {
+		return nil, err
+	}
+	opt.ListenAddr = []string{addr}
+	w, err := newWebDAV(f, &opt, vfsOpt, proxyOpt)
+	if err != nil {
+		return nil, err
+	}
+	err = w.serve()
+	if err != nil {
+		w.shutdownVFS()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Addr returns the URL the server is listening on
+func (w *WebDAV) Addr() string {
+	return w.URL()
+}
+
+// Shutdown stops the server and its VFS
+func (w *WebDAV) Shutdown() error {
+	w.Close()
+	w.shutdownVFS()
 	return nil
 }
 
+// shutdownVFS stops the VFS if it isn't from the proxy
+func (w *WebDAV) shutdownVFS() {
+	if w._vfs != nil {
+		w._vfs.Shutdown()
+	}
+}
+
 // logRequest is called by the webdav module on every request
 func (w *WebDAV) logRequest(r *http.Request, err error) {
 	fs.Infof(r.URL.Path, "%s from %s", r.Method, r.RemoteAddr)
@@ -269,11 +493,16 @@ func (w *WebDAV) OpenFile(ctx context.Context, name string, flags int, perm os.F
 	if err != nil {
 		return nil, err
 	}
+	// Upload share links can't replace existing files
+	if sh, ok := ctx.Value(httplib.ContextShareKey).(*share.Share); ok && sh.Mode == share.ModeUpload && flags&os.O_CREATE != 0 {
+		flags = flags&^os.O_TRUNC | os.O_EXCL
+	}
 	f, err := VFS.OpenFile(name, flags, perm)
 	if err != nil {
 		return nil, err
 	}
-	return Handle{f}, nil
+	upload, _ := ctx.Value(contextUploadKey).(*uploadStatus)
+	return Handle{Handle: f, upload: upload, hashType: w.hashType}, nil
 }
 
 // RemoveAll removes a file or a directory and its contents
@@ -315,12 +544,32 @@ func (w *WebDAV) Stat(ctx context.Context, name string) (fi os.FileInfo, err err
 	if err != nil {
 		return nil, err
 	}
-	return FileInfo{fi}, nil
+	return FileInfo{FileInfo: fi, hashType: w.hashType}, nil
 }
 
 // Handle represents an open file
 type Handle struct {
 	vfs.Handle
+	upload   *uploadStatus // set if the file is being PUT
+	hashType hash.Type     // hash to use for the checksums
+}
+
+// Write data to the handle
+func (h Handle) Write(p []byte) (n int, err error) {
+	n, err = h.Handle.Write(p)
+	if err != nil && h.upload != nil {
+		h.upload.setErr(err)
+	}
+	return n, err
+}
+
+// Close the handle
+func (h Handle) Close() (err error) {
+	err = h.Handle.Close()
+	if err != nil && h.upload != nil {
+		h.upload.setErr(err)
+	}
+	return err
 }
 
 // Readdir reads directory entries from the handle
@@ -331,7 +580,7 @@ func (h Handle) Readdir(count int) (fis []os.FileInfo, err error) {
 	}
 	// Wrap each FileInfo
 	for i := range fis {
-		fis[i] = FileInfo{fis[i]}
+		fis[i] = FileInfo{FileInfo: fis[i], hashType: h.hashType}
 	}
 	return fis, nil
 }
@@ -342,19 +591,99 @@ func (h Handle) Stat() (fi os.FileInfo, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return FileInfo{fi}, nil
+	return FileInfo{FileInfo: fi, hashType: h.hashType}, nil
+}
+
+// XML names of the extra properties
+var (
+	quotaAvailableName = xml.Name{Space: "DAV:", Local: "quota-available-bytes"}
+	quotaUsedName      = xml.Name{Space: "DAV:", Local: "quota-used-bytes"}
+	checksumsName      = xml.Name{Space: "http://owncloud.org/ns", Local: "checksums"}
+	hashTypeName       = xml.Name{Space: "http://rclone.org/ns", Local: "hashtype"}
+)
+
+// checksumName returns the owncloud name of hashType, eg SHA1 or MD5
+func checksumName(hashType hash.Type) string {
+	return strings.ToUpper(strings.Replace(hashType.String(), "-", "", -1))
+}
+
+// DeadProps returns the extra properties for the handle - the quota
+// and the hash type used in the checksums for directories and the
+// checksums for files.
+func (h Handle) DeadProps() (map[xml.Name]webdav.Property, error) {
+	props := make(map[xml.Name]webdav.Property)
+	node := h.Handle.Node()
+	if node == nil {
+		return props, nil
+	}
+	if node.IsDir() {
+		// Tell rclone webdav remotes which hash the checksums are
+		if h.hashType != hash.None {
+			props[hashTypeName] = webdav.Property{
+				XMLName:  hashTypeName,
+				InnerXML: []byte(checksumName(h.hashType)),
+			}
+		}
+		VFS := node.VFS()
+		if VFS.Fs().Features().About == nil {
+			return props, nil
+		}
+		_, used, free := VFS.Statfs()
+		if free >= 0 {
+			props[quotaAvailableName] = webdav.Property{
+				XMLName:  quotaAvailableName,
+				InnerXML: []byte(strconv.FormatInt(free, 10)),
+			}
+		}
+		if used >= 0 {
+			props[quotaUsedName] = webdav.Property{
+				XMLName:  quotaUsedName,
+				InnerXML: []byte(strconv.FormatInt(used, 10)),
+			}
+		}
+		return props, nil
+	}
+	if h.hashType == hash.None {
+		return props, nil
+	}
+	o, ok := node.DirEntry().(fs.Object)
+	if !ok {
+		return props, nil
+	}
+	sum, err := o.Hash(context.TODO(), h.hashType)
+	if err != nil || sum == "" {
+		return props, nil
+	}
+	// owncloud names the hashes like this, eg SHA1:xxx MD5:xxx
+	props[checksumsName] = webdav.Property{
+		XMLName:  checksumsName,
+		InnerXML: []byte(`<checksum xmlns="http://owncloud.org/ns">` + checksumName(h.hashType) + ":" + sum + `</checksum>`),
+	}
+	return props, nil
+}
+
+// Patch refuses to change any properties as they are all computed
+func (h Handle) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	pstat := webdav.Propstat{Status: http.StatusForbidden}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: p.XMLName})
+		}
+	}
+	return []webdav.Propstat{pstat}, nil
 }
 
 // FileInfo represents info about a file satisfying os.FileInfo and
 // also some additional interfaces for webdav for ETag and ContentType
 type FileInfo struct {
 	os.FileInfo
+	hashType hash.Type // hash to use for the ETag
 }
 
 // ETag returns an ETag for the FileInfo
 func (fi FileInfo) ETag(ctx context.Context) (etag string, err error) {
 	// defer log.Trace(fi, "")("etag=%q, err=%v", &etag, &err)
-	if hashType == hash.None {
+	if fi.hashType == hash.None {
 		return "", webdav.ErrNotImplemented
 	}
 	node, ok := (fi.FileInfo).(vfs.Node)
@@ -367,7 +696,7 @@ func (fi FileInfo) ETag(ctx context.Context) (etag string, err error) {
 	if !ok {
 		return "", webdav.ErrNotImplemented
 	}
-	hash, err := o.Hash(ctx, hashType)
+	hash, err := o.Hash(ctx, fi.hashType)
 	if err != nil || hash == "" {
 		return "", webdav.ErrNotImplemented
 	}
@@ -11,19 +11,22 @@ import (
 	"flag"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	_ "github.com/rclone/rclone/backend/local"
-	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/cmd/serve/servetest"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/filter"
-	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/webdav"
@@ -38,9 +41,9 @@ const (
 
 // check interfaces
 var (
-	_ os.FileInfo         = FileInfo{nil}
-	_ webdav.ETager       = FileInfo{nil}
-	_ webdav.ContentTyper = FileInfo{nil}
+	_ os.FileInfo         = FileInfo{}
+	_ webdav.ETager       = FileInfo{}
+	_ webdav.ContentTyper = FileInfo{}
 )
 
 // TestWebDav runs the webdav server then runs the unit tests for the
@@ -48,21 +51,22 @@ var (
 func TestWebDav(t *testing.T) {
 	// Configure and start the server
 	start := func(f fs.Fs) (configmap.Simple, func()) {
-		opt := httplib.DefaultOpt
-		opt.ListenAddr = testBindAddress
+		opt := DefaultOpt
+		opt.ListenAddr = []string{testBindAddress}
 		opt.BasicUser = testUser
 		opt.BasicPass = testPass
 		opt.Template = testTemplate
-		hashType = hash.MD5
+		opt.HashName = "MD5"
 
 		// Start the server
-		w := newWebDAV(f, &opt)
+		w, err := newWebDAV(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+		require.NoError(t, err)
 		assert.NoError(t, w.serve())
 
 		// Config for the backend we'll use to connect to the server
 		config := configmap.Simple{
 			"type":   "webdav",
-			"vendor": "other",
+			"vendor": "rclone",
 			"url":    w.Server.URL(),
 			"user":   testUser,
 			"pass":   obscure.MustObscure(testPass),
@@ -94,12 +98,13 @@ func TestHTTPFunction(t *testing.T) {
 	f, err := fs.NewFs("../http/testdata/files")
 	assert.NoError(t, err)
 
-	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
 	opt.Template = testTemplate
 
 	// Start the server
-	w := newWebDAV(f, &opt)
+	w, err := newWebDAV(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, err)
 	assert.NoError(t, w.serve())
 	defer func() {
 		w.Close()
@@ -255,3 +260,76 @@ func HelpTestGET(t *testing.T, testURL string) {
 		checkGolden(t, test.Golden, body)
 	}
 }
+
+func TestPropfindProps(t *testing.T) {
+	f, err := fs.NewFs("../http/testdata/files")
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = []string{testBindAddress}
+	opt.HashName = "SHA-1"
+	w, err := newWebDAV(f, &opt, &vfsflags.Opt, &proxyflags.Opt)
+	require.NoError(t, err)
+	require.NoError(t, w.serve())
+	defer func() {
+		w.Close()
+		w.Wait()
+	}()
+
+	propfind := func(URL string) string {
+		req, err := http.NewRequest("PROPFIND", w.Server.URL()+URL, strings.NewReader(`<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:r="http://rclone.org/ns">
+ <d:prop>
+  <d:quota-available-bytes/>
+  <d:quota-used-bytes/>
+  <oc:checksums/>
+  <r:hashtype/>
+ </d:prop>
+</d:propfind>`))
+		require.NoError(t, err)
+		req.Header.Set("Depth", "0")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// Directories have the quota and the hash type
+	body := propfind("")
+	assert.Regexp(t, `<D:quota-available-bytes>\d+</D:quota-available-bytes>`, body)
+	assert.Regexp(t, `<D:quota-used-bytes>\d+</D:quota-used-bytes>`, body)
+	assert.Regexp(t, `<hashtype xmlns="http://rclone.org/ns">SHA1</hashtype>`, body)
+
+	// Files have the checksums
+	body = propfind("two.txt")
+	assert.Contains(t, body, `<checksum xmlns="http://owncloud.org/ns">SHA1:`)
+	assert.NotRegexp(t, `quota-used-bytes>\d`, body)
+}
+
+func TestUploadStatus(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusMethodNotAllowed},
+		{errors.New("potato"), http.StatusMethodNotAllowed},
+		{errors.Wrap(vfs.ENOSPC, "upload failed"), http.StatusInsufficientStorage},
+		{errors.Wrap(vfs.EFBIG, "upload failed"), http.StatusRequestEntityTooLarge},
+	} {
+		rw := httptest.NewRecorder()
+		upload := &uploadStatus{ResponseWriter: rw}
+		if test.err != nil {
+			upload.setErr(test.err)
+		}
+		// what the webdav module does with a failed PUT
+		upload.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := upload.Write([]byte(webdav.StatusText(http.StatusMethodNotAllowed)))
+		require.NoError(t, err)
+		assert.Equal(t, test.status, rw.Code, test.err)
+		assert.Equal(t, http.StatusText(test.status), rw.Body.String(), test.err)
+	}
+}
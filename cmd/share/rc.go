@@ -0,0 +1,151 @@
+package share
+
+import (
+	"context"
+	"time"
+
+	libshare "github.com/rclone/rclone/cmd/serve/share"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "share/create",
+		AuthRequired: true,
+		Fn:           rcCreate,
+		Title:        "Create a share link to a file or directory on an rclone server",
+		Help: `This makes a signed link to a file or directory on a server
+started with "rclone serve http" or "rclone serve webdav" which can be
+used without a user name and password.
+
+This takes the following parameters
+
+- url - the URL of the server (required)
+- path - path of the file or directory on the server - end directories with / (default root)
+- secret - the --share-secret of the server (required)
+- store - the --share-store of the server (default shares.json next to the config file)
+- expire - duration the link lasts for (default 24h)
+- mode - "read" or "upload" (default read)
+- maxDownloads - maximum number of downloads (default unlimited)
+
+It returns
+
+- id - the ID of the share which can be used to revoke it
+- url - the share link
+- expires - when the share expires
+
+Eg
+
+    rclone rc share/create url=http://localhost:8080/ path=dir/ secret=potato expire=1h
+`,
+	})
+	rc.Add(rc.Call{
+		Path:         "share/list",
+		AuthRequired: true,
+		Fn:           rcList,
+		Title:        "List the share links",
+		Help: `This takes the following parameters
+
+- store - the share store (default shares.json next to the config file)
+
+It returns
+
+- shares - a list of the shares
+`,
+	})
+	rc.Add(rc.Call{
+		Path:         "share/revoke",
+		AuthRequired: true,
+		Fn:           rcRevoke,
+		Title:        "Revoke a share link",
+		Help: `This stops the share link with the given ID working.
+
+This takes the following parameters
+
+- id - ID of the share (required)
+- store - the share store (default shares.json next to the config file)
+`,
+	})
+}
+
+// rcOptions reads the Options from the rc parameters
+func rcOptions(in rc.Params) (opt libshare.Options, err error) {
+	opt.Secret, err = in.GetString("secret")
+	if rc.NotErrParamNotFound(err) {
+		return opt, err
+	}
+	opt.Store, err = in.GetString("store")
+	if rc.NotErrParamNotFound(err) {
+		return opt, err
+	}
+	return opt, nil
+}
+
+func rcCreate(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	opt, err := rcOptions(in)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := in.GetString("url")
+	if err != nil {
+		return nil, err
+	}
+	remote, err := in.GetString("path")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	expire, err := in.GetDuration("expire")
+	if rc.IsErrParamNotFound(err) {
+		expire = 24 * time.Hour
+	} else if err != nil {
+		return nil, err
+	}
+	mode := libshare.ModeRead
+	modeString, err := in.GetString("mode")
+	if err == nil {
+		err = mode.Set(modeString)
+	}
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	maxDownloads, err := in.GetInt64("maxDownloads")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	shares := libshare.New(&opt)
+	sh, err := shares.Create(remote, expire, mode, int(maxDownloads))
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{
+		"id":      sh.ID,
+		"url":     shares.URL(sh, baseURL),
+		"expires": sh.Expires,
+	}, nil
+}
+
+func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	opt, err := rcOptions(in)
+	if err != nil {
+		return nil, err
+	}
+	list, err := libshare.New(&opt).Store().List()
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{
+		"shares": list,
+	}, nil
+}
+
+func rcRevoke(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	opt, err := rcOptions(in)
+	if err != nil {
+		return nil, err
+	}
+	id, err := in.GetString("id")
+	if err != nil {
+		return nil, err
+	}
+	return nil, libshare.New(&opt).Store().Revoke(id)
+}
@@ -0,0 +1,119 @@
+// Package share provides the share command and rc calls
+package share
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/cmd"
+	libshare "github.com/rclone/rclone/cmd/serve/share"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/spf13/cobra"
+)
+
+var (
+	opt          = libshare.Options{}
+	serverURL    = ""
+	expire       = fs.Duration(24 * time.Hour)
+	upload       = false
+	maxDownloads = 0
+	list         = false
+	revoke       = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.StringVarP(cmdFlags, &opt.Secret, "share-secret", "", opt.Secret, "Secret the server checks share links with")
+	flags.StringVarP(cmdFlags, &opt.Store, "share-store", "", opt.Store, "File the share links are kept in - blank for the default")
+	flags.StringVarP(cmdFlags, &serverURL, "url", "", serverURL, "URL of the server, eg http://localhost:8080/")
+	flags.FVarP(cmdFlags, &expire, "expire", "", "The amount of time that the link will be valid")
+	flags.BoolVarP(cmdFlags, &upload, "upload", "", upload, "Make an upload only link to a directory")
+	flags.IntVarP(cmdFlags, &maxDownloads, "max-downloads", "", maxDownloads, "Maximum number of downloads - 0 for unlimited")
+	flags.BoolVarP(cmdFlags, &list, "list", "", list, "List the shares")
+	flags.StringVarP(cmdFlags, &revoke, "revoke", "", revoke, "Revoke the share with this ID")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "share path",
+	Short: `Make a share link to a file or directory on an rclone server.`,
+	Long: `rclone share makes signed, expiring links to a file or directory
+served by "rclone serve http" or "rclone serve webdav" which can be used
+without a user name and password.
+
+    rclone share --url http://example.com:8080/ --share-secret SECRET path/to/file
+    rclone share --url http://example.com:8080/ --share-secret SECRET --expire 1h path/to/dir/
+    rclone share --url http://example.com:8080/ --share-secret SECRET --upload incoming/
+    rclone share --list
+    rclone share --revoke ID
+
+The path is relative to the root of the server. End it with "/" to
+share a directory.
+
+--share-secret and --share-store must be the same as those the
+server was started with. The share is recorded in the share store and
+the link printed.
+
+By default the link is read only. Use --upload to make a link which
+can only upload files into a directory. Use --max-downloads to limit
+the number of times files can be downloaded with the link.
+
+Use --list to see the shares and --revoke to stop a share link
+working.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		if list || revoke != "" {
+			cmd.CheckArgs(0, 0, command, args)
+		} else {
+			cmd.CheckArgs(1, 1, command, args)
+		}
+		cmd.Run(false, false, command, func() error {
+			shares := libshare.New(&opt)
+			switch {
+			case list:
+				return listShares(shares)
+			case revoke != "":
+				return shares.Store().Revoke(revoke)
+			}
+			if serverURL == "" {
+				return errors.New("need --url to make a share link")
+			}
+			mode := libshare.ModeRead
+			if upload {
+				mode = libshare.ModeUpload
+			}
+			sh, err := shares.Create(args[0], time.Duration(expire), mode, maxDownloads)
+			if err != nil {
+				return err
+			}
+			fmt.Println(shares.URL(sh, serverURL))
+			return nil
+		})
+	},
+}
+
+// listShares prints the shares in shares
+func listShares(shares *libshare.Shares) error {
+	list, err := shares.Store().List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tMode\tExpires\tDownloads\tPath\n")
+	for _, sh := range list {
+		downloads := fmt.Sprintf("%d", sh.Downloads)
+		if sh.MaxDownloads > 0 {
+			downloads += fmt.Sprintf("/%d", sh.MaxDownloads)
+		}
+		path := "/" + sh.Path
+		if sh.Dir && sh.Path != "" {
+			path += "/"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", sh.ID, sh.Mode, sh.Expires.Local().Format("2006-01-02 15:04:05"), downloads, path)
+	}
+	return w.Flush()
+}
@@ -2,8 +2,12 @@ package sync
 
 import (
 	"context"
+	"log"
+	"os"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/sync"
@@ -12,12 +16,14 @@ import (
 
 var (
 	createEmptySrcDirs = false
+	planFile           = ""
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after sync")
+	flags.StringVarP(cmdFlags, &planFile, "plan", "", planFile, "Write the operations the sync would do to this JSON file instead of doing them")
 }
 
 var commandDefinition = &cobra.Command{
@@ -45,11 +51,28 @@ extended explanation in the ` + "`" + `copy` + "`" + ` command above if unsure.
 If dest:path doesn't exist, it is created and the source:path contents
 go there.
 
+If ` + "`--plan plan.json`" + ` is given then nothing is changed.  Instead
+the operations the sync would do are written to plan.json (or to
+standard output if it is ` + "`-`" + `) as a list of copy, update,
+delete, rename, mkdir and rmdir items with the reason for each and
+fingerprints of the files involved.  The plan can be reviewed and then
+carried out with ` + "`rclone apply plan.json`" + `.  Files which
+only differ in modification time are planned as updates.
+
 **Note**: Use the ` + "`-P`" + `/` + "`--progress`" + ` flag to view real-time transfer statistics
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, srcFileName, fdst := cmd.NewFsSrcFileDst(args)
+		if planFile != "" {
+			if srcFileName != "" {
+				log.Fatalf("Can't use --plan with a single file source")
+			}
+			cmd.Run(false, false, command, func() error {
+				return writePlan(fdst, fsrc)
+			})
+			return
+		}
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
 				return sync.Sync(context.Background(), fdst, fsrc, createEmptySrcDirs)
@@ -58,3 +81,16 @@ go there.
 		})
 	},
 }
+
+// writePlan writes the plan for syncing fsrc to fdst to planFile
+func writePlan(fdst, fsrc fs.Fs) (err error) {
+	if planFile == "-" {
+		return sync.SyncPlan(context.Background(), fdst, fsrc, createEmptySrcDirs, os.Stdout)
+	}
+	out, err := os.Create(planFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to create plan file")
+	}
+	defer fs.CheckClose(out, &err)
+	return sync.SyncPlan(context.Background(), fdst, fsrc, createEmptySrcDirs, out)
+}
@@ -0,0 +1,49 @@
+package undo
+
+import (
+	"context"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/sync"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "undo remote:journal",
+	Short: `Undo the changes recorded by --journal.`,
+	Long: `
+Undo the changes made by a sync, copy or move which was run with
+` + "`--journal remote:journal`" + `.
+
+When ` + "`--journal`" + ` is set, files in the destination which
+would be deleted or overwritten are moved into a time stamped
+directory under the journal path instead, using server side moves
+where possible.  Every change made is recorded in a ` + "`journal.jsonl`" + `
+file in that directory.
+
+This command reads the journal and reverses the changes, newest
+first.  Deleted and overwritten files are moved back into place,
+files which were created are deleted, and files which were moved by
+` + "`rclone move`" + ` are moved back to the source.
+
+Pass either the time stamped journal directory, or the path given to
+` + "`--journal`" + ` to undo the most recent journal in it.
+
+    rclone sync --journal remote:journal source:path dest:path
+    rclone undo remote:journal
+
+**Important**: Since this can cause data loss, test first with the
+` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsDir(args)
+		cmd.Run(true, true, command, func() error {
+			return sync.Undo(context.Background(), f)
+		})
+	},
+}
@@ -0,0 +1,368 @@
+// Package backup makes dated snapshot backups of a source into a
+// directory of snapshots, reusing unchanged files from the previous
+// snapshot without uploading them again.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/fs/march"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// DateFormat is the format of the snapshot directory names
+const DateFormat = "2006-01-02"
+
+// Snapshot describes a single dated snapshot directory
+type Snapshot struct {
+	Name string    // name of the directory, eg "2026-10-16"
+	Date time.Time // date parsed from the name
+}
+
+// Snapshots returns the snapshots found in the root of f, sorted
+// oldest first.
+//
+// Directories whose names don't parse as a date are ignored.
+func Snapshots(ctx context.Context, f fs.Fs) (snapshots []Snapshot, err error) {
+	entries, err := list.DirSorted(ctx, f, false, "")
+	if err == fs.ErrorDirNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, ok := entry.(fs.Directory); !ok {
+			continue
+		}
+		date, err := time.Parse(DateFormat, entry.Remote())
+		if err != nil {
+			fs.Debugf(entry, "Ignoring directory which isn't a snapshot")
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Name: entry.Remote(), Date: date})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	return snapshots, nil
+}
+
+// subFs returns an Fs for the directory dir within f
+func subFs(f fs.Fs, dir string) (fs.Fs, error) {
+	return cache.Get(fspath.JoinRootPath(fs.ConfigString(f), dir))
+}
+
+// backupMarch is used to march the source against the new snapshot
+type backupMarch struct {
+	ctx    context.Context
+	fdst   fs.Fs // the new snapshot
+	fprev  fs.Fs // the previous snapshot or nil
+	wg     sync.WaitGroup
+	tokens chan struct{}
+	mu     sync.Mutex
+	err    error // first error encountered
+	reused int64 // number of files reused from the previous snapshot
+	// directories only in the snapshot to be removed at the end
+	dstOnlyDirs []string
+}
+
+// setErr records the first error seen
+func (b *backupMarch) setErr(err error) {
+	err = fs.CountError(err)
+	b.mu.Lock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.mu.Unlock()
+}
+
+// background runs fn in a go routine limiting the concurrency to --transfers
+func (b *backupMarch) background(fn func()) {
+	b.wg.Add(1)
+	b.tokens <- struct{}{}
+	go func() {
+		defer func() {
+			<-b.tokens
+			b.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+// reuse tries to make remote in the new snapshot from prev without
+// uploading it.  It returns false if that wasn't possible.
+func (b *backupMarch) reuse(prev fs.Object, remote string) (ok bool, err error) {
+	if doHardlink := b.fdst.Features().Hardlink; doHardlink != nil && operations.SameConfig(prev.Fs(), b.fdst) {
+		if operations.SkipDestructive(b.ctx, prev, "hard link") {
+			return true, nil
+		}
+		_, err = doHardlink(b.ctx, prev, remote)
+		if err == nil {
+			fs.Infof(prev, "Hard linked into new snapshot")
+			return true, nil
+		} else if err != fs.ErrorCantHardlink {
+			return false, err
+		}
+	}
+	if b.fdst.Features().Copy != nil && operations.SameConfig(prev.Fs(), b.fdst) {
+		_, err = operations.Copy(b.ctx, b.fdst, nil, remote, prev)
+		return err == nil, err
+	}
+	return false, nil
+}
+
+// transfer makes src appear in the new snapshot, replacing dst if set
+func (b *backupMarch) transfer(dst, src fs.Object) {
+	b.background(func() {
+		remote := src.Remote()
+		var prev fs.Object
+		if b.fprev != nil {
+			o, err := b.fprev.NewObject(b.ctx, remote)
+			if err == nil && operations.Equal(b.ctx, src, o) {
+				prev = o
+			}
+		}
+		// Remove the existing file first as it may be a hard
+		// link into an older snapshot which must not be
+		// modified.
+		if dst != nil {
+			err := operations.DeleteFile(b.ctx, dst)
+			if err != nil {
+				b.setErr(err)
+				return
+			}
+		}
+		if prev != nil {
+			ok, err := b.reuse(prev, remote)
+			if err != nil {
+				b.setErr(err)
+				return
+			}
+			if ok {
+				b.mu.Lock()
+				b.reused++
+				b.mu.Unlock()
+				return
+			}
+		}
+		_, err := operations.Copy(b.ctx, b.fdst, nil, remote, src)
+		if err != nil {
+			b.setErr(err)
+		}
+	})
+}
+
+// SrcOnly is called for a DirEntry found only in the source
+func (b *backupMarch) SrcOnly(src fs.DirEntry) (recurse bool) {
+	switch x := src.(type) {
+	case fs.Object:
+		b.transfer(nil, x)
+	case fs.Directory:
+		return true
+	}
+	return false
+}
+
+// DstOnly is called for a DirEntry found only in the destination
+func (b *backupMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
+	switch x := dst.(type) {
+	case fs.Object:
+		b.background(func() {
+			err := operations.DeleteFile(b.ctx, x)
+			if err != nil {
+				b.setErr(err)
+			}
+		})
+	case fs.Directory:
+		b.mu.Lock()
+		b.dstOnlyDirs = append(b.dstOnlyDirs, x.Remote())
+		b.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// removeDstOnlyDirs removes the directories which were only in the
+// snapshot once their files have been deleted
+func (b *backupMarch) removeDstOnlyDirs() error {
+	// Remove the deepest first so parents are empty when reached
+	dirs := b.dstOnlyDirs
+	sort.Slice(dirs, func(i, j int) bool {
+		return len(dirs[i]) > len(dirs[j])
+	})
+	for _, dir := range dirs {
+		err := operations.TryRmdir(b.ctx, b.fdst, dir)
+		// bucket based remotes remove the directory with its last file
+		if err != nil && err != fs.ErrorDirNotFound {
+			return errors.Wrapf(err, "failed to remove directory %q", dir)
+		}
+	}
+	return nil
+}
+
+// Match is called for a DirEntry found both in the source and destination
+func (b *backupMarch) Match(ctx context.Context, dst, src fs.DirEntry) (recurse bool) {
+	switch srcX := src.(type) {
+	case fs.Object:
+		dstX, ok := dst.(fs.Object)
+		if ok && operations.Equal(ctx, srcX, dstX) {
+			fs.Debugf(srcX, "Unchanged since snapshot was last updated")
+			return false
+		}
+		if !ok {
+			b.setErr(errors.Errorf("%q is a directory in the snapshot but a file in the source", src.Remote()))
+			return false
+		}
+		b.transfer(dstX, srcX)
+	case fs.Directory:
+		if _, ok := dst.(fs.Directory); ok {
+			return true
+		}
+		b.setErr(errors.Errorf("%q is a file in the snapshot but a directory in the source", src.Remote()))
+	}
+	return false
+}
+
+// Backup makes a snapshot of fsrc in a directory named after now in
+// fdst.
+//
+// Files which are unchanged since the most recent earlier snapshot
+// are hard linked if the destination supports it, server side copied
+// if it supports that, or uploaded from fsrc otherwise.
+//
+// If a snapshot for the same date exists already it is updated to
+// match fsrc.
+//
+// It returns the name of the snapshot directory.
+func Backup(ctx context.Context, fdst, fsrc fs.Fs, now time.Time) (name string, err error) {
+	name = now.Format(DateFormat)
+	snapshots, err := Snapshots(ctx, fdst)
+	if err != nil {
+		return name, errors.Wrap(err, "failed to list snapshots")
+	}
+	b := &backupMarch{
+		ctx:    ctx,
+		tokens: make(chan struct{}, fs.Config.Transfers),
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Name < name {
+			b.fprev, err = subFs(fdst, snapshots[i].Name)
+			if err != nil {
+				return name, errors.Wrap(err, "failed to open previous snapshot")
+			}
+			fs.Infof(fdst, "Comparing against previous snapshot %q", snapshots[i].Name)
+			break
+		}
+	}
+	b.fdst, err = subFs(fdst, name)
+	if err != nil {
+		return name, errors.Wrap(err, "failed to open snapshot")
+	}
+	if operations.Overlapping(fsrc, fdst) {
+		return name, fs.ErrorOverlapping
+	}
+	err = operations.Mkdir(ctx, b.fdst, "")
+	if err != nil {
+		return name, err
+	}
+	m := &march.March{
+		Ctx:      ctx,
+		Fdst:     b.fdst,
+		Fsrc:     fsrc,
+		Dir:      "",
+		Callback: b,
+	}
+	err = m.Run()
+	b.wg.Wait()
+	if b.err != nil {
+		return name, b.err
+	}
+	if err != nil {
+		return name, err
+	}
+	err = b.removeDstOnlyDirs()
+	if err != nil {
+		return name, err
+	}
+	fs.Infof(b.fdst, "Snapshot complete: %d files reused from previous snapshot", b.reused)
+	return name, nil
+}
+
+// PruneOpt describes which snapshots Prune keeps
+type PruneOpt struct {
+	KeepDaily   int // keep the last snapshot of this many days
+	KeepWeekly  int // keep the last snapshot of this many weeks
+	KeepMonthly int // keep the last snapshot of this many months
+}
+
+// keepPeriods marks the newest snapshot in each of the n most recent
+// periods in keep
+func keepPeriods(snapshots []Snapshot, keep map[string]bool, n int, period func(time.Time) string) {
+	seen := map[string]bool{}
+	for i := len(snapshots) - 1; i >= 0 && len(seen) < n; i-- {
+		p := period(snapshots[i].Date)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		keep[snapshots[i].Name] = true
+	}
+}
+
+// SelectPrune returns the snapshots which should be removed from
+// snapshots (which must be sorted oldest first) according to opt.
+//
+// The newest snapshot in each of the most recent opt.KeepDaily days,
+// opt.KeepWeekly ISO weeks and opt.KeepMonthly months is kept.
+func SelectPrune(snapshots []Snapshot, opt PruneOpt) (remove []Snapshot) {
+	keep := map[string]bool{}
+	keepPeriods(snapshots, keep, opt.KeepDaily, func(t time.Time) string {
+		return t.Format(DateFormat)
+	})
+	keepPeriods(snapshots, keep, opt.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	})
+	keepPeriods(snapshots, keep, opt.KeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+	for _, snapshot := range snapshots {
+		if !keep[snapshot.Name] {
+			remove = append(remove, snapshot)
+		}
+	}
+	return remove
+}
+
+// Prune removes the snapshots in f which aren't kept by opt.
+func Prune(ctx context.Context, f fs.Fs, opt PruneOpt) error {
+	if opt.KeepDaily <= 0 && opt.KeepWeekly <= 0 && opt.KeepMonthly <= 0 {
+		return errors.New("refusing to prune all snapshots - set at least one keep option")
+	}
+	snapshots, err := Snapshots(ctx, f)
+	if err != nil {
+		return errors.Wrap(err, "failed to list snapshots")
+	}
+	remove := SelectPrune(snapshots, opt)
+	fs.Infof(f, "Keeping %d snapshots, removing %d", len(snapshots)-len(remove), len(remove))
+	for _, snapshot := range remove {
+		fsnap, err := subFs(f, snapshot.Name)
+		if err != nil {
+			return err
+		}
+		err = operations.Purge(ctx, fsnap, "")
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove snapshot %q", snapshot.Name)
+		}
+	}
+	return nil
+}
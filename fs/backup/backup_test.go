@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Some times used in the tests
+var (
+	t1 = fstest.Time("2001-02-03T04:05:06.499999999Z")
+	t2 = fstest.Time("2011-12-25T12:59:59.123456789Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/one", "one", t1)
+	file2 := r.WriteFile("two", "two", t1)
+	r.Mkdir(ctx, r.Fremote)
+
+	day1 := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	name, err := Backup(ctx, r.Fremote, r.Flocal, day1)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-15", name)
+
+	file2b := r.WriteFile("two", "two changed", t2)
+	file3 := r.WriteFile("three", "three", t2)
+
+	day2 := day1.Add(24 * time.Hour)
+	name, err = Backup(ctx, r.Fremote, r.Flocal, day2)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-16", name)
+
+	in := func(dir string, item fstest.Item) fstest.Item {
+		item.Path = dir + "/" + item.Path
+		return item
+	}
+	fstest.CheckItems(t, r.Fremote,
+		in("2026-10-15", file1),
+		in("2026-10-15", file2),
+		in("2026-10-16", file1),
+		in("2026-10-16", file2b),
+		in("2026-10-16", file3),
+	)
+
+	// Unchanged files are hard linked rather than copied on local disk
+	if r.Fremote.Features().IsLocal {
+		stat := func(path string) os.FileInfo {
+			fi, err := os.Stat(filepath.Join(r.Fremote.Root(), filepath.FromSlash(path)))
+			require.NoError(t, err)
+			return fi
+		}
+		assert.True(t, os.SameFile(stat("2026-10-15/sub dir/one"), stat("2026-10-16/sub dir/one")))
+		assert.False(t, os.SameFile(stat("2026-10-15/two"), stat("2026-10-16/two")))
+	}
+
+	snapshots, err := Snapshots(ctx, r.Fremote)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "2026-10-15", snapshots[0].Name)
+	assert.Equal(t, "2026-10-16", snapshots[1].Name)
+
+	// Backing up again on the same day updates the snapshot
+	r.WriteFile("two", "two", t1)
+	_, err = Backup(ctx, r.Fremote, r.Flocal, day2)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote,
+		in("2026-10-15", file1),
+		in("2026-10-15", file2),
+		in("2026-10-16", file1),
+		in("2026-10-16", file2),
+		in("2026-10-16", file3),
+	)
+
+	// Directories removed from the source are removed from the snapshot
+	require.NoError(t, os.RemoveAll(filepath.Join(r.LocalName, "sub dir")))
+	_, err = Backup(ctx, r.Fremote, r.Flocal, day2)
+	require.NoError(t, err)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{
+		in("2026-10-15", file1),
+		in("2026-10-15", file2),
+		in("2026-10-16", file2),
+		in("2026-10-16", file3),
+	}, []string{
+		"2026-10-15",
+		"2026-10-15/sub dir",
+		"2026-10-16",
+	}, fs.GetModifyWindow(r.Fremote))
+
+	require.NoError(t, Prune(ctx, r.Fremote, PruneOpt{KeepDaily: 1}))
+	fstest.CheckItems(t, r.Fremote,
+		in("2026-10-16", file2),
+		in("2026-10-16", file3),
+	)
+}
+
+func TestSelectPrune(t *testing.T) {
+	var snapshots []Snapshot
+	for date := fstest.Time("2026-01-01T00:00:00Z"); date.Before(fstest.Time("2026-04-01T00:00:00Z")); date = date.AddDate(0, 0, 1) {
+		snapshots = append(snapshots, Snapshot{Name: date.Format(DateFormat), Date: date})
+	}
+	names := func(snapshots []Snapshot) (names []string) {
+		for _, snapshot := range snapshots {
+			names = append(names, snapshot.Name)
+		}
+		return names
+	}
+
+	remove := SelectPrune(snapshots, PruneOpt{KeepDaily: 3})
+	assert.Equal(t, len(snapshots)-3, len(remove))
+	assert.NotContains(t, names(remove), "2026-03-31")
+
+	remove = SelectPrune(snapshots, PruneOpt{KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 3})
+	kept := map[string]bool{}
+	for _, name := range names(snapshots) {
+		kept[name] = true
+	}
+	for _, name := range names(remove) {
+		delete(kept, name)
+	}
+	assert.Equal(t, map[string]bool{
+		"2026-03-31": true, // day, week and month
+		"2026-03-30": true, // day
+		"2026-03-29": true, // previous week (Sunday)
+		"2026-02-28": true, // month
+		"2026-01-31": true, // month
+	}, kept)
+
+	assert.Equal(t, snapshots, SelectPrune(snapshots, PruneOpt{}))
+}
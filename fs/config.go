@@ -80,6 +80,7 @@ type ConfigInfo struct {
 	BackupDir              string
 	Suffix                 string
 	SuffixKeepExtension    bool
+	Journal                string
 	UseListR               bool
 	BufferSize             SizeSuffix
 	BwLimit                BwTimetable
@@ -41,6 +41,10 @@ const (
 	EntryObject // 1
 )
 
+// LinkSuffix is added to the name of an object which stores a
+// symbolic link as its contents
+const LinkSuffix = ".rclonelink"
+
 // Globals
 var (
 	// Filesystem registry
@@ -51,6 +55,7 @@ var (
 	ErrorCantCopy                    = errors.New("can't copy object - incompatible remotes")
 	ErrorCantMove                    = errors.New("can't move object - incompatible remotes")
 	ErrorCantDirMove                 = errors.New("can't move directory - incompatible remotes")
+	ErrorCantHardlink                = errors.New("can't hard link object - incompatible remotes")
 	ErrorCantUploadEmptyFiles        = errors.New("can't upload empty files to this remote")
 	ErrorDirExists                   = errors.New("can't copy directory - destination already exists")
 	ErrorCantSetModTime              = errors.New("can't set modified time")
@@ -558,6 +563,14 @@ type Features struct {
 	// If destination exists then return fs.ErrorDirExists
 	DirMove func(ctx context.Context, src Fs, srcRemote, dstRemote string) error
 
+	// Hardlink makes src available at remote as a hard link, so
+	// the two share the same data without copying it.
+	//
+	// Will only be called if src.Fs().Name() == f.Name()
+	//
+	// If it isn't possible then return fs.ErrorCantHardlink
+	Hardlink func(ctx context.Context, src Object, remote string) (Object, error)
+
 	// ChangeNotify calls the passed function with a path
 	// that has had changes. If the implementation
 	// uses polling, it should adhere to the given interval.
@@ -728,6 +741,9 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(DirMover); ok {
 		ft.DirMove = do.DirMove
 	}
+	if do, ok := f.(Hardlinker); ok {
+		ft.Hardlink = do.Hardlink
+	}
 	if do, ok := f.(ChangeNotifier); ok {
 		ft.ChangeNotify = do.ChangeNotify
 	}
@@ -811,6 +827,9 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.DirMove == nil {
 		ft.DirMove = nil
 	}
+	if mask.Hardlink == nil {
+		ft.Hardlink = nil
+	}
 	if mask.ChangeNotify == nil {
 		ft.ChangeNotify = nil
 	}
@@ -933,6 +952,17 @@ type DirMover interface {
 	DirMove(ctx context.Context, src Fs, srcRemote, dstRemote string) error
 }
 
+// Hardlinker is an optional interface for Fs
+type Hardlinker interface {
+	// Hardlink makes src available at remote as a hard link, so
+	// the two share the same data without copying it.
+	//
+	// Will only be called if src.Fs().Name() == f.Name()
+	//
+	// If it isn't possible then return fs.ErrorCantHardlink
+	Hardlink(ctx context.Context, src Object, remote string) (Object, error)
+}
+
 // ChangeNotifier is an optional interface for Fs
 type ChangeNotifier interface {
 	// ChangeNotify calls the passed function with a path
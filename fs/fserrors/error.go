@@ -4,6 +4,7 @@ package fserrors
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -422,6 +423,40 @@ func ShouldRetry(err error) bool {
 	return false
 }
 
+// Errors which indicate that the remote couldn't be reached at all
+//
+// These are added to in retriable_errors*.go
+var networkErrors = []error{}
+
+// IsNetworkError returns true if err shows that the remote couldn't
+// be reached, eg because the network is down, the host name couldn't
+// be resolved or the connection was refused or timed out.
+//
+// Unlike ShouldRetry it doesn't return true for errors which happen
+// part way through a transfer on a working connection.
+func IsNetworkError(err error) (isNetwork bool) {
+	if err == nil {
+		return false
+	}
+	errors.Walk(err, func(c error) bool {
+		switch x := c.(type) {
+		case *net.DNSError:
+			isNetwork = true
+		case *net.OpError:
+			isNetwork = x.Op == "dial"
+		case net.Error:
+			isNetwork = x.Timeout()
+		}
+		for _, networkErr := range networkErrors {
+			if c == networkErr {
+				isNetwork = true
+			}
+		}
+		return isNetwork
+	})
+	return isNetwork
+}
+
 // ShouldRetryHTTP returns a boolean as to whether this resp deserves.
 // It checks to see if the HTTP response code is in the slice
 // retryErrorCodes.
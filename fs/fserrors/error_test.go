@@ -153,6 +153,41 @@ func TestShouldRetry(t *testing.T) {
 	}
 }
 
+func TestIsNetworkError(t *testing.T) {
+	for i, test := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("potato"), false},
+		{io.EOF, false},
+		{makeNetErr(syscall.EPIPE), false},
+		{makeNetErr(syscall.ECONNREFUSED), true},
+		{makeNetErr(syscall.EHOSTUNREACH), true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("potato")}, true},
+		{&net.DNSError{Err: "no such host", Name: "example.invalid"}, true},
+		{
+			errors.Wrap(&url.Error{
+				Op:  "get",
+				URL: "http://localhost/",
+				Err: makeNetErr(syscall.ENETUNREACH),
+			}, "listing error"),
+			true,
+		},
+		{
+			errors.Wrap(&url.Error{
+				Op:  "get",
+				URL: "http://localhost/",
+				Err: errUseOfClosedNetworkConnection,
+			}, "listing error"),
+			false,
+		},
+	} {
+		got := IsNetworkError(test.err)
+		assert.Equal(t, test.want, got, fmt.Sprintf("test #%d: %v", i, test.err))
+	}
+}
+
 func TestRetryAfter(t *testing.T) {
 	e := NewErrorRetryAfter(time.Second)
 	after := e.RetryAfter()
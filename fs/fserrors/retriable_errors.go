@@ -18,4 +18,12 @@ func init() {
 		syscall.EWOULDBLOCK,
 		syscall.ECONNRESET,
 	)
+	networkErrors = append(networkErrors,
+		syscall.ETIMEDOUT,
+		syscall.ECONNREFUSED,
+		syscall.EHOSTDOWN,
+		syscall.EHOSTUNREACH,
+		syscall.ENETDOWN,
+		syscall.ENETUNREACH,
+	)
 }
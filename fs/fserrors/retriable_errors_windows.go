@@ -52,4 +52,13 @@ func init() {
 		syscall.ERROR_NETNAME_DELETED,
 		syscall.ERROR_BROKEN_PIPE,
 	)
+	networkErrors = append(networkErrors,
+		WSAENETDOWN,
+		WSAENETUNREACH,
+		WSAETIMEDOUT,
+		WSAECONNREFUSED,
+		WSAEHOSTDOWN,
+		WSAEHOSTUNREACH,
+		WSAHOST_NOT_FOUND,
+	)
 }
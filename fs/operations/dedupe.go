@@ -5,13 +5,16 @@ package operations
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"path"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/walk"
@@ -350,3 +353,278 @@ func Deduplicate(ctx context.Context, f fs.Fs, mode DeduplicateMode) error {
 	}
 	return nil
 }
+
+// DedupeLinkMode is what DeduplicateByHash replaces the duplicates it
+// removes with
+type DedupeLinkMode int
+
+// Dedupe link modes
+const (
+	DedupeLinkNone     DedupeLinkMode = iota // delete the duplicates
+	DedupeLinkSymlink                        // replace the duplicates with .rclonelink files
+	DedupeLinkHardlink                       // replace the duplicates with hard links
+)
+
+func (x DedupeLinkMode) String() string {
+	switch x {
+	case DedupeLinkNone:
+		return "none"
+	case DedupeLinkSymlink:
+		return "symlink"
+	case DedupeLinkHardlink:
+		return "hardlink"
+	}
+	return "unknown"
+}
+
+// Set a DedupeLinkMode from a string
+func (x *DedupeLinkMode) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "none", "":
+		*x = DedupeLinkNone
+	case "symlink":
+		*x = DedupeLinkSymlink
+	case "hardlink":
+		*x = DedupeLinkHardlink
+	default:
+		return errors.Errorf("Unknown link mode for dedupe %q.", s)
+	}
+	return nil
+}
+
+// Type of the value
+func (x *DedupeLinkMode) Type() string {
+	return "string"
+}
+
+// dedupeHashKey identifies a set of files with identical contents
+type dedupeHashKey struct {
+	hash string
+	size int64
+}
+
+// dedupeFindByHash scans f for files with the same hash and size
+// whatever their names, returning the sets of duplicates found.
+//
+// Files are grouped by size first and only the files which share a
+// size with another file are hashed, using --checkers at once.
+//
+// Each set is sorted by name and the sets are sorted by the name of
+// their first file.
+func dedupeFindByHash(ctx context.Context, f fs.Fs, ht hash.Type) ([][]fs.Object, error) {
+	var mu sync.Mutex
+	bySize := map[int64][]fs.Object{}
+	err := walk.ListR(ctx, f, "", true, fs.Config.MaxDepth, walk.ListObjects, func(entries fs.DirEntries) error {
+		entries.ForObject(func(o fs.Object) {
+			// Empty files and files of unknown size can't waste space
+			size := o.Size()
+			if size <= 0 {
+				return
+			}
+			mu.Lock()
+			bySize[size] = append(bySize[size], o)
+			mu.Unlock()
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "find duplicate files")
+	}
+
+	// Hash the files which have the same size as another
+	toHash := make(chan fs.Object, fs.Config.Checkers)
+	go func() {
+		defer close(toHash)
+		for _, objs := range bySize {
+			if len(objs) < 2 {
+				continue
+			}
+			for _, o := range objs {
+				select {
+				case <-ctx.Done():
+					return
+				case toHash <- o:
+				}
+			}
+		}
+	}()
+	files := map[dedupeHashKey][]fs.Object{}
+	var wg sync.WaitGroup
+	wg.Add(fs.Config.Checkers)
+	for i := 0; i < fs.Config.Checkers; i++ {
+		go func() {
+			defer wg.Done()
+			for o := range toHash {
+				sum, err := o.Hash(ctx, ht)
+				if err != nil {
+					err = fs.CountError(err)
+					fs.Errorf(o, "Failed to read %v: %v", ht, err)
+					continue
+				}
+				if sum == "" {
+					fs.Debugf(o, "Ignoring as it has no %v", ht)
+					continue
+				}
+				key := dedupeHashKey{hash: sum, size: o.Size()}
+				mu.Lock()
+				files[key] = append(files[key], o)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	duplicates := [][]fs.Object{}
+	for _, objs := range files {
+		if len(objs) > 1 {
+			sort.Slice(objs, func(i, j int) bool {
+				return objs[i].Remote() < objs[j].Remote()
+			})
+			duplicates = append(duplicates, objs)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i][0].Remote() < duplicates[j][0].Remote()
+	})
+	return duplicates, nil
+}
+
+// dedupeLinkTarget returns the relative path to use in a link at
+// remote pointing to target
+func dedupeLinkTarget(remote, target string) string {
+	var from []string
+	if dir := path.Dir(remote); dir != "." {
+		from = strings.Split(dir, "/")
+	}
+	to := strings.Split(target, "/")
+	i := 0
+	for i < len(from) && i < len(to)-1 && from[i] == to[i] {
+		i++
+	}
+	return strings.Repeat("../", len(from)-i) + strings.Join(to[i:], "/")
+}
+
+// dedupeReplace removes the duplicate o of keep, replacing it with a
+// link to keep if required.  It logs and counts any errors.
+func dedupeReplace(ctx context.Context, f fs.Fs, link DedupeLinkMode, keep, o fs.Object) (err error) {
+	if link == DedupeLinkNone {
+		return DeleteFile(ctx, o)
+	}
+	if SkipDestructive(ctx, o, "replace with "+link.String()) {
+		return nil
+	}
+	remote := o.Remote()
+	switch link {
+	case DedupeLinkSymlink:
+		target := dedupeLinkTarget(remote, keep.Remote())
+		_, err = Rcat(ctx, f, remote+fs.LinkSuffix, ioutil.NopCloser(strings.NewReader(target)), o.ModTime(ctx))
+		if err != nil {
+			err = errors.Wrap(err, "failed to make symlink")
+		} else {
+			err = o.Remove(ctx)
+		}
+	case DedupeLinkHardlink:
+		// Link to a temporary name then rename it over the duplicate
+		// so there is always a copy of the file at remote
+		var tmp fs.Object
+		tmp, err = f.Features().Hardlink(ctx, keep, remote+".rclone-dedupe")
+		if err != nil {
+			err = errors.Wrap(err, "failed to make hard link")
+		} else if _, err = f.Features().Move(ctx, tmp, remote); err != nil {
+			_ = tmp.Remove(ctx)
+		}
+	}
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(o, "Couldn't replace with %s: %v", link, err)
+		return err
+	}
+	accounting.Stats(ctx).Deletes(1)
+	fs.Infof(o, "Replaced with %s to %q", link, keep.Remote())
+	return nil
+}
+
+// dedupeInteractiveByHash asks which of the identical objs to keep
+// returning -1 to skip them
+func dedupeInteractiveByHash(ctx context.Context, ht hash.Type, objs []fs.Object) int {
+	sum, _ := objs[0].Hash(ctx, ht)
+	fmt.Printf("%d identical files of %d bytes, %v %s\n", len(objs), objs[0].Size(), ht, sum)
+	for i, o := range objs {
+		fmt.Printf("  %d: %s, %s\n", i+1, o.ModTime(ctx).Local().Format("2006-01-02 15:04:05.000000000"), o.Remote())
+	}
+	switch config.Command([]string{"sSkip and do nothing", "kKeep just one (choose which in next step)"}) {
+	case 'k':
+		return config.ChooseNumber("Enter the number of the file to keep", 1, len(objs)) - 1
+	}
+	return -1
+}
+
+// DeduplicateByHash finds files with the same contents (by hash and
+// size) anywhere under f whatever their names and reports how much
+// space they waste.
+//
+// Unless mode is DeduplicateSkip, one of each set of duplicates is
+// kept as chosen by mode and the others are deleted or replaced with
+// links to it as chosen by link.
+func DeduplicateByHash(ctx context.Context, f fs.Fs, mode DeduplicateMode, link DedupeLinkMode) error {
+	ht := f.Hashes().GetOne()
+	if ht == hash.None {
+		return errors.Errorf("%v: can't dedupe by hash as no hashes are supported", f)
+	}
+	if mode == DeduplicateRename {
+		return errors.Errorf("can't use %v mode when deduping by hash", mode)
+	}
+	if link == DedupeLinkHardlink && (f.Features().Hardlink == nil || f.Features().Move == nil) {
+		return errors.Errorf("%v: can't make hard links", f)
+	}
+	fs.Infof(f, "Looking for files with duplicate %v using %v mode.", ht, mode)
+
+	duplicates, err := dedupeFindByHash(ctx, f, ht)
+	if err != nil {
+		return err
+	}
+
+	var wasted fs.SizeSuffix
+	for _, objs := range duplicates {
+		size := objs[0].Size()
+		wasted += fs.SizeSuffix(size * int64(len(objs)-1))
+		fs.Logf(objs[0], "Found %d identical files of size %v", len(objs), fs.SizeSuffix(size))
+		for _, o := range objs[1:] {
+			fs.Logf(o, "Duplicate of %q", objs[0].Remote())
+		}
+		keep := -1
+		switch mode {
+		case DeduplicateInteractive:
+			keep = dedupeInteractiveByHash(ctx, ht, objs)
+		case DeduplicateFirst, DeduplicateLargest, DeduplicateSmallest:
+			// all the same size so keep the first by name
+			keep = 0
+		case DeduplicateNewest:
+			sortOldestFirst(objs)
+			keep = len(objs) - 1
+		case DeduplicateOldest:
+			sortOldestFirst(objs)
+			keep = 0
+		}
+		if keep < 0 {
+			continue
+		}
+		count := 0
+		for i, o := range objs {
+			if i == keep {
+				continue
+			}
+			err := dedupeReplace(ctx, f, link, objs[keep], o)
+			if err == nil {
+				count++
+			}
+		}
+		if count > 0 {
+			fs.Logf(objs[keep], "Kept and removed %d identical copies", count)
+		}
+	}
+	fs.Logf(f, "Found %d sets of identical files wasting %v", len(duplicates), wasted)
+	return nil
+}
@@ -239,3 +239,61 @@ func TestMergeDirs(t *testing.T) {
 	assert.Equal(t, 0, len(objs))
 	assert.Equal(t, "dupe1", dirs[0].Remote())
 }
+
+func TestDeduplicateByHash(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	skipIfNoHash(t, r.Fremote)
+
+	file1 := r.WriteObject(context.Background(), "one.txt", "This is one", t1)
+	r.WriteObject(context.Background(), "sub/dup.txt", "This is one", t2)
+	r.WriteObject(context.Background(), "sub/deeper/dup2.txt", "This is one", t3)
+	file4 := r.WriteObject(context.Background(), "other.txt", "This is other", t1)
+	file5 := r.WriteObject(context.Background(), "empty1.txt", "", t1)
+	file6 := r.WriteObject(context.Background(), "empty2.txt", "", t1)
+
+	err := operations.DeduplicateByHash(context.Background(), r.Fremote, operations.DeduplicateOldest, operations.DedupeLinkNone)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Fremote, file1, file4, file5, file6)
+
+	err = operations.DeduplicateByHash(context.Background(), r.Fremote, operations.DeduplicateRename, operations.DedupeLinkNone)
+	assert.Error(t, err)
+}
+
+func TestDeduplicateByHashSymlink(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	skipIfNoHash(t, r.Fremote)
+
+	file1 := r.WriteObject(context.Background(), "a/one.txt", "This is one", t2)
+	r.WriteObject(context.Background(), "b/c/two.txt", "This is one", t1)
+	r.WriteObject(context.Background(), "a/three.txt", "This is one", t3)
+
+	err := operations.DeduplicateByHash(context.Background(), r.Fremote, operations.DeduplicateFirst, operations.DedupeLinkSymlink)
+	require.NoError(t, err)
+
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{
+		file1,
+		fstest.NewItem("a/three.txt"+fs.LinkSuffix, "one.txt", t3),
+		fstest.NewItem("b/c/two.txt"+fs.LinkSuffix, "../../a/one.txt", t1),
+	}, nil, fs.GetModifyWindow(r.Fremote))
+}
+
+func TestDeduplicateByHashHardlink(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	skipIfNoHash(t, r.Fremote)
+	if r.Fremote.Features().Hardlink == nil {
+		t.Skip("Can't test hard links")
+	}
+
+	r.WriteObject(context.Background(), "one.txt", "This is one", t1)
+	file2 := r.WriteObject(context.Background(), "sub/two.txt", "This is one", t2)
+
+	err := operations.DeduplicateByHash(context.Background(), r.Fremote, operations.DeduplicateNewest, operations.DedupeLinkHardlink)
+	require.NoError(t, err)
+
+	// one.txt is now a hard link to sub/two.txt so has its modtime
+	fstest.CheckItems(t, r.Fremote, fstest.NewItem("one.txt", "This is one", t2), file2)
+}
@@ -247,6 +247,25 @@ func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, opt equalOpt)
 	return true
 }
 
+// unWrapSameFs unwraps src while the object underneath is from the
+// same Fs.  This removes wrappers which only cache information about
+// the object, such as the VFS directory cache, so the backend can
+// recognise the object for server side operations.  Wrappers from
+// other backends, such as crypt, change the Fs so are kept.
+func unWrapSameFs(src fs.Object) fs.Object {
+	for {
+		u, ok := src.(fs.ObjectUnWrapper)
+		if !ok {
+			return src
+		}
+		next := u.UnWrap()
+		if next == nil || next.Fs() != src.Fs() {
+			return src
+		}
+		src = next
+	}
+}
+
 // Used to remove a failed copy
 //
 // Returns whether the file was successfully removed or not
@@ -368,7 +387,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		if doCopy := f.Features().Copy; doCopy != nil && (SameConfig(src.Fs(), f) || (SameRemoteType(src.Fs(), f) && f.Features().ServerSideAcrossConfigs)) {
 			in := tr.Account(nil) // account the transfer
 			in.ServerSideCopyStart()
-			newDst, err = doCopy(ctx, src, remote)
+			newDst, err = doCopy(ctx, unWrapSameFs(src), remote)
 			if err == nil {
 				dst = newDst
 				in.ServerSideCopyEnd(dst.Size()) // account the bytes for the server side transfer
@@ -539,7 +558,7 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 			}
 		}
 		// Move dst <- src
-		newDst, err = doMove(ctx, src, remote)
+		newDst, err = doMove(ctx, unWrapSameFs(src), remote)
 		switch err {
 		case nil:
 			fs.Infof(src, "Moved (server side)")
@@ -9,6 +9,8 @@ import (
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +40,36 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+// fsObject is an object in f
+type fsObject struct {
+	fs.Object
+	f fs.Info
+}
+
+func (o fsObject) Fs() fs.Info { return o.f }
+
+// wrappedObject is an object in f wrapping another object
+type wrappedObject struct {
+	fsObject
+	wrapped fs.Object
+}
+
+func (o wrappedObject) UnWrap() fs.Object { return o.wrapped }
+
+func TestUnWrapSameFs(t *testing.T) {
+	f := mockfs.NewFs("f", "")
+	other := mockfs.NewFs("other", "")
+	base := fsObject{Object: mockobject.New("file"), f: f}
+
+	// A wrapper in the same Fs is removed
+	same := wrappedObject{fsObject: fsObject{Object: base, f: f}, wrapped: base}
+	assert.Equal(t, fs.Object(base), unWrapSameFs(same))
+
+	// A wrapper from another Fs, like crypt, is kept
+	crypt := wrappedObject{fsObject: fsObject{Object: base, f: other}, wrapped: base}
+	assert.Equal(t, fs.Object(crypt), unWrapSameFs(crypt))
+
+	// Not wrapped
+	assert.Equal(t, fs.Object(base), unWrapSameFs(base))
+}
@@ -0,0 +1,117 @@
+package operations
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/walk"
+)
+
+// PruneOpt controls which versions Prune keeps
+type PruneOpt struct {
+	KeepVersions int         // keep this many newest versions of each file
+	KeepWithin   fs.Duration // keep versions younger than this
+}
+
+// timestampLayout is the layout of the timestamp suffix made by
+// --suffix -$(date +%Y%m%d-%H%M%S) which is looked for if --suffix
+// isn't set.  Only this exact layout is recognised so that files
+// which just end in a date, eg "report-20200102", are never taken to
+// be versions.
+const timestampLayout = "-20060102-150405"
+
+// VersionName undoes the naming done by --suffix and
+// --suffix-keep-extension on remote.
+//
+// If --suffix is set then it is removed from remote, otherwise a
+// timestamp suffix in timestampLayout is looked for and removed.  It returns the
+// original name of the file and the time parsed from the suffix, or
+// the zero time if there wasn't a timestamp.
+//
+// If no suffix is found then remote is returned unchanged.
+func VersionName(remote string) (original string, t time.Time) {
+	stem, ext := remote, ""
+	if fs.Config.SuffixKeepExtension {
+		ext = path.Ext(remote)
+		stem = strings.TrimSuffix(remote, ext)
+	}
+	if suffix := fs.Config.Suffix; suffix != "" {
+		if !strings.HasSuffix(stem, suffix) || len(stem) == len(suffix) {
+			return remote, t
+		}
+		return stem[:len(stem)-len(suffix)] + ext, t
+	}
+	i := len(stem) - len(timestampLayout)
+	if i <= 0 {
+		return remote, t
+	}
+	t, err := time.ParseInLocation(timestampLayout, stem[i:], time.Local)
+	if err != nil {
+		return remote, time.Time{}
+	}
+	return stem[:i] + ext, t
+}
+
+// version is a single version of a file
+type version struct {
+	o fs.Object
+	t time.Time
+}
+
+// Prune deletes old versions of files left by --backup-dir and
+// --suffix in f.
+//
+// Versions of the same file are grouped using VersionName.  The
+// newest opt.KeepVersions versions of each file and any versions
+// younger than opt.KeepWithin are kept and the rest are deleted.
+//
+// The age of a version is taken from its timestamp suffix if it has
+// one, or from its modification time otherwise.
+func Prune(ctx context.Context, f fs.Fs, opt PruneOpt) error {
+	if opt.KeepVersions <= 0 && opt.KeepWithin <= 0 {
+		return errors.New("refusing to prune all versions - set keep versions or keep within")
+	}
+	var mu sync.Mutex
+	versions := map[string][]version{}
+	err := walk.ListR(ctx, f, "", false, ConfigMaxDepth(true), walk.ListObjects, func(entries fs.DirEntries) error {
+		entries.ForObject(func(o fs.Object) {
+			original, t := VersionName(o.Remote())
+			if t.IsZero() {
+				t = o.ModTime(ctx)
+			}
+			mu.Lock()
+			versions[original] = append(versions[original], version{o: o, t: t})
+			mu.Unlock()
+		})
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list versions")
+	}
+	cutoff := time.Now().Add(-time.Duration(opt.KeepWithin))
+	toBeDeleted := make(fs.ObjectsChan, fs.Config.Checkers)
+	go func() {
+		defer close(toBeDeleted)
+		for original, vs := range versions {
+			sort.Slice(vs, func(i, j int) bool {
+				return vs[i].t.After(vs[j].t)
+			})
+			kept := 0
+			for i, v := range vs {
+				if i < opt.KeepVersions || (opt.KeepWithin > 0 && v.t.After(cutoff)) {
+					kept++
+					continue
+				}
+				toBeDeleted <- v.o
+			}
+			fs.Debugf(original, "Keeping %d of %d versions", kept, len(vs))
+		}
+	}()
+	return DeleteFiles(ctx, toBeDeleted)
+}
@@ -0,0 +1,84 @@
+package operations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionName(t *testing.T) {
+	oldSuffix, oldKeepExtension := fs.Config.Suffix, fs.Config.SuffixKeepExtension
+	defer func() {
+		fs.Config.Suffix, fs.Config.SuffixKeepExtension = oldSuffix, oldKeepExtension
+	}()
+	local := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+		require.NoError(t, err)
+		return tm
+	}
+	for _, test := range []struct {
+		in           string
+		suffix       string
+		keepExt      bool
+		wantOriginal string
+		wantTime     time.Time
+	}{
+		{"file.txt", "", false, "file.txt", time.Time{}},
+		{"file.txt.bak", ".bak", false, "file.txt", time.Time{}},
+		{"file.bak.txt", ".bak", true, "file.txt", time.Time{}},
+		{".bak", ".bak", false, ".bak", time.Time{}},
+		{"dir/file.txt-20200102-150405", "", false, "dir/file.txt", local("2020-01-02 15:04:05")},
+		{"file-20200102-150405.txt", "", true, "file.txt", local("2020-01-02 15:04:05")},
+		{"-20200102-150405", "", false, "-20200102-150405", time.Time{}},
+		{"file-20201302-150405", "", false, "file-20201302-150405", time.Time{}},
+		{"report-20200102", "", false, "report-20200102", time.Time{}},
+		{"file.txt-2020-01-02", "", false, "file.txt-2020-01-02", time.Time{}},
+		{"file.txt.20200102-150405", "", false, "file.txt.20200102-150405", time.Time{}},
+		{"file_2020-01-02T15:04:05", "", false, "file_2020-01-02T15:04:05", time.Time{}},
+	} {
+		fs.Config.Suffix, fs.Config.SuffixKeepExtension = test.suffix, test.keepExt
+		gotOriginal, gotTime := operations.VersionName(test.in)
+		assert.Equal(t, test.wantOriginal, gotOriginal, test.in)
+		assert.True(t, test.wantTime.Equal(gotTime), "%s: want %v got %v", test.in, test.wantTime, gotTime)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+	now := time.Now()
+	stamp := func(age time.Duration) string {
+		return now.Add(-age).Format("20060102-150405")
+	}
+	file1 := r.WriteObject(ctx, "a.txt-"+stamp(time.Hour), "1", t1)
+	file2 := r.WriteObject(ctx, "a.txt-"+stamp(48*time.Hour), "2", t1)
+	file3 := r.WriteObject(ctx, "a.txt-"+stamp(72*time.Hour), "3", t1)
+	file4 := r.WriteObject(ctx, "dir/b.txt-"+stamp(96*time.Hour), "4", t1)
+	file5 := r.WriteObject(ctx, "dir/b.txt-"+stamp(120*time.Hour), "5", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4, file5)
+
+	err := operations.Prune(ctx, r.Fremote, operations.PruneOpt{})
+	require.Error(t, err)
+
+	fs.Config.DryRun = true
+	err = operations.Prune(ctx, r.Fremote, operations.PruneOpt{KeepVersions: 1})
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4, file5)
+
+	err = operations.Prune(ctx, r.Fremote, operations.PruneOpt{KeepVersions: 1, KeepWithin: fs.Duration(50 * time.Hour)})
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file4)
+
+	err = operations.Prune(ctx, r.Fremote, operations.PruneOpt{KeepWithin: fs.Duration(50 * time.Hour)})
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+}
@@ -304,6 +304,45 @@ func rcSize(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/prune",
+		AuthRequired: true,
+		Fn:           rcPrune,
+		Title:        "Remove old versions of files left by --backup-dir and --suffix",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:path/to/backup-dir"
+- keepVersions - integer - keep this many newest versions of each file (optional)
+- keepWithin - string - keep versions younger than this eg "30d" (optional)
+
+At least one of keepVersions and keepWithin must be set.
+
+See the [prune command](/commands/rclone_prune/) command for more information on the above.
+`,
+	})
+}
+
+// Prune old versions
+func rcPrune(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, err := rc.GetFs(in)
+	if err != nil {
+		return nil, err
+	}
+	var opt PruneOpt
+	keepVersions, err := in.GetInt64("keepVersions")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	opt.KeepVersions = int(keepVersions)
+	keepWithin, err := in.GetDuration("keepWithin")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	opt.KeepWithin = fs.Duration(keepWithin)
+	return nil, Prune(ctx, f, opt)
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:         "operations/publiclink",
@@ -43,7 +43,7 @@ var DefaultOpt = Options{
 }
 
 func init() {
-	DefaultOpt.HTTPOptions.ListenAddr = "localhost:5572"
+	DefaultOpt.HTTPOptions.ListenAddr = []string{"localhost:5572"}
 }
 
 // WriteJSON writes JSON in out to w
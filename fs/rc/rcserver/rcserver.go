@@ -123,7 +123,7 @@ func (s *Server) Serve() error {
 	if err != nil {
 		return err
 	}
-	fs.Logf(nil, "Serving remote control on %s", s.URL())
+	fs.Logf(nil, "Serving remote control on %s", strings.Join(s.URLs(), ", "))
 	// Open the files in the browser if set
 	if s.files != nil {
 		openURL, err := url.Parse(s.URL())
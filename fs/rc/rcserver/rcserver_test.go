@@ -31,7 +31,7 @@ const (
 // We'll do the majority of the testing with the httptest framework
 func TestRcServer(t *testing.T) {
 	opt := rc.DefaultOpt
-	opt.HTTPOptions.ListenAddr = testBindAddress
+	opt.HTTPOptions.ListenAddr = []string{testBindAddress}
 	opt.HTTPOptions.Template = testTemplate
 	opt.Enabled = true
 	opt.Serve = true
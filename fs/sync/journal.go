@@ -0,0 +1,495 @@
+// Undoable journal of the changes made by sync, copy and move
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/lib/atexit"
+)
+
+const (
+	journalFileName   = "journal.jsonl"     // name of the journal in the journal directory
+	journalFilesDir   = "files"             // directory where displaced files are kept
+	journalTimeFormat = "2006-01-02-150405" // format of the journal directory names
+)
+
+// Operations recorded in the journal
+const (
+	journalCopy      = "copy"      // file created or replaced in the destination by a copy
+	journalMove      = "move"      // file moved from the source to the destination
+	journalSrcDelete = "srcdelete" // source file deleted by move as it was in the destination already
+	journalRename    = "rename"    // destination file renamed by --track-renames
+	journalOverwrite = "overwrite" // destination file moved into the journal before being replaced
+	journalDelete    = "delete"    // destination file moved into the journal instead of being deleted
+)
+
+// journalHeader is the first line of the journal file
+type journalHeader struct {
+	Src     string    `json:"src"`
+	Dst     string    `json:"dst"`
+	Started time.Time `json:"started"`
+}
+
+// journalEntry is a single change recorded in the journal. Each one
+// is a line of the journal file after the header.
+type journalEntry struct {
+	Op      string    `json:"op"`
+	Path    string    `json:"path"`
+	From    string    `json:"from,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Time    time.Time `json:"time"`
+}
+
+// journal records the changes made to the destination and stores
+// the files which would otherwise be lost
+type journal struct {
+	journalHeader
+	Entries []journalEntry // entries read by loadJournal
+	f       fs.Fs          // the directory for this journal
+	files   fs.Fs          // the directory displaced files are moved to
+	key     string         // key in journals
+	mu      sync.Mutex
+	path    string          // local file the entries are appended to
+	spool   bool            // set if path must be uploaded to f as f isn't local
+	fd      *os.File        // open journal file or nil
+	header  bool            // set once the header has been written
+	count   int             // number of entries written
+	err     error           // first error writing the journal
+	atexit  atexit.FnHandle // ends the journal at exit if kept for a retry
+}
+
+// journals in use which are kept so retries add to the same journal
+var (
+	journalsMu sync.Mutex
+	journals   = map[string]*journal{}
+)
+
+// getJournal returns the journal for changes made syncing fsrc to
+// fdst.
+//
+// If the previous sync of fsrc to fdst failed with a retriable error
+// then its journal is returned so that all the retries of a sync are
+// recorded in one journal.  Otherwise a new journal is made in a time
+// stamped directory under --journal.
+func getJournal(fdst, fsrc fs.Fs) (j *journal, err error) {
+	if fs.Config.BackupDir != "" || fs.Config.Suffix != "" {
+		return nil, fserrors.FatalError(errors.New("can't use --journal with --backup-dir or --suffix"))
+	}
+	if fs.Config.NoCheckDest {
+		return nil, fserrors.FatalError(errors.New("can't use --no-check-dest with --journal"))
+	}
+	if fs.Config.CopyDest != "" {
+		// --copy-dest replaces files without them being recorded
+		return nil, fserrors.FatalError(errors.New("can't use --copy-dest with --journal"))
+	}
+	key := fs.Config.Journal + "\x00" + fs.ConfigString(fsrc) + "\x00" + fs.ConfigString(fdst)
+	journalsMu.Lock()
+	defer journalsMu.Unlock()
+	if j := journals[key]; j != nil {
+		fs.Infof(j.f, "Continuing journal of previous attempt")
+		return j, nil
+	}
+	now := time.Now()
+	j = &journal{
+		journalHeader: journalHeader{
+			Src:     fs.ConfigString(fsrc),
+			Dst:     fs.ConfigString(fdst),
+			Started: now,
+		},
+		key: key,
+	}
+	dir := fspath.JoinRootPath(fs.Config.Journal, now.Format(journalTimeFormat))
+	j.f, err = cache.Get(dir)
+	if err != nil {
+		return nil, fserrors.FatalError(errors.Errorf("Failed to make fs for --journal %q: %v", dir, err))
+	}
+	j.files, err = cache.Get(fspath.JoinRootPath(dir, journalFilesDir))
+	if err != nil {
+		return nil, fserrors.FatalError(errors.Errorf("Failed to make fs for --journal %q: %v", dir, err))
+	}
+	if operations.Overlapping(fdst, j.f) || operations.Overlapping(fsrc, j.f) {
+		return nil, fserrors.FatalError(errors.New("can't use --journal which overlaps the source or destination"))
+	}
+	if j.f.Features().IsLocal {
+		j.path = filepath.Join(j.f.Root(), journalFileName)
+	} else {
+		// Append to a local file and upload it at the end
+		j.spool = true
+	}
+	journals[key] = j
+	fs.Infof(j.f, "Recording changes in journal")
+	return j, nil
+}
+
+// open opens the journal file and writes the header if it hasn't
+// been written by a previous attempt
+//
+// Call with mu held
+func (j *journal) open() (err error) {
+	if j.spool && j.path == "" {
+		j.fd, err = ioutil.TempFile("", "rclone-journal-")
+		if err == nil {
+			j.path = j.fd.Name()
+			fs.Infof(j.f, "Recording journal in %q until it is uploaded", j.path)
+		}
+	} else {
+		err = os.MkdirAll(filepath.Dir(j.path), 0777)
+		if err == nil {
+			j.fd, err = os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if j.header {
+		return nil
+	}
+	err = j.write(j.journalHeader)
+	if err != nil {
+		return err
+	}
+	j.header = true
+	return nil
+}
+
+// write appends v as a line to the journal file and flushes it to
+// disk so it survives a crash
+//
+// Call with mu held
+func (j *journal) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = j.fd.Write(append(data, '\n'))
+	if err != nil {
+		return err
+	}
+	return j.fd.Sync()
+}
+
+// add records op on o in the journal. It should be called once the
+// change has been made.
+//
+// If the journal can't be written it returns a fatal error so the
+// sync stops rather than making changes which can't be undone.
+//
+// It is safe to call on a nil journal.
+func (j *journal) add(op string, o fs.ObjectInfo, from string) error {
+	if j == nil {
+		return nil
+	}
+	entry := journalEntry{
+		Op:      op,
+		Path:    o.Remote(),
+		From:    from,
+		Size:    o.Size(),
+		ModTime: o.ModTime(context.Background()),
+		Time:    time.Now(),
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var err error
+	if j.fd == nil {
+		err = j.open()
+	}
+	if err == nil {
+		err = j.write(entry)
+	}
+	if err != nil {
+		if j.err == nil {
+			j.err = fserrors.FatalError(errors.Wrap(err, "failed to write journal"))
+		}
+		return j.err
+	}
+	j.count++
+	return nil
+}
+
+// deleteFiles deletes the objects read from in, moving them into
+// backupDir, and records them in the journal once they are moved.
+//
+// If the journal is nil this is the same as
+// operations.DeleteFilesWithBackupDir.
+func (j *journal) deleteFiles(ctx context.Context, in fs.ObjectsChan, backupDir fs.Fs) error {
+	if j == nil {
+		return operations.DeleteFilesWithBackupDir(ctx, in, backupDir)
+	}
+	var wg sync.WaitGroup
+	wg.Add(fs.Config.Transfers)
+	var errorCount int32
+	var fatalErrorCount int32
+	for i := 0; i < fs.Config.Transfers; i++ {
+		go func() {
+			defer wg.Done()
+			for dst := range in {
+				err := operations.DeleteFileWithBackupDir(ctx, dst, backupDir)
+				if err == nil {
+					err = j.add(journalDelete, dst, "")
+				}
+				if err == nil {
+					continue
+				}
+				atomic.AddInt32(&errorCount, 1)
+				if fserrors.IsFatalError(err) {
+					fs.Errorf(nil, "Got fatal error on delete: %s", err)
+					atomic.AddInt32(&fatalErrorCount, 1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if errorCount > 0 {
+		err := errors.Errorf("failed to delete %d files", errorCount)
+		if fatalErrorCount > 0 {
+			return fserrors.FatalError(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// finish is called at the end of each attempt at the sync with the
+// error it returned.  It closes the journal file and uploads it if it
+// isn't local.
+//
+// If syncErr can be retried then the journal is kept for the next
+// attempt, otherwise it is ended.  A journal kept for an attempt which
+// never happens is ended at exit.
+//
+// It is safe to call on a nil journal.
+func (j *journal) finish(ctx context.Context, syncErr error) (err error) {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	err = j.err
+	if j.fd != nil {
+		closeErr := j.fd.Close()
+		if err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "failed to close journal")
+		}
+		j.fd = nil
+	}
+	if j.count == 0 {
+		fs.Infof(j.f, "No changes to record in journal")
+	} else {
+		if j.spool && err == nil {
+			err = j.upload(ctx)
+		}
+		fs.Infof(j.f, "Recorded %d changes in journal", j.count)
+	}
+	if syncErr != nil && err == nil && !fserrors.IsFatalError(syncErr) {
+		if j.atexit == nil {
+			j.atexit = atexit.Register(func() {
+				j.mu.Lock()
+				defer j.mu.Unlock()
+				// only uploaded if no attempt is in progress
+				j.end(j.fd == nil)
+			})
+		}
+		return nil
+	}
+	if j.atexit != nil {
+		atexit.Unregister(j.atexit)
+		j.atexit = nil
+	}
+	j.end(err == nil)
+	return err
+}
+
+// end removes the journal from journals so it isn't used again. If
+// the journal was spooled then the local copy is removed if it has
+// been uploaded.
+//
+// Call with mu held
+func (j *journal) end(uploaded bool) {
+	journalsMu.Lock()
+	delete(journals, j.key)
+	journalsMu.Unlock()
+	if j.spool && uploaded && j.path != "" {
+		_ = os.Remove(j.path)
+	}
+}
+
+// upload copies the local journal file to the journal directory
+//
+// Call with mu held
+func (j *journal) upload(ctx context.Context) (err error) {
+	in, err := os.Open(j.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open journal")
+	}
+	_, err = operations.Rcat(ctx, j.f, journalFileName, in, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload journal - it is in %q", j.path)
+	}
+	return nil
+}
+
+// readJournal reads the journal file in f
+func readJournal(ctx context.Context, f fs.Fs) (j *journal, err error) {
+	o, err := f.NewObject(ctx, journalFileName)
+	if err != nil {
+		return nil, err
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open journal")
+	}
+	defer fs.CheckClose(in, &err)
+	j = &journal{f: f}
+	dec := json.NewDecoder(in)
+	err = dec.Decode(&j.journalHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode journal")
+	}
+	for {
+		var entry journalEntry
+		err = dec.Decode(&entry)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			// The last line may be incomplete if rclone crashed
+			fs.Logf(f, "Ignoring rest of journal after %d entries: %v", len(j.Entries), err)
+			break
+		}
+		j.Entries = append(j.Entries, entry)
+	}
+	return j, nil
+}
+
+// loadJournal reads the journal in f ready for undoing.
+//
+// If f doesn't contain a journal file then the most recent journal
+// directory in f is used.
+func loadJournal(ctx context.Context, f fs.Fs) (j *journal, err error) {
+	j, err = readJournal(ctx, f)
+	if err == fs.ErrorObjectNotFound {
+		entries, err := list.DirSorted(ctx, f, false, "")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find journal")
+		}
+		var dirs []string
+		for _, entry := range entries {
+			if _, ok := entry.(fs.Directory); !ok {
+				continue
+			}
+			if _, err := time.Parse(journalTimeFormat, entry.Remote()); err == nil {
+				dirs = append(dirs, entry.Remote())
+			}
+		}
+		if len(dirs) == 0 {
+			return nil, errors.Errorf("no journal found in %v", f)
+		}
+		sort.Strings(dirs)
+		f, err = cache.Get(fspath.JoinRootPath(fs.ConfigString(f), dirs[len(dirs)-1]))
+		if err != nil {
+			return nil, err
+		}
+		j, err = readJournal(ctx, f)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read journal")
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read journal")
+	}
+	j.files, err = cache.Get(fspath.JoinRootPath(fs.ConfigString(j.f), journalFilesDir))
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Undo reverses the changes recorded in the journal in f, which
+// should be a journal directory made by --journal.  If it is the
+// directory passed to --journal then the most recent journal is used.
+//
+// The changes are undone newest first.  Files which were deleted or
+// overwritten are moved back from the journal, files which were
+// created are deleted and files which were moved are moved back to
+// the source.
+func Undo(ctx context.Context, f fs.Fs) (err error) {
+	j, err := loadJournal(ctx, f)
+	if err != nil {
+		return err
+	}
+	fs.Infof(j.f, "Undoing %d changes from %v to %v made at %v", len(j.Entries), j.Src, j.Dst, j.Started)
+	fdst, err := cache.Get(j.Dst)
+	if err != nil {
+		return errors.Wrap(err, "failed to make fs for destination")
+	}
+	fsrc, err := cache.Get(j.Src)
+	if err != nil {
+		return errors.Wrap(err, "failed to make fs for source")
+	}
+	// lookup finds remote in f returning nil if not found
+	lookup := func(f fs.Fs, remote string) fs.Object {
+		o, err := f.NewObject(ctx, remote)
+		if err != nil {
+			return nil
+		}
+		return o
+	}
+	var lastErr error
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		entry := j.Entries[i]
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = nil
+		switch entry.Op {
+		case journalCopy:
+			if o := lookup(fdst, entry.Path); o != nil {
+				err = operations.DeleteFile(ctx, o)
+			}
+		case journalMove:
+			if o := lookup(fdst, entry.Path); o != nil {
+				_, err = operations.Move(ctx, fsrc, lookup(fsrc, entry.Path), entry.Path, o)
+			}
+		case journalSrcDelete:
+			if o := lookup(fdst, entry.Path); o != nil {
+				_, err = operations.Copy(ctx, fsrc, lookup(fsrc, entry.Path), entry.Path, o)
+			}
+		case journalRename:
+			if o := lookup(fdst, entry.Path); o != nil {
+				_, err = operations.Move(ctx, fdst, lookup(fdst, entry.From), entry.From, o)
+			}
+		case journalOverwrite, journalDelete:
+			saved := lookup(j.files, entry.Path)
+			if saved == nil {
+				fs.Logf(entry.Path, "Not restoring as not found in journal")
+				continue
+			}
+			_, err = operations.Move(ctx, fdst, lookup(fdst, entry.Path), entry.Path, saved)
+		default:
+			err = errors.Errorf("unknown journal operation %q", entry.Op)
+		}
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(entry.Path, "Failed to undo %s: %v", entry.Op, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,168 @@
+// Test the --journal and undo
+
+package sync
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setJournal sets --journal to a temporary directory returning a
+// function to tidy up
+func setJournal(t *testing.T) (journalFs fs.Fs, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rclone-journal")
+	require.NoError(t, err)
+	journalFs, err = fs.NewFs(dir)
+	require.NoError(t, err)
+	fs.Config.Journal = dir
+	return journalFs, func() {
+		fs.Config.Journal = ""
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSyncWithJournal(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	journalFs, cleanup := setJournal(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("new", "new file", t1)
+	file2 := r.WriteFile("sub dir/changed", "changed file", t2)
+	file2old := r.WriteObject(ctx, "sub dir/changed", "old", t1)
+	file3 := r.WriteObject(ctx, "deleted", "deleted file", t1)
+	fstest.CheckItems(t, r.Fremote, file2old, file3)
+
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	j, err := loadJournal(ctx, journalFs)
+	require.NoError(t, err)
+	var ops []string
+	for _, entry := range j.Entries {
+		ops = append(ops, entry.Op+" "+entry.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		"copy new",
+		"overwrite sub dir/changed",
+		"copy sub dir/changed",
+		"delete deleted",
+	}, ops)
+
+	err = Undo(ctx, journalFs)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file2old, file3)
+}
+
+func TestMoveWithJournal(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	journalFs, cleanup := setJournal(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("moved", "moved file", t1)
+	file2 := r.WriteFile("same", "same file", t1)
+	r.WriteObject(ctx, "same", "same file", t1)
+	file3 := r.WriteFile("changed", "changed file", t2)
+	file3old := r.WriteObject(ctx, "changed", "old", t1)
+
+	err := MoveDir(ctx, r.Fremote, r.Flocal, false, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	err = Undo(ctx, journalFs)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+	fstest.CheckItems(t, r.Fremote, file2, file3old)
+}
+
+func TestJournalDryRun(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	journalFs, cleanup := setJournal(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("new", "new file", t1)
+	r.Mkdir(ctx, r.Fremote)
+
+	fs.Config.DryRun = true
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote)
+
+	_, err = loadJournal(ctx, journalFs)
+	require.Error(t, err)
+
+	fs.Config.BackupDir = "backup"
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	fs.Config.BackupDir = ""
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--journal")
+
+	fs.Config.CopyDest = "copy"
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	fs.Config.CopyDest = ""
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--copy-dest")
+	count, _, err := operations.Count(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestJournalRetries(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	_, cleanup := setJournal(t)
+	defer cleanup()
+
+	src := object.NewStaticObjectInfo("one", t1, 3, true, nil, nil)
+	j, err := getJournal(r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	require.NoError(t, j.add(journalCopy, src, ""))
+
+	// The entry is on disk before the sync finishes
+	j2, err := loadJournal(ctx, j.f)
+	require.NoError(t, err)
+	require.Len(t, j2.Entries, 1)
+
+	// A retriable error keeps the journal for the next attempt
+	// with the file closed
+	require.NoError(t, j.finish(ctx, errors.New("retry me")))
+	assert.Nil(t, j.fd)
+	assert.NotNil(t, j.atexit)
+	again, err := getJournal(r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	assert.True(t, j == again)
+	require.NoError(t, again.add(journalCopy, src, ""))
+
+	// Success closes it
+	require.NoError(t, again.finish(ctx, nil))
+	j2, err = loadJournal(ctx, j.f)
+	require.NoError(t, err)
+	assert.Len(t, j2.Entries, 2)
+	assert.Nil(t, j.fd)
+	assert.Nil(t, j.atexit)
+	journalsMu.Lock()
+	assert.Len(t, journals, 0)
+	journalsMu.Unlock()
+}
@@ -0,0 +1,384 @@
+// Machine readable sync plans and applying them
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// Operations in a Plan
+const (
+	PlanCopy   = "copy"   // copy a file which isn't in the destination
+	PlanUpdate = "update" // replace a file in the destination
+	PlanDelete = "delete" // delete a file from the destination
+	PlanRename = "rename" // rename a file in the destination
+	PlanMkdir  = "mkdir"  // make an empty directory in the destination
+	PlanRmdir  = "rmdir"  // remove an empty directory from the destination
+)
+
+// PlanItem is a single operation in a Plan
+type PlanItem struct {
+	Op             string `json:"op"`                       // operation to do
+	Path           string `json:"path"`                     // path of the file or directory in the destination
+	From           string `json:"from,omitempty"`           // path of the file being renamed from
+	Reason         string `json:"reason"`                   // why the operation is needed
+	Size           int64  `json:"size"`                     // size of the source or the file being deleted
+	SrcFingerprint string `json:"srcFingerprint,omitempty"` // fingerprint of the source file
+	DstFingerprint string `json:"dstFingerprint,omitempty"` // fingerprint of the destination file
+}
+
+// Plan is a list of the operations a sync intends to do
+type Plan struct {
+	Src     string     `json:"src"`     // source of the sync
+	Dst     string     `json:"dst"`     // destination of the sync
+	Created time.Time  `json:"created"` // when the plan was made
+	Items   []PlanItem `json:"items"`   // the operations in the order they were planned
+	mu      sync.Mutex
+}
+
+// fingerprint returns the fingerprint of o or "" if o is nil
+func fingerprint(ctx context.Context, o fs.ObjectInfo) string {
+	if o == nil {
+		return ""
+	}
+	return fs.Fingerprint(ctx, o, true)
+}
+
+// add an item to the plan
+//
+// It is safe to call on a nil plan.
+func (p *Plan) add(item PlanItem) {
+	if p == nil {
+		return
+	}
+	fs.Infof(item.Path, "Planned %s: %s", item.Op, item.Reason)
+	p.mu.Lock()
+	p.Items = append(p.Items, item)
+	p.mu.Unlock()
+}
+
+// transfer records a copy or update of src over dst
+func (p *Plan) transfer(ctx context.Context, dst, src fs.Object) {
+	item := PlanItem{
+		Op:             PlanCopy,
+		Path:           src.Remote(),
+		Reason:         "not in destination",
+		Size:           src.Size(),
+		SrcFingerprint: fingerprint(ctx, src),
+	}
+	if dst != nil {
+		item.Op = PlanUpdate
+		item.DstFingerprint = fingerprint(ctx, dst)
+		if dst.Size() != src.Size() {
+			item.Reason = "sizes differ"
+		} else {
+			item.Reason = "modification times or hashes differ"
+		}
+	}
+	p.add(item)
+}
+
+// rename records a rename of dst to match src
+func (p *Plan) rename(ctx context.Context, dst, src fs.Object) {
+	p.add(PlanItem{
+		Op:             PlanRename,
+		Path:           src.Remote(),
+		From:           dst.Remote(),
+		Reason:         "renamed in source",
+		Size:           src.Size(),
+		SrcFingerprint: fingerprint(ctx, src),
+		DstFingerprint: fingerprint(ctx, dst),
+	})
+}
+
+// deletes records the objects read from in as being deleted
+func (p *Plan) deletes(ctx context.Context, in fs.ObjectsChan) error {
+	for o := range in {
+		p.add(PlanItem{
+			Op:             PlanDelete,
+			Path:           o.Remote(),
+			Reason:         "not in source",
+			Size:           o.Size(),
+			DstFingerprint: fingerprint(ctx, o),
+		})
+	}
+	return nil
+}
+
+// dirs records op on the directories in entriesMap
+//
+// mkdir is planned shortest path first and rmdir longest first.
+func (p *Plan) dirs(op string, entriesMap map[string]fs.DirEntry) {
+	var entries fs.DirEntries
+	for _, entry := range entriesMap {
+		if _, ok := entry.(fs.Directory); ok {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Sort(entries)
+	reason := "empty directory in source"
+	if op == PlanRmdir {
+		reason = "not in source"
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	for _, entry := range entries {
+		p.add(PlanItem{
+			Op:     op,
+			Path:   entry.Remote(),
+			Reason: reason,
+		})
+	}
+}
+
+// errPlanReadOnly is returned if something tries to modify an object
+// while a plan is being made
+var errPlanReadOnly = errors.New("can't modify the destination while making a plan")
+
+// planObject stops an object in the destination being changed while
+// the plan is made, for example by the modification time being
+// updated when comparing it with the source.
+type planObject struct {
+	fs.Object
+}
+
+// SetModTime refuses to set the modification time so the file is
+// planned to be updated instead
+func (o planObject) SetModTime(ctx context.Context, t time.Time) error {
+	return fs.ErrorCantSetModTime
+}
+
+// Update refuses to change the object
+func (o planObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return errPlanReadOnly
+}
+
+// Remove refuses to remove the object
+func (o planObject) Remove(ctx context.Context) error {
+	return errPlanReadOnly
+}
+
+// UnWrap returns the wrapped Object
+func (o planObject) UnWrap() fs.Object {
+	return o.Object
+}
+
+// SyncPlan works out what Sync would do to make fdst the same as
+// fsrc without changing anything and writes it as a JSON Plan to out.
+//
+// The plan can be carried out later with Apply.
+func SyncPlan(ctx context.Context, fdst, fsrc fs.Fs, copyEmptySrcDirs bool, out io.Writer) error {
+	if fs.Config.BackupDir != "" || fs.Config.Suffix != "" || fs.Config.CopyDest != "" || fs.Config.Journal != "" {
+		return fserrors.FatalError(errors.New("can't make a plan with --backup-dir, --suffix, --copy-dest or --journal"))
+	}
+	p := &Plan{
+		Src:     fs.ConfigString(fsrc),
+		Dst:     fs.ConfigString(fdst),
+		Created: time.Now(),
+		Items:   []PlanItem{},
+	}
+	err := runSyncCopyMovePlan(ctx, fdst, fsrc, fs.Config.DeleteMode, false, false, copyEmptySrcDirs, p)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	err = encoder.Encode(p)
+	if err != nil {
+		return errors.Wrap(err, "failed to write plan")
+	}
+	fs.Logf(nil, "Planned %d operations", len(p.Items))
+	return nil
+}
+
+// ReadPlan reads a JSON Plan from in
+func ReadPlan(in io.Reader) (*Plan, error) {
+	p := new(Plan)
+	err := json.NewDecoder(in).Decode(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read plan")
+	}
+	return p, nil
+}
+
+// planApplier carries out a Plan
+type planApplier struct {
+	ctx  context.Context
+	fdst fs.Fs
+	fsrc fs.Fs
+}
+
+// lookup finds remote in f returning nil if not found
+func (a *planApplier) lookup(f fs.Fs, remote string) (fs.Object, error) {
+	o, err := f.NewObject(a.ctx, remote)
+	if err == fs.ErrorObjectNotFound || err == fs.ErrorNotAFile {
+		return nil, nil
+	}
+	return o, err
+}
+
+// check finds remote in f and checks it has the fingerprint want,
+// where "" means it shouldn't exist
+func (a *planApplier) check(f fs.Fs, remote string, want string) (fs.Object, error) {
+	o, err := a.lookup(f, remote)
+	if err != nil {
+		return nil, err
+	}
+	if got := fingerprint(a.ctx, o); got != want {
+		if want == "" {
+			return nil, errors.Errorf("refusing as %q has appeared in %v since the plan was made", remote, f)
+		}
+		return nil, errors.Errorf("refusing as %q in %v has changed since the plan was made", remote, f)
+	}
+	return o, nil
+}
+
+// apply carries out a single item
+func (a *planApplier) apply(item PlanItem) (err error) {
+	switch item.Op {
+	case PlanCopy, PlanUpdate:
+		src, err := a.check(a.fsrc, item.Path, item.SrcFingerprint)
+		if err != nil {
+			return err
+		}
+		dst, err := a.check(a.fdst, item.Path, item.DstFingerprint)
+		if err != nil {
+			return err
+		}
+		if src == nil {
+			return errors.Errorf("refusing as %q isn't in the source", item.Path)
+		}
+		_, err = operations.Copy(a.ctx, a.fdst, dst, item.Path, src)
+		return err
+	case PlanDelete:
+		dst, err := a.check(a.fdst, item.Path, item.DstFingerprint)
+		if err != nil || dst == nil {
+			return err
+		}
+		return operations.DeleteFile(a.ctx, dst)
+	case PlanRename:
+		_, err := a.check(a.fsrc, item.Path, item.SrcFingerprint)
+		if err != nil {
+			return err
+		}
+		dst, err := a.check(a.fdst, item.From, item.DstFingerprint)
+		if err != nil {
+			return err
+		}
+		if dst == nil {
+			return errors.Errorf("refusing as %q isn't in the destination", item.From)
+		}
+		existing, err := a.lookup(a.fdst, item.Path)
+		if err != nil {
+			return err
+		}
+		_, err = operations.Move(a.ctx, a.fdst, existing, item.Path, dst)
+		return err
+	case PlanMkdir:
+		return operations.Mkdir(a.ctx, a.fdst, item.Path)
+	case PlanRmdir:
+		err := operations.TryRmdir(a.ctx, a.fdst, item.Path)
+		if err != nil {
+			fs.Debugf(fs.LogDirName(a.fdst, item.Path), "Failed to Rmdir: %v", err)
+		}
+		return nil
+	}
+	return errors.Errorf("unknown plan operation %q", item.Op)
+}
+
+// applyAll applies the items which have an Op in ops using up to
+// --transfers at once, returning the number which failed
+func (a *planApplier) applyAll(items []PlanItem, ops ...string) (errorCount int) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		tokens = make(chan struct{}, fs.Config.Transfers)
+	)
+	for _, item := range items {
+		item := item
+		wanted := false
+		for _, op := range ops {
+			wanted = wanted || item.Op == op
+		}
+		if !wanted || a.ctx.Err() != nil {
+			continue
+		}
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			err := a.apply(item)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(item.Path, "Failed to %s: %v", item.Op, err)
+				mu.Lock()
+				errorCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errorCount
+}
+
+// Apply carries out the operations in p.
+//
+// Before each operation the fingerprints of the source and
+// destination files are checked against those in the plan, and the
+// operation is refused if they have changed.
+//
+// Renames are done first, then copies and updates, then deletes if
+// there were no errors (unless --ignore-errors is set), then
+// directories are made and removed.
+func Apply(ctx context.Context, p *Plan) error {
+	fdst, err := cache.Get(p.Dst)
+	if err != nil {
+		return errors.Wrap(err, "failed to make fs for destination")
+	}
+	fsrc, err := cache.Get(p.Src)
+	if err != nil {
+		return errors.Wrap(err, "failed to make fs for source")
+	}
+	fs.Infof(fdst, "Applying %d operations planned at %v", len(p.Items), p.Created)
+	a := &planApplier{
+		ctx:  ctx,
+		fdst: fdst,
+		fsrc: fsrc,
+	}
+	errorCount := a.applyAll(p.Items, PlanRename)
+	errorCount += a.applyAll(p.Items, PlanCopy, PlanUpdate)
+	if (errorCount > 0 || accounting.Stats(ctx).Errored()) && !fs.Config.IgnoreErrors {
+		fs.Errorf(fdst, "%v", fs.ErrorNotDeleting)
+	} else {
+		errorCount += a.applyAll(p.Items, PlanDelete)
+	}
+	// Directories are done in plan order one at a time
+	for _, item := range p.Items {
+		if item.Op == PlanMkdir || item.Op == PlanRmdir {
+			errorCount += a.applyAll([]PlanItem{item}, item.Op)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to apply %d operations", errorCount)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Test sync plans
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncPlanAndApply(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteFile("new", "new file", t1)
+	file2 := r.WriteFile("sub dir/changed", "changed file", t2)
+	r.WriteObject(ctx, "sub dir/changed", "old", t1)
+	r.WriteObject(ctx, "deleted/file", "deleted file", t1)
+	file4 := r.WriteBoth(ctx, "same", "same file", t1)
+	file5 := r.WriteFile("touched", "touched file", t2)
+	r.WriteObject(ctx, "touched", "touched file", t1)
+
+	var buf bytes.Buffer
+	err := SyncPlan(ctx, r.Fremote, r.Flocal, false, &buf)
+	require.NoError(t, err)
+
+	// Nothing should have changed
+	fstest.CheckItems(t, r.Fremote,
+		fstest.NewItem("sub dir/changed", "old", t1),
+		fstest.NewItem("deleted/file", "deleted file", t1),
+		file4,
+		fstest.NewItem("touched", "touched file", t1),
+	)
+
+	p, err := ReadPlan(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, fs.ConfigString(r.Flocal), p.Src)
+	assert.Equal(t, fs.ConfigString(r.Fremote), p.Dst)
+	var ops []string
+	for _, item := range p.Items {
+		ops = append(ops, item.Op+" "+item.Path)
+		switch item.Op {
+		case PlanCopy:
+			assert.NotEqual(t, "", item.SrcFingerprint)
+			assert.Equal(t, "", item.DstFingerprint)
+		case PlanUpdate:
+			assert.NotEqual(t, "", item.SrcFingerprint)
+			assert.NotEqual(t, "", item.DstFingerprint)
+			if item.Path == "touched" {
+				assert.Equal(t, "modification times or hashes differ", item.Reason)
+			} else {
+				assert.Equal(t, "sizes differ", item.Reason)
+			}
+		case PlanDelete:
+			assert.NotEqual(t, "", item.DstFingerprint)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"copy new",
+		"update sub dir/changed",
+		"update touched",
+		"delete deleted/file",
+		"rmdir deleted",
+	}, ops)
+
+	err = Apply(ctx, p)
+	require.NoError(t, err)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, file2, file4, file5}, []string{"sub dir"}, fs.GetModifyWindow(r.Fremote))
+}
+
+func TestApplyRefusesChanged(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteFile("one", "one", t1)
+	r.WriteFile("two", "two", t1)
+	r.WriteObject(ctx, "three", "three", t1)
+	r.Mkdir(ctx, r.Fremote)
+
+	var buf bytes.Buffer
+	err := SyncPlan(ctx, r.Fremote, r.Flocal, false, &buf)
+	require.NoError(t, err)
+	p, err := ReadPlan(&buf)
+	require.NoError(t, err)
+	require.Len(t, p.Items, 3)
+
+	// Change the source of one copy and the file to be deleted
+	file2 := r.WriteFile("two", "two changed", t2)
+	file3b := r.WriteObject(ctx, "three", "three changed", t2)
+
+	accounting.GlobalStats().ResetCounters()
+	err = Apply(ctx, p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to apply 1 operations")
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	// deletes are skipped as there were errors
+	fstest.CheckItems(t, r.Fremote, file1, file3b)
+	accounting.GlobalStats().ResetCounters()
+}
@@ -68,6 +68,8 @@ type syncCopyMove struct {
 	renameCheck            []fs.Object            // accumulate files to check for rename here
 	compareCopyDest        fs.Fs                  // place to check for files to server side copy
 	backupDir              fs.Fs                  // place to store overwrites/deletes
+	journal                *journal               // journal of changes if --journal is set
+	plan                   *Plan                  // if set record what would be done here instead of doing it
 	checkFirst             bool                   // if set run all the checkers before starting transfers
 }
 
@@ -213,6 +215,16 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	return s, nil
 }
 
+// setJournal records changes in j and moves overwritten and deleted
+// files into it
+func (s *syncCopyMove) setJournal(j *journal) {
+	if j == nil {
+		return
+	}
+	s.journal = j
+	s.backupDir = j.files
+}
+
 // Check to see if the context has been cancelled
 func (s *syncCopyMove) aborting() bool {
 	return s.ctx.Err() != nil
@@ -289,6 +301,10 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 		src := pair.Src
 		var err error
 		tr := accounting.Stats(s.ctx).NewCheckingTransfer(src)
+		// Don't let the checks change the destination when planning
+		if s.plan != nil && pair.Dst != nil {
+			pair.Dst = planObject{Object: pair.Dst}
+		}
 		// Check to see if can store this
 		if src.Storable() {
 			NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, pair.Dst, pair.Src, s.compareCopyDest, s.backupDir)
@@ -307,6 +323,7 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 						if err != nil {
 							s.processError(err)
 						} else {
+							s.processError(s.journal.add(journalOverwrite, pair.Dst, ""))
 							// If successful zero out the dst as it is no longer there and copy the file
 							pair.Dst = nil
 							ok = out.Put(s.ctx, pair)
@@ -325,7 +342,11 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 				// If moving need to delete the files we don't need to copy
 				if s.DoMove {
 					// Delete src if no error on copy
-					s.processError(operations.DeleteFile(s.ctx, src))
+					err := operations.DeleteFile(s.ctx, src)
+					if err == nil {
+						err = s.journal.add(journalSrcDelete, src, "")
+					}
+					s.processError(err)
 				}
 			}
 		}
@@ -363,10 +384,18 @@ func (s *syncCopyMove) pairCopyOrMove(ctx context.Context, in *pipe, fdst fs.Fs,
 			return
 		}
 		src := pair.Src
-		if s.DoMove {
+		if s.plan != nil {
+			s.plan.transfer(ctx, pair.Dst, src)
+		} else if s.DoMove {
 			_, err = operations.Move(ctx, fdst, pair.Dst, src.Remote(), src)
+			if err == nil {
+				err = s.journal.add(journalMove, src, "")
+			}
 		} else {
 			_, err = operations.Copy(ctx, fdst, pair.Dst, src.Remote(), src)
+			if err == nil {
+				err = s.journal.add(journalCopy, src, "")
+			}
 		}
 		s.processError(err)
 	}
@@ -457,7 +486,11 @@ func (s *syncCopyMove) startDeleters() {
 	s.deletersWg.Add(1)
 	go func() {
 		defer s.deletersWg.Done()
-		err := operations.DeleteFilesWithBackupDir(s.ctx, s.deleteFilesCh, s.backupDir)
+		if s.plan != nil {
+			s.processError(s.plan.deletes(s.ctx, s.deleteFilesCh))
+			return
+		}
+		err := s.journal.deleteFiles(s.ctx, s.deleteFilesCh, s.backupDir)
 		s.processError(err)
 	}()
 }
@@ -504,7 +537,10 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 		}
 		close(toDelete)
 	}()
-	return operations.DeleteFilesWithBackupDir(s.ctx, toDelete, s.backupDir)
+	if s.plan != nil {
+		return s.plan.deletes(s.ctx, toDelete)
+	}
+	return s.journal.deleteFiles(s.ctx, toDelete, s.backupDir)
 }
 
 // This deletes the empty directories in the slice passed in.  It
@@ -751,15 +787,36 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 		return false
 	}
 
+	// Record the rename in the plan instead of doing it
+	if s.plan != nil {
+		s.plan.rename(s.ctx, dst, src)
+		s.dstFilesMu.Lock()
+		delete(s.dstFiles, dst.Remote())
+		s.dstFilesMu.Unlock()
+		return true
+	}
+
 	// Find dst object we are about to overwrite if it exists
 	dstOverwritten, _ := s.fdst.NewObject(s.ctx, src.Remote())
 
+	// Move it into the journal so it can be restored
+	if dstOverwritten != nil && s.journal != nil {
+		err := operations.MoveBackupDir(s.ctx, s.backupDir, dstOverwritten)
+		if err != nil {
+			fs.Debugf(src, "Failed to move %q to journal before rename: %v", src.Remote(), err)
+			return false
+		}
+		s.processError(s.journal.add(journalOverwrite, dstOverwritten, ""))
+		dstOverwritten = nil
+	}
+
 	// Rename dst to have name src.Remote()
 	_, err := operations.Move(s.ctx, s.fdst, dstOverwritten, src.Remote(), dst)
 	if err != nil {
 		fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
 		return false
 	}
+	s.processError(s.journal.add(journalRename, src, dst.Remote()))
 
 	// remove file from dstFiles if present
 	s.dstFilesMu.Lock()
@@ -832,7 +889,11 @@ func (s *syncCopyMove) run() error {
 	s.stopDeleters()
 
 	if s.copyEmptySrcDirs {
-		s.processError(copyEmptyDirectories(s.ctx, s.fdst, s.srcEmptyDirs))
+		if s.plan != nil {
+			s.plan.dirs(PlanMkdir, s.srcEmptyDirs)
+		} else {
+			s.processError(copyEmptyDirectories(s.ctx, s.fdst, s.srcEmptyDirs))
+		}
 	}
 
 	// Delete files after
@@ -848,6 +909,8 @@ func (s *syncCopyMove) run() error {
 	if s.deleteMode != fs.DeleteModeOff {
 		if s.currentError() != nil && !fs.Config.IgnoreErrors {
 			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeletingDirs)
+		} else if s.plan != nil {
+			s.plan.dirs(PlanRmdir, s.dstEmptyDirs)
 		} else {
 			s.processError(deleteEmptyDirectories(s.ctx, s.fdst, s.dstEmptyDirs))
 		}
@@ -1014,10 +1077,37 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 // If DoMove is true then files will be moved instead of copied
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (err error) {
+	return runSyncCopyMovePlan(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs, nil)
+}
+
+// As runSyncCopyMove but if p is set then the operations which would
+// be done are recorded in p instead of being done
+func runSyncCopyMovePlan(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool, p *Plan) (err error) {
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
+	if p != nil && DoMove {
+		return fserrors.FatalError(errors.New("can't make a plan for a move"))
+	}
+	// Make the journal if required - this is shared by both passes
+	var j *journal
+	if fs.Config.Journal != "" && p == nil {
+		if fs.Config.DryRun {
+			fs.Logf(nil, "Not writing journal as --dry-run is set")
+		} else {
+			j, err = getJournal(fdst, fsrc)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				finishErr := j.finish(ctx, err)
+				if err == nil {
+					err = finishErr
+				}
+			}()
+		}
+	}
 	// Run an extra pass to delete only
 	if deleteMode == fs.DeleteModeBefore {
 		if fs.Config.TrackRenames {
@@ -1028,6 +1118,8 @@ func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		if err != nil {
 			return err
 		}
+		do.setJournal(j)
+		do.plan = p
 		err = do.run()
 		if err != nil {
 			return err
@@ -1039,6 +1131,8 @@ func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	if err != nil {
 		return err
 	}
+	do.setJournal(j)
+	do.plan = p
 	return do.run()
 }
 
@@ -1064,8 +1158,8 @@ func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 		return nil
 	}
 
-	// First attempt to use DirMover if exists, same Fs and no filters are active
-	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() {
+	// First attempt to use DirMover if exists, same Fs, no filters are active and no journal is needed
+	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() && fs.Config.Journal == "" {
 		if operations.SkipDestructive(ctx, fdst, "server side directory move") {
 			return nil
 		}
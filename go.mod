@@ -29,7 +29,6 @@ require (
 	github.com/iikira/Baidu-Login v1.2.2 // indirect
 	github.com/iikira/BaiduPCS-Go v3.5.6+incompatible
 	github.com/iikira/baidu-tools v0.0.0-20190609113215-4dd64618064d
-	github.com/jlaffaye/ftp v0.0.0-20200720194710-13949d38913e
 	github.com/jzelinskie/whirlpool v0.0.0-20170603002051-c19460b8caa6
 	github.com/kardianos/osext v0.0.0-20190222173326-2bc1f35cddc0 // indirect
 	github.com/koofr/go-httpclient v0.0.0-20200420163713-93aa7c75b348
@@ -68,7 +67,6 @@ require (
 	go.etcd.io/bbolt v1.3.5
 	go.opencensus.io v0.22.4 // indirect
 	go.uber.org/zap v1.15.0 // indirect
-	goftp.io/server v0.4.0
 	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899
 	golang.org/x/net v0.0.0-20200707034311-ab3426394381
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
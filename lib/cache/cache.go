@@ -13,8 +13,9 @@ type Cache struct {
 	mu             sync.Mutex
 	cache          map[string]*cacheEntry
 	expireRunning  bool
-	expireDuration time.Duration // expire the cache entry when it is older than this
-	expireInterval time.Duration // interval to run the cache expire
+	expireDuration time.Duration           // expire the cache entry when it is older than this
+	expireInterval time.Duration           // interval to run the cache expire
+	finalize       func(value interface{}) // if set called with values removed from the cache
 }
 
 // New creates a new cache with the default expire duration and interval
@@ -36,6 +37,26 @@ type cacheEntry struct {
 	pinCount int         // non zero if the entry should not be removed
 }
 
+// SetFinalizer sets a function to be called with each value when it
+// is removed from the cache by expiry or Clear.
+func (c *Cache) SetFinalizer(finalize func(value interface{})) {
+	c.mu.Lock()
+	c.finalize = finalize
+	c.mu.Unlock()
+}
+
+// finalizeAll calls the finalizer on the entries removed
+//
+// call without the lock held
+func finalizeAll(finalize func(value interface{}), removed []*cacheEntry) {
+	if finalize == nil {
+		return
+	}
+	for _, entry := range removed {
+		finalize(entry.value)
+	}
+}
+
 // CreateFunc is called to create new values.  If the create function
 // returns an error it will be cached if ok is true, otherwise the
 // error will just be returned, allowing negative caching if required.
@@ -143,12 +164,13 @@ func (c *Cache) Rename(oldKey, newKey string) (value interface{}, found bool) {
 
 // cacheExpire expires any entries that haven't been used recently
 func (c *Cache) cacheExpire() {
+	var removed []*cacheEntry
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	now := time.Now()
 	for key, entry := range c.cache {
 		if entry.pinCount <= 0 && now.Sub(entry.lastUsed) > c.expireDuration {
 			delete(c.cache, key)
+			removed = append(removed, entry)
 		}
 	}
 	if len(c.cache) != 0 {
@@ -157,15 +179,22 @@ func (c *Cache) cacheExpire() {
 	} else {
 		c.expireRunning = false
 	}
+	finalize := c.finalize
+	c.mu.Unlock()
+	finalizeAll(finalize, removed)
 }
 
 // Clear removes everything from the cache
 func (c *Cache) Clear() {
+	var removed []*cacheEntry
 	c.mu.Lock()
-	for k := range c.cache {
+	for k, entry := range c.cache {
 		delete(c.cache, k)
+		removed = append(removed, entry)
 	}
+	finalize := c.finalize
 	c.mu.Unlock()
+	finalizeAll(finalize, removed)
 }
 
 // Entries returns the number of entries in the cache
@@ -125,6 +125,33 @@ func TestCacheExpire(t *testing.T) {
 	c.mu.Unlock()
 }
 
+func TestCacheFinalize(t *testing.T) {
+	c, create := setup(t)
+	var finalized []interface{}
+	c.SetFinalizer(func(value interface{}) {
+		finalized = append(finalized, value)
+	})
+
+	_, err := c.Get("/", create)
+	require.NoError(t, err)
+
+	// Not expired yet
+	c.cacheExpire()
+	assert.Equal(t, 0, len(finalized))
+
+	c.mu.Lock()
+	c.cache["/"].lastUsed = time.Now().Add(-c.expireDuration - 60*time.Second)
+	c.mu.Unlock()
+	c.cacheExpire()
+	assert.Equal(t, []interface{}{"/"}, finalized)
+
+	// Clear finalizes too
+	finalized = nil
+	c.Put("a", "A")
+	c.Clear()
+	assert.Equal(t, []interface{}{"A"}, finalized)
+}
+
 func TestCachePin(t *testing.T) {
 	c, create := setup(t)
 
@@ -0,0 +1,8 @@
+// Package diskusage reads the free space on a file system
+package diskusage
+
+import "errors"
+
+// ErrUnsupported is returned if reading the free space isn't
+// supported on this OS
+var ErrUnsupported = errors.New("reading free disk space not supported on this OS")
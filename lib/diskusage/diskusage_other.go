@@ -0,0 +1,9 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!windows
+
+package diskusage
+
+// Free returns the number of bytes free for an unprivileged user in
+// the file system containing dir
+func Free(dir string) (int64, error) {
+	return 0, ErrUnsupported
+}
@@ -0,0 +1,27 @@
+package diskusage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-diskusage-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	free, err := Free(dir)
+	if err == ErrUnsupported {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	assert.True(t, free > 0)
+
+	_, err = Free(dir + "/notfound")
+	assert.Error(t, err)
+}
@@ -0,0 +1,18 @@
+// +build darwin dragonfly freebsd linux
+
+package diskusage
+
+import (
+	"syscall"
+)
+
+// Free returns the number of bytes free for an unprivileged user in
+// the file system containing dir
+func Free(dir string) (int64, error) {
+	var s syscall.Statfs_t
+	err := syscall.Statfs(dir, &s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(s.Bsize) * int64(s.Bavail), nil // nolint: unconvert
+}
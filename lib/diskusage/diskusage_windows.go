@@ -0,0 +1,27 @@
+// +build windows
+
+package diskusage
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// Free returns the number of bytes free for this user in the file
+// system containing dir
+func Free(dir string) (int64, error) {
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	err = windows.GetDiskFreeSpaceEx(
+		dirPtr,
+		&available, // lpFreeBytesAvailable - for this user
+		&total,     // lpTotalNumberOfBytes
+		&free,      // lpTotalNumberOfFreeBytes
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int64(available), nil
+}
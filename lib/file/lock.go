@@ -0,0 +1,36 @@
+package file
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Lock takes an exclusive lock on the file at path, creating it if
+// necessary, waiting until any other holder has released it.
+//
+// The lock is advisory and is held by the open file so it excludes
+// other rclone processes as well as other callers in this one. Lock
+// a separate file rather than the data file if the data file is
+// replaced by renaming.
+//
+// Call the returned function to release the lock.
+func Lock(path string) (unlock func() error, err error) {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open lock file")
+	}
+	err = lockFile(fd)
+	if err != nil {
+		_ = fd.Close()
+		return nil, errors.Wrapf(err, "failed to lock %q", path)
+	}
+	return func() error {
+		err := unlockFile(fd)
+		closeErr := fd.Close()
+		if err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
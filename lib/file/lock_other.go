@@ -0,0 +1,19 @@
+//+build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package file
+
+import "os"
+
+// LockImplemented is a constant indicating whether the
+// implementation of Lock actually excludes other processes.
+const LockImplemented = false
+
+// lockFile does nothing on this platform
+func lockFile(fd *os.File) error {
+	return nil
+}
+
+// unlockFile does nothing on this platform
+func unlockFile(fd *os.File) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	if !LockImplemented {
+		t.Skip("Lock not implemented on this platform")
+	}
+	dir, tidy := testDir(t)
+	defer tidy()
+	path := filepath.Join(dir, "file.lock")
+
+	unlock, err := Lock(path)
+	require.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlock2, err := Lock(path)
+		assert.NoError(t, err)
+		close(locked)
+		assert.NoError(t, unlock2())
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("second lock taken while first held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, unlock())
+	select {
+	case <-locked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("second lock not taken after first released")
+	}
+}
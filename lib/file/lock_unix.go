@@ -0,0 +1,28 @@
+//+build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package file
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockImplemented is a constant indicating whether the
+// implementation of Lock actually excludes other processes.
+const LockImplemented = true
+
+// lockFile takes an exclusive lock on fd waiting until it is free
+func lockFile(fd *os.File) error {
+	for {
+		err := unix.Flock(int(fd.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFile releases the lock on fd
+func unlockFile(fd *os.File) error {
+	return unix.Flock(int(fd.Fd()), unix.LOCK_UN)
+}
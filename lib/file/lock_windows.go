@@ -0,0 +1,25 @@
+//+build windows
+
+package file
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockImplemented is a constant indicating whether the
+// implementation of Lock actually excludes other processes.
+const LockImplemented = true
+
+// lockFile takes an exclusive lock on fd waiting until it is free
+func lockFile(fd *os.File) error {
+	ol := new(windows.Overlapped)
+	return windows.LockFileEx(windows.Handle(fd.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, ol)
+}
+
+// unlockFile releases the lock on fd
+func unlockFile(fd *os.File) error {
+	ol := new(windows.Overlapped)
+	return windows.UnlockFileEx(windows.Handle(fd.Fd()), 0, 1, 0, ol)
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 The goftp Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+Package ftpserver is a fork of goftp.io/server/core v0.4.1 for rclone
+serve ftp.
+
+It has these changes to the original:
+
+  - ServerOpts.Commands adds commands to the server or replaces the
+    built in ones, which BuiltinCommand returns
+  - Conn has Driver, SessionID, Features, WriteMessage,
+    WriteMessageLines, BuildPath and SendOutofbandData for commands
+    made outside the package
+  - a Driver implementing DriverCloser is closed when its connection
+    ends
+  - ServerOpts.ForceTLS is copied by NewServer
+  - connections made with implicit TLS are marked as using TLS so
+    PBSZ and PROT work and the data connections are encrypted
+  - the FEAT response is sorted
+
+http://tools.ietf.org/html/rfc959
+
+http://www.faqs.org/rfcs/rfc2389.html
+http://www.faqs.org/rfcs/rfc959.html
+
+http://tools.ietf.org/html/rfc2428
+*/
+package ftpserver
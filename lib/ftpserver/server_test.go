@@ -0,0 +1,88 @@
+package ftpserver
+
+import (
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver is a Driver which records when it is closed
+type testDriver struct {
+	Driver
+	closed chan struct{}
+}
+
+func (d *testDriver) Close() error {
+	close(d.closed)
+	return nil
+}
+
+// testFactory makes a testDriver
+type testFactory struct {
+	d *testDriver
+}
+
+func (f testFactory) NewDriver() (Driver, error) {
+	return f.d, nil
+}
+
+// commandHello responds with the path given between two lines
+type commandHello struct{}
+
+func (cmd commandHello) IsExtend() bool     { return true }
+func (cmd commandHello) RequireParam() bool { return true }
+func (cmd commandHello) RequireAuth() bool  { return false }
+
+func (cmd commandHello) Execute(conn *Conn, param string) {
+	conn.WriteMessageLines(250, "Hello", conn.BuildPath(param), "End")
+}
+
+func TestServerHooks(t *testing.T) {
+	d := &testDriver{closed: make(chan struct{})}
+	server := NewServer(&ServerOpts{
+		Factory:  testFactory{d},
+		Logger:   &DiscardLogger{},
+		Commands: map[string]Command{"hello": commandHello{}},
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Shutdown()
+	}()
+
+	nc, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	c := textproto.NewConn(nc)
+	defer func() {
+		_ = c.Close()
+	}()
+	_, _, err = c.ReadResponse(220)
+	require.NoError(t, err)
+
+	cmd := func(expected int, command string) string {
+		_, err := c.Cmd("%s", command)
+		require.NoError(t, err)
+		_, message, err := c.ReadResponse(expected)
+		require.NoError(t, err, command)
+		return message
+	}
+
+	// Extra commands are run and listed in FEAT
+	assert.Equal(t, "Hello\n /files/a.txt\nEnd", cmd(250, "HELLO files/a.txt"))
+	assert.Contains(t, cmd(211, "FEAT"), "\n HELLO\n")
+
+	// The driver is closed when the connection ends
+	cmd(221, "QUIT")
+	select {
+	case <-d.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("driver not closed")
+	}
+}
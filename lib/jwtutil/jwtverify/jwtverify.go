@@ -0,0 +1,421 @@
+// Package jwtverify verifies JSON Web Tokens using the keys from a
+// JSON Web Key Set (JWKS) as issued by OpenID Connect providers.
+//
+// It is separate from jwtutil so it can be used by packages which
+// can't depend on the config system.
+package jwtverify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // for crypto.SHA256
+	_ "crypto/sha512" // for crypto.SHA384 and crypto.SHA512
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// minRefresh is the minimum time between reloads of the key set
+// when a token with an unknown key ID is seen
+const minRefresh = time.Minute
+
+// Options for the Verifier
+type Options struct {
+	JWKS     string        // file name or http(s) URL of the JSON Web Key Set
+	Issuer   string        // the "iss" claim must be this
+	Audience string        // the "aud" claim must contain this
+	Leeway   time.Duration // allowed clock skew when checking times
+}
+
+// Verifier checks tokens against the keys in a JSON Web Key Set
+type Verifier struct {
+	opt    Options
+	client *http.Client
+	mu     sync.Mutex
+	keys   []*key    // keys currently in use
+	loaded time.Time // when the keys were last loaded
+}
+
+// key is a public key read from the JWKS
+type key struct {
+	id        string
+	publicKey crypto.PublicKey
+}
+
+// Claims are the claims in a verified token
+type Claims map[string]interface{}
+
+// String returns the claim called name if it is a string
+func (c Claims) String(name string) (value string, ok bool) {
+	value, ok = c[name].(string)
+	return value, ok
+}
+
+// New makes a Verifier from opt and loads the keys
+//
+// The issuer and audience must be set as without them any token
+// signed by the keys would be accepted, including ones the issuer
+// made for other services.
+func New(opt *Options) (*Verifier, error) {
+	if opt.JWKS == "" {
+		return nil, errors.New("jwtverify: no JWKS supplied")
+	}
+	if opt.Issuer == "" || opt.Audience == "" {
+		return nil, errors.New("jwtverify: issuer and audience must be set")
+	}
+	v := &Verifier{
+		opt: *opt,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+	err := v.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// isURL returns true if the JWKS should be fetched with http
+func (v *Verifier) isURL() bool {
+	return strings.HasPrefix(v.opt.JWKS, "http://") || strings.HasPrefix(v.opt.JWKS, "https://")
+}
+
+// read reads the raw JWKS
+func (v *Verifier) read() (data []byte, err error) {
+	if !v.isURL() {
+		return ioutil.ReadFile(v.opt.JWKS)
+	}
+	resp, err := v.client.Get(v.opt.JWKS)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("HTTP error %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Reload reads the keys from the JWKS again
+func (v *Verifier) Reload() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.reload()
+}
+
+// reload reads the keys - call with mu held
+func (v *Verifier) reload() error {
+	v.loaded = time.Now()
+	data, err := v.read()
+	if err != nil {
+		return errors.Wrapf(err, "jwtverify: failed to read JWKS %q", v.opt.JWKS)
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return errors.Wrapf(err, "jwtverify: failed to parse JWKS %q", v.opt.JWKS)
+	}
+	v.keys = keys
+	fs.Debugf(nil, "jwtverify: loaded %d keys from %q", len(keys), v.opt.JWKS)
+	return nil
+}
+
+// jsonWebKey is a single key in a JWKS
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseKeySet reads the signing keys from a JWKS ignoring any key
+// types which aren't supported
+func parseKeySet(data []byte) (keys []*key, err error) {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = json.Unmarshal(data, &keySet)
+	if err != nil {
+		return nil, err
+	}
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %q", jwk.Kid)
+		}
+		if publicKey == nil {
+			fs.Debugf(nil, "jwtverify: ignoring key %q with unsupported type %q", jwk.Kid, jwk.Kty)
+			continue
+		}
+		keys = append(keys, &key{
+			id:        jwk.Kid,
+			publicKey: publicKey,
+		})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys found")
+	}
+	return keys, nil
+}
+
+// decodeInt decodes a base64url encoded big endian integer
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the public key for jwk or nil if the key type
+// isn't supported
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad modulus")
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad exponent")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad x")
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad y")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+// findKeys returns the keys which may have signed a token with the
+// key ID kid, reloading the key set if kid isn't known.
+func (v *Verifier) findKeys(kid string) []*key {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	find := func() (keys []*key) {
+		for _, k := range v.keys {
+			if kid == "" || k.id == kid {
+				keys = append(keys, k)
+			}
+		}
+		return keys
+	}
+	keys := find()
+	if len(keys) == 0 && time.Since(v.loaded) >= minRefresh {
+		// The keys may have been rotated so fetch them again
+		err := v.reload()
+		if err != nil {
+			fs.Errorf(nil, "%v", err)
+		}
+		keys = find()
+	}
+	return keys
+}
+
+// verifySignature checks signature over signed with publicKey using alg
+func verifySignature(alg string, publicKey crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported algorithm %q", alg)
+	}
+	hasher := hash.New()
+	_, _ = hasher.Write(signed)
+	digest := hasher.Sum(nil)
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key is not an RSA key")
+		}
+		if alg[0] == 'R' {
+			return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		}
+		return rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+	case "ES":
+		ecKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key is not an EC key")
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("bad signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("bad signature")
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported algorithm %q", alg)
+}
+
+// numericDate reads the claim called name as a time
+func (c Claims) numericDate(name string) (t time.Time, ok bool, err error) {
+	value, ok := c[name]
+	if !ok {
+		return t, false, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return t, false, errors.Errorf("claim %q is not a number", name)
+	}
+	return time.Unix(int64(seconds), 0), true, nil
+}
+
+// hasAudience returns true if the "aud" claim contains audience
+func (c Claims) hasAudience(audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, item := range aud {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Verify checks the signature and the claims of token, returning the
+// claims if it is valid.
+func (v *Verifier) Verify(token string) (claims Claims, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwtverify: token must have 3 parts")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: bad header encoding")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err = json.Unmarshal(headerJSON, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: bad header")
+	}
+	if len(header.Alg) != 5 {
+		return nil, errors.Errorf("jwtverify: unsupported algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: bad signature encoding")
+	}
+
+	// Check the signature with the matching keys
+	keys := v.findKeys(header.Kid)
+	if len(keys) == 0 {
+		return nil, errors.Errorf("jwtverify: no key found with ID %q", header.Kid)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	for _, k := range keys {
+		err = verifySignature(header.Alg, k.publicKey, signed, signature)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: signature check failed")
+	}
+
+	// Read and check the claims
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: bad claims encoding")
+	}
+	err = json.Unmarshal(claimsJSON, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify: bad claims")
+	}
+	err = v.checkClaims(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtverify")
+	}
+	return claims, nil
+}
+
+// checkClaims checks the times, issuer and audience of the claims
+func (v *Verifier) checkClaims(claims Claims) error {
+	now := time.Now()
+	exp, ok, err := claims.numericDate("exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.After(exp.Add(v.opt.Leeway)) {
+		return errors.Errorf("token expired at %v", exp)
+	}
+	nbf, ok, err := claims.numericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Before(nbf.Add(-v.opt.Leeway)) {
+		return errors.Errorf("token not valid until %v", nbf)
+	}
+	if v.opt.Issuer != "" {
+		if iss, _ := claims.String("iss"); iss != v.opt.Issuer {
+			return errors.Errorf("token issuer %q is not %q", iss, v.opt.Issuer)
+		}
+	}
+	if v.opt.Audience != "" && !claims.hasAudience(v.opt.Audience) {
+		return errors.Errorf("token audience %v does not contain %q", claims["aud"], v.opt.Audience)
+	}
+	return nil
+}
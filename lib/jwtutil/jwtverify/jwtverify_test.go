@@ -0,0 +1,232 @@
+package jwtverify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// rsaJWK returns the JWK for the public part of k
+func rsaJWK(kid string, k *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(k.N.Bytes()),
+		"e":   b64(big.NewInt(int64(k.E)).Bytes()),
+	}
+}
+
+// ecJWK returns the JWK for the public part of k
+func ecJWK(kid string, k *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": k.Curve.Params().Name,
+		"x":   b64(k.X.Bytes()),
+		"y":   b64(k.Y.Bytes()),
+	}
+}
+
+// sign makes a token with claims signed by k using alg
+func sign(t *testing.T, alg, kid string, k crypto.Signer, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[2:]]
+	hasher := hash.New()
+	_, _ = hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+	var signature []byte
+	switch key := k.(type) {
+	case *rsa.PrivateKey:
+		if alg[0] == 'P' {
+			signature, err = rsa.SignPSS(rand.Reader, key, hash, digest, nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		}
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		require.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(signature[size-len(rBytes):size], rBytes)
+		copy(signature[2*size-len(sBytes):], sBytes)
+	}
+	return signed + "." + b64(signature)
+}
+
+// writeJWKS writes the keys as a JWKS to path
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+}
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-jwtverify")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	jwksPath := filepath.Join(dir, "jwks.json")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writeJWKS(t, jwksPath, rsaJWK("rsa", rsaKey), ecJWK("ec", ecKey), map[string]string{"kty": "oct", "kid": "hmac"})
+
+	_, err = New(&Options{})
+	assert.Error(t, err)
+	_, err = New(&Options{JWKS: jwksPath, Audience: "rclone"})
+	assert.Error(t, err)
+	_, err = New(&Options{JWKS: jwksPath, Issuer: "https://issuer.example.com"})
+	assert.Error(t, err)
+	_, err = New(&Options{JWKS: filepath.Join(dir, "notfound.json"), Issuer: "https://issuer.example.com", Audience: "rclone"})
+	assert.Error(t, err)
+
+	v, err := New(&Options{
+		JWKS:     jwksPath,
+		Issuer:   "https://issuer.example.com",
+		Audience: "rclone",
+	})
+	require.NoError(t, err)
+	require.Len(t, v.keys, 2)
+
+	now := time.Now().Unix()
+	goodClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "user1",
+			"iss": "https://issuer.example.com",
+			"aud": []string{"other", "rclone"},
+			"exp": now + 3600,
+			"nbf": now - 60,
+		}
+	}
+
+	for _, alg := range []string{"RS256", "RS384", "RS512", "PS256", "PS512"} {
+		claims, err := v.Verify(sign(t, alg, "rsa", rsaKey, goodClaims()))
+		require.NoError(t, err, alg)
+		sub, ok := claims.String("sub")
+		assert.True(t, ok)
+		assert.Equal(t, "user1", sub)
+	}
+	_, err = v.Verify(sign(t, "ES256", "ec", ecKey, goodClaims()))
+	require.NoError(t, err)
+
+	// No kid tries all the keys
+	_, err = v.Verify(sign(t, "ES256", "", ecKey, goodClaims()))
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name  string
+		token string
+	}{
+		{"Garbage", "potato"},
+		{"WrongKey", sign(t, "RS256", "rsa", otherKey, goodClaims())},
+		{"UnknownKid", sign(t, "RS256", "other", otherKey, goodClaims())},
+		{"WrongKeyType", sign(t, "ES256", "rsa", ecKey, goodClaims())},
+		{"Expired", sign(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+			c := goodClaims()
+			c["exp"] = now - 60
+			return c
+		}())},
+		{"NoExpiry", sign(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+			c := goodClaims()
+			delete(c, "exp")
+			return c
+		}())},
+		{"NotYetValid", sign(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+			c := goodClaims()
+			c["nbf"] = now + 3600
+			return c
+		}())},
+		{"WrongIssuer", sign(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+			c := goodClaims()
+			c["iss"] = "https://evil.example.com"
+			return c
+		}())},
+		{"WrongAudience", sign(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+			c := goodClaims()
+			c["aud"] = "other"
+			return c
+		}())},
+	} {
+		_, err := v.Verify(test.token)
+		assert.Error(t, err, test.name)
+	}
+
+	// Tokens using alg none or HMAC are refused
+	header := b64([]byte(`{"alg":"none","kid":"rsa"}`))
+	payload, err := json.Marshal(goodClaims())
+	require.NoError(t, err)
+	_, err = v.Verify(header + "." + b64(payload) + ".")
+	assert.Error(t, err)
+	header = b64([]byte(`{"alg":"HS256","kid":"rsa"}`))
+	_, err = v.Verify(header + "." + b64(payload) + ".c2lnbmF0dXJl")
+	assert.Error(t, err)
+}
+
+func TestVerifyURLReload(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := []map[string]string{rsaJWK("old", oldKey)}
+	fetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": jwks})
+	}))
+	defer ts.Close()
+
+	v, err := New(&Options{JWKS: ts.URL, Issuer: "issuer", Audience: "rclone"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	claims := map[string]interface{}{"sub": "user", "iss": "issuer", "aud": "rclone", "exp": time.Now().Unix() + 3600}
+	_, err = v.Verify(sign(t, "RS256", "old", oldKey, claims))
+	require.NoError(t, err)
+
+	// Rotate the keys - the new key isn't found until the keys are
+	// old enough to be fetched again
+	jwks = []map[string]string{rsaJWK("new", newKey)}
+	newToken := sign(t, "RS256", "new", newKey, claims)
+	_, err = v.Verify(newToken)
+	assert.Error(t, err)
+	assert.Equal(t, 1, fetches)
+
+	v.loaded = time.Now().Add(-minRefresh)
+	_, err = v.Verify(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// Explicit reload
+	require.NoError(t, v.Reload())
+	assert.Equal(t, 3, fetches)
+}
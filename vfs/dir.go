@@ -18,6 +18,7 @@ import (
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdircache"
 )
 
 // Dir represents a directory entry
@@ -137,6 +138,7 @@ func (d *Dir) ForgetAll() (hasVirtual bool) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	fs.Debugf(d.path, "forgetting directory cache")
+	d.vfs.dirCache.ForgetAll(d.path)
 	for _, node := range d.items {
 		if dir, ok := node.(*Dir); ok {
 			if dir.ForgetAll() {
@@ -163,6 +165,7 @@ func (d *Dir) ForgetAll() (hasVirtual bool) {
 //
 // It does not invalidate or clear the cache of the parent directory.
 func (d *Dir) forgetDirPath(relativePath string) {
+	d.vfs.dirCache.ForgetAll(path.Join(d.Path(), relativePath))
 	dir := d.cachedDir(relativePath)
 	if dir == nil {
 		return
@@ -172,6 +175,7 @@ func (d *Dir) forgetDirPath(relativePath string) {
 
 // invalidateDir invalidates the directory cache for absPath relative to the root
 func (d *Dir) invalidateDir(absPath string) {
+	d.vfs.dirCache.Forget(absPath)
 	node := d.vfs.root.cachedNode(absPath)
 	if dir, ok := node.(*Dir); ok {
 		dir.mu.Lock()
@@ -281,6 +285,7 @@ func (d *Dir) rename(newParent *Dir, fsDir fs.Directory) {
 	d.path = fsDir.Remote()
 	d.read = time.Time{}
 	d.mu.Unlock()
+	d.vfs.dirCache.ForgetAll(fsDir.Remote())
 }
 
 // addObject adds a new object or directory to the directory
@@ -298,7 +303,10 @@ func (d *Dir) addObject(node Node) {
 	}
 	d.virtual[leaf] = vAdd
 	fs.Debugf(d.path, "Added virtual directory entry %v: %q", vAdd, leaf)
+	dPath := d.path
 	d.mu.Unlock()
+	// the stored listing no longer matches
+	d.vfs.dirCache.Forget(dPath)
 }
 
 // AddVirtual adds a virtual object of name and size to the directory
@@ -342,7 +350,10 @@ func (d *Dir) delObject(leaf string) {
 	}
 	d.virtual[leaf] = vDel
 	fs.Debugf(d.path, "Added virtual directory entry %v: %q", vDel, leaf)
+	dPath := d.path
 	d.mu.Unlock()
+	// the stored listing no longer matches
+	d.vfs.dirCache.Forget(dPath)
 }
 
 // DelVirtual removes an object from the directory listing
@@ -359,20 +370,35 @@ func (d *Dir) DelVirtual(leaf string) {
 // read the directory and sets d.items - must be called with the lock held
 func (d *Dir) _readDir() error {
 	when := time.Now()
-	if age, stale := d._age(when); stale {
-		if age != 0 {
-			fs.Debugf(d.path, "Re-reading directory (%v old)", age)
-		}
-	} else {
+	if d.read.IsZero() {
+		d._readDirFromStore()
+	}
+	age, stale := d._age(when)
+	if !stale {
+		return nil
+	}
+	// If offline use the old listing if we have one rather than
+	// waiting for the remote
+	if !d.read.IsZero() && d.vfs.Offline() {
 		return nil
 	}
+	if age != 0 {
+		fs.Debugf(d.path, "Re-reading directory (%v old)", age)
+	}
 	entries, err := list.DirSorted(context.TODO(), d.f, false, d.path)
 	if err == fs.ErrorDirNotFound {
 		// We treat directory not found as empty because we
 		// create directories on the fly
 	} else if err != nil {
+		if d.vfs.checkOffline(err) && !d.read.IsZero() {
+			fs.Infof(d.path, "Remote unreachable - using cached directory listing (%v old)", age)
+			return nil
+		}
 		return err
 	}
+	d.vfs.goOnline()
+	// stored in the background as d.mu is held
+	d.vfs.dirCache.Store(d.path, entries, when)
 
 	err = d._readDirFromEntries(entries, nil, time.Time{})
 	if err != nil {
@@ -383,6 +409,27 @@ func (d *Dir) _readDir() error {
 	return nil
 }
 
+// _readDirFromStore reads the directory listing stored on disk if
+// there is one. The directory is marked as read when the listing was
+// read from the remote so it will be refreshed if it is too old.
+//
+// must be called with the lock held
+func (d *Dir) _readDirFromStore() {
+	entries, read, err := d.vfs.dirCache.Get(context.TODO(), d.path)
+	if err == vfsdircache.ErrorNotFound {
+		return
+	} else if err != nil {
+		fs.Errorf(d.path, "Failed to load stored directory listing: %v", err)
+		return
+	}
+	err = d._readDirFromEntries(entries, nil, time.Time{})
+	if err != nil {
+		fs.Errorf(d.path, "Failed to use stored directory listing: %v", err)
+		return
+	}
+	d.read = read
+}
+
 // update d.items for each dir in the DirTree below this one and
 // set the last read time - must be called with the lock held
 func (d *Dir) _readDirFromDirTree(dirTree dirtree.DirTree, when time.Time) error {
@@ -394,14 +441,27 @@ func (d *Dir) _readDirFromDirTree(dirTree dirtree.DirTree, when time.Time) error
 func (d *Dir) _readDirFromEntries(entries fs.DirEntries, dirTree dirtree.DirTree, when time.Time) error {
 	var err error
 	// Cache the items by name
-	found := make(map[string]struct{})
+	found := make(map[string]bool) // value set if the entry is a link
 	for _, entry := range entries {
 		name := path.Base(entry.Remote())
 		if name == "." || name == ".." {
 			continue
 		}
+		isLink := false
+		if _, ok := entry.(fs.Object); ok {
+			if d.vfs.Opt.LockRemote && isLockObject(name) {
+				continue
+			}
+			name, isLink = d.linkLeaf(name)
+		}
+		if wasLink, ok := found[name]; ok && (isLink || wasLink) {
+			fs.Errorf(d.path, "Symlink %q clashes with a file or directory of the same name - ignoring the symlink", name)
+			if isLink {
+				continue
+			}
+		}
 		node := d.items[name]
-		found[name] = struct{}{}
+		found[name] = isLink
 		virtualState := d.virtual[name]
 		switch virtualState {
 		case vAdd:
@@ -421,8 +481,10 @@ func (d *Dir) _readDirFromEntries(entries fs.DirEntries, dirTree dirtree.DirTree
 		case fs.Object:
 			obj := item
 			// Reuse old file value if it exists
-			if file, ok := node.(*File); node != nil && ok {
+			if file, ok := node.(*File); node != nil && ok && file.IsSymlink() == isLink {
 				file.setObjectNoUpdate(obj)
+			} else if isLink {
+				node = newLink(d, d.path, obj, name, "")
 			} else {
 				node = newFile(d, d.path, obj, name)
 			}
@@ -666,7 +728,7 @@ func (d *Dir) Create(name string, flags int) (*File, error) {
 
 // Mkdir creates a new directory
 func (d *Dir) Mkdir(name string) (*Dir, error) {
-	if d.vfs.Opt.ReadOnly {
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
 		return nil, EROFS
 	}
 	path := path.Join(d.path, name)
@@ -700,7 +762,7 @@ func (d *Dir) Mkdir(name string) (*Dir, error) {
 
 // Remove the directory
 func (d *Dir) Remove() error {
-	if d.vfs.Opt.ReadOnly {
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
 		return EROFS
 	}
 	// Check directory is empty first
@@ -770,7 +832,7 @@ func (d *Dir) RemoveName(name string) error {
 
 // Rename the file
 func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
-	if d.vfs.Opt.ReadOnly {
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
 		return EROFS
 	}
 	oldPath := path.Join(d.path, oldName)
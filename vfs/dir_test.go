@@ -10,7 +10,10 @@ import (
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdircache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -572,3 +575,46 @@ func TestDirRename(t *testing.T) {
 	err = dir.Rename("potato", "tuba", dir)
 	assert.Equal(t, EROFS, err)
 }
+
+func TestDirPersist(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.DirCachePersist = true
+	opt.DirCacheTime = time.Hour
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	vfs := New(r.Fremote, &opt)
+	ctx := context.Background()
+
+	r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+	node, err := vfs.Stat("dir")
+	require.NoError(t, err)
+	checkListing(t, node.(*Dir), []string{"file1,14,false"})
+	// the listings are stored in the background
+	assert.Eventually(t, func() bool {
+		return vfs.Stats()["dirCache"].(rc.Params)["dirs"] == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Restart the VFS after changing the remote behind its back
+	vfs.Shutdown()
+	r.WriteObject(ctx, "dir/file2", "file2 contents", t1)
+	vfs = New(r.Fremote, &opt)
+	defer cleanupVFS(t, vfs)
+
+	// The stored listing should be used as it isn't old enough
+	// to be re-read
+	node, err = vfs.Stat("dir")
+	require.NoError(t, err)
+	dir := node.(*Dir)
+	checkListing(t, dir, []string{"file1,14,false"})
+	file, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	_, ok := file.(*File).getObject().(*vfsdircache.Object)
+	assert.True(t, ok)
+	data, err := vfs.ReadFile("dir/file1")
+	require.NoError(t, err)
+	assert.Equal(t, "file1 contents", string(data))
+
+	// Forgetting the directory should read it from the remote
+	vfs.root.ForgetPath("dir", fs.EntryDirectory)
+	checkListing(t, dir, []string{"file1,14,false", "file2,14,false"})
+}
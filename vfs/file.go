@@ -48,6 +48,8 @@ type File struct {
 	pendingModTime   time.Time                       // will be applied once o becomes available, i.e. after file was written
 	pendingRenameFun func(ctx context.Context) error // will be run/renamed after all writers close
 	appendMode       bool                            // file was opened with O_APPEND
+	link             bool                            // file is a symbolic link stored as an fs.LinkSuffix object
+	target           string                          // target of the symbolic link if known
 	sys              atomic.Value                    // user defined info to be attached here
 
 	muRW sync.Mutex // synchronize RWFileHandle.openPending(), RWFileHandle.close() and File.Remove
@@ -92,6 +94,9 @@ func (f *File) IsDir() bool {
 func (f *File) Mode() (mode os.FileMode) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
+	if f.link {
+		return os.ModeSymlink | 0777
+	}
 	mode = f.d.vfs.Opt.FilePerms
 	if f.appendMode {
 		mode |= os.ModeAppend
@@ -174,6 +179,10 @@ func (f *File) rename(ctx context.Context, destDir *Dir, newName string) error {
 	oldPath := f.Path()
 	// File.mu is unlocked here to call Dir.Path()
 	newPath := path.Join(destDir.Path(), newName)
+	remotePath := newPath
+	if f.IsSymlink() {
+		remotePath += fs.LinkSuffix
+	}
 
 	renameCall := func(ctx context.Context) (err error) {
 		// chain rename calls if any
@@ -190,13 +199,13 @@ func (f *File) rename(ctx context.Context, destDir *Dir, newName string) error {
 		var newObject fs.Object
 		// if o is nil then are writing the file so no need to rename the object
 		if o != nil {
-			if o.Remote() == newPath {
+			if o.Remote() == remotePath {
 				return nil // no need to rename
 			}
 
 			// do the move of the remote object
-			dstOverwritten, _ := d.Fs().NewObject(ctx, newPath)
-			newObject, err = operations.Move(ctx, d.Fs(), dstOverwritten, newPath, o)
+			dstOverwritten, _ := d.Fs().NewObject(ctx, remotePath)
+			newObject, err = operations.Move(ctx, d.Fs(), dstOverwritten, remotePath, o)
 			if err != nil {
 				fs.Errorf(f.Path(), "File.Rename error: %v", err)
 				return err
@@ -480,7 +489,7 @@ func (f *File) openWrite(flags int) (fh *WriteFileHandle, err error) {
 	d := f.d
 	f.mu.RUnlock()
 
-	if d.vfs.Opt.ReadOnly {
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
 		return nil, EROFS
 	}
 	// fs.Debugf(f.Path(), "File.openWrite")
@@ -529,7 +538,7 @@ func (f *File) Remove() (err error) {
 	d := f.d
 	f.mu.RUnlock()
 
-	if d.vfs.Opt.ReadOnly {
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
 		return EROFS
 	}
 
@@ -607,9 +616,14 @@ func (f *File) Fs() fs.Fs {
 //   O_SYNC   open for synchronous I/O.
 //   O_TRUNC  if possible, truncate file when opene
 //
-// We ignore O_SYNC and O_EXCL
+// We ignore O_SYNC. O_EXCL is checked by VFS.OpenFile, which returns
+// EEXIST if the file exists already, so it is ignored here too.
 func (f *File) Open(flags int) (fd Handle, err error) {
 	defer log.Trace(f.Path(), "flags=%s", decodeOpenFlags(flags))("fd=%v, err=%v", &fd, &err)
+	// Symbolic links are followed by the caller so can't be opened
+	if f.IsSymlink() {
+		return nil, EINVAL
+	}
 	var (
 		write    bool // if set need write support
 		read     bool // if set need read support
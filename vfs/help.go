@@ -48,6 +48,34 @@ Or individual files or directories:
 
     rclone rc vfs/forget file=path/to/file dir=path/to/dir
 
+### VFS Persistent Directory Cache
+
+Normally the directory cache is only kept in memory so it has to be
+built again by listing the remote every time rclone starts, which can
+be slow for remotes with many files.
+
+    --vfs-dir-cache-persist              Store directory listings on disk so they can be used after a restart.
+    --vfs-dir-cache-max-size SizeSuffix  Max total size of directory listings stored on disk. (default off)
+
+With ` + "`--vfs-dir-cache-persist`" + ` rclone stores each directory
+listing it reads from the remote in the cache directory (see
+` + "`--cache-dir`" + `), including the sizes, modification times and
+any hashes which the remote returns without extra transactions.
+
+When rclone is restarted these listings are used as if they were read
+when they were originally listed, so they are only read from the
+remote again once they are older than ` + "`--dir-cache-time`" + `. This
+makes long ` + "`--dir-cache-time`" + ` settings practical, especially with
+remotes which support polling for changes.  If the remote can't be
+reached, the stored listings are used however old they are.
+
+Changes through the mount, changes notified by polling, SIGHUP and
+vfs/forget all remove the affected stored listings too.
+
+If ` + "`--vfs-dir-cache-max-size`" + ` is set then the least recently used
+listings are removed every ` + "`--vfs-cache-poll-interval`" + ` to keep the
+total size below it.
+
 ### VFS File Buffering
 
 The ` + "`--buffer-size`" + ` flag determines the amount of memory,
@@ -81,6 +109,7 @@ find that you need one or the other or both.
     --vfs-cache-mode CacheMode           Cache mode off|minimal|writes|full (default off)
     --vfs-cache-max-age duration         Max age of objects in the cache. (default 1h0m0s)
     --vfs-cache-max-size SizeSuffix      Max total size of objects in the cache. (default off)
+    --vfs-cache-min-free-space SizeSuffix  Evict files from the cache to keep this much free space on the cache file system. (default off)
     --vfs-cache-poll-interval duration   Interval to poll the cache for stale objects. (default 1m0s)
     --vfs-write-back duration            Time to writeback files after last use when using cache. (default 5s)
 
@@ -104,6 +133,18 @@ for two reasons.  Firstly because it is only checked every
 --vfs-cache-poll-interval.  Secondly because open files cannot be
 evicted from the cache.
 
+If the cache is on a disk shared with other things then use
+--vfs-cache-min-free-space to stop it filling the disk.  Whenever the
+free space on the file system holding the cache drops below this,
+rclone evicts files from the cache, least recently used first,
+skipping files which are open or not uploaded yet.  If it can't free
+enough space this way then writes to the cache wait for uploads to
+finish and free some space rather than filling the disk.  If there
+still isn't enough free space after 10 seconds the write fails with
+"No space left on device".  The
+free space is shown in the output of the vfs/stats remote control
+command.
+
 #### --vfs-cache-mode off
 
 In this mode the cache will read directly from the remote and write
@@ -159,6 +200,78 @@ the data that has been downloaded present in them.
 This mode should support all normal file system operations and is
 otherwise identical to --vfs-cache-mode writes.
 
+#### Pinning files in the cache
+
+With --vfs-cache-mode full files and directories can be pinned so
+they are kept available when the remote can't be reached.  Pinned
+files are downloaded in the background, downloaded again when they
+change on the remote and are never evicted by --vfs-cache-max-age or
+--vfs-cache-max-size.
+
+    --vfs-pin stringArray        Keep files matching this glob in the cache (may be repeated).
+    --vfs-pin-refresh duration   Interval to list pinned directories for changes if the remote can't notify them. (default 1h0m0s)
+
+Files can be pinned with globs in the same format as --include, eg
+--vfs-pin "*.pdf" or --vfs-pin "/Documents/**".  Files and directories
+can also be pinned and unpinned while running with the vfs/pin and
+vfs/unpin remote control commands.  These pins are remembered when
+rclone restarts.
+
+Pinned directories are listed again when the remote reports a change
+in them with --poll-interval.  They are also listed every
+--vfs-pin-refresh to find changes the remote doesn't report.  Listing
+a large pinned tree can be expensive, so don't set this too low.
+
+#### Encrypting the cache
+
+The cache normally keeps plain copies of the files read and written,
+even if the remote is a crypt remote.  With --vfs-cache-encrypt the
+file data and the metadata in the cache are encrypted instead.
+
+    --vfs-cache-encrypt                  Encrypt the files in the cache.
+    --vfs-cache-encrypt-password string  Password to encrypt the cache with. If not set --password-command is used.
+
+The key is made from the password, which can also be supplied with
+the RCLONE_VFS_CACHE_ENCRYPT_PASSWORD environment variable.  If it
+isn't set the command given with --password-command is run and its
+output is used as the password.  Rclone refuses to use the cache if
+the password is different to the one it was made with.
+
+Files are encrypted in blocks of 64k with XChaCha20-Poly1305, so
+they can still be read and written at any offset and partly cached
+with --vfs-cache-mode full.  Each block takes 40 bytes more on disk.
+Blocks are tied to their position and to the file they belong to, so
+blocks which are changed, moved, swapped between files or zeroed are
+detected when read.  The password is never shown in the rc output.
+
+The encrypted cache is stored apart from the unencrypted one, so
+turning this flag on or off starts with an empty cache.  Make sure
+any pending uploads have finished first.  Note that the names of the
+files and directories in the cache are not encrypted.
+
+#### Offline mode
+
+If rclone finds that the remote can't be reached, eg because the
+network is down or the connection is refused, the VFS goes offline
+and logs an error saying so.
+
+While offline
+
+  * Directory listings are served from the directory cache however
+    old they are.  Directories which haven't been listed yet can't be
+    read.
+  * File data is served from the VFS cache if it is there, so files
+    pinned or read with --vfs-cache-mode full stay readable.
+  * Files written with --vfs-cache-mode writes or full are kept in the
+    cache and their uploads are held until the remote is back.
+  * Other changes, such as making and removing directories, renaming
+    or deleting files, return a read only file system error.
+
+Rclone checks the remote every 15 seconds while offline and goes back
+online, starting the held uploads, when it can be reached again.  The
+current state is shown in the output of the vfs/stats remote control
+command.
+
 ### VFS Performance
 
 These flags may be used to enable/disable features of the VFS for
@@ -186,6 +299,19 @@ unless it is set to "off" in which case there will be no limit.
     --vfs-read-chunk-size SizeSuffix        Read the source objects in chunks. (default 128M)
     --vfs-read-chunk-size-limit SizeSuffix  Max chunk doubling size (default "off")
 
+With --vfs-cache-mode full rclone can instead read files into the
+cache with several streams at once, which can be much quicker for big
+files on remotes with high latency.
+
+    --vfs-read-streams int                   Number of parallel streams to read files into the cache with. (default 0)
+    --vfs-read-stream-chunk-size SizeSuffix  Size of the range each stream reads. (default 16M)
+
+When --vfs-read-streams is greater than 1, files bigger than
+--vfs-read-stream-chunk-size are split into chunks of that size and
+up to --vfs-read-streams of them, starting with the one being read
+and continuing with the ones following it, are downloaded in parallel.
+Chunks already in the cache are not downloaded again.
+
 Sometimes rclone is delivered reads or writes out of order. Rather
 than seeking rclone will wait a short time for the in sequence read or
 write to come in. These flags only come into effect when not using an
@@ -194,6 +320,60 @@ on disk cach file.
     --vfs-read-wait duration   Time to wait for in-sequence read before seeking. (default 20ms)
     --vfs-write-wait duration  Time to wait for in-sequence write before giving error. (default 1s)
 
+### VFS Symlinks
+
+Most remotes have no concept of a symbolic link. If --vfs-links is
+set then rclone stores symlinks on the remote as small files with a
+'.rclonelink' extension containing the target of the link, in the
+same way as the --links flag of the local backend does. These are
+shown in the VFS as symlinks without the extension, so a link can be
+made on a mount and read back from a local copy made with --links.
+
+    --vfs-links   Translate symlinks to/from regular files with a '.rclonelink' extension.
+
+Symlinks can be created and read with the mount commands and with
+"rclone serve sftp". Rclone never follows symlinks itself - that is
+left to the program using the file system - and opening a symlink
+directly gives an error. The target of a link is limited to 4096
+bytes.
+
+If a file or directory and a '.rclonelink' file have the same name
+on the remote the symlink is ignored and an error is logged, and a
+symlink can't be made with the name of an existing file.
+
+The SFTP protocol library rclone uses turns the target of a new link
+into an absolute path, so "rclone serve sftp" stores it relative to
+the directory of the link instead.
+
+### VFS File Locking
+
+Advisory locks - flock(2) and fcntl(2) byte range locks - taken on a
+mount are passed to rclone and kept by the VFS, so programs such as
+SQLite which rely on them work. They are shared with the other users
+of the same VFS, for example "rclone serve webdav" LOCK requests. The
+locks are advisory so they don't stop files being read or written.
+
+By default the locks only exist in the rclone process. If
+--vfs-lock-remote is set then locks are also published on the remote
+so several rclone instances sharing it cooperate. Each instance holding
+locks on a file writes a small object next to it called
+"<file>.rclonelock.<id>" with a lease which is renewed while the locks
+are held and removed when they are released. If an instance stops
+without releasing its locks the lease runs out after --vfs-lock-lease.
+Locks published on the remote cover the whole file. Remotes have no
+atomic way of creating objects so this is best effort rather than a
+guarantee.
+
+    --vfs-lock-remote            Publish advisory locks on the remote so rclone instances sharing it cooperate.
+    --vfs-lock-lease duration    How long a lock published on the remote lasts unless renewed. (default 1m0s)
+
+Opening a file with O_CREATE and O_EXCL, which programs use to make
+lock files, fails with "file exists" if the VFS can see the file
+already. This is checked against the VFS directory cache rather than
+atomically on the remote, so it doesn't stop another rclone instance
+or a program using the remote directly making the file at the same
+time.
+
 ### VFS Case Sensitivity
 
 Linux file systems are case-sensitive: two files can differ only
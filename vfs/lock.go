@@ -0,0 +1,316 @@
+package vfs
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/random"
+)
+
+// LockType is the type of an advisory lock
+type LockType byte
+
+// Types of lock
+const (
+	LockShared    LockType = iota // shared (read) lock
+	LockExclusive                 // exclusive (write) lock
+)
+
+// LockEOF as the End of a Lock means the lock extends to the end of
+// the file however long it gets
+const LockEOF = math.MaxInt64
+
+// how often to poll a remote lock held by someone else when waiting
+var lockPollInterval = time.Second
+
+// Lock is an advisory lock on the bytes Start to End inclusive of a
+// File.
+//
+// Locks are advisory - they stop other conflicting locks being taken
+// but they don't stop the file being read or written.
+type Lock struct {
+	Owner uint64   // identifies the holder of the lock
+	Start int64    // first byte locked
+	End   int64    // last byte locked or LockEOF
+	Type  LockType // shared or exclusive
+	PID   int32    // process holding the lock if known
+}
+
+// overlaps returns true if the lock covers any of start..end
+func (lk *Lock) overlaps(start, end int64) bool {
+	return lk.Start <= end && start <= lk.End
+}
+
+// conflicts returns true if lk and other can't both be held
+func (lk *Lock) conflicts(other *Lock) bool {
+	return lk.Owner != other.Owner &&
+		lk.overlaps(other.Start, other.End) &&
+		(lk.Type == LockExclusive || other.Type == LockExclusive)
+}
+
+// fileLocks is the locks held on a single File
+type fileLocks struct {
+	locks  []Lock
+	remote *remoteLock // lock object held on the remote if set
+	busy   bool        // set while the lock object on the remote is being changed
+}
+
+// conflict returns the first lock which conflicts with lk or nil
+func (fl *fileLocks) conflict(lk *Lock) *Lock {
+	for i := range fl.locks {
+		if fl.locks[i].conflicts(lk) {
+			return &fl.locks[i]
+		}
+	}
+	return nil
+}
+
+// clear removes start..end from the locks held by owner, splitting
+// them if necessary
+func (fl *fileLocks) clear(owner uint64, start, end int64) {
+	var locks []Lock
+	for _, lk := range fl.locks {
+		if lk.Owner != owner || !lk.overlaps(start, end) {
+			locks = append(locks, lk)
+			continue
+		}
+		if lk.Start < start {
+			before := lk
+			before.End = start - 1
+			locks = append(locks, before)
+		}
+		if lk.End > end {
+			after := lk
+			after.Start = end + 1
+			locks = append(locks, after)
+		}
+	}
+	fl.locks = locks
+}
+
+// exclusive returns true if any of the locks are exclusive
+func (fl *fileLocks) exclusive() bool {
+	for i := range fl.locks {
+		if fl.locks[i].Type == LockExclusive {
+			return true
+		}
+	}
+	return false
+}
+
+// set adds lk replacing any locks its owner holds in the same range
+func (fl *fileLocks) set(lk Lock) {
+	fl.clear(lk.Owner, lk.Start, lk.End)
+	fl.locks = append(fl.locks, lk)
+}
+
+// lockManager keeps track of the advisory locks on the files in a VFS
+type lockManager struct {
+	vfs     *VFS
+	id      string // identifies this VFS in lock objects on the remote
+	mu      sync.Mutex
+	changed chan struct{}        // closed and remade when locks are released
+	files   map[*File]*fileLocks // locks held on each file
+	stop    context.CancelFunc   // stops the lease renewer if running
+	renewWg sync.WaitGroup       // wait for the lease renewer to stop
+}
+
+// newLockManager makes a lockManager for vfs
+func newLockManager(vfs *VFS) *lockManager {
+	return &lockManager{
+		vfs:     vfs,
+		id:      random.String(16),
+		changed: make(chan struct{}),
+		files:   make(map[*File]*fileLocks),
+	}
+}
+
+// _kick wakes up anyone waiting for a lock
+//
+// call with the lock held
+func (m *lockManager) _kick() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// lock takes the lock lk on f, waiting for it if wait is set
+func (m *lockManager) lock(ctx context.Context, f *File, lk Lock, wait bool) error {
+	if lk.Start < 0 || lk.End < lk.Start {
+		return EINVAL
+	}
+	exclusive := lk.Type == LockExclusive
+	for {
+		m.mu.Lock()
+		fl := m.files[f]
+		if fl == nil {
+			fl = &fileLocks{}
+			m.files[f] = fl
+		}
+		poll := false
+		if !fl.busy && fl.conflict(&lk) == nil {
+			if !m._needRemote(fl, exclusive) {
+				fl.set(lk)
+				// lk may have replaced an exclusive lock
+				m._released(f, fl)
+				m.mu.Unlock()
+				return nil
+			}
+			// Take the lock on the remote without holding the
+			// mutex - busy stops anyone else changing it
+			fl.busy = true
+			old := fl.remote
+			m.mu.Unlock()
+			rl, err := m.lockRemote(ctx, f.Path(), old, exclusive)
+			m.mu.Lock()
+			fl.busy = false
+			if err == nil {
+				fl.remote = rl
+				fl.set(lk)
+				if rl != nil {
+					m._startRenew()
+				}
+				m._kick()
+				m.mu.Unlock()
+				return nil
+			}
+			m._released(f, fl)
+			if err != EAGAIN {
+				m.mu.Unlock()
+				return err
+			}
+			// the lock is held on the remote which won't tell us
+			// when it is released so poll it
+			poll = true
+		}
+		changed := m.changed
+		m.mu.Unlock()
+		if !wait {
+			return EAGAIN
+		}
+		var timer <-chan time.Time
+		if poll {
+			timer = time.After(lockPollInterval)
+		}
+		select {
+		case <-changed:
+		case <-timer:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// _released tidies up after locks on f have been removed or
+// replaced, removing the lock object on the remote if no locks are
+// left or making it shared if no exclusive locks are left.
+//
+// call with the lock held - it is dropped while the lock object is
+// changed
+func (m *lockManager) _released(f *File, fl *fileLocks) {
+	m._downgradeRemote(f, fl)
+	if len(fl.locks) == 0 && !fl.busy {
+		if rl := fl.remote; rl != nil {
+			fl.busy = true
+			fl.remote = nil
+			m.mu.Unlock()
+			m.removeRemote(context.TODO(), rl)
+			m.mu.Lock()
+			fl.busy = false
+		}
+		// nobody can take a lock while busy so fl is still empty
+		delete(m.files, f)
+	}
+	m._kick()
+}
+
+// unlock removes start..end from the locks owner holds on f
+func (m *lockManager) unlock(f *File, owner uint64, start, end int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fl := m.files[f]
+	if fl == nil {
+		return
+	}
+	fl.clear(owner, start, end)
+	m._released(f, fl)
+}
+
+// unlockOwner removes all the locks owner holds on f
+func (m *lockManager) unlockOwner(f *File, owner uint64) {
+	m.unlock(f, owner, 0, LockEOF)
+}
+
+// test returns a lock held on f which conflicts with lk if there is one
+func (m *lockManager) test(f *File, lk Lock) (conflict Lock, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fl := m.files[f]
+	if fl == nil {
+		return conflict, false
+	}
+	if c := fl.conflict(&lk); c != nil {
+		return *c, true
+	}
+	return conflict, false
+}
+
+// shutdown drops all the locks and releases any held on the remote
+func (m *lockManager) shutdown() {
+	m.mu.Lock()
+	files := make([]*File, 0, len(m.files))
+	for f := range m.files {
+		files = append(files, f)
+	}
+	// _released drops the lock so don't range over m.files
+	for _, f := range files {
+		if fl := m.files[f]; fl != nil {
+			fl.locks = nil
+			m._released(f, fl)
+		}
+	}
+	stop := m.stop
+	m.stop = nil
+	m.mu.Unlock()
+	if stop != nil {
+		stop()
+		m.renewWg.Wait()
+	}
+}
+
+// Lock takes the advisory lock lk on the file.
+//
+// If a conflicting lock is held then it returns EAGAIN unless wait is
+// set in which case it waits until the lock can be taken or ctx is
+// cancelled.
+//
+// If the owner of lk already holds locks overlapping it then they are
+// replaced by lk, so this can be used to upgrade or downgrade a lock.
+func (f *File) Lock(ctx context.Context, lk Lock, wait bool) (err error) {
+	err = f.VFS().locks.lock(ctx, f, lk, wait)
+	if err != nil && err != EAGAIN && err != ctx.Err() {
+		fs.Errorf(f.Path(), "File.Lock failed: %v", err)
+	}
+	return err
+}
+
+// Unlock releases the bytes start..end inclusive from the locks held
+// by owner.
+func (f *File) Unlock(owner uint64, start, end int64) {
+	f.VFS().locks.unlock(f, owner, start, end)
+}
+
+// UnlockAll releases all the locks held by owner.
+func (f *File) UnlockAll(owner uint64) {
+	f.VFS().locks.unlockOwner(f, owner)
+}
+
+// TestLock returns a lock which would stop lk being taken and true,
+// or false if lk could be taken.
+//
+// Only the locks held by this VFS are checked.
+func (f *File) TestLock(lk Lock) (conflict Lock, found bool) {
+	return f.VFS().locks.test(f, lk)
+}
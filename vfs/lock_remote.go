@@ -0,0 +1,262 @@
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// When --vfs-lock-remote is set, locks are also published on the
+// remote so that rclone instances sharing it can cooperate.
+//
+// Each VFS holding locks on a file writes a lock object next to it
+// called "<file>.rclonelock.<id>" with a lease which it renews while
+// the locks are held. A lock is refused if another VFS holds an
+// unexpired lease which conflicts with it. Remote locks cover the
+// whole file and remotes have no atomic create so this is best
+// effort - two instances locking at the same moment both back off.
+
+// lockObjectMarker separates the file name from the VFS id in the
+// name of a lock object
+const lockObjectMarker = ".rclonelock."
+
+// isLockObject returns true if leaf is the name of a lock object
+func isLockObject(leaf string) bool {
+	return strings.Contains(leaf, lockObjectMarker)
+}
+
+// remoteLock is a lock object this VFS holds on the remote
+type remoteLock struct {
+	remote    string // path of the lock object
+	exclusive bool   // set if the lock is exclusive
+}
+
+// lockInfo is the contents of a lock object
+type lockInfo struct {
+	ID        string    // id of the VFS holding the lock
+	Host      string    // host name of the VFS holding the lock
+	Exclusive bool      // whether the lock is exclusive
+	Expires   time.Time // when the lease runs out
+}
+
+// checkRemote returns EAGAIN if another VFS holds a lock on the file
+// at filePath which conflicts with a lock of the type passed in
+func (m *lockManager) checkRemote(ctx context.Context, filePath string, exclusive bool) error {
+	dir, leaf := path.Split(filePath)
+	dir = strings.TrimSuffix(dir, "/")
+	entries, err := m.vfs.f.List(ctx, dir)
+	if err == fs.ErrorDirNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to list lock objects")
+	}
+	prefix := leaf + lockObjectMarker
+	now := time.Now()
+	for _, entry := range entries {
+		o, ok := entry.(fs.Object)
+		if !ok {
+			continue
+		}
+		name := path.Base(o.Remote())
+		if !strings.HasPrefix(name, prefix) || name == prefix+m.id {
+			continue
+		}
+		info, err := readLockInfo(ctx, o)
+		if err != nil {
+			fs.Errorf(o, "Ignoring unreadable lock object: %v", err)
+			continue
+		}
+		if now.After(info.Expires) {
+			fs.Debugf(o, "Ignoring expired lock object")
+			continue
+		}
+		if exclusive || info.Exclusive {
+			fs.Debugf(filePath, "Locked on the remote by %q on %q", info.ID, info.Host)
+			return EAGAIN
+		}
+	}
+	return nil
+}
+
+// readLockInfo reads the lock object o
+func readLockInfo(ctx context.Context, o fs.Object) (info lockInfo, err error) {
+	in, err := o.Open(ctx)
+	if err != nil {
+		return info, err
+	}
+	defer fs.CheckClose(in, &err)
+	err = json.NewDecoder(in).Decode(&info)
+	return info, err
+}
+
+// putRemote writes the lock object for rl with a new lease
+//
+// call without the lock held
+func (m *lockManager) putRemote(ctx context.Context, rl *remoteLock) error {
+	host, _ := os.Hostname()
+	data, err := json.Marshal(lockInfo{
+		ID:        m.id,
+		Host:      host,
+		Exclusive: rl.exclusive,
+		Expires:   time.Now().Add(m.vfs.Opt.LockLease),
+	})
+	if err != nil {
+		return err
+	}
+	in := ioutil.NopCloser(bytes.NewReader(data))
+	_, err = operations.RcatSize(ctx, m.vfs.f, rl.remote, in, int64(len(data)), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to write lock object")
+	}
+	return nil
+}
+
+// _needRemote returns true if a lock object needs to be written to
+// the remote for fl to take a lock of the type passed in
+//
+// call with the lock held
+func (m *lockManager) _needRemote(fl *fileLocks, exclusive bool) bool {
+	if !m.vfs.Opt.LockRemote {
+		return false
+	}
+	return fl.remote == nil || (!fl.remote.exclusive && exclusive)
+}
+
+// _downgradeRemote rewrites the lock object for fl as shared if it
+// is exclusive but only shared locks are left, so other VFSes can take
+// shared locks. If it can't be rewritten then it stays exclusive.
+//
+// call with the lock held - it is dropped while the lock object is
+// written
+func (m *lockManager) _downgradeRemote(f *File, fl *fileLocks) {
+	rl := fl.remote
+	if rl == nil || !rl.exclusive || fl.busy || len(fl.locks) == 0 || fl.exclusive() {
+		return
+	}
+	shared := &remoteLock{
+		remote:    rl.remote,
+		exclusive: false,
+	}
+	fl.busy = true
+	m.mu.Unlock()
+	err := m.putRemote(context.TODO(), shared)
+	m.mu.Lock()
+	fl.busy = false
+	if err != nil {
+		fs.Errorf(f.Path(), "Failed to make lock shared: %v", err)
+		return
+	}
+	fl.remote = shared
+}
+
+// lockRemote takes a lock of the type passed in on the remote for
+// the file at filePath replacing old if set. It returns the lock
+// object now held which may be nil if the VFS is read only.
+//
+// call without the lock held with the fileLocks marked busy
+func (m *lockManager) lockRemote(ctx context.Context, filePath string, old *remoteLock, exclusive bool) (*remoteLock, error) {
+	err := m.checkRemote(ctx, filePath, exclusive)
+	if err != nil {
+		return nil, err
+	}
+	if m.vfs.Opt.ReadOnly {
+		// can't publish the lock but nothing conflicts with it
+		return old, nil
+	}
+	rl := &remoteLock{
+		remote:    filePath + lockObjectMarker + m.id,
+		exclusive: exclusive,
+	}
+	err = m.putRemote(ctx, rl)
+	if err != nil {
+		return nil, err
+	}
+	// Check nobody else took a lock while we were writing ours
+	err = m.checkRemote(ctx, filePath, exclusive)
+	if err != nil {
+		if old != nil {
+			_ = m.putRemote(ctx, old)
+		} else {
+			m.removeRemote(ctx, rl)
+		}
+		return nil, err
+	}
+	return rl, nil
+}
+
+// removeRemote removes the lock object for rl
+//
+// call without the lock held
+func (m *lockManager) removeRemote(ctx context.Context, rl *remoteLock) {
+	o, err := m.vfs.f.NewObject(ctx, rl.remote)
+	if err == nil {
+		err = o.Remove(ctx)
+	}
+	if err != nil {
+		fs.Errorf(rl.remote, "Failed to remove lock object: %v", err)
+	}
+}
+
+// _startRenew starts the go routine which renews the leases on the
+// lock objects if it isn't running
+//
+// call with the lock held
+func (m *lockManager) _startRenew() {
+	if m.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stop = cancel
+	m.renewWg.Add(1)
+	go m.renewer(ctx)
+}
+
+// renewer renews the leases on the lock objects until ctx is cancelled
+func (m *lockManager) renewer(ctx context.Context) {
+	defer m.renewWg.Done()
+	interval := m.vfs.Opt.LockLease / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// Mark the files busy so the lock objects can be
+		// written without holding the mutex
+		renew := make(map[*File]*fileLocks)
+		m.mu.Lock()
+		for f, fl := range m.files {
+			if fl.remote != nil && !fl.busy {
+				fl.busy = true
+				renew[f] = fl
+			}
+		}
+		m.mu.Unlock()
+		for f, fl := range renew {
+			err := m.putRemote(ctx, fl.remote)
+			if err != nil {
+				fs.Errorf(f.Path(), "Failed to renew lock: %v", err)
+			}
+		}
+		m.mu.Lock()
+		for f, fl := range renew {
+			fl.busy = false
+			m._released(f, fl)
+		}
+		m.mu.Unlock()
+	}
+}
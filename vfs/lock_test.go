@@ -0,0 +1,177 @@
+package vfs
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockFile makes a file to test locking on
+func lockFile(t *testing.T, vfs *VFS, name string) *File {
+	fd, err := vfs.OpenFile(name, os.O_CREATE|os.O_WRONLY, 0777)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	node, err := vfs.Stat(name)
+	require.NoError(t, err)
+	return node.(*File)
+}
+
+func TestFileLocks(t *testing.T) {
+	fl := &fileLocks{}
+	fl.set(Lock{Owner: 1, Start: 0, End: 99, Type: LockShared})
+	assert.Nil(t, fl.conflict(&Lock{Owner: 2, Start: 50, End: 60, Type: LockShared}))
+	assert.NotNil(t, fl.conflict(&Lock{Owner: 2, Start: 50, End: 60, Type: LockExclusive}))
+	assert.Nil(t, fl.conflict(&Lock{Owner: 2, Start: 100, End: 200, Type: LockExclusive}))
+	assert.Nil(t, fl.conflict(&Lock{Owner: 1, Start: 50, End: 60, Type: LockExclusive}))
+
+	// Unlocking the middle splits the lock
+	fl.clear(1, 40, 59)
+	require.Len(t, fl.locks, 2)
+	assert.Equal(t, int64(39), fl.locks[0].End)
+	assert.Equal(t, int64(60), fl.locks[1].Start)
+	assert.Nil(t, fl.conflict(&Lock{Owner: 2, Start: 40, End: 59, Type: LockExclusive}))
+
+	// Setting a lock replaces the owner's locks in its range
+	fl.set(Lock{Owner: 1, Start: 0, End: LockEOF, Type: LockExclusive})
+	require.Len(t, fl.locks, 1)
+	assert.NotNil(t, fl.conflict(&Lock{Owner: 2, Start: 1000, End: 1000, Type: LockShared}))
+}
+
+func TestFileLock(t *testing.T) {
+	_, vfs, cleanup := newTestVFS(t)
+	defer cleanup()
+	ctx := context.Background()
+	file := lockFile(t, vfs, "file")
+
+	whole := func(owner uint64, lockType LockType) Lock {
+		return Lock{Owner: owner, Start: 0, End: LockEOF, Type: lockType}
+	}
+
+	require.NoError(t, file.Lock(ctx, whole(1, LockShared), false))
+	require.NoError(t, file.Lock(ctx, whole(2, LockShared), false))
+	assert.Equal(t, EAGAIN, file.Lock(ctx, whole(3, LockExclusive), false))
+
+	conflict, found := file.TestLock(whole(3, LockExclusive))
+	assert.True(t, found)
+	assert.Equal(t, LockShared, conflict.Type)
+
+	assert.Equal(t, EINVAL, file.Lock(ctx, Lock{Owner: 3, Start: 10, End: 5}, false))
+
+	// Waiting for a lock succeeds when the others are released
+	done := make(chan error)
+	go func() {
+		done <- file.Lock(ctx, whole(3, LockExclusive), true)
+	}()
+	file.UnlockAll(1)
+	select {
+	case err := <-done:
+		t.Fatalf("lock taken too early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	file.Unlock(2, 0, LockEOF)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for lock")
+	}
+	_, found = file.TestLock(whole(1, LockShared))
+	assert.True(t, found)
+
+	// Waiting is cancelled by the context
+	ctxCancel, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, file.Lock(ctxCancel, whole(1, LockShared), true))
+
+	file.UnlockAll(3)
+	_, found = file.TestLock(whole(1, LockExclusive))
+	assert.False(t, found)
+}
+
+func TestFileLockRemote(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.LockRemote = true
+	r, vfs1, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Make a second VFS - the options need to differ or New will
+	// return vfs1
+	opt2 := opt
+	opt2.LockLease = 2 * time.Minute
+	vfs2 := New(r.Fremote, &opt2)
+	defer cleanupVFS(t, vfs2)
+
+	file1 := lockFile(t, vfs1, "file")
+	node, err := vfs2.Stat("file")
+	require.NoError(t, err)
+	file2 := node.(*File)
+
+	whole := func(lockType LockType) Lock {
+		return Lock{Owner: 1, Start: 0, End: LockEOF, Type: lockType}
+	}
+
+	require.NoError(t, file1.Lock(ctx, whole(LockShared), false))
+	require.NoError(t, file2.Lock(ctx, whole(LockShared), false))
+	assert.Equal(t, EAGAIN, file2.Lock(ctx, whole(LockExclusive), false))
+
+	// Lock objects aren't shown in the VFS
+	root, err := vfs1.Root()
+	require.NoError(t, err)
+	root.ForgetAll()
+	nodes, err := root.ReadDirAll()
+	require.NoError(t, err)
+	for _, node := range nodes {
+		assert.False(t, strings.Contains(node.Name(), lockObjectMarker), node.Name())
+	}
+
+	// Releasing the lock on vfs1 lets vfs2 upgrade
+	file1.UnlockAll(1)
+	require.NoError(t, file2.Lock(ctx, whole(LockExclusive), false))
+	assert.Equal(t, EAGAIN, file1.Lock(ctx, whole(LockShared), false))
+	file2.UnlockAll(1)
+	require.NoError(t, file1.Lock(ctx, whole(LockExclusive), false))
+
+	// Replacing the exclusive lock with a shared one lets vfs2
+	// take a shared lock too
+	require.NoError(t, file1.Lock(ctx, whole(LockShared), false))
+	require.NoError(t, file2.Lock(ctx, whole(LockShared), false))
+	file2.UnlockAll(1)
+
+	// As does releasing the last exclusive lock
+	file1.UnlockAll(1)
+	require.NoError(t, file1.Lock(ctx, Lock{Owner: 1, Start: 20, End: LockEOF, Type: LockShared}, false))
+	require.NoError(t, file1.Lock(ctx, Lock{Owner: 2, Start: 0, End: 10, Type: LockExclusive}, false))
+	assert.Equal(t, EAGAIN, file2.Lock(ctx, whole(LockShared), false))
+	file1.UnlockAll(2)
+	require.NoError(t, file2.Lock(ctx, whole(LockShared), false))
+	file2.UnlockAll(1)
+	file1.UnlockAll(1)
+
+	// Concurrent lockers don't leave lock objects behind
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(owner uint64) {
+			defer wg.Done()
+			lk := Lock{Owner: owner, Start: 0, End: LockEOF, Type: LockShared}
+			assert.NoError(t, file1.Lock(ctx, lk, true))
+			file1.UnlockAll(owner)
+		}(uint64(10 + i))
+	}
+	wg.Wait()
+	entries, err := r.Fremote.List(ctx, "")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, strings.Contains(entry.Remote(), lockObjectMarker), entry.Remote())
+	}
+}
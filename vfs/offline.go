@@ -0,0 +1,133 @@
+// Offline mode for when the remote can't be reached
+
+package vfs
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// offlineProbeInterval is how often the remote is checked to see if
+// it can be reached again while the VFS is offline
+var offlineProbeInterval = 15 * time.Second
+
+// errCacheOffline is used to put the VFS offline if the cache found
+// the remote unreachable when it started
+var errCacheOffline = errors.New("remote unreachable when loading the cache")
+
+// Offline returns whether the VFS is offline, ie the remote can't be
+// reached.
+//
+// While offline, directory listings are served from the directory
+// cache, file data from the VFS cache and uploads are held until the
+// remote can be reached again.
+func (vfs *VFS) Offline() bool {
+	vfs.offlineMu.Lock()
+	defer vfs.offlineMu.Unlock()
+	return vfs.offline
+}
+
+// checkOffline checks err and if it shows the remote can't be
+// reached puts the VFS offline.
+//
+// It returns true if err was a network error.
+func (vfs *VFS) checkOffline(err error) bool {
+	if !fserrors.IsNetworkError(err) {
+		return false
+	}
+	vfs.goOffline(err)
+	return true
+}
+
+// goOffline puts the VFS offline because of err and starts checking
+// the remote to see when it comes back.
+func (vfs *VFS) goOffline(err error) {
+	vfs.offlineMu.Lock()
+	defer vfs.offlineMu.Unlock()
+	if vfs.offline {
+		return
+	}
+	fs.Errorf(vfs.f, "VFS: remote unreachable - going offline: %v", err)
+	vfs.offline = true
+	vfs.offlineSince = time.Now()
+	vfs.offlineErr = err
+	if vfs.cache != nil {
+		vfs.cache.SetOffline(true)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	vfs.stopProbe = cancel
+	go vfs.probe(ctx)
+}
+
+// goOnline puts the VFS back online if it was offline and starts any
+// held uploads.
+func (vfs *VFS) goOnline() {
+	vfs.offlineMu.Lock()
+	defer vfs.offlineMu.Unlock()
+	if !vfs.offline {
+		return
+	}
+	fs.Logf(vfs.f, "VFS: remote reachable again - going online after %v offline", time.Since(vfs.offlineSince).Truncate(time.Second))
+	vfs.offline = false
+	vfs.offlineSince = time.Time{}
+	vfs.offlineErr = nil
+	vfs._stopProbe()
+	if vfs.cache != nil {
+		vfs.cache.SetOffline(false)
+	}
+}
+
+// _stopProbe stops the prober if it is running
+//
+// call with offlineMu held
+func (vfs *VFS) _stopProbe() {
+	if vfs.stopProbe != nil {
+		vfs.stopProbe()
+		vfs.stopProbe = nil
+	}
+}
+
+// probe lists the root of the remote at regular intervals and puts
+// the VFS back online when it succeeds.
+//
+// doesn't return until the VFS is online or the context is cancelled
+func (vfs *VFS) probe(ctx context.Context) {
+	ticker := time.NewTicker(offlineProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		_, err := vfs.f.List(ctx, "")
+		if ctx.Err() != nil {
+			return
+		}
+		if fserrors.IsNetworkError(err) {
+			fs.Debugf(vfs.f, "VFS: remote still unreachable: %v", err)
+			continue
+		}
+		vfs.goOnline()
+		return
+	}
+}
+
+// offlineStats returns info about the offline state
+func (vfs *VFS) offlineStats() (out rc.Params) {
+	vfs.offlineMu.Lock()
+	defer vfs.offlineMu.Unlock()
+	out = rc.Params{
+		"offline": vfs.offline,
+	}
+	if vfs.offline {
+		out["since"] = vfs.offlineSince
+		out["error"] = vfs.offlineErr.Error()
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package vfs
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineFs wraps an fs.Fs and fails listings with a network error
+// while offline is set
+type offlineFs struct {
+	fs.Fs
+	mu      sync.Mutex
+	offline bool
+}
+
+func (f *offlineFs) setOffline(offline bool) {
+	f.mu.Lock()
+	f.offline = offline
+	f.mu.Unlock()
+}
+
+// List the objects and directories in dir into entries
+func (f *offlineFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	f.mu.Lock()
+	offline := f.offline
+	f.mu.Unlock()
+	if offline {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+	return f.Fs.List(ctx, dir)
+}
+
+func TestVFSOffline(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+	file1 := r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	oldProbeInterval := offlineProbeInterval
+	offlineProbeInterval = 10 * time.Millisecond
+	defer func() {
+		offlineProbeInterval = oldProbeInterval
+	}()
+
+	f := &offlineFs{Fs: r.Fremote}
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeWrites
+	opt.WriteBack = writeBackDelay
+	opt.DirCacheTime = 0 // always re-read directories
+	vfs := New(f, &opt)
+	defer cleanupVFS(t, vfs)
+
+	// read the directories while online
+	_, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	assert.False(t, vfs.Offline())
+
+	f.setOffline(true)
+
+	// the old directory listings should be used
+	node, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	assert.Equal(t, file1.Size, node.Size())
+	assert.True(t, vfs.Offline())
+	stats := vfs.Stats()["offline"].(rc.Params)
+	assert.Equal(t, true, stats["offline"])
+	assert.Contains(t, stats["error"], "connection refused")
+
+	// changes which need the remote are refused
+	assert.Equal(t, EROFS, vfs.Mkdir("dir/sub", 0777))
+	assert.Equal(t, EROFS, vfs.Remove("dir/file1"))
+
+	// writes go to the cache and are held
+	fd, err := vfs.OpenFile("dir/file2", os.O_CREATE|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("file2 contents"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	time.Sleep(3 * writeBackDelay)
+	_, err = r.Fremote.NewObject(ctx, "dir/file2")
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+
+	// come back online which should upload the file
+	f.setOffline(false)
+	for i := 0; i < 100 && vfs.Offline(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, vfs.Offline())
+	vfs.WaitForWriters(waitForWritersDelay)
+	_, err = r.Fremote.NewObject(ctx, "dir/file2")
+	assert.NoError(t, err)
+
+	require.NoError(t, vfs.Mkdir("dir/sub", 0777))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 )
 
 const getVFSHelp = ` 
@@ -386,3 +387,269 @@ func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out["vfses"] = names
 	return out, nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/stats",
+		Title: "Stats for a VFS.",
+		Help: `
+This returns stats for the selected VFS.
+
+    {
+        // Status of the persistent directory cache - only present
+        // if --vfs-dir-cache-persist is set
+        "dirCache": {
+            "bytesUsed": 1234,
+            "dirs": 3,
+            "path": "/home/user/.cache/rclone/vfsDir/local/mnt/a"
+        },
+        // Status of the disk cache - only present if --vfs-cache-mode > off
+        "diskCache": {
+            "bytesUsed": 0,          // bytes of file data in the cache
+            "dirtyBytes": 0,         // size of the files not uploaded yet
+            "dirtyFiles": 0,         // number of files not uploaded yet
+            "encrypted": false,      // true if --vfs-cache-encrypt is set
+            "files": 0,              // number of files in the cache
+            "freeSpace": 123456789,  // bytes free on the cache file system or -1 if unknown
+            "hashType": 1,
+            "offline": false,        // true if uploads are held as the remote is offline
+            "openFiles": 0,          // number of files open
+            "path": "/home/user/.cache/rclone/vfs/local/mnt/a",
+            "pathMeta": "/home/user/.cache/rclone/vfsMeta/local/mnt/a",
+            "pinnedBytes": 0,
+            "pinnedFiles": 0,
+            "pins": [],
+            "uploadErrors": 0,       // number of uploads whose last try failed
+            "uploads": [],           // the upload queue as returned by vfs/queue
+            "uploadsInProgress": 0,
+            "uploadsQueued": 0
+        },
+        "fs": "/mnt/a",
+        "inUse": 1,
+        // Whether the remote can be reached
+        "offline": {
+            "offline": true,
+            "since": "2020-06-01T12:00:00.000000000+01:00", // only if offline
+            "error": "dial tcp: connection refused"        // only if offline
+        },
+        "opt": {
+            // All the VFS options
+        }
+    }
+` + getVFSHelp,
+		Fn: rcStats,
+	})
+}
+
+func rcStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	return vfs.Stats(), nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/pin",
+		Title: "Pin a file or directory in the VFS cache.",
+		Help: `
+This downloads the file or all the files in the directory given by
+the "path" parameter into the VFS cache in the background and keeps
+them there, up to date with the remote, until they are unpinned with
+vfs/unpin.  Pinned files are never evicted from the cache.
+
+    rclone rc vfs/pin path=Documents/flight
+
+Pins are remembered when the VFS is restarted.  This needs
+--vfs-cache-mode full.
+
+It returns a list of the pinned paths under the key "pins".
+` + getVFSHelp,
+		Fn: rcPin,
+	})
+	rc.Add(rc.Call{
+		Path:  "vfs/unpin",
+		Title: "Unpin a file or directory pinned with vfs/pin.",
+		Help: `
+This removes the pin made by vfs/pin for the "path" parameter.  The
+files it pinned are left in the VFS cache but may be evicted as
+normal.
+
+    rclone rc vfs/unpin path=Documents/flight
+
+It returns a list of the pinned paths under the key "pins".
+` + getVFSHelp,
+		Fn: rcUnpin,
+	})
+}
+
+func rcPin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	return pinOrUnpin(in, (*VFS).Pin)
+}
+
+func rcUnpin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	return pinOrUnpin(in, (*VFS).Unpin)
+}
+
+// pinOrUnpin calls fn on the "path" parameter of the VFS in in
+func pinOrUnpin(in rc.Params, fn func(*VFS, string) error) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	path, err := in.GetString("path")
+	if err != nil {
+		return nil, err
+	}
+	err = fn(vfs, strings.Trim(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{
+		"pins": vfs.cache.Pins(),
+	}, nil
+}
+
+// getVFSCache gets the VFS with its cache or returns an error if the
+// cache isn't in use
+func getVFSCache(in rc.Params) (vfs *VFS, err error) {
+	vfs, err = getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("can't call this unless using the VFS cache")
+	}
+	return vfs, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/queue",
+		Title: "Queue info for a VFS.",
+		Help: `
+This returns info about the upload queue for the selected VFS.
+
+This is only useful if --vfs-cache-mode > off. If you call it when
+the --vfs-cache-mode is off, it will return an error.
+
+The uploads being transferred come first, then the rest of the queue
+in the order they will be uploaded.
+
+    {
+        "queue": [
+            {
+                "name": "file1",   // name (full path) of the file
+                "id": 123,         // id of the queue item, for vfs/queue-set-expiry
+                "size": 79,        // size of the file in bytes
+                "expiry": 4.5,     // seconds from now when the file is eligible for upload
+                "tries": 1,        // number of times the upload has been tried
+                "delay": 5.0,      // delay between upload attempts in seconds
+                "uploading": false, // true if the file is being uploaded
+                "error": "..."     // the error from the last try - only present if it failed
+            }
+        ]
+    }
+
+The expiry time is the time until the file is eligible for being
+uploaded in floating point seconds. This may go negative. As rclone
+only transfers --transfers files at once, only the lowest
+--transfers expiry times will have uploading as true.  So there may
+be files with negative expiry times for which uploading is false.
+` + getVFSHelp,
+		Fn: rcQueue,
+	})
+	rc.Add(rc.Call{
+		Path:  "vfs/queue-set-expiry",
+		Title: "Set the expiry time for an item queued for upload.",
+		Help: `
+Use this to adjust the expiry time for an item in the upload queue.
+You will need to read the id of the item using vfs/queue first.
+
+You can then set "expiry" to a floating point number of seconds from
+now when the item is eligible for upload. If you want the item to be
+uploaded as soon as possible then set it to a large negative number
+(eg -1000000000). If you want the upload of the item to be delayed
+for a long time then set it to a large positive number.
+
+If "relative" is set to true then "expiry" is added to the current
+expiry time of the item instead.
+
+    rclone rc vfs/queue-set-expiry id=123 expiry=-1000000000
+
+Setting the expiry of an item which has already started uploading
+returns an error - the item will carry on being uploaded.
+
+This takes the following parameters
+
+- id - a numeric ID as returned from vfs/queue
+- expiry - a new expiry time as floating point seconds
+- relative - if set, expiry is relative to the current expiry (optional, boolean)
+
+This returns an error if called without the VFS cache or if the id
+isn't in the queue.
+` + getVFSHelp,
+		Fn: rcQueueSetExpiry,
+	})
+	rc.Add(rc.Call{
+		Path:  "vfs/cache-clean",
+		Title: "Clean the VFS cache now.",
+		Help: `
+This removes files which are older than --vfs-cache-max-age, then the
+least recently used files until the cache is below
+--vfs-cache-max-size, straight away rather than waiting for the next
+--vfs-cache-poll-interval.  Open files and files which haven't been
+uploaded yet are never removed.
+
+It returns the disk cache stats after cleaning as returned by
+vfs/stats under the key "diskCache".
+` + getVFSHelp,
+		Fn: rcCacheClean,
+	})
+}
+
+func rcQueue(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFSCache(in)
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{
+		"queue": vfs.cache.Queue(),
+	}, nil
+}
+
+func rcQueueSetExpiry(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFSCache(in)
+	if err != nil {
+		return nil, err
+	}
+	id, err := in.GetInt64("id")
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := in.GetFloat64("expiry")
+	if err != nil {
+		return nil, err
+	}
+	relative, err := in.GetBool("relative")
+	if err != nil && !rc.IsErrParamNotFound(err) {
+		return nil, err
+	}
+	err = vfs.cache.QueueSetExpiry(writeback.Handle(id), time.Duration(expiry*float64(time.Second)), relative)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func rcCacheClean(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFSCache(in)
+	if err != nil {
+		return nil, err
+	}
+	vfs.cache.Clean()
+	return rc.Params{
+		"diskCache": vfs.cache.Stats(),
+	}, nil
+}
@@ -2,11 +2,15 @@ package vfs
 
 import (
 	"context"
+	"encoding/json"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -116,3 +120,88 @@ func TestRcList(t *testing.T) {
 		},
 	}, out)
 }
+
+func TestRcStats(t *testing.T) {
+	_, vfs, cleanup, call := rcNewRun(t, "vfs/stats")
+	defer cleanup()
+	vfs.Opt.CacheEncryptPass = "potato"
+
+	out, err := call.Fn(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, vfs.Opt, out["opt"])
+
+	// The cache password must not be shown
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "potato")
+}
+
+func TestRcQueue(t *testing.T) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping test on non local remote")
+	}
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeWrites
+	opt.WriteBack = time.Hour
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+	ctx := context.Background()
+	queue := rc.Calls.Get("vfs/queue")
+	setExpiry := rc.Calls.Get("vfs/queue-set-expiry")
+	cacheClean := rc.Calls.Get("vfs/cache-clean")
+
+	out, err := queue.Fn(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(out["queue"].([]writeback.QueueInfo)))
+
+	// write a file which won't be uploaded for an hour
+	fd, err := vfs.OpenFile("file1", os.O_CREATE|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("file1 contents"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	out, err = queue.Fn(ctx, nil)
+	require.NoError(t, err)
+	items := out["queue"].([]writeback.QueueInfo)
+	require.Equal(t, 1, len(items))
+	assert.Equal(t, "file1", items[0].Name)
+	assert.Equal(t, int64(14), items[0].Size)
+	assert.False(t, items[0].Uploading)
+	assert.True(t, items[0].Expiry > 3500)
+
+	stats := vfs.Stats()["diskCache"].(rc.Params)
+	assert.Equal(t, 1, stats["dirtyFiles"])
+	assert.Equal(t, int64(14), stats["dirtyBytes"])
+	assert.Equal(t, 1, len(stats["uploads"].([]writeback.QueueInfo)))
+
+	// the dirty file can't be cleaned from the cache
+	out, err = cacheClean.Fn(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, out["diskCache"].(rc.Params)["files"])
+
+	_, err = setExpiry.Fn(ctx, rc.Params{"id": int64(12345), "expiry": -1.0})
+	assert.Error(t, err)
+
+	// make it upload now
+	_, err = setExpiry.Fn(ctx, rc.Params{"id": int64(items[0].ID), "expiry": -1e9})
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		out, err = queue.Fn(ctx, nil)
+		require.NoError(t, err)
+		if len(out["queue"].([]writeback.QueueInfo)) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, len(out["queue"].([]writeback.QueueInfo)))
+	_, err = r.Fremote.NewObject(ctx, "file1")
+	assert.NoError(t, err)
+}
+
+func TestRcQueueNoCache(t *testing.T) {
+	_, _, cleanup, call := rcNewRun(t, "vfs/queue")
+	defer cleanup()
+	_, err := call.Fn(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "VFS cache")
+}
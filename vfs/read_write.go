@@ -339,6 +339,9 @@ func (fh *RWFileHandle) _writeAt(b []byte, off int64, release bool) (n int, err
 	if release {
 		fh.mu.Lock()
 	}
+	if err == vfscache.ErrNoSpace {
+		return n, ENOSPC
+	}
 	if err != nil {
 		return n, err
 	}
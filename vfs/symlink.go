@@ -0,0 +1,145 @@
+package vfs
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// Symbolic links are stored on the remote as objects with the
+// fs.LinkSuffix extension holding the target of the link, in the
+// same way as the --links flag of the local backend translates them.
+
+// maxLinkSize is the largest link target which will be read
+const maxLinkSize = 4096
+
+// linkLeaf returns the leaf name in the VFS and whether it is a link
+// for the leaf name of an object on the remote
+func (d *Dir) linkLeaf(leaf string) (name string, isLink bool) {
+	if !d.vfs.Opt.Links || leaf == fs.LinkSuffix || !strings.HasSuffix(leaf, fs.LinkSuffix) {
+		return leaf, false
+	}
+	return strings.TrimSuffix(leaf, fs.LinkSuffix), true
+}
+
+// newLink creates a new File which is a symbolic link stored in o
+//
+// target may be "" in which case it is read from o when needed
+func newLink(d *Dir, dPath string, o fs.Object, leaf string, target string) *File {
+	f := newFile(d, dPath, o, leaf)
+	f.link = true
+	f.target = target
+	return f
+}
+
+// IsSymlink returns true if the File is a symbolic link
+func (f *File) IsSymlink() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.link
+}
+
+// Readlink returns the target of the symbolic link
+func (f *File) Readlink() (target string, err error) {
+	f.mu.RLock()
+	link, target, o := f.link, f.target, f.o
+	f.mu.RUnlock()
+	if !link {
+		return "", EINVAL
+	}
+	if target != "" {
+		return target, nil
+	}
+	if o == nil {
+		return "", ENOENT
+	}
+	in, err := o.Open(context.TODO())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open symlink")
+	}
+	defer fs.CheckClose(in, &err)
+	b, err := ioutil.ReadAll(io.LimitReader(in, maxLinkSize+1))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read symlink")
+	}
+	if len(b) > maxLinkSize {
+		return "", errors.New("symlink target too long")
+	}
+	target = string(b)
+	f.mu.Lock()
+	f.target = target
+	f.mu.Unlock()
+	return target, nil
+}
+
+// Readlink returns EINVAL for a directory as it isn't a symbolic link
+func (d *Dir) Readlink() (string, error) {
+	return "", EINVAL
+}
+
+// Symlink creates name in the directory as a symbolic link to target
+func (d *Dir) Symlink(target, name string) (*File, error) {
+	if !d.vfs.Opt.Links {
+		return nil, ENOSYS
+	}
+	if d.vfs.Opt.ReadOnly || d.vfs.Offline() {
+		return nil, EROFS
+	}
+	if target == "" || len(target) > maxLinkSize {
+		return nil, EINVAL
+	}
+	_, err := d.stat(name)
+	switch err {
+	case ENOENT:
+		// not found, carry on
+	case nil:
+		return nil, EEXIST
+	default:
+		fs.Errorf(d, "Dir.Symlink failed to read directory: %v", err)
+		return nil, err
+	}
+	dPath := d.Path()
+	remote := path.Join(dPath, name) + fs.LinkSuffix
+	// Check the remote too as the link would clash with a file or
+	// link of the same name which isn't in the directory listing yet
+	for _, clash := range []string{remote, strings.TrimSuffix(remote, fs.LinkSuffix)} {
+		if _, err := d.f.NewObject(context.TODO(), clash); err == nil {
+			return nil, EEXIST
+		}
+	}
+	in := ioutil.NopCloser(strings.NewReader(target))
+	o, err := operations.RcatSize(context.TODO(), d.f, remote, in, int64(len(target)), time.Now())
+	if err != nil {
+		fs.Errorf(d, "Dir.Symlink failed to create symlink: %v", err)
+		return nil, err
+	}
+	f := newLink(d, dPath, o, name, target)
+	d.addObject(f)
+	return f, nil
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (vfs *VFS) Symlink(oldname, newname string) error {
+	dir, leaf, err := vfs.StatParent(newname)
+	if err != nil {
+		return err
+	}
+	_, err = dir.Symlink(oldname, leaf)
+	return err
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (vfs *VFS) Readlink(name string) (string, error) {
+	node, err := vfs.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	return node.Readlink()
+}
@@ -0,0 +1,100 @@
+package vfs
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlink(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.Links = true
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	file1 := r.WriteObject(context.Background(), "dir/file1", "file1 contents", t1)
+	link1 := r.WriteObject(context.Background(), "dir/link1"+fs.LinkSuffix, "file1", t1)
+	fstest.CheckItems(t, r.Fremote, file1, link1)
+
+	// Existing links are read from the remote
+	node, err := vfs.Stat("dir/link1")
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, node.Mode()&os.ModeSymlink)
+	target, err := vfs.Readlink("dir/link1")
+	require.NoError(t, err)
+	assert.Equal(t, "file1", target)
+
+	// Links can't be opened
+	_, err = vfs.OpenFile("dir/link1", os.O_RDONLY, 0)
+	assert.Equal(t, EINVAL, err)
+
+	// Files and directories aren't links
+	_, err = vfs.Readlink("dir/file1")
+	assert.Equal(t, EINVAL, err)
+	_, err = vfs.Readlink("dir")
+	assert.Equal(t, EINVAL, err)
+
+	// Make a new link
+	require.NoError(t, vfs.Symlink("../dir/file1", "dir/link2"))
+	target, err = vfs.Readlink("dir/link2")
+	require.NoError(t, err)
+	assert.Equal(t, "../dir/file1", target)
+	assert.Equal(t, EEXIST, vfs.Symlink("file1", "dir/link2"))
+	assert.Equal(t, EEXIST, vfs.Symlink("file1", "dir/file1"))
+
+	// Rename keeps the suffix on the remote
+	require.NoError(t, vfs.Rename("dir/link2", "dir/link3"))
+	link3 := fstest.NewItem("dir/link3"+fs.LinkSuffix, "../dir/file1", t1)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, link1, link3}, []string{"dir"}, fs.ModTimeNotSupported)
+
+	// Remove removes the object on the remote
+	require.NoError(t, vfs.Remove("dir/link1"))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, link3}, []string{"dir"}, fs.ModTimeNotSupported)
+
+	vfs.Opt.ReadOnly = true
+	assert.Equal(t, EROFS, vfs.Symlink("file1", "dir/link4"))
+}
+
+func TestSymlinkClash(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.Links = true
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	file1 := r.WriteObject(context.Background(), "dir/file1", "file1 contents", t1)
+	link1 := r.WriteObject(context.Background(), "dir/file1"+fs.LinkSuffix, "file2", t1)
+	fstest.CheckItems(t, r.Fremote, file1, link1)
+
+	// The file wins over a link with the same name
+	node, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), node.Mode()&os.ModeSymlink)
+	assert.Equal(t, EEXIST, vfs.Symlink("file2", "dir/file1"))
+
+	// A link clashing with a file which isn't in the listing yet
+	// is refused
+	r.WriteObject(context.Background(), "dir/file2", "file2 contents", t1)
+	assert.Equal(t, EEXIST, vfs.Symlink("file1", "dir/file2"))
+}
+
+func TestSymlinkDisabled(t *testing.T) {
+	r, vfs, cleanup := newTestVFS(t)
+	defer cleanup()
+
+	link1 := r.WriteObject(context.Background(), "dir/link1"+fs.LinkSuffix, "file1", t1)
+	fstest.CheckItems(t, r.Fremote, link1)
+
+	// Without --vfs-links the link is a normal file
+	node, err := vfs.Stat("dir/link1" + fs.LinkSuffix)
+	require.NoError(t, err)
+	assert.True(t, node.IsFile())
+	assert.Equal(t, os.FileMode(0), node.Mode()&os.ModeSymlink)
+
+	assert.Equal(t, ENOSYS, vfs.Symlink("file1", "dir/link2"))
+}
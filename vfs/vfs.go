@@ -27,17 +27,21 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/log"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/vfs/vfscache"
 	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdircache"
 )
 
 // Node represents either a directory (*Dir) or a file (*File)
@@ -55,6 +59,7 @@ type Node interface {
 	Truncate(size int64) error
 	Path() string
 	SetSys(interface{})
+	Readlink() (string, error)
 }
 
 // Check interfaces
@@ -162,11 +167,21 @@ type VFS struct {
 	Opt         vfscommon.Options
 	cache       *vfscache.Cache
 	cancelCache context.CancelFunc
+	dirCache    *vfsdircache.Cache // directory listings stored on disk - may be nil
+	cancelDir   context.CancelFunc
 	usageMu     sync.Mutex
 	usageTime   time.Time
 	usage       *fs.Usage
 	pollChan    chan time.Duration
 	inUse       int32 // count of number of opens accessed with atomic
+
+	offlineMu    sync.Mutex         // protects the following
+	offline      bool               // set if the remote can't be reached
+	offlineSince time.Time          // when the VFS went offline
+	offlineErr   error              // the error which put the VFS offline
+	stopProbe    context.CancelFunc // stops the offline prober if set
+
+	locks *lockManager // advisory locks held on files
 }
 
 // Keep track of active VFS keyed on fs.ConfigString(f)
@@ -203,7 +218,7 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	defer activeMu.Unlock()
 	configName := fs.ConfigString(f)
 	for _, activeVFS := range active[configName] {
-		if vfs.Opt == activeVFS.Opt {
+		if reflect.DeepEqual(vfs.Opt, activeVFS.Opt) {
 			fs.Debugf(f, "Re-using VFS from active cache")
 			atomic.AddInt32(&activeVFS.inUse, 1)
 			return activeVFS
@@ -214,18 +229,32 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 
 	// Create root directory
 	vfs.root = newDir(vfs, f, nil, fsDir)
+	vfs.locks = newLockManager(vfs)
+
+	// Load the directory listings from disk if required
+	if vfs.Opt.DirCachePersist {
+		ctx, cancel := context.WithCancel(context.Background())
+		dirCache, err := vfsdircache.New(ctx, f, &vfs.Opt)
+		if err != nil {
+			fs.Errorf(nil, "Failed to create vfs dir cache - disabling: %v", err)
+			cancel()
+		} else {
+			vfs.dirCache = dirCache
+			vfs.cancelDir = cancel
+		}
+	}
+
+	vfs.SetCacheMode(vfs.Opt.CacheMode)
 
 	// Start polling function
 	if do := vfs.f.Features().ChangeNotify; do != nil {
 		vfs.pollChan = make(chan time.Duration)
-		do(context.TODO(), vfs.root.changeNotify, vfs.pollChan)
+		do(context.TODO(), vfs.changeNotify, vfs.pollChan)
 		vfs.pollChan <- vfs.Opt.PollInterval
 	} else {
 		fs.Infof(f, "poll-interval is not supported by this remote")
 	}
 
-	vfs.SetCacheMode(vfs.Opt.CacheMode)
-
 	// Pin the Fs into the cache so that when we use cache.NewFs
 	// with the same remote string we get this one. The Pin is
 	// removed by Shutdown
@@ -253,6 +282,14 @@ func (vfs *VFS) Fs() fs.Fs {
 	return vfs.f
 }
 
+// changeNotify is called by the remote when relativePath changes
+func (vfs *VFS) changeNotify(relativePath string, entryType fs.EntryType) {
+	vfs.root.changeNotify(relativePath, entryType)
+	if vfs.cache != nil {
+		vfs.cache.Changed(relativePath, entryType)
+	}
+}
+
 // SetCacheMode change the cache mode
 func (vfs *VFS) SetCacheMode(cacheMode vfscommon.CacheMode) {
 	vfs.shutdownCache()
@@ -269,6 +306,12 @@ func (vfs *VFS) SetCacheMode(cacheMode vfscommon.CacheMode) {
 		vfs.Opt.CacheMode = cacheMode
 		vfs.cancelCache = cancel
 		vfs.cache = cache
+		cache.SetNetworkErrorFn(vfs.goOffline)
+		if cache.Offline() {
+			vfs.goOffline(errCacheOffline)
+		} else if vfs.Offline() {
+			cache.SetOffline(true)
+		}
 	}
 }
 
@@ -303,17 +346,70 @@ func (vfs *VFS) Shutdown() {
 	}
 	activeMu.Unlock()
 
+	vfs.offlineMu.Lock()
+	vfs._stopProbe()
+	vfs.offlineMu.Unlock()
+
+	if vfs.cancelDir != nil {
+		vfs.cancelDir()
+		vfs.cancelDir = nil
+		vfs.dirCache.Wait()
+	}
+
+	vfs.locks.shutdown()
+
 	vfs.shutdownCache()
 }
 
 // CleanUp deletes the contents of the on disk cache
 func (vfs *VFS) CleanUp() error {
+	err := vfs.dirCache.CleanUp()
+	if err != nil {
+		return err
+	}
 	if vfs.Opt.CacheMode == vfscommon.CacheModeOff {
 		return nil
 	}
 	return vfs.cache.CleanUp()
 }
 
+// Pin keeps the file or directory name in the on disk cache,
+// downloading it in the background.  It needs --vfs-cache-mode full.
+func (vfs *VFS) Pin(name string) error {
+	if vfs.cache == nil || vfs.Opt.CacheMode < vfscommon.CacheModeFull {
+		return errors.New("pinning needs --vfs-cache-mode full")
+	}
+	_, err := vfs.Stat(name)
+	if err != nil {
+		return err
+	}
+	return vfs.cache.Pin(name)
+}
+
+// Unpin removes a pin made by Pin
+func (vfs *VFS) Unpin(name string) error {
+	if vfs.cache == nil {
+		return errors.New("pinning needs --vfs-cache-mode full")
+	}
+	return vfs.cache.Unpin(name)
+}
+
+// Stats returns info about the VFS
+func (vfs *VFS) Stats() (out rc.Params) {
+	out = make(rc.Params)
+	out["fs"] = fs.ConfigString(vfs.f)
+	out["opt"] = vfs.Opt
+	out["inUse"] = atomic.LoadInt32(&vfs.inUse)
+	out["offline"] = vfs.offlineStats()
+	if vfs.cache != nil {
+		out["diskCache"] = vfs.cache.Stats()
+	}
+	if vfs.dirCache != nil {
+		out["dirCache"] = vfs.dirCache.Stats()
+	}
+	return out
+}
+
 // FlushDirCache empties the directory cache
 func (vfs *VFS) FlushDirCache() {
 	vfs.root.ForgetAll()
@@ -474,6 +570,9 @@ func (vfs *VFS) OpenFile(name string, flags int, perm os.FileMode) (fd Handle, e
 		if err != nil {
 			return nil, err
 		}
+	} else if flags&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+		// If O_CREATE and O_EXCL are set then the file must not exist
+		return nil, EEXIST
 	}
 	return node.Open(flags)
 }
@@ -290,6 +290,10 @@ func TestVFSOpenFile(t *testing.T) {
 	fd, err = vfs.OpenFile("not found/new_file.txt", os.O_WRONLY|os.O_CREATE, 0777)
 	assert.Equal(t, os.ErrNotExist, err)
 	assert.Nil(t, fd)
+
+	fd, err = vfs.OpenFile("file1", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0777)
+	assert.Equal(t, EEXIST, err)
+	assert.Nil(t, fd)
 }
 
 func TestVFSRename(t *testing.T) {
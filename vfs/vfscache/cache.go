@@ -17,9 +17,13 @@ import (
 	"github.com/rclone/rclone/fs"
 	fscache "github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs/vfscache/cachecrypt"
 	"github.com/rclone/rclone/vfs/vfscache/writeback"
 	"github.com/rclone/rclone/vfs/vfscommon"
 )
@@ -44,12 +48,28 @@ type Cache struct {
 	metaRoot   string               // root of the cache metadata directory
 	hashType   hash.Type            // hash to use locally and remotely
 	hashOption *fs.HashesOption     // corresponding OpenOption
+	key        *cachecrypt.Key      // key to encrypt the cache files with - nil if not encrypted
+	keyPath    string               // file the key parameters are stored in if encrypted
 	writeback  *writeback.WriteBack // holds Items for writeback
 	avFn       AddVirtualFn         // if set, can be called to add dir entries
+	pinPath    string               // file the pinned paths are stored in
+	pinFilter  *filter.Filter       // files pinned by --vfs-pin - may be nil
+	pinKick    chan struct{}        // wakes up the pinner
+	cleanKick  chan struct{}        // wakes up the cleaner to free space
 
 	mu   sync.Mutex       // protects the following variables
 	item map[string]*Item // files/directories in the cache
 	used int64            // total size of files in the cache
+
+	freeMu      sync.Mutex // protects the following variables
+	freeChecked time.Time  // when the free space was last read
+	low         bool       // set if free space was below --vfs-cache-min-free-space
+
+	pinMu sync.Mutex          // protects the following variables
+	pins  map[string]struct{} // files and directories pinned with Pin
+
+	netErrMu sync.Mutex     // protects the following variables
+	netErrFn NetworkErrorFn // if set, called when the remote can't be reached
 }
 
 // AddVirtualFn if registered by the WithAddVirtual method, can be
@@ -60,6 +80,12 @@ type Cache struct {
 // go into the directory tree.
 type AddVirtualFn func(remote string, size int64, isDir bool) error
 
+// NetworkErrorFn if registered with SetNetworkErrorFn is called with
+// errors from the remote which show that it can't be reached.
+//
+// Uploads are held from then on until SetOffline(false) is called.
+type NetworkErrorFn func(err error)
+
 // New creates a new cache heirachy for fremote
 //
 // This starts background goroutines which can be cancelled with the
@@ -72,10 +98,16 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		}
 		fRoot = strings.Replace(fRoot, ":", "", -1)
 	}
-	root := file.UNCPath(filepath.Join(config.CacheDir, "vfs", fremote.Name(), fRoot))
+	// Encrypted caches are kept apart from unencrypted ones
+	dataDir, metaDir := "vfs", "vfsMeta"
+	if opt.CacheEncrypt {
+		dataDir, metaDir = "vfsCrypt", "vfsCryptMeta"
+	}
+	root := file.UNCPath(filepath.Join(config.CacheDir, dataDir, fremote.Name(), fRoot))
 	fs.Debugf(nil, "vfs cache: root is %q", root)
-	metaRoot := file.UNCPath(filepath.Join(config.CacheDir, "vfsMeta", fremote.Name(), fRoot))
+	metaRoot := file.UNCPath(filepath.Join(config.CacheDir, metaDir, fremote.Name(), fRoot))
 	fs.Debugf(nil, "vfs cache: metadata root is %q", root)
+	pinPath := file.UNCPath(filepath.Join(config.CacheDir, "vfsPin", fremote.Name(), fRoot, "pins.json"))
 
 	fcache, err := fscache.Get(root)
 	if err != nil {
@@ -88,6 +120,31 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 
 	hashType, hashOption := operations.CommonHash(fcache, fremote)
 
+	var (
+		key     *cachecrypt.Key
+		keyPath string
+	)
+	if opt.CacheEncrypt {
+		password, err := cachecrypt.Password(opt.CacheEncryptPass)
+		if err != nil {
+			return nil, err
+		}
+		keyPath = file.UNCPath(filepath.Join(config.CacheDir, "vfsCryptKey", fremote.Name(), fRoot, "key.json"))
+		key, err = cachecrypt.LoadKey(keyPath, password)
+		if err != nil {
+			return nil, err
+		}
+		fs.Debugf(nil, "vfs cache: encrypting cache with key from %q", keyPath)
+	}
+
+	pinFilter, err := newPinFilter(opt)
+	if err != nil {
+		return nil, err
+	}
+	if pinFilter != nil && opt.CacheMode < vfscommon.CacheModeFull {
+		fs.Errorf(nil, "vfs cache: --vfs-pin needs --vfs-cache-mode full to keep files available")
+	}
+
 	c := &Cache{
 		fremote:    fremote,
 		fcache:     fcache,
@@ -98,8 +155,15 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		item:       make(map[string]*Item),
 		hashType:   hashType,
 		hashOption: hashOption,
+		key:        key,
+		keyPath:    keyPath,
 		writeback:  writeback.New(ctx, opt),
 		avFn:       avFn,
+		pinPath:    pinPath,
+		pinFilter:  pinFilter,
+		pinKick:    make(chan struct{}, 1),
+		cleanKick:  make(chan struct{}, 1),
+		pins:       make(map[string]struct{}),
 	}
 
 	// Make sure cache directories exist
@@ -114,10 +178,17 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		return nil, errors.Wrap(err, "failed to load cache")
 	}
 
+	// load the pinned paths
+	err = c.loadPins()
+	if err != nil {
+		return nil, err
+	}
+
 	// Remove any empty directories
 	c.purgeEmptyDirs()
 
 	go c.cleaner(ctx)
+	go c.pinner(ctx)
 
 	return c, nil
 }
@@ -347,10 +418,22 @@ func (c *Cache) SetModTime(name string, modTime time.Time) {
 func (c *Cache) CleanUp() error {
 	err1 := os.RemoveAll(c.root)
 	err2 := os.RemoveAll(c.metaRoot)
+	err3 := os.Remove(c.pinPath)
+	if c.keyPath != "" {
+		if err := os.Remove(c.keyPath); err != nil && !os.IsNotExist(err) && err3 == nil {
+			err3 = err
+		}
+	}
 	if err1 != nil {
 		return err1
 	}
-	return err2
+	if err2 != nil {
+		return err2
+	}
+	if err3 != nil && !os.IsNotExist(err3) {
+		return err3
+	}
+	return nil
 }
 
 // walk walks the cache calling the function
@@ -413,7 +496,7 @@ func (c *Cache) _purgeOld(maxAge time.Duration, remove func(item *Item)) {
 	defer c.mu.Unlock()
 	cutoff := time.Now().Add(-maxAge)
 	for name, item := range c.item {
-		if !item.inUse() {
+		if !item.inUse() && !c.isPinned(name) {
 			// If not locked and access time too long ago - delete the file
 			dt := item.getATime().Sub(cutoff)
 			// fs.Debugf(name, "atime=%v cutoff=%v, dt=%v", item.info.ATime, cutoff, dt)
@@ -471,9 +554,9 @@ func (c *Cache) _purgeOverQuota(quota int64, remove func(item *Item)) {
 
 	var items Items
 
-	// Make a slice of unused files
-	for _, item := range c.item {
-		if !item.inUse() {
+	// Make a slice of unused files which aren't pinned
+	for name, item := range c.item {
+		if !item.inUse() && !c.isPinned(name) {
 			items = append(items, item)
 		}
 	}
@@ -511,6 +594,9 @@ func (c *Cache) clean() {
 	// oldest first
 	c.purgeOverQuota(int64(c.opt.CacheMaxSize))
 
+	// Then remove any files needed to keep the free space up
+	c.purgeMinFreeSpace(int64(c.opt.CacheMinFreeSpace))
+
 	// Stats
 	c.mu.Lock()
 	newItems, newUsed := len(c.item), fs.SizeSuffix(c.used)
@@ -543,6 +629,8 @@ func (c *Cache) cleaner(ctx context.Context) {
 		select {
 		case <-timer.C:
 			c.clean()
+		case <-c.cleanKick:
+			c.purgeMinFreeSpace(int64(c.opt.CacheMinFreeSpace))
 		case <-ctx.Done():
 			fs.Debugf(nil, "vfs cache: cleaner exiting")
 			return
@@ -586,3 +674,140 @@ func (c *Cache) AddVirtual(remote string, size int64, isDir bool) error {
 	}
 	return c.avFn(remote, size, isDir)
 }
+
+// Queue returns info about the files queued for upload, the ones
+// being uploaded first, then in the order they will be uploaded.
+func (c *Cache) Queue() []writeback.QueueInfo {
+	queue := c.writeback.Queue()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range queue {
+		if item := c.item[queue[i].Name]; item != nil {
+			_, _, queue[i].Size = item.getStats()
+		}
+	}
+	return queue
+}
+
+// QueueSetExpiry sets the time the upload with id will start.
+//
+// If relative is set then expiry is added to the current expiry time
+// of the upload, otherwise it is added to the current time.
+func (c *Cache) QueueSetExpiry(id writeback.Handle, expiry time.Duration, relative bool) error {
+	when := time.Now()
+	if relative {
+		found := false
+		for _, info := range c.writeback.Queue() {
+			if info.ID == id {
+				when = when.Add(time.Duration(info.Expiry * float64(time.Second)))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("item %d not found in the upload queue", id)
+		}
+	}
+	return c.writeback.SetExpiry(id, when.Add(expiry))
+}
+
+// Clean removes files which are too old or over --vfs-cache-max-size
+// from the cache now rather than waiting for the next
+// --vfs-cache-poll-interval.
+func (c *Cache) Clean() {
+	c.clean()
+}
+
+// SetNetworkErrorFn registers fn to be called when the remote can't
+// be reached.
+func (c *Cache) SetNetworkErrorFn(fn NetworkErrorFn) {
+	c.netErrMu.Lock()
+	c.netErrFn = fn
+	c.netErrMu.Unlock()
+}
+
+// networkError checks err and, if it shows the remote can't be
+// reached, holds the uploads and calls the NetworkErrorFn.
+//
+// It returns true if err was a network error.
+func (c *Cache) networkError(err error) bool {
+	if !fserrors.IsNetworkError(err) {
+		return false
+	}
+	c.writeback.SetOffline(true)
+	c.netErrMu.Lock()
+	fn := c.netErrFn
+	c.netErrMu.Unlock()
+	if fn != nil {
+		fn(err)
+	}
+	return true
+}
+
+// SetOffline sets whether the remote can be reached or not.
+//
+// While offline uploads are held in the writeback queue. They are
+// started as soon as the cache is set back online.
+func (c *Cache) SetOffline(offline bool) {
+	c.writeback.SetOffline(offline)
+}
+
+// Offline returns whether uploads are being held because the remote
+// can't be reached.
+func (c *Cache) Offline() bool {
+	return c.writeback.Offline()
+}
+
+// Stats returns info about the Cache
+func (c *Cache) Stats() (out rc.Params) {
+	out = make(rc.Params)
+	// read only - no locking needed to read these
+	out["path"] = c.root
+	out["pathMeta"] = c.metaRoot
+	out["hashType"] = c.hashType
+	out["freeSpace"] = c.freeSpace()
+	out["encrypted"] = c.key != nil
+
+	uploadsInProgress, uploadsQueued := c.writeback.Stats()
+	out["uploadsInProgress"] = uploadsInProgress
+	out["uploadsQueued"] = uploadsQueued
+	out["offline"] = c.Offline()
+
+	var (
+		openFiles  int
+		dirtyFiles int
+		dirtyBytes int64
+	)
+	c.mu.Lock()
+	out["files"] = len(c.item)
+	out["bytesUsed"] = c.used
+	for _, item := range c.item {
+		open, dirty, size := item.getStats()
+		if open {
+			openFiles++
+		}
+		if dirty {
+			dirtyFiles++
+			dirtyBytes += size
+		}
+	}
+	c.mu.Unlock()
+	out["openFiles"] = openFiles
+	out["dirtyFiles"] = dirtyFiles
+	out["dirtyBytes"] = dirtyBytes
+
+	uploads := c.Queue()
+	uploadErrors := 0
+	for _, upload := range uploads {
+		if upload.Error != "" {
+			uploadErrors++
+		}
+	}
+	out["uploads"] = uploads
+	out["uploadErrors"] = uploadErrors
+
+	out["pins"] = c.Pins()
+	out["pinnedFiles"], out["pinnedBytes"] = c.pinnedStats()
+
+	return out
+}
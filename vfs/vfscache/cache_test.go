@@ -663,3 +663,98 @@ func TestCacheDump(t *testing.T) {
 	out = c.Dump()
 	assert.Equal(t, "Cache{\n}\n", out)
 }
+
+func TestCachePurgeMinFreeSpace(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	opt.WriteBack = 0
+	opt.CacheMinFreeSpace = 1000
+	_, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+
+	// Pretend the disk has free bytes free
+	var free int64 = 2000
+	oldDiskFree := diskFree
+	diskFree = func(dir string) (int64, error) {
+		return free, nil
+	}
+	defer func() {
+		diskFree = oldDiskFree
+	}()
+
+	// Test funcs
+	var removed []string
+	remove := func(item *Item) {
+		removed = append(removed, item.name)
+		item.remove("TestCachePurgeMinFreeSpace")
+	}
+
+	// Make some test files
+	potato := c.Item("sub/dir/potato")
+	itemWrite(t, potato, "hello")
+	potato2 := c.Item("sub/dir2/potato2")
+	itemWrite(t, potato2, "hello2")
+
+	// Check nothing removed if open
+	free = 990
+	removed = nil
+	c._purgeMinFreeSpace(1000, remove)
+	assert.Equal(t, []string(nil), removed)
+
+	require.NoError(t, potato.Close(nil))
+	require.NoError(t, potato2.Close(nil))
+
+	// make potato2 definitely after potato
+	potato2.info.ATime = time.Now().Add(10 * time.Second)
+
+	// Check nothing removed if enough free space
+	free = 2000
+	removed = nil
+	c._purgeMinFreeSpace(-1, remove)
+	c._purgeMinFreeSpace(1000, remove)
+	assert.Equal(t, []string(nil), removed)
+
+	// Check only potato removed to free enough space
+	free = 996
+	removed = nil
+	c._purgeMinFreeSpace(1000, remove)
+	assert.Equal(t, []string{"sub/dir/potato"}, removed)
+	assert.Equal(t, []string{
+		`name="sub/dir2/potato2" opens=0 size=6`,
+	}, itemAsString(c))
+
+	// Check low space is reported and kicks the cleaner
+	c.freeMu.Lock()
+	c.freeChecked = time.Time{}
+	c.freeMu.Unlock()
+	assert.True(t, c.lowSpace())
+	select {
+	case <-c.cleanKick:
+	default:
+		t.Error("cleaner not kicked")
+	}
+	assert.Equal(t, free, c.Stats()["freeSpace"])
+
+	// Check the value is cached
+	free = 2000
+	assert.True(t, c.lowSpace())
+	c.freeMu.Lock()
+	c.freeChecked = time.Time{}
+	c.freeMu.Unlock()
+	assert.False(t, c.lowSpace())
+
+	// Check writes don't wait with enough free space
+	assert.NoError(t, c.waitFreeSpace())
+
+	// Check writes fail if the free space stays low
+	oldFreeSpaceTimeout := freeSpaceTimeout
+	freeSpaceTimeout = 100 * time.Millisecond
+	defer func() {
+		freeSpaceTimeout = oldFreeSpaceTimeout
+	}()
+	free = 500
+	c.freeMu.Lock()
+	c.freeChecked = time.Time{}
+	c.freeMu.Unlock()
+	assert.Equal(t, ErrNoSpace, c.waitFreeSpace())
+}
@@ -0,0 +1,205 @@
+// Package cachecrypt encrypts the files stored in the VFS cache.
+//
+// Data files start with a header holding a random file ID, followed
+// by blocks which are sealed independently with XChaCha20-Poly1305 so
+// they can be read and written at random offsets. The file ID and the
+// block index are authenticated with each block so blocks can't be
+// moved within a file or between files.
+//
+// Each block occupies a fixed size slot in the file on disk so the
+// cache files stay sparse. The blocks which have been written are
+// recorded in Blocks which the caller stores with its (encrypted)
+// metadata, so a slot which is all zeros is only read as a hole if it
+// has never been written.
+//
+// Metadata files are small so they are sealed in one go.
+package cachecrypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keyFileVersion = 1
+	saltSize       = 16
+	checkText      = "rclone vfs cache key"
+)
+
+var (
+	// additional data for metadata so it can't be confused with
+	// a data block
+	metaAD = []byte("rclone vfs cache metadata")
+
+	// ErrorBadPassword is returned if the password doesn't match
+	// the one the cache was made with
+	ErrorBadPassword = errors.New("vfs cache: wrong password for encrypted cache")
+)
+
+// Key encrypts and decrypts the files in one cache
+type Key struct {
+	aead cipher.AEAD
+}
+
+// keyFile is the format of the file storing the parameters of the key
+type keyFile struct {
+	Version int
+	Salt    []byte // salt for scrypt
+	Check   []byte // checkText sealed with the key
+}
+
+// Password returns the password to use to encrypt the cache.
+//
+// If password is empty then --password-command is run to read it.
+func Password(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	if len(fs.Config.PasswordCommand) == 0 {
+		return "", errors.New("vfs cache: need --vfs-cache-encrypt-password or --password-command to encrypt the cache")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(fs.Config.PasswordCommand[0], fs.Config.PasswordCommand[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		if ers := strings.TrimSpace(stderr.String()); ers != "" {
+			fs.Errorf(nil, "--password-command stderr: %s", ers)
+		}
+		return "", errors.Wrap(err, "vfs cache: password command failed")
+	}
+	password = strings.Trim(stdout.String(), "\r\n")
+	if password == "" {
+		return "", errors.New("vfs cache: password command returned an empty password")
+	}
+	return password, nil
+}
+
+// newKey makes a Key from the password and salt
+func newKey(password string, salt []byte) (*Key, error) {
+	dk, err := scrypt.Key([]byte(password), salt, 16384, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to derive key")
+	}
+	aead, err := chacha20poly1305.NewX(dk)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// LoadKey reads the key parameters from keyPath and makes the Key
+// from the password.
+//
+// If keyPath doesn't exist then a new salt is made and stored in it.
+func LoadKey(keyPath, password string) (k *Key, err error) {
+	var kf keyFile
+	data, err := ioutil.ReadFile(keyPath)
+	if err == nil {
+		err = json.Unmarshal(data, &kf)
+		if err != nil {
+			return nil, errors.Wrap(err, "vfs cache: corrupt key file")
+		}
+		if kf.Version != keyFileVersion {
+			return nil, errors.Errorf("vfs cache: unsupported key file version %d", kf.Version)
+		}
+		k, err = newKey(password, kf.Salt)
+		if err != nil {
+			return nil, err
+		}
+		check, err := k.Open(kf.Check)
+		if err != nil || string(check) != checkText {
+			return nil, ErrorBadPassword
+		}
+		return k, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "vfs cache: failed to read key file")
+	}
+
+	// Make a new key
+	kf.Version = keyFileVersion
+	kf.Salt = make([]byte, saltSize)
+	if _, err = rand.Read(kf.Salt); err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to make salt")
+	}
+	k, err = newKey(password, kf.Salt)
+	if err != nil {
+		return nil, err
+	}
+	kf.Check, err = k.Seal([]byte(checkText))
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(&kf, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(keyPath), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to make key directory")
+	}
+	err = ioutil.WriteFile(keyPath, data, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to write key file")
+	}
+	return k, nil
+}
+
+// nonce makes a new random nonce in the start of buf
+func (k *Key) nonce(buf []byte) ([]byte, error) {
+	nonce := buf[:k.aead.NonceSize()]
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to make nonce")
+	}
+	return nonce, nil
+}
+
+// Seal encrypts a metadata file returning the nonce followed by the
+// ciphertext
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	out := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(plaintext)+k.aead.Overhead())
+	nonce, err := k.nonce(out)
+	if err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(out, nonce, plaintext, metaAD), nil
+}
+
+// Open decrypts a metadata file made with Seal
+func (k *Key) Open(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < k.aead.NonceSize() {
+		return nil, errors.New("vfs cache: encrypted metadata too short")
+	}
+	nonceSize := k.aead.NonceSize()
+	plaintext, err := k.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], metaAD)
+	if err != nil {
+		return nil, errors.Wrap(err, "vfs cache: failed to decrypt metadata")
+	}
+	return plaintext, nil
+}
+
+// blockAD returns the additional data for block i of the file with
+// the given ID so blocks can't be moved to a different place in the
+// file or to a different file
+func blockAD(id []byte, i int64) []byte {
+	ad := make([]byte, len(id)+8)
+	copy(ad, id)
+	binary.BigEndian.PutUint64(ad[len(id):], uint64(i))
+	return ad
+}
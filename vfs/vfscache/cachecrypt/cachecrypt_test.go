@@ -0,0 +1,256 @@
+package cachecrypt
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizes(t *testing.T) {
+	for _, size := range []int64{0, 1, BlockSize - 1, BlockSize, BlockSize + 1, 10*BlockSize + 17} {
+		assert.Equal(t, size, DecryptedSize(EncryptedSize(size)), size)
+	}
+	assert.Equal(t, int64(headerSize), EncryptedSize(0))
+	assert.Equal(t, int64(headerSize+1+blockOverhead), EncryptedSize(1))
+	assert.Equal(t, int64(headerSize+2*slotSize), EncryptedSize(2*BlockSize))
+	assert.Equal(t, int64(0), DecryptedSize(0))
+}
+
+func TestBlocks(t *testing.T) {
+	var b Blocks
+	b.set(0)
+	b.set(9)
+	b.set(17)
+	assert.True(t, b.Has(0))
+	assert.False(t, b.Has(1))
+	assert.True(t, b.Has(9))
+	assert.True(t, b.Has(17))
+	assert.False(t, b.Has(100))
+	b.trim(10)
+	assert.True(t, b.Has(9))
+	assert.False(t, b.Has(17))
+	b.trim(9)
+	assert.False(t, b.Has(9))
+	assert.True(t, b.Has(0))
+	b.trim(0)
+	assert.Len(t, b, 0)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-cachecrypt-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	keyPath := filepath.Join(dir, "sub", "key.json")
+
+	k, err := LoadKey(keyPath, "potato")
+	require.NoError(t, err)
+	sealed, err := k.Seal([]byte("hello"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "hello")
+
+	// Same password reads the same key
+	k2, err := LoadKey(keyPath, "potato")
+	require.NoError(t, err)
+	plaintext, err := k2.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(plaintext))
+
+	// Wrong password is detected
+	_, err = LoadKey(keyPath, "carrot")
+	assert.Equal(t, ErrorBadPassword, err)
+
+	// Tampering is detected
+	sealed[len(sealed)-1] ^= 1
+	_, err = k2.Open(sealed)
+	assert.Error(t, err)
+}
+
+func TestPassword(t *testing.T) {
+	password, err := Password("potato")
+	require.NoError(t, err)
+	assert.Equal(t, "potato", password)
+
+	_, err = Password("")
+	assert.Error(t, err)
+}
+
+// Check the encrypted file behaves like a plain file when doing
+// random writes, truncates and reads
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-cachecrypt-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	k, err := LoadKey(filepath.Join(dir, "key.json"), "potato")
+	require.NoError(t, err)
+
+	osPath := filepath.Join(dir, "file")
+	fd, err := os.OpenFile(osPath, os.O_CREATE|os.O_RDWR, 0600)
+	require.NoError(t, err)
+	f, err := k.NewFile(fd, nil)
+	require.NoError(t, err)
+
+	var (
+		want   []byte
+		random = rand.New(rand.NewSource(1))
+		max    = 5*BlockSize + 1234
+	)
+	for i := 0; i < 200; i++ {
+		switch random.Intn(5) {
+		case 0:
+			size := random.Intn(max)
+			require.NoError(t, f.Truncate(int64(size)))
+			if size < len(want) {
+				want = want[:size]
+			} else {
+				want = append(want, make([]byte, size-len(want))...)
+			}
+		default:
+			off := random.Intn(max)
+			b := make([]byte, random.Intn(2*BlockSize))
+			_, _ = random.Read(b)
+			n, err := f.WriteAt(b, int64(off))
+			require.NoError(t, err)
+			require.Equal(t, len(b), n)
+			if end := off + len(b); end > len(want) {
+				want = append(want, make([]byte, end-len(want))...)
+			}
+			copy(want[off:], b)
+		}
+
+		fi, err := f.Stat()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(want)), fi.Size())
+
+		off := random.Intn(max)
+		b := make([]byte, random.Intn(2*BlockSize))
+		n, err := f.ReadAt(b, int64(off))
+		if off+len(b) > len(want) {
+			assert.Equal(t, io.EOF, err)
+		} else {
+			require.NoError(t, err)
+		}
+		if off < len(want) {
+			end := off + n
+			require.True(t, bytes.Equal(want[off:end], b[:n]), "read %d at %d", len(b), off)
+		} else {
+			assert.Equal(t, 0, n)
+		}
+	}
+	written := f.Written()
+	require.NoError(t, f.Close())
+
+	// The file on disk should be the encrypted size and reopening
+	// should give the same contents
+	fi, err := os.Stat(osPath)
+	require.NoError(t, err)
+	assert.Equal(t, EncryptedSize(int64(len(want))), fi.Size())
+
+	fd, err = os.Open(osPath)
+	require.NoError(t, err)
+	f, err = k.NewFile(fd, written)
+	require.NoError(t, err)
+	got := make([]byte, len(want))
+	_, err = f.ReadAt(got, 0)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(want, got))
+
+	// Corruption is detected
+	require.NoError(t, f.Close())
+	if len(want) > 0 {
+		fd, err = os.OpenFile(osPath, os.O_RDWR, 0600)
+		require.NoError(t, err)
+		_, err = fd.WriteAt([]byte{1}, headerSize+30)
+		require.NoError(t, err)
+		f, err = k.NewFile(fd, written)
+		require.NoError(t, err)
+		_, err = f.ReadAt(got[:1], 0)
+		assert.Error(t, err)
+		require.NoError(t, f.Close())
+	}
+}
+
+// Check blocks which are zeroed or moved between files are detected
+func TestFileTamper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-cachecrypt-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	k, err := LoadKey(filepath.Join(dir, "key.json"), "potato")
+	require.NoError(t, err)
+
+	// write makes a file at name with 3 blocks of data leaving the
+	// middle one as a hole
+	write := func(name string) Blocks {
+		fd, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_RDWR, 0600)
+		require.NoError(t, err)
+		f, err := k.NewFile(fd, nil)
+		require.NoError(t, err)
+		_, err = f.WriteAt(bytes.Repeat([]byte(name), BlockSize), 0)
+		require.NoError(t, err)
+		_, err = f.WriteAt(bytes.Repeat([]byte(name), BlockSize), 2*BlockSize)
+		require.NoError(t, err)
+		written := f.Written()
+		require.NoError(t, f.Close())
+		return written
+	}
+	// read reads block i of the file at name
+	read := func(name string, written Blocks, i int64) ([]byte, error) {
+		fd, err := os.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+		f, err := k.NewFile(fd, written)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, f.Close())
+		}()
+		b := make([]byte, BlockSize)
+		_, err = f.ReadAt(b, i*BlockSize)
+		return b, err
+	}
+	writtenA := write("a")
+	writtenB := write("b")
+	assert.True(t, writtenA.Has(0))
+	assert.False(t, writtenA.Has(1))
+	assert.True(t, writtenA.Has(2))
+
+	// The hole reads as zeros
+	b, err := read("a", writtenA, 1)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, BlockSize), b)
+
+	// Swap block 0 of b into a
+	slot := make([]byte, slotSize)
+	fdA, err := os.OpenFile(filepath.Join(dir, "a"), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	fdB, err := os.Open(filepath.Join(dir, "b"))
+	require.NoError(t, err)
+	_, err = fdB.ReadAt(slot, slotOffset(0))
+	require.NoError(t, err)
+	require.NoError(t, fdB.Close())
+	_, err = fdA.WriteAt(slot, slotOffset(0))
+	require.NoError(t, err)
+
+	// Zero block 2 of a
+	_, err = fdA.WriteAt(make([]byte, slotSize), slotOffset(2))
+	require.NoError(t, err)
+	require.NoError(t, fdA.Close())
+
+	_, err = read("a", writtenA, 0)
+	assert.Error(t, err)
+	_, err = read("a", writtenA, 2)
+	assert.Error(t, err)
+	b, err = read("b", writtenB, 0)
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte("b"), BlockSize), b)
+}
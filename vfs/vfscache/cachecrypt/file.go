@@ -0,0 +1,393 @@
+package cachecrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// BlockSize is the size of the plaintext in each block
+	BlockSize = 64 * 1024
+	// size of the Poly1305 authentication tag
+	tagSize = 16
+	// overhead of each block on disk - the nonce and the tag
+	blockOverhead = chacha20poly1305.NonceSizeX + tagSize
+	// size of each block on disk
+	slotSize = BlockSize + blockOverhead
+	// size of the random ID of each file
+	fileIDSize = 24
+	// size of the header at the start of each file - fileMagic
+	// followed by the file ID
+	headerSize = 8 + fileIDSize
+	// fileMagic starts the header of each file
+	fileMagic = "RCLVFSC\x01"
+)
+
+// EncryptedSize returns the size on disk of a file with size bytes of
+// plaintext.
+//
+// The last block is only as long as it needs to be so the plaintext
+// size can be worked out from the size on disk.
+func EncryptedSize(size int64) int64 {
+	encryptedSize := headerSize + (size/BlockSize)*slotSize
+	if rem := size % BlockSize; rem > 0 {
+		encryptedSize += rem + blockOverhead
+	}
+	return encryptedSize
+}
+
+// DecryptedSize returns the size of the plaintext of a file which is
+// encryptedSize bytes on disk.
+func DecryptedSize(encryptedSize int64) int64 {
+	encryptedSize -= headerSize
+	if encryptedSize <= 0 {
+		return 0
+	}
+	size := (encryptedSize / slotSize) * BlockSize
+	if rem := encryptedSize % slotSize; rem > blockOverhead {
+		size += rem - blockOverhead
+	}
+	return size
+}
+
+// Blocks records which blocks of a File have been written.
+//
+// It is a bitmap with a bit set for each block written.
+type Blocks []byte
+
+// Has returns true if block i has been written
+func (b Blocks) Has(i int64) bool {
+	return i/8 < int64(len(b)) && b[i/8]&(1<<uint(i%8)) != 0
+}
+
+// set marks block i as written
+func (b *Blocks) set(i int64) {
+	for int64(len(*b)) <= i/8 {
+		*b = append(*b, 0)
+	}
+	(*b)[i/8] |= 1 << uint(i%8)
+}
+
+// trim forgets the blocks from n onwards
+func (b *Blocks) trim(n int64) {
+	if int64(len(*b)) > (n+7)/8 {
+		*b = (*b)[:(n+7)/8]
+	}
+	if n%8 != 0 && len(*b) > 0 {
+		(*b)[len(*b)-1] &= 1<<uint(n%8) - 1
+	}
+}
+
+// File is an encrypted file in the cache. It has the subset of the
+// methods of *os.File the cache uses and reads and writes plaintext.
+//
+// It is safe to call the methods concurrently.
+type File struct {
+	k       *Key
+	mu      sync.Mutex
+	fd      *os.File
+	id      []byte // random ID of this file
+	header  bool   // set if the header has been written
+	size    int64  // size of the plaintext
+	written Blocks // which blocks have been written
+}
+
+// NewFile makes an encrypted File which reads and writes fd. File
+// takes ownership of fd and closes it on Close.
+//
+// written should be the Blocks returned by Written when the file was
+// last used, or nil if the file is new.
+func (k *Key) NewFile(fd *os.File, written Blocks) (*File, error) {
+	fi, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		k:       k,
+		fd:      fd,
+		id:      make([]byte, fileIDSize),
+		size:    DecryptedSize(fi.Size()),
+		written: append(Blocks(nil), written...),
+	}
+	if fi.Size() == 0 {
+		// New file - the header is written with the first block
+		if _, err = rand.Read(f.id); err != nil {
+			return nil, errors.Wrap(err, "vfs cache: failed to make file ID")
+		}
+		return f, nil
+	}
+	header := make([]byte, headerSize)
+	_, err = fd.ReadAt(header, 0)
+	if err == io.EOF || (err == nil && !bytes.HasPrefix(header, []byte(fileMagic))) {
+		return nil, errors.Errorf("vfs cache: %q is not an encrypted cache file", fd.Name())
+	} else if err != nil {
+		return nil, err
+	}
+	copy(f.id, header[len(fileMagic):])
+	f.header = true
+	return f, nil
+}
+
+// Written returns which blocks of the file have been written. This
+// should be stored securely and passed to NewFile when the file is
+// opened again.
+func (f *File) Written() Blocks {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append(Blocks(nil), f.written...)
+}
+
+// _writeHeader writes the header if it hasn't been written yet
+//
+// Call with the mutex held
+func (f *File) _writeHeader() error {
+	if f.header {
+		return nil
+	}
+	header := append([]byte(fileMagic), f.id...)
+	_, err := f.fd.WriteAt(header, 0)
+	if err != nil {
+		return err
+	}
+	f.header = true
+	return nil
+}
+
+// slotOffset returns the offset on disk of block i
+func slotOffset(i int64) int64 {
+	return headerSize + i*slotSize
+}
+
+// blockLen returns the length of the plaintext in block i of a file
+// with the given size
+func blockLen(i int64, size int64) int {
+	n := size - i*BlockSize
+	if n <= 0 {
+		return 0
+	}
+	if n > BlockSize {
+		n = BlockSize
+	}
+	return int(n)
+}
+
+// isZero returns true if buf is all zeros
+func isZero(buf []byte) bool {
+	for _, c := range buf {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// _readBlock reads and decrypts the n bytes of plaintext in block i.
+//
+// If the block is a hole which has never been written it returns
+// zeros and hole set.
+//
+// Call with the mutex held
+func (f *File) _readBlock(i int64, n int) (plaintext []byte, hole bool, err error) {
+	buf := make([]byte, n+blockOverhead)
+	_, err = f.fd.ReadAt(buf, slotOffset(i))
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+	if isZero(buf) && !f.written.Has(i) {
+		return make([]byte, n), true, nil
+	}
+	nonce, ciphertext := buf[:chacha20poly1305.NonceSizeX], buf[chacha20poly1305.NonceSizeX:]
+	plaintext, err = f.k.aead.Open(ciphertext[:0], nonce, ciphertext, blockAD(f.id, i))
+	if err != nil {
+		return nil, false, errors.Errorf("vfs cache: encrypted block %d of %q is corrupt", i, f.fd.Name())
+	}
+	return plaintext, false, nil
+}
+
+// _writeBlock encrypts and writes plaintext as block i
+//
+// Call with the mutex held
+func (f *File) _writeBlock(i int64, plaintext []byte) error {
+	err := f._writeHeader()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, chacha20poly1305.NonceSizeX, len(plaintext)+blockOverhead)
+	nonce, err := f.k.nonce(buf)
+	if err != nil {
+		return err
+	}
+	buf = f.k.aead.Seal(buf, nonce, plaintext, blockAD(f.id, i))
+	_, err = f.fd.WriteAt(buf, slotOffset(i))
+	if err != nil {
+		return err
+	}
+	f.written.set(i)
+	return nil
+}
+
+// _resize changes the length of the plaintext in block i from oldLen
+// to newLen, padding with zeros or cutting it short.
+//
+// Call with the mutex held
+func (f *File) _resize(i int64, oldLen, newLen int) error {
+	if oldLen == 0 || newLen == 0 || oldLen == newLen {
+		return nil
+	}
+	plaintext, hole, err := f._readBlock(i, oldLen)
+	if err != nil {
+		return err
+	}
+	if hole {
+		return nil
+	}
+	if newLen < oldLen {
+		plaintext = plaintext[:newLen]
+	} else {
+		plaintext = append(plaintext, make([]byte, newLen-oldLen)...)
+	}
+	return f._writeBlock(i, plaintext)
+}
+
+// _truncate changes the size of the plaintext
+//
+// Call with the mutex held
+func (f *File) _truncate(size int64) error {
+	if size == f.size {
+		return nil
+	}
+	err := f._writeHeader()
+	if err != nil {
+		return err
+	}
+	// The block which is partially filled at the smaller of the
+	// sizes needs re-encrypting with its new length
+	i := f.size / BlockSize
+	if size < f.size {
+		i = size / BlockSize
+	}
+	err = f._resize(i, blockLen(i, f.size), blockLen(i, size))
+	if err != nil {
+		return err
+	}
+	err = f.fd.Truncate(EncryptedSize(size))
+	if err != nil {
+		return err
+	}
+	f.written.trim((size + BlockSize - 1) / BlockSize)
+	f.size = size
+	return nil
+}
+
+// Truncate changes the size of the file
+func (f *File) Truncate(size int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f._truncate(size)
+}
+
+// ReadAt reads len(b) bytes from the File starting at byte offset
+// off. It returns io.EOF if fewer than len(b) bytes were read.
+func (f *File) ReadAt(b []byte, off int64) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if off < 0 {
+		return 0, errors.New("vfs cache: negative offset")
+	}
+	for n < len(b) && off < f.size {
+		i := off / BlockSize
+		plaintext, _, err := f._readBlock(i, blockLen(i, f.size))
+		if err != nil {
+			return n, err
+		}
+		nn := copy(b[n:], plaintext[off-i*BlockSize:])
+		n += nn
+		off += int64(nn)
+	}
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt writes len(b) bytes to the File starting at byte offset
+// off, extending the file if necessary.
+func (f *File) WriteAt(b []byte, off int64) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if off < 0 {
+		return 0, errors.New("vfs cache: negative offset")
+	}
+	if end := off + int64(len(b)); len(b) > 0 && end > f.size {
+		err = f._truncate(end)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for n < len(b) {
+		i := off / BlockSize
+		inner := int(off - i*BlockSize)
+		blockSize := blockLen(i, f.size)
+		var plaintext []byte
+		if inner == 0 && len(b)-n >= blockSize {
+			// overwriting the whole block
+			plaintext = b[n : n+blockSize]
+		} else {
+			plaintext, _, err = f._readBlock(i, blockSize)
+			if err != nil {
+				return n, err
+			}
+			copy(plaintext[inner:], b[n:])
+		}
+		err = f._writeBlock(i, plaintext)
+		if err != nil {
+			return n, err
+		}
+		nn := blockSize - inner
+		if nn > len(b)-n {
+			nn = len(b) - n
+		}
+		n += nn
+		off += int64(nn)
+	}
+	return n, nil
+}
+
+// Stat returns the FileInfo of the file with the size of the
+// plaintext
+func (f *File) Stat() (os.FileInfo, error) {
+	fi, err := f.fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fileInfo{FileInfo: fi, size: f.size}, nil
+}
+
+// Sync commits the contents of the file to stable storage
+func (f *File) Sync() error {
+	return f.fd.Sync()
+}
+
+// Close the file
+func (f *File) Close() error {
+	return f.fd.Close()
+}
+
+// fileInfo is an os.FileInfo with the size of the plaintext
+type fileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+// Size returns the size of the plaintext
+func (fi fileInfo) Size() int64 {
+	return fi.size
+}
@@ -0,0 +1,141 @@
+package vfscache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs/vfscache/cachecrypt"
+)
+
+// cacheFile is an open cache file. It is an *os.File unless the cache
+// is encrypted in which case it is a *cachecrypt.File.
+type cacheFile interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Closer
+	Truncate(size int64) error
+	Stat() (os.FileInfo, error)
+	Sync() error
+}
+
+// openFile opens the cache file at osPath decrypting it if necessary.
+//
+// written is the record of the blocks written to an encrypted cache
+// file stored in the item's metadata.
+//
+// No locking in Cache
+func (c *Cache) openFile(osPath string, flag int, written cachecrypt.Blocks) (cacheFile, error) {
+	fd, err := file.OpenFile(osPath, flag, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = file.SetSparse(fd)
+	if err != nil {
+		fs.Debugf(osPath, "vfs cache: failed to set as a sparse file: %v", err)
+	}
+	if c.key == nil {
+		return fd, nil
+	}
+	f, err := c.key.NewFile(fd, written)
+	if err != nil {
+		_ = fd.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// statSize returns the size of the data in the cache file at osPath
+//
+// No locking in Cache
+func (c *Cache) statSize(osPath string) (size int64, err error) {
+	if c.key == nil {
+		fi, err := os.Stat(osPath)
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	}
+	f, err := c.openFile(osPath, os.O_RDONLY, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer fs.CheckClose(f, &err)
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// decodeMeta reads a metadata file into v decrypting it if necessary
+//
+// No locking in Cache
+func (c *Cache) decodeMeta(in io.Reader, v interface{}) error {
+	if c.key == nil {
+		return json.NewDecoder(in).Decode(v)
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	data, err = c.key.Open(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeMeta writes v as a metadata file encrypting it if necessary
+//
+// No locking in Cache
+func (c *Cache) encodeMeta(out io.Writer, v interface{}) error {
+	if c.key == nil {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err = c.key.Seal(data)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// written returns the record of the blocks written to f if it is an
+// encrypted cache file or nil otherwise
+func written(f cacheFile) cachecrypt.Blocks {
+	if f, ok := f.(*cachecrypt.File); ok {
+		return f.Written()
+	}
+	return nil
+}
+
+// copyEncrypted uploads the decrypted contents of the cache file for
+// name to the remote
+//
+// No locking in Cache
+func (c *Cache) copyEncrypted(ctx context.Context, name string, modTime time.Time, blocks cachecrypt.Blocks) (o fs.Object, err error) {
+	f, err := c.openFile(c.toOSPath(name), os.O_RDONLY, blocks)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(f, &err)
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	in := ioutil.NopCloser(io.NewSectionReader(f, 0, fi.Size()))
+	return operations.RcatSize(ctx, c.fremote, name, in, fi.Size(), modTime)
+}
@@ -66,6 +66,11 @@ type Downloaders struct {
 	waiters    []waiter
 	errorCount int   // number of consecutive errors
 	lastErr    error // last error received
+
+	// Parallel streams - see streams.go
+	streamWg  sync.WaitGroup     // to keep track of stream goroutines
+	fetching  map[int64]struct{} // start of the chunks being fetched by streams
+	streamPos int64              // last read position streams were scheduled for
 }
 
 // waiter is a range we are waiting for and a channel to signal when
@@ -227,6 +232,11 @@ func (dls *Downloaders) Close(inErr error) (err error) {
 		}
 	}
 	dls.cancel()
+	// wait for the streams without the mutex as they call back
+	// into kickWaiters which needs the lock
+	dls.mu.Unlock()
+	dls.streamWg.Wait()
+	dls.mu.Lock()
 	dls.wg.Wait()
 	dls.dls = nil
 	dls._dispatchWaiters()
@@ -305,6 +315,13 @@ func (dls *Downloaders) _ensureDownloader(r ranges.Range) (err error) {
 		r.Size = 0
 	}
 
+	// Fetch chunks around the read position in parallel if
+	// --vfs-read-streams is set
+	if dls._useStreams() {
+		dls._scheduleStreams(r.Pos)
+		return nil
+	}
+
 	var dl *downloader
 	// Look through downloaders to find one in range
 	// If there isn't one then start a new one
@@ -90,15 +90,17 @@ func TestDownloaders(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, size, src.Size())
 
-	newTest := func() (*testItem, *Downloaders) {
+	newTestOpt := func(opt vfscommon.Options) (*testItem, *Downloaders) {
 		item := &testItem{
 			t:    t,
 			size: size,
 		}
-		opt := vfscommon.DefaultOpt
 		dls := New(item, &opt, remote, src)
 		return item, dls
 	}
+	newTest := func() (*testItem, *Downloaders) {
+		return newTestOpt(vfscommon.DefaultOpt)
+	}
 	cancel := func(dls *Downloaders) {
 		assert.NoError(t, dls.Close(nil))
 	}
@@ -129,4 +131,35 @@ func TestDownloaders(t *testing.T) {
 		time.Sleep(time.Second)
 		assert.True(t, item.HasRange(r))
 	})
+
+	t.Run("Streams", func(t *testing.T) {
+		opt := vfscommon.DefaultOpt
+		opt.ReadStreams = 4
+		opt.ReadStreamChunk = 1024 * 1024
+		item, dls := newTestOpt(opt)
+		defer cancel(dls)
+
+		for _, r := range []ranges.Range{
+			{Pos: 100, Size: 250},
+			{Pos: 3*1024*1024 - 100, Size: 250},
+			{Pos: 25000000, Size: 250},
+		} {
+			err := dls.Download(r)
+			require.NoError(t, err)
+			assert.True(t, item.HasRange(r))
+		}
+
+		// the chunks ahead of the read position should be fetched
+		// by the streams without being asked for
+		ahead := ranges.Range{Pos: 25000000 + 2*1024*1024, Size: 250}
+		for i := 0; i < 500 && !item.HasRange(ahead); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.True(t, item.HasRange(ahead))
+
+		// the partial chunk at the end should be read
+		r := ranges.Range{Pos: size - 100, Size: 100}
+		require.NoError(t, dls.Download(r))
+		assert.True(t, item.HasRange(r))
+	})
 }
@@ -0,0 +1,135 @@
+package downloaders
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/lib/ranges"
+)
+
+// size of the buffer each stream reads into before writing to the
+// cache file
+const streamBufferSize = 64 * 1024
+
+// _useStreams returns true if the item should be fetched with
+// multiple parallel streams rather than a single sequential
+// downloader.
+//
+// call with lock held
+func (dls *Downloaders) _useStreams() bool {
+	return dls.opt.ReadStreams > 1 &&
+		dls.opt.ReadStreamChunk > 0 &&
+		dls.src.Size() > int64(dls.opt.ReadStreamChunk)
+}
+
+// _scheduleStreams starts streams to fetch the missing chunks from
+// the chunk containing pos onwards, up to --vfs-read-streams chunks
+// ahead of pos. Chunks which are already being fetched or are
+// present in the cache are skipped.
+//
+// call with lock held
+func (dls *Downloaders) _scheduleStreams(pos int64) {
+	if dls.ctx.Err() != nil {
+		return
+	}
+	dls.streamPos = pos
+	var (
+		size      = dls.src.Size()
+		chunkSize = int64(dls.opt.ReadStreamChunk)
+		start     = pos - pos%chunkSize
+		end       = start + int64(dls.opt.ReadStreams)*chunkSize
+	)
+	if dls.fetching == nil {
+		dls.fetching = make(map[int64]struct{})
+	}
+	for chunk := start; chunk < end && chunk < size && len(dls.fetching) < dls.opt.ReadStreams; chunk += chunkSize {
+		if _, found := dls.fetching[chunk]; found {
+			continue
+		}
+		r := ranges.Range{Pos: chunk, Size: chunkSize}
+		r.Clip(size)
+		r = dls.item.FindMissing(r)
+		if r.IsEmpty() {
+			continue
+		}
+		dls.fetching[chunk] = struct{}{}
+		dls.streamWg.Add(1)
+		go dls.stream(chunk, r)
+	}
+}
+
+// stream fetches r, which is inside the chunk starting at chunk, then
+// lets the waiters know
+func (dls *Downloaders) stream(chunk int64, r ranges.Range) {
+	defer dls.streamWg.Done()
+	n, err := dls.fetchRange(r)
+	dls.mu.Lock()
+	delete(dls.fetching, chunk)
+	if dls.ctx.Err() != nil {
+		err = nil
+	}
+	dls._countErrors(n, err)
+	if err == nil {
+		// carry on reading ahead of the last read position
+		dls._scheduleStreams(dls.streamPos)
+	}
+	dls.mu.Unlock()
+	if err != nil {
+		fs.Errorf(dls.src, "vfs cache: failed to download range %v: %v", r, err)
+	}
+	err = dls.kickWaiters()
+	if err != nil {
+		fs.Errorf(dls.src, "vfs cache: failed to kick waiters: %v", err)
+	}
+}
+
+// fetchRange downloads r from the source and writes it to the cache
+// file, kicking the waiters as the data arrives.
+//
+// It returns the number of bytes read.
+func (dls *Downloaders) fetchRange(r ranges.Range) (n int64, err error) {
+	fs.Debugf(dls.src, "vfs cache: stream downloading %d-%d size %v", r.Pos, r.End(), fs.SizeSuffix(r.Size))
+	tr := accounting.Stats(dls.ctx).NewTransfer(dls.src)
+	defer func() {
+		tr.Done(err)
+	}()
+	in0, err := operations.NewReOpen(dls.ctx, dls.src, fs.Config.LowLevelRetries, &fs.RangeOption{Start: r.Pos, End: r.End() - 1})
+	if err != nil {
+		return 0, errors.Wrap(err, "vfs reader: failed to open source file")
+	}
+	in := tr.Account(in0)
+	defer fs.CheckClose(in, &err)
+
+	buf := make([]byte, streamBufferSize)
+	offset := r.Pos
+	for offset < r.End() {
+		if dls.ctx.Err() != nil {
+			return n, nil
+		}
+		nr, er := in.Read(buf)
+		if nr > 0 {
+			n += int64(nr)
+			_, _, ew := dls.item.WriteAtNoOverwrite(buf[:nr], offset)
+			if ew != nil {
+				return n, errors.Wrap(ew, "vfs reader: failed to write to cache file")
+			}
+			offset += int64(nr)
+			if waitErr := dls.kickWaiters(); waitErr != nil {
+				return n, waitErr
+			}
+		}
+		if er == io.EOF {
+			break
+		}
+		if er != nil {
+			return n, errors.Wrap(er, "vfs reader: failed to read source file")
+		}
+	}
+	if offset < r.End() {
+		return n, errors.Errorf("vfs reader: read %d bytes but expected %d", offset-r.Pos, r.Size)
+	}
+	return n, nil
+}
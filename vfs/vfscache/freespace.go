@@ -0,0 +1,153 @@
+package vfscache
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/diskusage"
+)
+
+const (
+	// how often to read the free space when writing to the cache
+	freeSpaceCheckInterval = time.Second
+	// how often to check the free space while a write is waiting
+	freeSpacePause = 100 * time.Millisecond
+)
+
+// how long a write waits for the free space to be above
+// --vfs-cache-min-free-space before failing - overridden in the tests
+var freeSpaceTimeout = 10 * time.Second
+
+// ErrNoSpace is returned when writing to the cache if the free space
+// stays below --vfs-cache-min-free-space
+var ErrNoSpace = errors.New("vfs cache: not enough free space to write to the cache")
+
+// diskFree reads the free space in the file system containing dir -
+// overridden in the tests
+var diskFree = diskusage.Free
+
+// freeSpace returns the free space on the file system containing the
+// cache or -1 if it can't be read
+//
+// No locking in Cache
+func (c *Cache) freeSpace() int64 {
+	free, err := diskFree(c.root)
+	if err != nil {
+		fs.Debugf(nil, "vfs cache: failed to read free space: %v", err)
+		return -1
+	}
+	return free
+}
+
+// lowSpace returns true if the free space on the cache file system is
+// below --vfs-cache-min-free-space.
+//
+// The free space is read at most every freeSpaceCheckInterval and
+// each time it is found to be low the cleaner is kicked to evict
+// items.
+func (c *Cache) lowSpace() bool {
+	minFree := int64(c.opt.CacheMinFreeSpace)
+	if minFree < 0 {
+		return false
+	}
+	c.freeMu.Lock()
+	defer c.freeMu.Unlock()
+	if time.Since(c.freeChecked) < freeSpaceCheckInterval {
+		return c.low
+	}
+	c.freeChecked = time.Now()
+	free := c.freeSpace()
+	low := free >= 0 && free < minFree
+	if low != c.low {
+		if low {
+			fs.Errorf(nil, "vfs cache: free space %v is below --vfs-cache-min-free-space %v - evicting files and slowing writes", fs.SizeSuffix(free), c.opt.CacheMinFreeSpace)
+		} else {
+			fs.Infof(nil, "vfs cache: free space %v is above --vfs-cache-min-free-space again", fs.SizeSuffix(free))
+		}
+		c.low = low
+	}
+	if low {
+		select {
+		case c.cleanKick <- struct{}{}:
+		default:
+		}
+	}
+	return low
+}
+
+// waitFreeSpace should be called before writing data to the cache.
+//
+// If the free space is low it blocks the write until the cleaner and
+// any uploads have brought the free space back above
+// --vfs-cache-min-free-space rather than filling the disk. If that
+// doesn't happen within freeSpaceTimeout it returns ErrNoSpace.
+func (c *Cache) waitFreeSpace() error {
+	if !c.lowSpace() {
+		return nil
+	}
+	timeout := time.After(freeSpaceTimeout)
+	ticker := time.NewTicker(freeSpacePause)
+	defer ticker.Stop()
+	for c.lowSpace() {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return ErrNoSpace
+		}
+	}
+	return nil
+}
+
+// purgeMinFreeSpace removes items until the free space is above
+// --vfs-cache-min-free-space
+func (c *Cache) purgeMinFreeSpace(minFree int64) {
+	c._purgeMinFreeSpace(minFree, func(item *Item) {
+		item.remove("low free space")
+	})
+}
+
+// _purgeMinFreeSpace removes items which aren't in use or pinned,
+// least recently used first, until it expects the free space to be
+// above minFree
+func (c *Cache) _purgeMinFreeSpace(minFree int64, remove func(item *Item)) {
+	if minFree < 0 {
+		return
+	}
+	free := c.freeSpace()
+	if free < 0 || free >= minFree {
+		return
+	}
+	need := minFree - free
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var items Items
+
+	// Make a slice of unused files which aren't pinned
+	for name, item := range c.item {
+		if !item.inUse() && !c.isPinned(name) {
+			items = append(items, item)
+		}
+	}
+
+	sort.Sort(items)
+
+	// Remove items until enough space should be free
+	for _, item := range items {
+		if need <= 0 {
+			break
+		}
+		size := item.getDiskSize()
+		need -= size
+		c.used -= size
+		remove(item)
+		// Remove the entry
+		delete(c.item, item.name)
+	}
+	if need > 0 {
+		fs.Debugf(nil, "vfs cache: can't free %v more to reach --vfs-cache-min-free-space as the rest of the cache is in use", fs.SizeSuffix(need))
+	}
+}
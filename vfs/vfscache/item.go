@@ -2,7 +2,6 @@ package vfscache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -12,8 +11,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/operations"
-	"github.com/rclone/rclone/lib/file"
 	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscache/cachecrypt"
 	"github.com/rclone/rclone/vfs/vfscache/downloaders"
 	"github.com/rclone/rclone/vfs/vfscache/writeback"
 )
@@ -54,7 +53,7 @@ type Item struct {
 	opens       int                      // number of times file is open
 	downloaders *downloaders.Downloaders // a record of the downloaders in action - may be nil
 	o           fs.Object                // object we are caching - may be nil
-	fd          *os.File                 // handle we are using to read and write to the file
+	fd          cacheFile                // handle we are using to read and write to the file
 	metaDirty   bool                     // set if the info needs writeback
 	modified    bool                     // set if the file has been modified since the last Open
 	info        Info                     // info about the file to persist to backing store
@@ -70,6 +69,8 @@ type Info struct {
 	Rs          ranges.Ranges // which parts of the file are present
 	Fingerprint string        // fingerprint of remote object
 	Dirty       bool          // set if the backing file has been modified
+	// which blocks of an encrypted backing file have been written
+	Blocks cachecrypt.Blocks `json:",omitempty"`
 }
 
 // Items are a slice of *Item ordered by ATime
@@ -115,7 +116,7 @@ func newItem(c *Cache, name string) (item *Item) {
 
 	// check the cache file exists
 	osPath := c.toOSPath(name)
-	fi, statErr := os.Stat(osPath)
+	size, statErr := c.statSize(osPath)
 	if statErr != nil {
 		if os.IsNotExist(statErr) {
 			item._removeMeta("cache file doesn't exist")
@@ -134,7 +135,7 @@ func newItem(c *Cache, name string) (item *Item) {
 
 	// Get size estimate (which is best we can do until Open() called)
 	if statErr == nil {
-		item.info.Size = fi.Size()
+		item.info.Size = size
 	}
 	return item
 }
@@ -146,6 +147,13 @@ func (item *Item) inUse() bool {
 	return item.opens != 0 || item.metaDirty || item.info.Dirty
 }
 
+// getStats returns whether the item is open and dirty and its size
+func (item *Item) getStats() (open, dirty bool, size int64) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	return item.opens != 0, item.metaDirty || item.info.Dirty, item.info.Size
+}
+
 // getATime returns the ATime of the item
 func (item *Item) getATime() time.Time {
 	item.mu.Lock()
@@ -176,8 +184,7 @@ func (item *Item) load() (exists bool, err error) {
 		return true, errors.Wrap(err, "vfs cache item: failed to read metadata")
 	}
 	defer fs.CheckClose(in, &err)
-	decoder := json.NewDecoder(in)
-	err = decoder.Decode(&item.info)
+	err = item.c.decodeMeta(in, &item.info)
 	if err != nil {
 		return true, errors.Wrap(err, "vfs cache item: corrupt metadata")
 	}
@@ -195,9 +202,10 @@ func (item *Item) _save() (err error) {
 		return errors.Wrap(err, "vfs cache item: failed to write metadata")
 	}
 	defer fs.CheckClose(out, &err)
-	encoder := json.NewEncoder(out)
-	encoder.SetIndent("", "\t")
-	err = encoder.Encode(item.info)
+	if item.fd != nil {
+		item.info.Blocks = written(item.fd)
+	}
+	err = item.c.encodeMeta(out, item.info)
 	if err != nil {
 		return errors.Wrap(err, "vfs cache item: failed to encode metadata")
 	}
@@ -220,17 +228,12 @@ func (item *Item) _truncate(size int64) (err error) {
 	fd := item.fd
 	if fd == nil {
 		osPath := item.c.toOSPath(item.name) // No locking in Cache
-		fd, err = file.OpenFile(osPath, os.O_CREATE|os.O_WRONLY, 0600)
+		fd, err = item.c.openFile(osPath, os.O_CREATE|os.O_RDWR, item.info.Blocks)
 		if err != nil {
 			return errors.Wrap(err, "vfs cache: truncate: failed to open cache file")
 		}
 
 		defer fs.CheckClose(fd, &err)
-
-		err = file.SetSparse(fd)
-		if err != nil {
-			fs.Debugf(item.name, "vfs cache: truncate: failed to set as a sparse file: %v", err)
-		}
 	}
 
 	fs.Debugf(item.name, "vfs cache: truncate to size=%d", size)
@@ -239,6 +242,7 @@ func (item *Item) _truncate(size int64) (err error) {
 	if err != nil {
 		return errors.Wrap(err, "vfs cache: truncate")
 	}
+	item.info.Blocks = written(fd)
 
 	item.info.Size = size
 
@@ -319,20 +323,21 @@ func (item *Item) Truncate(size int64) (err error) {
 //
 // Call with mutex held
 func (item *Item) _getSize() (size int64, err error) {
-	var fi os.FileInfo
 	if item.fd != nil {
+		var fi os.FileInfo
 		fi, err = item.fd.Stat()
+		if err == nil {
+			size = fi.Size()
+		}
 	} else {
 		osPath := item.c.toOSPath(item.name) // No locking in Cache
-		fi, err = os.Stat(osPath)
+		size, err = item.c.statSize(osPath)
 	}
 	if err != nil {
 		if os.IsNotExist(err) && item.o != nil {
 			size = item.o.Size()
 			err = nil
 		}
-	} else {
-		size = fi.Size()
 	}
 	if err == nil {
 		item.info.Size = size
@@ -427,14 +432,10 @@ func (item *Item) Open(o fs.Object) (err error) {
 	}
 	item.modified = false
 
-	fd, err := file.OpenFile(osPath, os.O_RDWR, 0600)
+	fd, err := item.c.openFile(osPath, os.O_RDWR, item.info.Blocks)
 	if err != nil {
 		return errors.Wrap(err, "vfs cache item: open failed")
 	}
-	err = file.SetSparse(fd)
-	if err != nil {
-		fs.Debugf(item.name, "vfs cache: failed to set as a sparse file: %v", err)
-	}
 	item.fd = fd
 
 	err = item._save()
@@ -488,9 +489,16 @@ func (item *Item) _store(ctx context.Context, storeFn StoreFn) (err error) {
 
 	// Object has disappeared if cacheObj == nil
 	if cacheObj != nil {
-		o, name := item.o, item.name
+		o, name, modTime, blocks := item.o, item.name, item.info.ModTime, item.info.Blocks
+		if item.fd != nil {
+			blocks = written(item.fd)
+		}
 		item.mu.Unlock()
-		o, err := operations.Copy(ctx, item.c.fremote, o, name, cacheObj)
+		if item.c.key != nil {
+			o, err = item.c.copyEncrypted(ctx, name, modTime, blocks)
+		} else {
+			o, err = operations.Copy(ctx, item.c.fremote, o, name, cacheObj)
+		}
 		item.mu.Lock()
 		if err != nil {
 			return errors.Wrap(err, "vfs cache: failed to transfer file from cache to remote")
@@ -587,6 +595,7 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 	if item.fd == nil {
 		checkErr(errors.New("vfs cache item: internal error: didn't Open file"))
 	} else {
+		item.info.Blocks = written(item.fd)
 		checkErr(item.fd.Close())
 		item.fd = nil
 	}
@@ -613,13 +622,7 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 			checkErr(item._store(context.Background(), storeFn))
 		} else {
 			// asynchronous writeback
-			item.c.writeback.SetID(&item.writeBackID)
-			id := item.writeBackID
-			item.mu.Unlock()
-			item.c.writeback.Add(id, item.name, item.modified, func(ctx context.Context) error {
-				return item.store(ctx, storeFn)
-			})
-			item.mu.Lock()
+			item._queueWriteBack(storeFn)
 		}
 	}
 
@@ -629,6 +632,22 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 	return err
 }
 
+// _queueWriteBack puts the item on the writeback queue to be
+// uploaded later
+//
+// call with lock held - it will be unlocked and relocked
+func (item *Item) _queueWriteBack(storeFn StoreFn) {
+	item.c.writeback.SetID(&item.writeBackID)
+	id := item.writeBackID
+	item.mu.Unlock()
+	item.c.writeback.Add(id, item.name, item.modified, func(ctx context.Context) error {
+		err := item.store(ctx, storeFn)
+		item.c.networkError(err)
+		return err
+	})
+	item.mu.Lock()
+}
+
 // reload is called with valid items recovered from a cache reload.
 //
 // If they are dirty then it makes sure they get uploaded
@@ -643,16 +662,27 @@ func (item *Item) reload(ctx context.Context) error {
 		return nil
 	}
 	// see if the object still exists
-	obj, _ := item.c.fremote.NewObject(ctx, item.name)
-	// open the file with the object (or nil)
-	err := item.Open(obj)
-	if err != nil {
-		return err
-	}
-	// close the file to execute the writeback if needed
-	err = item.Close(nil)
-	if err != nil {
-		return err
+	obj, err := item.c.fremote.NewObject(ctx, item.name)
+	if item.c.networkError(err) {
+		// We can't tell whether the object exists so don't
+		// check it against the cache as that would discard
+		// the changes. Instead queue the upload for when the
+		// remote comes back.
+		fs.Infof(item.name, "vfs cache: remote unreachable - queuing for upload without checking: %v", err)
+		item.mu.Lock()
+		item._queueWriteBack(nil)
+		item.mu.Unlock()
+	} else {
+		// open the file with the object (or nil)
+		err = item.Open(obj)
+		if err != nil {
+			return err
+		}
+		// close the file to execute the writeback if needed
+		err = item.Close(nil)
+		if err != nil {
+			return err
+		}
 	}
 	// put the file into the directory listings
 	size, err := item._getSize()
@@ -666,6 +696,38 @@ func (item *Item) reload(ctx context.Context) error {
 	return nil
 }
 
+// upToDate returns true if the whole of o is in the cache already
+func (item *Item) upToDate(ctx context.Context, o fs.Object) bool {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	return item.info.Fingerprint != "" &&
+		item.info.Fingerprint == fs.Fingerprint(ctx, o, false) &&
+		item.info.Size == o.Size() &&
+		item._exists() &&
+		item._present()
+}
+
+// download makes sure the whole of o is in the cache, fetching any
+// parts which aren't with the downloaders
+func (item *Item) download(o fs.Object) (err error) {
+	err = item.Open(o)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := item.Close(nil)
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if item._present() {
+		return nil
+	}
+	return item._ensure(0, item.info.Size)
+}
+
 // check the fingerprint of an object and update the item or delete
 // the cached file accordingly
 //
@@ -914,6 +976,10 @@ func (item *Item) WriteAt(b []byte, off int64) (n int, err error) {
 		return 0, errors.New("vfs cache item WriteAt: internal error: didn't Open file")
 	}
 	item.mu.Unlock()
+	err = item.c.waitFreeSpace()
+	if err != nil {
+		return 0, err
+	}
 	// Do the writing with Item.mu unlocked
 	n, err = item.fd.WriteAt(b, off)
 	if err == nil && n != len(b) {
@@ -948,6 +1014,10 @@ func (item *Item) WriteAt(b []byte, off int64) (n int, err error) {
 // It returns n the total bytes processed and skipped the number of
 // bytes which were processed but not actually written to the file.
 func (item *Item) WriteAtNoOverwrite(b []byte, off int64) (n int, skipped int, err error) {
+	err = item.c.waitFreeSpace()
+	if err != nil {
+		return 0, 0, err
+	}
 	item.mu.Lock()
 
 	var (
@@ -597,3 +597,55 @@ func TestItemReadWrite(t *testing.T) {
 		assert.False(t, item.remove(fileName))
 	})
 }
+
+func TestItemEncrypted(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	opt.WriteBack = 0
+	opt.CacheEncrypt = true
+	opt.CacheEncryptPass = "potato"
+	r, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	assert.Contains(t, c.root, "vfsCrypt")
+
+	// Write a new file and check it is uploaded decrypted
+	contents := "secret contents " + random.String(100)
+	item, _ := c.get("secret")
+	itemWrite(t, item, contents)
+	require.NoError(t, item.Close(nil))
+	checkObject(t, r, "secret", contents)
+
+	// The files on disk shouldn't have the contents in
+	data, err := ioutil.ReadFile(c.toOSPath("secret"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret contents")
+	meta, err := ioutil.ReadFile(c.toOSPathMeta("secret"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(meta), "Rs")
+
+	// Read part of an existing file into the cache
+	contents2, obj, item2 := newFileLength(t, r, c, "existing", 300*1024)
+	require.NoError(t, item2.Open(obj))
+	buf := make([]byte, 10)
+	n, err := item2.ReadAt(buf, 200*1024)
+	require.NoError(t, err)
+	assert.Equal(t, contents2[200*1024:200*1024+n], string(buf[:n]))
+	size, err := item2.GetSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(300*1024), size)
+	require.NoError(t, item2.Close(nil))
+
+	// Check the metadata can be read back
+	info := item2.info
+	c.mu.Lock()
+	delete(c.item, item2.name)
+	c.mu.Unlock()
+	item3, _ := c._get("existing")
+	assert.Equal(t, info.Rs, item3.info.Rs)
+	assert.Equal(t, int64(300*1024), item3.info.Size)
+
+	// A cache with a different password can't be opened
+	opt.CacheEncryptPass = "carrot"
+	_, err = New(context.Background(), r.Fremote, &opt, nil)
+	assert.Error(t, err)
+}
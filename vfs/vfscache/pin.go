@@ -0,0 +1,320 @@
+// Pinning files and directories so they are kept in the cache
+
+package vfscache
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// NB Cache.pinMu may be taken with Cache.mu held but Cache.mu must
+// never be taken with Cache.pinMu held.
+
+// newPinFilter makes the filter for the --vfs-pin globs or returns
+// nil if there aren't any
+func newPinFilter(opt *vfscommon.Options) (*filter.Filter, error) {
+	if len(opt.Pin) == 0 {
+		return nil, nil
+	}
+	filterOpt := filter.DefaultOpt
+	filterOpt.IncludeRule = opt.Pin
+	pinFilter, err := filter.NewFilter(&filterOpt)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad --vfs-pin")
+	}
+	return pinFilter, nil
+}
+
+// loadPins reads the pinned paths from disk
+func (c *Cache) loadPins() error {
+	data, err := ioutil.ReadFile(c.pinPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read pins")
+	}
+	var pins []string
+	err = json.Unmarshal(data, &pins)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode pins")
+	}
+	for _, name := range pins {
+		c.pins[name] = struct{}{}
+	}
+	return nil
+}
+
+// _savePins writes the pinned paths to disk
+//
+// call with pinMu held
+func (c *Cache) _savePins() error {
+	err := os.MkdirAll(filepath.Dir(c.pinPath), 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make pins directory")
+	}
+	data, err := json.MarshalIndent(c._pinList(), "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode pins")
+	}
+	err = ioutil.WriteFile(c.pinPath, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write pins")
+	}
+	return nil
+}
+
+// _pinList returns the pinned paths sorted
+//
+// call with pinMu held
+func (c *Cache) _pinList() []string {
+	pins := make([]string, 0, len(c.pins))
+	for name := range c.pins {
+		pins = append(pins, name)
+	}
+	sort.Strings(pins)
+	return pins
+}
+
+// Pins returns the paths pinned with Pin
+func (c *Cache) Pins() []string {
+	c.pinMu.Lock()
+	defer c.pinMu.Unlock()
+	return c._pinList()
+}
+
+// Pin marks the file or directory name as pinned.
+//
+// Pinned files, and all the files in pinned directories, are
+// downloaded into the cache in the background, kept up to date when
+// they change on the remote and are never evicted.
+//
+// name should be a remote path not an osPath
+func (c *Cache) Pin(name string) error {
+	name = clean(name)
+	c.pinMu.Lock()
+	c.pins[name] = struct{}{}
+	err := c._savePins()
+	c.pinMu.Unlock()
+	if err != nil {
+		return err
+	}
+	fs.Infof(name, "vfs cache: pinned")
+	c.kickPinner()
+	return nil
+}
+
+// Unpin removes the pin from name which should have been pinned with
+// Pin.  The files it pinned may be evicted from the cache as normal
+// afterwards.
+//
+// name should be a remote path not an osPath
+func (c *Cache) Unpin(name string) error {
+	name = clean(name)
+	c.pinMu.Lock()
+	defer c.pinMu.Unlock()
+	if _, found := c.pins[name]; !found {
+		return errors.Errorf("%q is not pinned", name)
+	}
+	delete(c.pins, name)
+	err := c._savePins()
+	if err != nil {
+		return err
+	}
+	fs.Infof(name, "vfs cache: unpinned")
+	return nil
+}
+
+// isPinned returns whether the file name is pinned either by Pin or
+// by --vfs-pin
+//
+// name should be a remote path not an osPath
+func (c *Cache) isPinned(name string) bool {
+	if c.pinFilter != nil && c.pinFilter.Include(name, 0, time.Time{}) {
+		return true
+	}
+	c.pinMu.Lock()
+	defer c.pinMu.Unlock()
+	for pin := range c.pins {
+		if pin == "" || name == pin || strings.HasPrefix(name, pin+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// kickPinner wakes up the pinner if it isn't busy
+func (c *Cache) kickPinner() {
+	select {
+	case c.pinKick <- struct{}{}:
+	default:
+	}
+}
+
+// Changed should be called when name is reported as changed on the
+// remote.  It wakes the pinner if name might be or contain pinned
+// files.
+//
+// name should be a remote path not an osPath
+func (c *Cache) Changed(name string, entryType fs.EntryType) {
+	if c.changeAffectsPins(clean(name), entryType) {
+		c.kickPinner()
+	}
+}
+
+// changeAffectsPins returns whether a change to name might change
+// pinned files
+func (c *Cache) changeAffectsPins(name string, entryType fs.EntryType) bool {
+	if c.pinFilter != nil {
+		if entryType == fs.EntryDirectory || c.pinFilter.Include(name, 0, time.Time{}) {
+			return true
+		}
+	}
+	c.pinMu.Lock()
+	defer c.pinMu.Unlock()
+	for pin := range c.pins {
+		if pin == "" || name == "" || name == pin || strings.HasPrefix(name, pin+"/") || strings.HasPrefix(pin, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pinner downloads pinned files when kicked and every --vfs-pin-refresh
+//
+// doesn't return until context is cancelled
+func (c *Cache) pinner(ctx context.Context) {
+	var tick <-chan time.Time
+	if c.opt.PinRefresh > 0 {
+		ticker := time.NewTicker(c.opt.PinRefresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		c.pinAll(ctx)
+		select {
+		case <-c.pinKick:
+		case <-tick:
+		case <-ctx.Done():
+			fs.Debugf(nil, "vfs cache: pinner exiting")
+			return
+		}
+	}
+}
+
+// pinAll makes sure all the pinned files are in the cache and up to
+// date
+func (c *Cache) pinAll(ctx context.Context) {
+	pins := c.Pins()
+	if len(pins) == 0 && c.pinFilter == nil {
+		return
+	}
+	seen := map[string]struct{}{}
+	pinObject := func(o fs.Object) {
+		if _, found := seen[o.Remote()]; found {
+			return
+		}
+		seen[o.Remote()] = struct{}{}
+		c.pinObject(ctx, o)
+	}
+	if c.pinFilter != nil {
+		err := c.pinWalk(ctx, "", c.pinFilter.IncludeDirectory(ctx, c.fremote), pinObject)
+		if err != nil {
+			fs.Errorf(nil, "vfs cache: failed to list files for --vfs-pin: %v", err)
+		}
+	}
+	for _, name := range pins {
+		if ctx.Err() != nil {
+			return
+		}
+		if name != "" {
+			o, err := c.fremote.NewObject(ctx, name)
+			if err == nil {
+				pinObject(o)
+				continue
+			}
+		}
+		err := c.pinWalk(ctx, name, nil, pinObject)
+		if err == fs.ErrorDirNotFound {
+			fs.Debugf(name, "vfs cache: pinned path not found on remote")
+		} else if err != nil {
+			fs.Errorf(name, "vfs cache: failed to list pinned directory: %v", err)
+		}
+	}
+}
+
+// pinWalk calls fn for every object in dir and its subdirectories
+// which includeDir (if set) allows
+func (c *Cache) pinWalk(ctx context.Context, dir string, includeDir func(string) (bool, error), fn func(fs.Object)) error {
+	entries, err := c.fremote.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch x := entry.(type) {
+		case fs.Object:
+			fn(x)
+		case fs.Directory:
+			if includeDir != nil {
+				include, err := includeDir(x.Remote())
+				if err != nil {
+					return err
+				}
+				if !include {
+					continue
+				}
+			}
+			err = c.pinWalk(ctx, x.Remote(), includeDir, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pinObject downloads o into the cache if it is pinned and isn't
+// there already
+func (c *Cache) pinObject(ctx context.Context, o fs.Object) {
+	// walking for --vfs-pin finds all the files in the directories
+	// which might contain pinned files
+	if !c.isPinned(o.Remote()) {
+		return
+	}
+	item := c.Item(o.Remote())
+	if item.IsDirty() || item.upToDate(ctx, o) {
+		return
+	}
+	fs.Infof(o, "vfs cache: downloading pinned file")
+	err := item.download(o)
+	if err != nil {
+		fs.Errorf(o, "vfs cache: failed to download pinned file: %v", err)
+	}
+}
+
+// pinnedStats returns the number of pinned files in the cache and
+// their size on disk
+func (c *Cache) pinnedStats() (files int, bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, item := range c.item {
+		if c.isPinned(name) {
+			files++
+			bytes += item.getDiskSize()
+		}
+	}
+	return files, bytes
+}
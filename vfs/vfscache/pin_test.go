@@ -0,0 +1,124 @@
+package vfscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t1 = time.Date(2020, 10, 17, 12, 0, 0, 0, time.UTC)
+
+// waitPresent waits for the item to be fully downloaded
+func waitPresent(t *testing.T, item *Item) {
+	for i := 0; i < 100; i++ {
+		if item.present() && item.Exists() {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %q to be downloaded", item.name)
+}
+
+func TestCachePin(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 0
+	opt.PinRefresh = 0
+	opt.WriteBack = 0
+	r, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	r.WriteObject(ctx, "dir/pinned", "pinned contents", t1)
+	other := r.WriteObject(ctx, "other", "other contents", t1)
+
+	assert.False(t, c.isPinned("dir/pinned"))
+	require.NoError(t, c.Pin("/dir/"))
+	assert.Equal(t, []string{"dir"}, c.Pins())
+	assert.True(t, c.isPinned("dir/pinned"))
+	assert.False(t, c.isPinned("dir2/pinned"))
+	assert.False(t, c.isPinned("other"))
+
+	pinned := c.Item("dir/pinned")
+	waitPresent(t, pinned)
+
+	files, bytes := c.pinnedStats()
+	assert.Equal(t, 1, files)
+	assert.Equal(t, int64(len("pinned contents")), bytes)
+
+	// Pins are persisted
+	c.pins = map[string]struct{}{}
+	require.NoError(t, c.loadPins())
+	assert.Equal(t, []string{"dir"}, c.Pins())
+
+	// Pinned files aren't purged
+	o, err := r.Fremote.NewObject(ctx, other.Path)
+	require.NoError(t, err)
+	require.NoError(t, c.Item("other").download(o))
+	var removed []string
+	c._purgeOld(-10*time.Second, func(item *Item) {
+		removed = append(removed, item.name)
+	})
+	assert.Equal(t, []string{"other"}, removed)
+
+	require.NoError(t, c.Unpin("dir"))
+	assert.Error(t, c.Unpin("dir"))
+	assert.Equal(t, []string{}, c.Pins())
+	assert.False(t, c.isPinned("dir/pinned"))
+}
+
+func TestCachePinFilter(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 0
+	opt.PinRefresh = 0
+	opt.WriteBack = 0
+	opt.Pin = []string{"*.pdf"}
+	r, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	r.WriteObject(ctx, "dir/doc.pdf", "pdf contents", t1)
+	r.WriteObject(ctx, "dir/doc.txt", "txt contents", t1)
+
+	assert.True(t, c.isPinned("dir/doc.pdf"))
+	assert.False(t, c.isPinned("dir/doc.txt"))
+
+	c.pinAll(ctx)
+	waitPresent(t, c.Item("dir/doc.pdf"))
+	assert.False(t, c.Item("dir/doc.txt").Exists())
+}
+
+func TestCachePinChanged(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 0
+	opt.PinRefresh = 0
+	opt.WriteBack = 0
+	opt.Pin = []string{"*.pdf"}
+	_, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	require.NoError(t, c.Pin("dir/sub"))
+
+	for _, test := range []struct {
+		name      string
+		entryType fs.EntryType
+		want      bool
+	}{
+		{"dir/sub/file", fs.EntryObject, true},
+		{"dir/sub", fs.EntryObject, true},
+		{"dir", fs.EntryObject, true},
+		{"dir/other", fs.EntryObject, false},
+		{"other", fs.EntryObject, false},
+		{"other.pdf", fs.EntryObject, true},
+		{"other", fs.EntryDirectory, true},
+		{"", fs.EntryObject, true},
+	} {
+		assert.Equal(t, test.want, c.changeAffectsPins(test.name, test.entryType), test.name)
+	}
+}
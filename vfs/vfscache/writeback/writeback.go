@@ -5,10 +5,12 @@ package writeback
 import (
 	"container/heap"
 	"context"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/vfs/vfscommon"
@@ -34,6 +36,7 @@ type WriteBack struct {
 	timer   *time.Timer               // next scheduled time for the uploader
 	expiry  time.Time                 // time the next item exires or IsZero
 	uploads int                       // number of uploads in progress
+	offline bool                      // if set, uploads are held until the remote is reachable
 
 	// read and written with atomic
 	id Handle // id of the last writeBackItem created
@@ -72,6 +75,7 @@ type writeBackItem struct {
 	putFn     PutFn              // To write the object data
 	tries     int                // number of times we have tried to upload
 	delay     time.Duration      // delay between upload attempts
+	err       error              // error from the last upload attempt if any
 }
 
 // A writeBackItems implements a priority queue by implementing
@@ -341,7 +345,13 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 	wbItem.uploading = false
 	wb.uploads--
 
-	if err != nil {
+	wbItem.err = err
+	if err != nil && fserrors.IsNoRetryError(err) {
+		// The upload can never succeed so don't retry it. The
+		// file stays dirty in the cache.
+		fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, not retrying: %v", wbItem.tries, err)
+		wb._delItem(wbItem)
+	} else if err != nil {
 		// FIXME should this have a max number of transfer attempts?
 		wbItem.delay *= 2
 		if wbItem.delay > maxUploadDelay {
@@ -351,6 +361,7 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 			fs.Infof(wbItem.name, "vfs cache: upload canceled")
 			// Upload was cancelled so reset timer
 			wbItem.delay = wb.opt.WriteBack
+			wbItem.err = nil
 		} else {
 			fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, will retry in %v: %v", wbItem.tries, wbItem.delay, err)
 		}
@@ -413,6 +424,12 @@ func (wb *WriteBack) processItems(ctx context.Context) {
 		return
 	}
 
+	if wb.offline {
+		fs.Debugf(nil, "vfs cache: delaying writeback as the remote is offline")
+		wb._stopTimer()
+		return
+	}
+
 	resetTimer := true
 	for wbItem := wb._peekItem(); wbItem != nil && time.Until(wbItem.expiry) <= 0; wbItem = wb._peekItem() {
 		// If reached transfer limit don't restart the timer
@@ -439,6 +456,116 @@ func (wb *WriteBack) processItems(ctx context.Context) {
 	}
 }
 
+// QueueInfo is information about an item queued for upload, returned
+// by Queue
+type QueueInfo struct {
+	Name      string  `json:"name"`            // name (full path) of the file
+	ID        Handle  `json:"id"`              // id of the queue item
+	Size      int64   `json:"size"`            // size of the file in bytes if known
+	Expiry    float64 `json:"expiry"`          // seconds from now when the file is eligible for upload
+	Tries     int     `json:"tries"`           // number of times we have tried to upload
+	Delay     float64 `json:"delay"`           // delay between upload attempts in seconds
+	Uploading bool    `json:"uploading"`       // true if the item is being uploaded
+	Error     string  `json:"error,omitempty"` // error from the last upload attempt if any
+}
+
+// Queue returns info about the items in the writeback queue, the ones
+// being uploaded first, then in the order they will be uploaded.
+//
+// Size is not filled in.
+func (wb *WriteBack) Queue() []QueueInfo {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	now := time.Now()
+	items := make([]*writeBackItem, 0, len(wb.lookup))
+	for _, wbItem := range wb.lookup {
+		items = append(items, wbItem)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.uploading != b.uploading {
+			return a.uploading
+		}
+		if a.expiry.Equal(b.expiry) {
+			return a.id < b.id
+		}
+		return a.expiry.Before(b.expiry)
+	})
+	queue := make([]QueueInfo, 0, len(items))
+	for _, wbItem := range items {
+		info := QueueInfo{
+			Name:      wbItem.name,
+			ID:        wbItem.id,
+			Expiry:    wbItem.expiry.Sub(now).Seconds(),
+			Tries:     wbItem.tries,
+			Delay:     wbItem.delay.Seconds(),
+			Uploading: wbItem.uploading,
+		}
+		if wbItem.err != nil {
+			info.Error = wbItem.err.Error()
+		}
+		queue = append(queue, info)
+	}
+	return queue
+}
+
+// SetExpiry sets the time the item with id will be uploaded.
+//
+// Setting it in the past makes the upload start as soon as possible.
+// It returns an error if the item isn't in the queue or is being
+// uploaded already.
+func (wb *WriteBack) SetExpiry(id Handle, expiry time.Time) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	wbItem, ok := wb.lookup[id]
+	if !ok {
+		return errors.Errorf("item %d not found in the upload queue", id)
+	}
+	if wbItem.uploading || !wbItem.onHeap {
+		return errors.Errorf("item %d is being uploaded already", id)
+	}
+	wb.items._update(wbItem, expiry)
+	wb._resetTimer()
+	return nil
+}
+
+// SetOffline sets whether the remote is reachable or not.
+//
+// While offline, items stay queued rather than being uploaded. When
+// the remote comes back online all the queued items are uploaded
+// straight away.
+func (wb *WriteBack) SetOffline(offline bool) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	if wb.offline == offline {
+		return
+	}
+	wb.offline = offline
+	if offline {
+		fs.Infof(nil, "vfs cache: holding %d uploads until the remote is back online", len(wb.items))
+		wb._stopTimer()
+		return
+	}
+	if len(wb.items) > 0 {
+		fs.Infof(nil, "vfs cache: remote back online - starting %d held uploads", len(wb.items))
+	}
+	now := time.Now()
+	for _, wbItem := range wb.items {
+		wbItem.delay = wb.opt.WriteBack
+		wbItem.expiry = now
+	}
+	heap.Init(&wb.items)
+	wb._resetTimer()
+}
+
+// Offline returns whether uploads are being held as the remote is
+// offline
+func (wb *WriteBack) Offline() bool {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.offline
+}
+
 // Stats return the number of uploads in progress and queued
 func (wb *WriteBack) Stats() (uploadsInProgress, uploadsQueued int) {
 	wb.mu.Lock()
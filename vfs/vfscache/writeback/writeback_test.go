@@ -11,8 +11,10 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newTestWriteBack(t *testing.T) (wb *WriteBack, cancel func()) {
@@ -347,6 +349,67 @@ func TestWriteBackAddFailRetry(t *testing.T) {
 	checkNotInLookup(t, wb, wbItem)
 }
 
+// Test an upload failing with an error which can't be retried
+func TestWriteBackAddFailNoRetry(t *testing.T) {
+	wb, cancel := newTestWriteBack(t)
+	defer cancel()
+
+	pi := newPutItem(t)
+
+	id := wb.Add(0, "one", true, pi.put)
+	wbItem := wb.lookup[id]
+	checkOnHeap(t, wb, wbItem)
+	checkInLookup(t, wb, wbItem)
+
+	<-pi.started
+	checkNotOnHeap(t, wb, wbItem)
+	checkInLookup(t, wb, wbItem)
+
+	pi.finish(fserrors.NoRetryError(errors.New("transfer failed BOOM")))
+	waitUntilNoTransfers(t, wb)
+	checkNotOnHeap(t, wb, wbItem)
+	checkNotInLookup(t, wb, wbItem)
+}
+
+// Test uploads are held while offline and started when back online
+func TestWriteBackOffline(t *testing.T) {
+	wb, cancel := newTestWriteBack(t)
+	defer cancel()
+
+	wb.SetOffline(true)
+	assert.True(t, wb.Offline())
+
+	pi := newPutItem(t)
+
+	id := wb.Add(0, "one", true, pi.put)
+	wbItem := wb.lookup[id]
+	checkOnHeap(t, wb, wbItem)
+	checkInLookup(t, wb, wbItem)
+
+	// wait for longer than the writeback time
+	time.Sleep(2 * wb.opt.WriteBack)
+	select {
+	case <-pi.started:
+		t.Fatal("upload started while offline")
+	default:
+	}
+	checkOnHeap(t, wb, wbItem)
+	_, queued := wb.Stats()
+	assert.Equal(t, 1, queued)
+
+	wb.SetOffline(false)
+	assert.False(t, wb.Offline())
+
+	<-pi.started
+	checkNotOnHeap(t, wb, wbItem)
+	checkInLookup(t, wb, wbItem)
+
+	pi.finish(nil) // transfer successful
+	waitUntilNoTransfers(t, wb)
+	checkNotOnHeap(t, wb, wbItem)
+	checkNotInLookup(t, wb, wbItem)
+}
+
 // Now test the upload being cancelled by another upload being added
 func TestWriteBackAddUpdate(t *testing.T) {
 	wb, cancel := newTestWriteBack(t)
@@ -491,6 +554,52 @@ func TestWriteBackGetStats(t *testing.T) {
 
 }
 
+func TestWriteBackQueue(t *testing.T) {
+	wb, cancel := newTestWriteBack(t)
+	defer cancel()
+
+	pi1 := newPutItem(t)
+	pi2 := newPutItem(t)
+
+	id1 := wb.Add(0, "one", true, pi1.put)
+	id2 := wb.Add(0, "two", true, pi2.put)
+
+	// delay the first item so the second goes first
+	require.NoError(t, wb.SetExpiry(id1, time.Now().Add(time.Hour)))
+	queue := wb.Queue()
+	require.Equal(t, 2, len(queue))
+	assert.Equal(t, "two", queue[0].Name)
+	assert.Equal(t, id2, queue[0].ID)
+	assert.Equal(t, "one", queue[1].Name)
+	assert.True(t, queue[1].Expiry > 3500)
+
+	assert.Error(t, wb.SetExpiry(Handle(1234), time.Now()))
+
+	// the second item uploads, fails and is retried
+	<-pi2.started
+	assert.Error(t, wb.SetExpiry(id2, time.Now()))
+	queue = wb.Queue()
+	assert.Equal(t, "two", queue[0].Name)
+	assert.True(t, queue[0].Uploading)
+	pi2.finish(errors.New("upload failed BOOM"))
+	waitUntilNoTransfers(t, wb)
+	queue = wb.Queue()
+	require.Equal(t, 2, len(queue))
+	assert.Equal(t, "two", queue[0].Name)
+	assert.Equal(t, 1, queue[0].Tries)
+	assert.Equal(t, "upload failed BOOM", queue[0].Error)
+	<-pi2.started
+	pi2.finish(nil)
+	waitUntilNoTransfers(t, wb)
+
+	// make the first upload happen now
+	require.NoError(t, wb.SetExpiry(id1, time.Now()))
+	<-pi1.started
+	pi1.finish(nil)
+	waitUntilNoTransfers(t, wb)
+	assert.Equal(t, 0, len(wb.Queue()))
+}
+
 // Test queuing more than fs.Config.Transfers
 func TestWriteBackMaxQueue(t *testing.T) {
 	wb, cancel := newTestWriteBack(t)
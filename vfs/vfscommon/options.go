@@ -15,6 +15,8 @@ type Options struct {
 	ReadOnly          bool          // if set VFS is read only
 	NoModTime         bool          // don't read mod times for files
 	DirCacheTime      time.Duration // how long to consider directory listing cache valid
+	DirCachePersist   bool          // if set store directory listings on disk
+	DirCacheMaxSize   fs.SizeSuffix // max total size of directory listings stored on disk
 	PollInterval      time.Duration
 	Umask             int
 	UID               uint32
@@ -23,14 +25,24 @@ type Options struct {
 	FilePerms         os.FileMode
 	ChunkSize         fs.SizeSuffix // if > 0 read files in chunks
 	ChunkSizeLimit    fs.SizeSuffix // if > ChunkSize double the chunk size after each chunk until reached
+	ReadStreams       int           // number of parallel streams to fetch a file into the cache with
+	ReadStreamChunk   fs.SizeSuffix // size of each range fetched by a stream
 	CacheMode         CacheMode
 	CacheMaxAge       time.Duration
 	CacheMaxSize      fs.SizeSuffix
+	CacheMinFreeSpace fs.SizeSuffix // evict files to keep this much free space on the cache file system
 	CachePollInterval time.Duration
+	CacheEncrypt      bool   // if set encrypt the files in the cache
+	CacheEncryptPass  string `json:"-"` // password to encrypt the cache with - if empty use --password-command - not shown by rc
 	CaseInsensitive   bool
+	Links             bool          // show objects with fs.LinkSuffix as symbolic links
+	LockRemote        bool          // if set publish advisory locks on the remote
+	LockLease         time.Duration // how long a lock published on the remote lasts unless renewed
 	WriteWait         time.Duration // time to wait for in-sequence write
 	ReadWait          time.Duration // time to wait for in-sequence read
 	WriteBack         time.Duration // time to wait before writing back dirty files
+	Pin               []string      // globs of files to keep in the cache
+	PinRefresh        time.Duration // how often to list pinned directories for changes
 }
 
 // DefaultOpt is the default values uses for Opt
@@ -39,6 +51,7 @@ var DefaultOpt = Options{
 	NoChecksum:        false,
 	NoSeek:            false,
 	DirCacheTime:      5 * 60 * time.Second,
+	DirCacheMaxSize:   -1,
 	PollInterval:      time.Minute,
 	ReadOnly:          false,
 	Umask:             0,
@@ -51,9 +64,14 @@ var DefaultOpt = Options{
 	CachePollInterval: 60 * time.Second,
 	ChunkSize:         128 * fs.MebiByte,
 	ChunkSizeLimit:    -1,
+	ReadStreams:       0,
+	ReadStreamChunk:   16 * fs.MebiByte,
 	CacheMaxSize:      -1,
+	CacheMinFreeSpace: -1,
+	LockLease:         time.Minute,
 	CaseInsensitive:   runtime.GOOS == "windows" || runtime.GOOS == "darwin", // default to true on Windows and Mac, false otherwise
 	WriteWait:         1000 * time.Millisecond,
 	ReadWait:          20 * time.Millisecond,
 	WriteBack:         5 * time.Second,
+	PinRefresh:        time.Hour,
 }
@@ -0,0 +1,197 @@
+package vfsdircache
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// Object is an fs.Object made from a stored directory listing.
+//
+// The metadata in the listing is returned directly. Anything else
+// finds the real object on the remote first.
+type Object struct {
+	f        fs.Fs
+	remote   string
+	size     int64
+	modTime  time.Time // IsZero if not stored
+	hashes   map[hash.Type]string
+	id       string
+	mimeType string
+
+	mu   sync.Mutex
+	o    fs.Object // the real object once found
+	tier string
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Object          = (*Object)(nil)
+	_ fs.IDer            = (*Object)(nil)
+	_ fs.MimeTyper       = (*Object)(nil)
+	_ fs.GetTierer       = (*Object)(nil)
+	_ fs.SetTierer       = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+)
+
+// resolve finds the real object on the remote
+func (o *Object) resolve(ctx context.Context) (fs.Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.o != nil {
+		return o.o, nil
+	}
+	obj, err := o.f.NewObject(ctx, o.remote)
+	if err != nil {
+		return nil, err
+	}
+	o.o = obj
+	return obj, nil
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// String returns a description of the Object
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// ModTime returns the modification date of the file
+//
+// If it wasn't stored it is read from the remote.
+func (o *Object) ModTime(ctx context.Context) time.Time {
+	if !o.modTime.IsZero() {
+		return o.modTime
+	}
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		fs.Debugf(o, "vfs dir cache: failed to read modification time: %v", err)
+		return time.Now()
+	}
+	return obj.ModTime(ctx)
+}
+
+// Size returns the size of the file
+func (o *Object) Size() int64 {
+	return o.size
+}
+
+// Hash returns the selected checksum of the file
+//
+// If it wasn't stored it is read from the remote.
+func (o *Object) Hash(ctx context.Context, ty hash.Type) (string, error) {
+	if sum, ok := o.hashes[ty]; ok {
+		return sum, nil
+	}
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return obj.Hash(ctx, ty)
+}
+
+// ID returns the ID of the Object if known, or "" if not
+func (o *Object) ID() string {
+	return o.id
+}
+
+// Storable says whether this object can be stored
+func (o *Object) Storable() bool {
+	return true
+}
+
+// SetModTime sets the metadata on the object to set the modification date
+func (o *Object) SetModTime(ctx context.Context, t time.Time) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.SetModTime(ctx, t)
+}
+
+// Open opens the file for read. Call Close() on the returned io.ReadCloser
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Open(ctx, options...)
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Update(ctx, in, src, options...)
+}
+
+// Remove this object
+func (o *Object) Remove(ctx context.Context) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Remove(ctx)
+}
+
+// MimeType returns the content type of the Object if known, or "" if not
+func (o *Object) MimeType(ctx context.Context) string {
+	return o.mimeType
+}
+
+// GetTier returns storage tier or class of the Object
+func (o *Object) GetTier() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.tier
+}
+
+// SetTier performs changing storage tier of the Object if
+// multiple storage classes supported
+func (o *Object) SetTier(tier string) error {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		return err
+	}
+	do, ok := obj.(fs.SetTierer)
+	if !ok {
+		return errors.New("vfs dir cache: underlying remote does not support SetTier")
+	}
+	err = do.SetTier(tier)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	o.tier = tier
+	o.mu.Unlock()
+	return nil
+}
+
+// UnWrap returns the real object on the remote so that server side
+// operations can be used on it, or nil if it can't be found
+func (o *Object) UnWrap() fs.Object {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		fs.Debugf(o, "vfs dir cache: failed to find object: %v", err)
+		return nil
+	}
+	return obj
+}
@@ -0,0 +1,516 @@
+// Package vfsdircache stores the VFS directory listings on disk so
+// they can be used again when the VFS is restarted
+package vfsdircache
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// listingName is the name of the file the listing of a directory is
+// stored in. It is stored in the directory with the same path as the
+// directory on the remote under the cache root.
+const listingName = ".rclone-dir.json"
+
+// listingVersion is the version of the listing format. Listings with
+// a different version are ignored.
+const listingVersion = 2
+
+// ErrorNotFound is returned by Get if the directory isn't stored
+var ErrorNotFound = errors.New("directory listing not in the cache")
+
+// Cache stores directory listings on disk
+type Cache struct {
+	// read only - no locking needed to read these
+	f          fs.Fs              // the remote the listings are from
+	opt        *vfscommon.Options // vfs Options
+	root       string             // root of the cache directory
+	hashes     hash.Set           // hashes to store if any
+	useModTime bool               // set if the modification times should be stored
+
+	stores chan storeRequest // listings waiting to be stored by the storer
+	done   chan struct{}     // closed when the storer has exited
+
+	mu   sync.Mutex // protects the following variables
+	dirs int        // number of listings stored
+	used int64      // total size of the listings stored
+	gen  uint64     // incremented whenever a listing is forgotten
+}
+
+// storeRequest is a listing waiting to be stored
+type storeRequest struct {
+	dir     string
+	entries fs.DirEntries
+	read    time.Time
+	gen     uint64 // Cache.gen when the listing was read
+}
+
+// listing is the on disk format of a directory listing
+type listing struct {
+	Version int       `json:"version"`
+	Dir     string    `json:"dir"`
+	Read    time.Time `json:"read"`
+	Entries []entry   `json:"entries"`
+}
+
+// entry is the on disk format of a single directory entry
+type entry struct {
+	Name     string            `json:"name"`
+	IsDir    bool              `json:"isDir,omitempty"`
+	Size     int64             `json:"size"`
+	ModTime  time.Time         `json:"modTime"`
+	Items    int64             `json:"items,omitempty"`
+	ID       string            `json:"id,omitempty"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Tier     string            `json:"tier,omitempty"`
+}
+
+// New creates a new directory listing cache for f
+//
+// This starts background goroutines to keep the cache under
+// --vfs-dir-cache-max-size and to store the listings passed to Store
+// which can be cancelled with the context passed in.
+func New(ctx context.Context, f fs.Fs, opt *vfscommon.Options) (*Cache, error) {
+	fRoot := filepath.FromSlash(f.Root())
+	if runtime.GOOS == "windows" {
+		if strings.HasPrefix(fRoot, `\\?`) {
+			fRoot = fRoot[3:]
+		}
+		fRoot = strings.Replace(fRoot, ":", "", -1)
+	}
+	root := file.UNCPath(filepath.Join(config.CacheDir, "vfsDir", f.Name(), fRoot))
+	fs.Debugf(nil, "vfs dir cache: root is %q", root)
+	err := os.MkdirAll(root, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make dir cache directory")
+	}
+
+	features := f.Features()
+	c := &Cache{
+		f:          f,
+		opt:        opt,
+		root:       root,
+		useModTime: !opt.NoModTime && !features.SlowModTime,
+		stores:     make(chan storeRequest, 64),
+		done:       make(chan struct{}),
+	}
+	if !features.SlowHash {
+		c.hashes = f.Hashes()
+	}
+
+	c.clean()
+	go c.cleaner(ctx)
+	go c.storer(ctx)
+
+	return c, nil
+}
+
+// toOSPath returns the OS path of the listing of dir
+func (c *Cache) toOSPath(dir string) string {
+	return filepath.Join(c.root, filepath.FromSlash(dir), listingName)
+}
+
+// toOSDir returns the OS path of the directory holding the listing
+// of dir and the listings of all the directories below it
+func (c *Cache) toOSDir(dir string) string {
+	return filepath.Join(c.root, filepath.FromSlash(dir))
+}
+
+// Get returns the stored listing of dir and the time it was read
+// from the remote.
+//
+// It returns ErrorNotFound if there isn't one.
+func (c *Cache) Get(ctx context.Context, dir string) (entries fs.DirEntries, read time.Time, err error) {
+	if c == nil {
+		return nil, read, ErrorNotFound
+	}
+	osPath := c.toOSPath(dir)
+	data, err := ioutil.ReadFile(osPath)
+	if os.IsNotExist(err) {
+		return nil, read, ErrorNotFound
+	} else if err != nil {
+		return nil, read, errors.Wrap(err, "vfs dir cache: failed to read listing")
+	}
+	var l listing
+	err = json.Unmarshal(data, &l)
+	if err != nil || l.Version != listingVersion || l.Dir != dir {
+		fs.Debugf(dir, "vfs dir cache: discarding bad listing: %v", err)
+		c.Forget(dir)
+		return nil, read, ErrorNotFound
+	}
+
+	// Mark the listing as used for the cleaner
+	now := time.Now()
+	if err := os.Chtimes(osPath, now, now); err != nil {
+		fs.Debugf(dir, "vfs dir cache: failed to set access time: %v", err)
+	}
+
+	entries = make(fs.DirEntries, 0, len(l.Entries))
+	for _, e := range l.Entries {
+		remote := e.Name
+		if dir != "" {
+			remote = dir + "/" + e.Name
+		}
+		if e.IsDir {
+			d := fs.NewDir(remote, e.ModTime).SetSize(e.Size).SetItems(e.Items).SetID(e.ID)
+			entries = append(entries, d)
+			continue
+		}
+		o := &Object{
+			f:        c.f,
+			remote:   remote,
+			size:     e.Size,
+			modTime:  e.ModTime,
+			id:       e.ID,
+			mimeType: e.MimeType,
+			tier:     e.Tier,
+		}
+		if len(e.Hashes) > 0 {
+			o.hashes = make(map[hash.Type]string, len(e.Hashes))
+			for name, sum := range e.Hashes {
+				var ht hash.Type
+				if ht.Set(name) == nil {
+					o.hashes[ht] = sum
+				}
+			}
+		}
+		entries = append(entries, o)
+	}
+	fs.Debugf(dir, "vfs dir cache: loaded %d entries read at %v", len(entries), l.Read)
+	return entries, l.Read, nil
+}
+
+// Store queues the listing of dir read from the remote at read to be
+// stored in the background so the caller doesn't wait for it.
+//
+// If the listing is forgotten before it is stored it isn't stored.
+func (c *Cache) Store(dir string, entries fs.DirEntries, read time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	req := storeRequest{
+		dir:     dir,
+		entries: entries,
+		read:    read,
+		gen:     c.gen,
+	}
+	c.mu.Unlock()
+	select {
+	case c.stores <- req:
+	default:
+		fs.Debugf(dir, "vfs dir cache: not storing listing as too many are waiting")
+	}
+}
+
+// storer stores the listings passed to Store until the context is
+// cancelled, then stores any still waiting
+func (c *Cache) storer(ctx context.Context) {
+	defer close(c.done)
+	store := func(req storeRequest) {
+		err := c.put(context.Background(), req)
+		if err != nil {
+			fs.Errorf(req.dir, "Failed to store directory listing: %v", err)
+		}
+	}
+	for {
+		select {
+		case req := <-c.stores:
+			store(req)
+		case <-ctx.Done():
+			for {
+				select {
+				case req := <-c.stores:
+					store(req)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Wait waits for the listings queued by Store to be stored once the
+// context passed to New has been cancelled
+func (c *Cache) Wait() {
+	if c == nil {
+		return
+	}
+	<-c.done
+}
+
+// Put stores the listing of dir read from the remote at read
+func (c *Cache) Put(ctx context.Context, dir string, entries fs.DirEntries, read time.Time) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	gen := c.gen
+	c.mu.Unlock()
+	return c.put(ctx, storeRequest{
+		dir:     dir,
+		entries: entries,
+		read:    read,
+		gen:     gen,
+	})
+}
+
+// put stores the listing in req
+func (c *Cache) put(ctx context.Context, req storeRequest) error {
+	dir, entries, read := req.dir, req.entries, req.read
+	l := listing{
+		Version: listingVersion,
+		Dir:     dir,
+		Read:    read,
+		Entries: make([]entry, 0, len(entries)),
+	}
+	for _, item := range entries {
+		e := entry{
+			Name: path.Base(item.Remote()),
+			Size: item.Size(),
+		}
+		switch x := item.(type) {
+		case fs.Directory:
+			e.IsDir = true
+			e.ModTime = x.ModTime(ctx)
+			e.Items = x.Items()
+			e.ID = x.ID()
+		case fs.Object:
+			if c.useModTime {
+				e.ModTime = x.ModTime(ctx)
+			}
+			if do, ok := x.(fs.IDer); ok {
+				e.ID = do.ID()
+			}
+			if do, ok := x.(fs.MimeTyper); ok {
+				e.MimeType = do.MimeType(ctx)
+			}
+			if do, ok := x.(fs.GetTierer); ok {
+				e.Tier = do.GetTier()
+			}
+			for _, ht := range c.hashes.Array() {
+				sum, err := x.Hash(ctx, ht)
+				if err == nil && sum != "" {
+					if e.Hashes == nil {
+						e.Hashes = make(map[string]string)
+					}
+					e.Hashes[ht.String()] = sum
+				}
+			}
+		}
+		l.Entries = append(l.Entries, e)
+	}
+	data, err := json.Marshal(&l)
+	if err != nil {
+		return errors.Wrap(err, "vfs dir cache: failed to encode listing")
+	}
+
+	osPath := c.toOSPath(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if req.gen != c.gen {
+		fs.Debugf(dir, "vfs dir cache: not storing listing as listings were forgotten since it was read")
+		return nil
+	}
+	err = os.MkdirAll(filepath.Dir(osPath), 0700)
+	if err != nil {
+		return errors.Wrap(err, "vfs dir cache: failed to make directory")
+	}
+	tmpPath := osPath + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "vfs dir cache: failed to write listing")
+	}
+	oldSize, existed := c._size(osPath)
+	err = os.Rename(tmpPath, osPath)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.Wrap(err, "vfs dir cache: failed to store listing")
+	}
+	if existed {
+		c.used -= oldSize
+	} else {
+		c.dirs++
+	}
+	c.used += int64(len(data))
+	return nil
+}
+
+// _size returns the size of the file at osPath and whether it exists
+//
+// call with mu held
+func (c *Cache) _size(osPath string) (size int64, exists bool) {
+	fi, err := os.Stat(osPath)
+	if err != nil {
+		return 0, false
+	}
+	return fi.Size(), true
+}
+
+// Forget removes the stored listing of dir
+func (c *Cache) Forget(dir string) {
+	if c == nil {
+		return
+	}
+	osPath := c.toOSPath(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	size, exists := c._size(osPath)
+	if !exists {
+		return
+	}
+	err := os.Remove(osPath)
+	if err != nil {
+		fs.Errorf(dir, "vfs dir cache: failed to remove listing: %v", err)
+		return
+	}
+	fs.Debugf(dir, "vfs dir cache: forgot listing")
+	c.dirs--
+	c.used -= size
+}
+
+// ForgetAll removes the stored listings of dir and all the
+// directories below it
+func (c *Cache) ForgetAll(dir string) {
+	if c == nil {
+		return
+	}
+	osDir := c.toOSDir(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	dirs, used := walkListings(osDir, nil)
+	if dirs == 0 {
+		return
+	}
+	err := os.RemoveAll(osDir)
+	if err != nil {
+		fs.Errorf(dir, "vfs dir cache: failed to remove listings: %v", err)
+		// recount as we don't know what got removed
+		c.dirs, c.used = walkListings(c.root, nil)
+		return
+	}
+	fs.Debugf(dir, "vfs dir cache: forgot %d listings", dirs)
+	c.dirs -= dirs
+	c.used -= used
+}
+
+// CleanUp removes all the stored listings
+func (c *Cache) CleanUp() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs, c.used = 0, 0
+	return os.RemoveAll(c.root)
+}
+
+// walkListings counts the listings under osDir and their total size,
+// calling fn for each one if set
+func walkListings(osDir string, fn func(osPath string, fi os.FileInfo)) (dirs int, used int64) {
+	_ = filepath.Walk(osDir, func(osPath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			// ignore errors - the directory may not exist
+			return nil
+		}
+		if fi.IsDir() || fi.Name() != listingName {
+			return nil
+		}
+		dirs++
+		used += fi.Size()
+		if fn != nil {
+			fn(osPath, fi)
+		}
+		return nil
+	})
+	return dirs, used
+}
+
+// clean counts the stored listings and removes the least recently
+// used ones if they are over --vfs-dir-cache-max-size
+func (c *Cache) clean() {
+	type listingFile struct {
+		osPath string
+		size   int64
+		atime  time.Time
+	}
+	var files []listingFile
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs, c.used = walkListings(c.root, func(osPath string, fi os.FileInfo) {
+		files = append(files, listingFile{osPath: osPath, size: fi.Size(), atime: fi.ModTime()})
+	})
+	maxSize := int64(c.opt.DirCacheMaxSize)
+	if maxSize <= 0 || c.used <= maxSize {
+		return
+	}
+	oldDirs, oldUsed := c.dirs, c.used
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].atime.Before(files[j].atime)
+	})
+	for _, lf := range files {
+		if c.used <= maxSize {
+			break
+		}
+		err := os.Remove(lf.osPath)
+		if err != nil {
+			fs.Errorf(nil, "vfs dir cache: failed to remove listing: %v", err)
+			continue
+		}
+		c.dirs--
+		c.used -= lf.size
+	}
+	fs.Infof(nil, "vfs dir cache: cleaned: listings %d (was %d), total size %v (was %v)", c.dirs, oldDirs, fs.SizeSuffix(c.used), fs.SizeSuffix(oldUsed))
+}
+
+// cleaner calls clean at regular intervals
+//
+// doesn't return until context is cancelled
+func (c *Cache) cleaner(ctx context.Context) {
+	if c.opt.CachePollInterval <= 0 {
+		fs.Debugf(nil, "vfs dir cache: cleaning thread disabled because poll interval <= 0")
+		return
+	}
+	timer := time.NewTicker(c.opt.CachePollInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			c.clean()
+		case <-ctx.Done():
+			fs.Debugf(nil, "vfs dir cache: cleaner exiting")
+			return
+		}
+	}
+}
+
+// Stats returns info about the Cache
+func (c *Cache) Stats() (out rc.Params) {
+	out = make(rc.Params)
+	out["path"] = c.root
+	c.mu.Lock()
+	out["dirs"] = c.dirs
+	out["bytesUsed"] = c.used
+	c.mu.Unlock()
+	return out
+}
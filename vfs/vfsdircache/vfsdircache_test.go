@@ -0,0 +1,170 @@
+package vfsdircache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local" // import the local backend
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+var t1 = fstest.Time("2001-02-03T04:05:06.499999999Z")
+
+func newTestCache(t *testing.T, opt vfscommon.Options) (r *fstest.Run, c *Cache, cleanup func()) {
+	r = fstest.NewRun(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := New(ctx, r.Fremote, &opt)
+	require.NoError(t, err)
+	cleanup = func() {
+		require.NoError(t, c.CleanUp())
+		_, err := os.Stat(c.root)
+		assert.True(t, os.IsNotExist(err))
+		cancel()
+		r.Finalise()
+	}
+	return r, c, cleanup
+}
+
+// read the listing of dir from the remote and store it
+func putDir(t *testing.T, r *fstest.Run, c *Cache, dir string) fs.DirEntries {
+	ctx := context.Background()
+	entries, err := list.DirSorted(ctx, r.Fremote, false, dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Put(ctx, dir, entries, time.Now()))
+	return entries
+}
+
+func TestCachePutGet(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	r, c, cleanup := newTestCache(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	file1 := r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+	r.WriteObject(ctx, "dir/sub/file2", "file2", t1)
+
+	_, _, err := c.Get(ctx, "dir")
+	assert.Equal(t, ErrorNotFound, err)
+
+	before := time.Now()
+	want := putDir(t, r, c, "dir")
+	putDir(t, r, c, "dir/sub")
+	stats := c.Stats()
+	assert.Equal(t, 2, stats["dirs"])
+	assert.True(t, stats["bytesUsed"].(int64) > 0)
+
+	got, read, err := c.Get(ctx, "dir")
+	require.NoError(t, err)
+	assert.False(t, read.Before(before))
+	require.Equal(t, len(want), len(got))
+	for i := range want {
+		assert.Equal(t, want[i].Remote(), got[i].Remote())
+		assert.Equal(t, want[i].Size(), got[i].Size())
+	}
+	o, ok := got[0].(*Object)
+	require.True(t, ok)
+	assert.Equal(t, file1.Path, o.Remote())
+	fstest.AssertTimeEqualWithPrecision(t, o.Remote(), file1.ModTime, o.ModTime(ctx), r.Fremote.Precision())
+	_, ok = got[1].(fs.Directory)
+	assert.True(t, ok)
+
+	// check the real object is found when needed
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.NotNil(t, o.o)
+	assert.Equal(t, o.o, o.UnWrap())
+	assert.Equal(t, o.o, fs.UnWrapObject(o))
+
+	// forget a single listing
+	c.Forget("dir")
+	_, _, err = c.Get(ctx, "dir")
+	assert.Equal(t, ErrorNotFound, err)
+	_, _, err = c.Get(ctx, "dir/sub")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Stats()["dirs"])
+
+	// forget a tree of listings
+	putDir(t, r, c, "dir")
+	c.ForgetAll("dir")
+	_, _, err = c.Get(ctx, "dir/sub")
+	assert.Equal(t, ErrorNotFound, err)
+	assert.Equal(t, 0, c.Stats()["dirs"])
+	assert.Equal(t, int64(0), c.Stats()["bytesUsed"])
+}
+
+func TestCacheClean(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	r, c, cleanup := newTestCache(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	r.WriteObject(ctx, "a/file", "contents", t1)
+	r.WriteObject(ctx, "b/file", "contents", t1)
+	putDir(t, r, c, "a")
+	putDir(t, r, c, "b")
+
+	// make a the least recently used
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(c.toOSPath("a"), old, old))
+
+	// limit the size to just over one listing
+	used := c.Stats()["bytesUsed"].(int64)
+	c.opt.DirCacheMaxSize = fs.SizeSuffix(used/2 + 1)
+	c.clean()
+
+	_, _, err := c.Get(ctx, "a")
+	assert.Equal(t, ErrorNotFound, err)
+	_, _, err = c.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Stats()["dirs"])
+}
+
+func TestCacheStore(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := New(ctx, r.Fremote, &opt)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c.CleanUp())
+	}()
+
+	r.WriteObject(ctx, "dir/file", "contents", t1)
+	entries, err := list.DirSorted(ctx, r.Fremote, false, "dir")
+	require.NoError(t, err)
+
+	// A listing read before a Forget isn't stored
+	c.mu.Lock()
+	gen := c.gen
+	c.mu.Unlock()
+	c.Forget("dir")
+	require.NoError(t, c.put(ctx, storeRequest{dir: "dir", entries: entries, read: time.Now(), gen: gen}))
+	_, _, err = c.Get(ctx, "dir")
+	assert.Equal(t, ErrorNotFound, err)
+
+	// Listings queued are stored before Wait returns
+	c.Store("dir", entries, time.Now())
+	cancel()
+	c.Wait()
+	got, _, err := c.Get(ctx, "dir")
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
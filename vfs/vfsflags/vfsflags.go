@@ -22,19 +22,31 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.NoChecksum, "no-checksum", "", Opt.NoChecksum, "Don't compare checksums on up/download.")
 	flags.BoolVarP(flagSet, &Opt.NoSeek, "no-seek", "", Opt.NoSeek, "Don't allow seeking in files.")
 	flags.DurationVarP(flagSet, &Opt.DirCacheTime, "dir-cache-time", "", Opt.DirCacheTime, "Time to cache directory entries for.")
+	flags.BoolVarP(flagSet, &Opt.DirCachePersist, "vfs-dir-cache-persist", "", Opt.DirCachePersist, "Store directory listings on disk so they can be used after a restart.")
+	flags.FVarP(flagSet, &Opt.DirCacheMaxSize, "vfs-dir-cache-max-size", "", "Max total size of directory listings stored on disk.")
 	flags.DurationVarP(flagSet, &Opt.PollInterval, "poll-interval", "", Opt.PollInterval, "Time to wait between polling for changes. Must be smaller than dir-cache-time. Only on supported remotes. Set to 0 to disable.")
 	flags.BoolVarP(flagSet, &Opt.ReadOnly, "read-only", "", Opt.ReadOnly, "Mount read-only.")
 	flags.FVarP(flagSet, &Opt.CacheMode, "vfs-cache-mode", "", "Cache mode off|minimal|writes|full")
 	flags.DurationVarP(flagSet, &Opt.CachePollInterval, "vfs-cache-poll-interval", "", Opt.CachePollInterval, "Interval to poll the cache for stale objects.")
 	flags.DurationVarP(flagSet, &Opt.CacheMaxAge, "vfs-cache-max-age", "", Opt.CacheMaxAge, "Max age of objects in the cache.")
 	flags.FVarP(flagSet, &Opt.CacheMaxSize, "vfs-cache-max-size", "", "Max total size of objects in the cache.")
+	flags.FVarP(flagSet, &Opt.CacheMinFreeSpace, "vfs-cache-min-free-space", "", "Evict files from the cache to keep this much free space on the cache file system.")
+	flags.BoolVarP(flagSet, &Opt.CacheEncrypt, "vfs-cache-encrypt", "", Opt.CacheEncrypt, "Encrypt the files in the cache.")
+	flags.StringVarP(flagSet, &Opt.CacheEncryptPass, "vfs-cache-encrypt-password", "", Opt.CacheEncryptPass, "Password to encrypt the cache with. If not set --password-command is used.")
 	flags.FVarP(flagSet, &Opt.ChunkSize, "vfs-read-chunk-size", "", "Read the source objects in chunks.")
 	flags.FVarP(flagSet, &Opt.ChunkSizeLimit, "vfs-read-chunk-size-limit", "", "If greater than --vfs-read-chunk-size, double the chunk size after each chunk read, until the limit is reached. 'off' is unlimited.")
+	flags.IntVarP(flagSet, &Opt.ReadStreams, "vfs-read-streams", "", Opt.ReadStreams, "Number of parallel streams to read files into the cache with in --vfs-cache-mode full. 0 or 1 to use a single stream.")
+	flags.FVarP(flagSet, &Opt.ReadStreamChunk, "vfs-read-stream-chunk-size", "", "Size of the range each --vfs-read-streams stream reads.")
 	flags.FVarP(flagSet, DirPerms, "dir-perms", "", "Directory permissions")
 	flags.FVarP(flagSet, FilePerms, "file-perms", "", "File permissions")
+	flags.BoolVarP(flagSet, &Opt.Links, "vfs-links", "", Opt.Links, "Translate symlinks to/from regular files with a '.rclonelink' extension.")
+	flags.BoolVarP(flagSet, &Opt.LockRemote, "vfs-lock-remote", "", Opt.LockRemote, "Publish advisory locks on the remote so rclone instances sharing it cooperate.")
+	flags.DurationVarP(flagSet, &Opt.LockLease, "vfs-lock-lease", "", Opt.LockLease, "How long a lock published on the remote lasts unless renewed.")
 	flags.BoolVarP(flagSet, &Opt.CaseInsensitive, "vfs-case-insensitive", "", Opt.CaseInsensitive, "If a file name not found, find a case insensitive match.")
 	flags.DurationVarP(flagSet, &Opt.WriteWait, "vfs-write-wait", "", Opt.WriteWait, "Time to wait for in-sequence write before giving error.")
 	flags.DurationVarP(flagSet, &Opt.ReadWait, "vfs-read-wait", "", Opt.ReadWait, "Time to wait for in-sequence read before seeking.")
 	flags.DurationVarP(flagSet, &Opt.WriteBack, "vfs-write-back", "", Opt.WriteBack, "Time to writeback files after last use when using cache.")
+	flags.StringArrayVarP(flagSet, &Opt.Pin, "vfs-pin", "", Opt.Pin, "Keep files matching this glob in the cache (may be repeated).")
+	flags.DurationVarP(flagSet, &Opt.PinRefresh, "vfs-pin-refresh", "", Opt.PinRefresh, "Interval to list pinned directories for changes if the remote can't notify them.")
 	platformFlags(flagSet)
 }
@@ -0,0 +1,119 @@
+// Extended attributes for files
+
+package vfs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// XattrPrefix is the prefix of the names of all the extended
+// attributes a File has
+const XattrPrefix = "user.rclone."
+
+// Names of the extended attributes which aren't hashes
+const (
+	XattrMimeType = XattrPrefix + "mimetype" // MIME type of the object
+	XattrTier     = XattrPrefix + "tier"     // storage tier of the object
+	XattrID       = XattrPrefix + "id"       // ID of the object on the remote
+)
+
+// XattrHash returns the name of the extended attribute holding the
+// hash of type ht, eg "user.rclone.md5" or "user.rclone.sha1"
+func XattrHash(ht hash.Type) string {
+	return XattrPrefix + strings.ToLower(strings.Replace(ht.String(), "-", "", -1))
+}
+
+// Listxattr returns the names of the extended attributes of the file.
+//
+// These are the hashes the remote supports, the MIME type and the
+// tier and ID if the object has them.  A file which is being
+// written has no extended attributes.
+func (f *File) Listxattr() (names []string) {
+	o := f.getObject()
+	if o == nil {
+		return nil
+	}
+	for _, ht := range o.Fs().Hashes().Array() {
+		names = append(names, XattrHash(ht))
+	}
+	names = append(names, XattrMimeType)
+	if do, ok := o.(fs.GetTierer); ok && do.GetTier() != "" {
+		names = append(names, XattrTier)
+	}
+	if do, ok := o.(fs.IDer); ok && do.ID() != "" {
+		names = append(names, XattrID)
+	}
+	return names
+}
+
+// Getxattr returns the value of the extended attribute called name
+// or ENOATTR if the file doesn't have it.
+//
+// Reading a hash may be slow if the remote has to calculate it.
+func (f *File) Getxattr(name string) (value []byte, err error) {
+	o := f.getObject()
+	if o == nil || !strings.HasPrefix(name, XattrPrefix) {
+		return nil, ENOATTR
+	}
+	var s string
+	switch name {
+	case XattrMimeType:
+		s = fs.MimeType(context.TODO(), o)
+	case XattrTier:
+		if do, ok := o.(fs.GetTierer); ok {
+			s = do.GetTier()
+		}
+	case XattrID:
+		if do, ok := o.(fs.IDer); ok {
+			s = do.ID()
+		}
+	default:
+		for _, ht := range o.Fs().Hashes().Array() {
+			if name == XattrHash(ht) {
+				s, err = o.Hash(context.TODO(), ht)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+	if s == "" {
+		return nil, ENOATTR
+	}
+	return []byte(s), nil
+}
+
+// Setxattr sets the extended attribute called name to value.
+//
+// Only the tier can be set, and only on remotes which support
+// changing it.  All the other attributes are read only.
+func (f *File) Setxattr(name string, value []byte) error {
+	if f.d.vfs.Opt.ReadOnly {
+		return EROFS
+	}
+	if name != XattrTier {
+		if !strings.HasPrefix(name, XattrPrefix) {
+			return ENOSYS
+		}
+		return EPERM
+	}
+	o := f.getObject()
+	if o == nil {
+		return EPERM
+	}
+	do, ok := o.(fs.SetTierer)
+	if !ok {
+		return ENOSYS
+	}
+	err := do.SetTier(string(value))
+	if err != nil {
+		fs.Errorf(f, "Failed to set tier: %v", err)
+		return err
+	}
+	return nil
+}
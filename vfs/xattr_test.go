@@ -0,0 +1,42 @@
+package vfs
+
+import (
+	"crypto/md5"
+	"fmt"
+	"testing"
+
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileXattr(t *testing.T) {
+	r, _, file, _, cleanup := fileCreate(t, vfscommon.CacheModeOff)
+	defer cleanup()
+
+	names := file.Listxattr()
+	assert.Contains(t, names, XattrMimeType)
+	for _, ht := range r.Fremote.Hashes().Array() {
+		assert.Contains(t, names, XattrHash(ht))
+	}
+
+	value, err := file.Getxattr(XattrMimeType)
+	require.NoError(t, err)
+	assert.NotEqual(t, "", string(value))
+
+	if r.Fremote.Hashes().Contains(hash.MD5) {
+		assert.Equal(t, "user.rclone.md5", XattrHash(hash.MD5))
+		value, err = file.Getxattr(XattrHash(hash.MD5))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("file1 contents"))), string(value))
+	}
+
+	_, err = file.Getxattr("user.rclone.potato")
+	assert.Equal(t, ENOATTR, err)
+	_, err = file.Getxattr("user.potato")
+	assert.Equal(t, ENOATTR, err)
+
+	assert.Equal(t, EPERM, file.Setxattr(XattrMimeType, []byte("text/plain")))
+	assert.Equal(t, ENOSYS, file.Setxattr("user.potato", []byte("potato")))
+}